@@ -16,8 +16,11 @@ import (
 	"github.com/streamingfast/shutter"
 	"github.com/streamingfast/substreams/client"
 	"github.com/streamingfast/substreams/metrics"
+	"github.com/streamingfast/substreams/orchestrator/stage"
+	"github.com/streamingfast/substreams/orchestrator/work"
 	"github.com/streamingfast/substreams/pipeline"
 	"github.com/streamingfast/substreams/service"
+	"github.com/streamingfast/substreams/storage/compression"
 	"github.com/streamingfast/substreams/wasm"
 	"go.uber.org/atomic"
 	"go.uber.org/zap"
@@ -52,7 +55,21 @@ type Tier1Config struct {
 	WASMExtensions  []wasm.WASMExtensioner
 	PipelineOptions []pipeline.PipelineOptioner
 
-	Tracing bool
+	Tracing              bool
+	SortedStoreSnapshots bool   // write full stores snapshots in the sorted format, loaded lazily
+	CacheCompression     string // codec used to compress the stores snapshots and execution outputs: "none" (default), "zstd" or "snappy"
+	WasmRuntime          string // wasm runtime executing the modules: "wazero" (default) or "wasmtime" (binaries built with cgo only)
+	WasmFuelMetering     bool   // measure the fuel consumed by each module, reported in the module stats and metering events
+	MaxWasmMemoryPages   uint32 // limit the linear memory of each module instance to that many pages of 64KiB, 0 for no limit
+
+	WasmCompilationCacheDir      string // directory where the compiled modules are kept across requests and restarts, disabled if empty
+	WasmCompilationCacheMaxBytes uint64 // maximum size of the compilation cache, wasm.DefaultCompilationCacheMaxBytes if 0
+
+	DeterminismCheckInterval uint64 // replay the modules executed on the blocks multiple of it to detect non-determinism, 0 to disable
+
+	SchedulingStrategy string // strategy picking the next job of parallel processing: "breadth-first" (default) or "critical-path"
+
+	LocalWorkers uint64 // run the jobs of parallel processing in this process, at most that many at the same time, instead of sending them to SubrequestsEndpoint, 0 to use tier2
 }
 
 type Tier1App struct {
@@ -160,6 +177,74 @@ func (a *Tier1App) Run() error {
 		opts = append(opts, service.WithModuleExecutionTracing())
 	}
 
+	if a.config.SortedStoreSnapshots {
+		opts = append(opts, service.WithSortedStoreSnapshots())
+	}
+
+	if a.config.WasmRuntime != "" {
+		if err := wasm.ValidateRuntime(a.config.WasmRuntime); err != nil {
+			return fmt.Errorf("invalid wasm runtime: %w", err)
+		}
+		opts = append(opts, service.WithWasmRuntime(a.config.WasmRuntime))
+	}
+
+	if a.config.WasmFuelMetering {
+		opts = append(opts, service.WithWasmFuelMetering())
+	}
+
+	if a.config.MaxWasmMemoryPages != 0 {
+		if a.config.MaxWasmMemoryPages > wasm.MaxMemoryPages {
+			return fmt.Errorf("invalid max wasm memory pages %d, must be at most %d", a.config.MaxWasmMemoryPages, wasm.MaxMemoryPages)
+		}
+		opts = append(opts, service.WithMaxWasmMemoryPages(a.config.MaxWasmMemoryPages))
+	}
+
+	if a.config.WasmCompilationCacheDir != "" {
+		maxBytes := a.config.WasmCompilationCacheMaxBytes
+		if maxBytes == 0 {
+			maxBytes = wasm.DefaultCompilationCacheMaxBytes
+		}
+		cache, err := wasm.OpenCompilationCache(a.config.WasmCompilationCacheDir, maxBytes)
+		if err != nil {
+			return fmt.Errorf("opening wasm compilation cache: %w", err)
+		}
+		opts = append(opts, service.WithWasmCompilationCache(cache))
+	}
+
+	if a.config.DeterminismCheckInterval != 0 {
+		opts = append(opts, service.WithDeterminismCheck(a.config.DeterminismCheckInterval))
+	}
+
+	if a.config.SchedulingStrategy != "" {
+		if _, err := stage.NewSchedulingStrategy(a.config.SchedulingStrategy, 0); err != nil {
+			return fmt.Errorf("invalid scheduling strategy: %w", err)
+		}
+		opts = append(opts, service.WithSchedulingStrategy(a.config.SchedulingStrategy))
+	}
+
+	cacheCompression, err := compression.ParseCodec(a.config.CacheCompression)
+	if err != nil {
+		return fmt.Errorf("invalid cache compression: %w", err)
+	}
+	if cacheCompression != compression.None {
+		opts = append(opts, service.WithCacheCompression(cacheCompression))
+	}
+
+	if a.config.LocalWorkers != 0 {
+		tier2, err := service.NewTier2(
+			a.logger.Named("local-tier2"),
+			mergedBlocksStore,
+			stateStore,
+			a.config.StateStoreDefaultTag,
+			a.config.StateBundleSize,
+			opts...,
+		)
+		if err != nil {
+			return fmt.Errorf("creating local tier2 service: %w", err)
+		}
+		opts = append(opts, service.WithWorkerFactory(work.NewLocalWorkerFactory(tier2.ProcessLocalRange, int(a.config.LocalWorkers))))
+	}
+
 	svc, err := service.NewTier1(
 		a.logger,
 		mergedBlocksStore,
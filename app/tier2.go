@@ -12,6 +12,7 @@ import (
 	"github.com/streamingfast/substreams/metrics"
 	"github.com/streamingfast/substreams/pipeline"
 	"github.com/streamingfast/substreams/service"
+	"github.com/streamingfast/substreams/storage/compression"
 	"github.com/streamingfast/substreams/wasm"
 	"go.uber.org/atomic"
 	"go.uber.org/zap"
@@ -29,7 +30,19 @@ type Tier2Config struct {
 	WASMExtensions  []wasm.WASMExtensioner
 	PipelineOptions []pipeline.PipelineOptioner
 
-	Tracing bool
+	Tracing              bool
+	SortedStoreSnapshots bool   // write full stores snapshots in the sorted format, loaded lazily
+	CacheCompression     string // codec used to compress the stores snapshots and execution outputs: "none" (default), "zstd" or "snappy"
+	WasmRuntime          string // wasm runtime executing the modules: "wazero" (default) or "wasmtime" (binaries built with cgo only)
+	WasmFuelMetering     bool   // measure the fuel consumed by each module, reported in the module stats and metering events
+	MaxWasmMemoryPages   uint32 // limit the linear memory of each module instance to that many pages of 64KiB, 0 for no limit
+
+	WasmCompilationCacheDir      string // directory where the compiled modules are kept across requests and restarts, disabled if empty
+	WasmCompilationCacheMaxBytes uint64 // maximum size of the compilation cache, wasm.DefaultCompilationCacheMaxBytes if 0
+
+	DeterminismCheckInterval uint64 // replay the modules executed on the blocks multiple of it to detect non-determinism, 0 to disable
+
+	RequestsCapacity uint64 // number of concurrent requests this server is sized for, used to report its load to tier1, 0 to not report it
 }
 
 type Tier2App struct {
@@ -86,6 +99,56 @@ func (a *Tier2App) Run() error {
 		opts = append(opts, service.WithModuleExecutionTracing())
 	}
 
+	if a.config.SortedStoreSnapshots {
+		opts = append(opts, service.WithSortedStoreSnapshots())
+	}
+
+	if a.config.WasmRuntime != "" {
+		if err := wasm.ValidateRuntime(a.config.WasmRuntime); err != nil {
+			return fmt.Errorf("invalid wasm runtime: %w", err)
+		}
+		opts = append(opts, service.WithWasmRuntime(a.config.WasmRuntime))
+	}
+
+	if a.config.WasmFuelMetering {
+		opts = append(opts, service.WithWasmFuelMetering())
+	}
+
+	if a.config.MaxWasmMemoryPages != 0 {
+		if a.config.MaxWasmMemoryPages > wasm.MaxMemoryPages {
+			return fmt.Errorf("invalid max wasm memory pages %d, must be at most %d", a.config.MaxWasmMemoryPages, wasm.MaxMemoryPages)
+		}
+		opts = append(opts, service.WithMaxWasmMemoryPages(a.config.MaxWasmMemoryPages))
+	}
+
+	if a.config.WasmCompilationCacheDir != "" {
+		maxBytes := a.config.WasmCompilationCacheMaxBytes
+		if maxBytes == 0 {
+			maxBytes = wasm.DefaultCompilationCacheMaxBytes
+		}
+		cache, err := wasm.OpenCompilationCache(a.config.WasmCompilationCacheDir, maxBytes)
+		if err != nil {
+			return fmt.Errorf("opening wasm compilation cache: %w", err)
+		}
+		opts = append(opts, service.WithWasmCompilationCache(cache))
+	}
+
+	if a.config.DeterminismCheckInterval != 0 {
+		opts = append(opts, service.WithDeterminismCheck(a.config.DeterminismCheckInterval))
+	}
+
+	if a.config.RequestsCapacity != 0 {
+		opts = append(opts, service.WithRequestsCapacity(a.config.RequestsCapacity))
+	}
+
+	cacheCompression, err := compression.ParseCodec(a.config.CacheCompression)
+	if err != nil {
+		return fmt.Errorf("invalid cache compression: %w", err)
+	}
+	if cacheCompression != compression.None {
+		opts = append(opts, service.WithCacheCompression(cacheCompression))
+	}
+
 	svc, err := service.NewTier2(
 		a.logger,
 		mergedBlocksStore,
@@ -0,0 +1,199 @@
+// Package blockfilter parses and evaluates the queries of the block filters of modules,
+// matched against the keys emitted by block index modules.
+package blockfilter
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Query is a parsed block filter query, matched against the keys emitted by a block
+// index module for a block.
+//
+// Keys are combined with `&&` (and), `||` (or), `-` (not) and parentheses. Keys
+// separated by spaces are combined with `&&`, which binds tighter than `||`:
+//
+//	type:transfer (contract:0xa0b8 || contract:0xdac1) -from:0x0000
+type Query struct {
+	raw  string
+	root queryNode
+}
+
+// ParseQuery parses a block filter query, see Query.
+func ParseQuery(in string) (*Query, error) {
+	p := &queryParser{in: in}
+	p.next()
+	if p.token == "" {
+		return nil, fmt.Errorf("empty query")
+	}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid query %q: %w", in, err)
+	}
+	if p.token != "" {
+		return nil, fmt.Errorf("invalid query %q: unexpected %q at offset %d", in, p.token, p.tokenOffset)
+	}
+	return &Query{raw: in, root: root}, nil
+}
+
+// Matches returns true if the keys, for which `hasKey` returns true, match the query.
+func (q *Query) Matches(hasKey func(key string) bool) bool {
+	return q.root.matches(hasKey)
+}
+
+// MatchesKeys returns true if `keys` match the query.
+func (q *Query) MatchesKeys(keys []string) bool {
+	set := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		set[key] = true
+	}
+	return q.Matches(func(key string) bool { return set[key] })
+}
+
+func (q *Query) String() string {
+	return q.raw
+}
+
+type queryNode interface {
+	matches(hasKey func(key string) bool) bool
+}
+
+type keyNode string
+type notNode struct{ node queryNode }
+type andNode []queryNode
+type orNode []queryNode
+
+func (n keyNode) matches(hasKey func(key string) bool) bool { return hasKey(string(n)) }
+func (n notNode) matches(hasKey func(key string) bool) bool { return !n.node.matches(hasKey) }
+
+func (n andNode) matches(hasKey func(key string) bool) bool {
+	for _, node := range n {
+		if !node.matches(hasKey) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n orNode) matches(hasKey func(key string) bool) bool {
+	for _, node := range n {
+		if node.matches(hasKey) {
+			return true
+		}
+	}
+	return false
+}
+
+type queryParser struct {
+	in  string
+	pos int
+
+	token       string // current token, empty at the end of the input
+	tokenOffset int
+}
+
+func (p *queryParser) next() {
+	for p.pos < len(p.in) && unicode.IsSpace(rune(p.in[p.pos])) {
+		p.pos++
+	}
+	p.tokenOffset = p.pos
+	if p.pos == len(p.in) {
+		p.token = ""
+		return
+	}
+
+	rest := p.in[p.pos:]
+	switch {
+	case strings.HasPrefix(rest, "&&"), strings.HasPrefix(rest, "||"):
+		p.token = rest[:2]
+	case rest[0] == '(' || rest[0] == ')' || rest[0] == '-':
+		p.token = rest[:1]
+	default:
+		end := strings.IndexFunc(rest, func(r rune) bool {
+			return unicode.IsSpace(r) || r == '(' || r == ')' || r == '&' || r == '|'
+		})
+		if end == -1 {
+			end = len(rest)
+		}
+		if end == 0 {
+			// a lone '&' or '|'
+			end = 1
+		}
+		p.token = rest[:end]
+	}
+	p.pos += len(p.token)
+}
+
+func (p *queryParser) parseOr() (queryNode, error) {
+	var nodes orNode
+	for {
+		node, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+		if p.token != "||" {
+			break
+		}
+		p.next()
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+func (p *queryParser) parseAnd() (queryNode, error) {
+	var nodes andNode
+	for {
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+		if p.token == "&&" {
+			p.next()
+			continue
+		}
+		if p.token == "" || p.token == "||" || p.token == ")" {
+			break
+		}
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+func (p *queryParser) parseUnary() (queryNode, error) {
+	switch p.token {
+	case "":
+		return nil, fmt.Errorf("unexpected end of query")
+	case "-":
+		p.next()
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{node}, nil
+	case "(":
+		p.next()
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.token != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis at offset %d", p.tokenOffset)
+		}
+		p.next()
+		return node, nil
+	case ")", "&&", "||", "&", "|":
+		return nil, fmt.Errorf("unexpected %q at offset %d", p.token, p.tokenOffset)
+	}
+
+	node := keyNode(p.token)
+	p.next()
+	return node, nil
+}
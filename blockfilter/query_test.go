@@ -0,0 +1,67 @@
+package blockfilter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryMatchesKeys(t *testing.T) {
+	tests := []struct {
+		query    string
+		keys     []string
+		expected bool
+	}{
+		{"type:transfer", []string{"type:transfer"}, true},
+		{"type:transfer", []string{"type:mint"}, false},
+		{"type:transfer", nil, false},
+		{"a b", []string{"a", "b"}, true},
+		{"a b", []string{"a"}, false},
+		{"a && b", []string{"a", "b"}, true},
+		{"a || b", []string{"b"}, true},
+		{"a || b", []string{"c"}, false},
+		{"-a", nil, true},
+		{"-a", []string{"a"}, false},
+		{"a || b c", []string{"b"}, false},
+		{"a || b c", []string{"a"}, true},
+		{"(a || b) c", []string{"b", "c"}, true},
+		{"(a || b) c", []string{"a"}, false},
+		{"-(a || b)", []string{"c"}, true},
+		{"-(a || b)", []string{"b"}, false},
+		{"type:transfer (contract:0xa0b8 || contract:0xdac1) -from:0x0000", []string{"type:transfer", "contract:0xdac1"}, true},
+		{"type:transfer (contract:0xa0b8 || contract:0xdac1) -from:0x0000", []string{"type:transfer", "contract:0xdac1", "from:0x0000"}, false},
+		{"key-with-dash", []string{"key-with-dash"}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			query, err := ParseQuery(test.query)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, query.MatchesKeys(test.keys))
+			assert.Equal(t, test.query, query.String())
+		})
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		query       string
+		expectedErr string
+	}{
+		{"", "empty query"},
+		{"   ", "empty query"},
+		{"a &&", `invalid query "a &&": unexpected end of query`},
+		{"|| a", `invalid query "|| a": unexpected "||" at offset 0`},
+		{"(a || b", `invalid query "(a || b": missing closing parenthesis at offset 7`},
+		{"a)", `invalid query "a)": unexpected ")" at offset 1`},
+		{"a & b", `invalid query "a & b": unexpected "&" at offset 2`},
+		{"-", `invalid query "-": unexpected end of query`},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			_, err := ParseQuery(test.query)
+			require.Error(t, err)
+			assert.Equal(t, test.expectedErr, err.Error())
+		})
+	}
+}
@@ -46,6 +46,7 @@ func (cs *ConnectServer) Blocks(
 		StartCursor:                         req.Msg.StartCursor,
 		FinalBlocksOnly:                     req.Msg.FinalBlocksOnly,
 		OutputModule:                        req.Msg.OutputModule,
+		OutputModules:                       req.Msg.OutputModules,
 		Modules:                             req.Msg.Modules,
 		DebugInitialStoreSnapshotForModules: req.Msg.DebugInitialStoreSnapshotForModules,
 	}
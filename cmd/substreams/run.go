@@ -35,6 +35,8 @@ func init() {
 	runCmd.Flags().Bool("production-mode", false, "Enable Production Mode, with high-speed parallel processing")
 	runCmd.Flags().Bool("skip-package-validation", false, "Do not perform any validation when reading substreams package")
 	runCmd.Flags().StringArrayP("params", "p", nil, "Set a params for parameterizable modules. Can be specified multiple times. Ex: -p module1=valA -p module2=valX&valY")
+	runCmd.Flags().String("wasm-runtime", "", "WASM runtime executing the modules on the server, 'wazero' or 'wasmtime' (sent as the X-Sf-Substreams-Wasm-Runtime header), defaults to the server's configured runtime")
+	runCmd.Flags().String("log-level", "", "Only print the module logs at this level or above: 'trace', 'debug', 'info', 'warn' or 'error' (logs without a level are 'info'), all of them if empty")
 	runCmd.Flags().String("test-file", "", "runs a test file")
 	runCmd.Flags().Bool("test-verbose", false, "print out all the results")
 	rootCmd.AddCommand(runCmd)
@@ -180,7 +182,13 @@ func runRun(cmd *cobra.Command, args []string) error {
 		toPrint = []string{outputModule}
 	}
 
+	minLogLevel, err := pbsubstreamsrpc.ParseLogLevel(mustGetString(cmd, "log-level"))
+	if err != nil {
+		return fmt.Errorf("invalid --log-level: %w", err)
+	}
+
 	ui := tui.New(req, pkg, toPrint)
+	ui.SetMinLogLevel(minLogLevel)
 	if err := ui.Init(outputMode); err != nil {
 		return fmt.Errorf("TUI initialization: %w", err)
 	}
@@ -202,6 +210,9 @@ func runRun(cmd *cobra.Command, args []string) error {
 	}
 	//parse additional-headers flag
 	additionalHeaders := mustGetStringSlice(cmd, "header")
+	if wasmRuntime := mustGetString(cmd, "wasm-runtime"); wasmRuntime != "" {
+		additionalHeaders = append(additionalHeaders, "X-Sf-Substreams-Wasm-Runtime: "+wasmRuntime)
+	}
 	if additionalHeaders != nil {
 		res := parseHeaders(additionalHeaders)
 		headerArray := make([]string, 0, len(res)*2)
@@ -89,6 +89,10 @@ var UpdatePoliciesMap = map[string]string{
 	manifest.UpdatePolicyMin:            "Min",
 	manifest.UpdatePolicyMax:            "Max",
 	manifest.UpdatePolicyAppend:         "Append",
+	manifest.UpdatePolicySetSum:         "SetSum",
+	manifest.UpdatePolicyBitwiseOr:      "BitwiseOr",
+	manifest.UpdatePolicyBitwiseAnd:     "BitwiseAnd",
+	manifest.UpdatePolicyCardinality:    "Cardinality",
 }
 
 type Generator struct {
@@ -7,7 +7,10 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/streamingfast/substreams/manifest"
 )
 
 //func TestGenerator_ModRs(t *testing.T) {
@@ -197,3 +200,25 @@ func TestGenerate_GenerateSubstreams(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, string(expectedMod), string(out))
 }
+
+func TestEngine_WritableStoreType(t *testing.T) {
+	engine := &Engine{Manifest: &manifest.Manifest{}}
+
+	tests := []struct {
+		updatePolicy string
+		valueType    string
+		expected     string
+	}{
+		{manifest.UpdatePolicySetSum, "int64", "substreams::store::StoreSetSumInt64"},
+		{manifest.UpdatePolicyBitwiseOr, "int64", "substreams::store::StoreBitwiseOrInt64"},
+		{manifest.UpdatePolicyBitwiseAnd, "bigint", "substreams::store::StoreBitwiseAndBigInt"},
+		{manifest.UpdatePolicyCardinality, "bytes", "substreams::store::StoreCardinalityRaw"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.updatePolicy, func(t *testing.T) {
+			module := &manifest.Module{UpdatePolicy: test.updatePolicy, ValueType: test.valueType}
+			assert.Equal(t, test.expected, engine.WritableStoreType(module))
+		})
+	}
+}
@@ -0,0 +1,94 @@
+// Package dataset holds the static key-value datasets that modules reference in the
+// manifest and look up deterministically through the `dataset` wasm extension namespace.
+//
+// A dataset is content-addressed: its content is a serialized `sf.substreams.v1.StoreEntries`
+// sorted by key, identified by its sha256 hash, which is part of the hash of the modules
+// referencing it.
+package dataset
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"google.golang.org/protobuf/proto"
+
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+)
+
+// Dataset is the decoded content of a dataset, immutable and safe for concurrent use.
+type Dataset struct {
+	Hash    string
+	entries []*pbsubstreams.StoreEntry // sorted by key, without duplicates
+}
+
+// Hash returns the hex-encoded sha256 hash of `content`.
+func Hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Encode sorts `entries` by key and serializes them as the content of a dataset, the
+// same entries always give the same content. Duplicated keys are rejected.
+func Encode(entries []*pbsubstreams.StoreEntry) ([]byte, error) {
+	sorted := make([]*pbsubstreams.StoreEntry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Key < sorted[j].Key })
+
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Key == sorted[i-1].Key {
+			return nil, fmt.Errorf("duplicate key %q", sorted[i].Key)
+		}
+	}
+
+	return proto.MarshalOptions{Deterministic: true}.Marshal(&pbsubstreams.StoreEntries{Entries: sorted})
+}
+
+// Decode verifies that `content` matches `expectedHash` and decodes it.
+func Decode(content []byte, expectedHash string) (*Dataset, error) {
+	if hash := Hash(content); hash != expectedHash {
+		return nil, fmt.Errorf("content hash %s does not match expected hash %s", hash, expectedHash)
+	}
+
+	entries := &pbsubstreams.StoreEntries{}
+	if err := proto.Unmarshal(content, entries); err != nil {
+		return nil, fmt.Errorf("unmarshalling entries: %w", err)
+	}
+
+	for i := 1; i < len(entries.Entries); i++ {
+		if entries.Entries[i].Key <= entries.Entries[i-1].Key {
+			return nil, fmt.Errorf("entries are not sorted by key, or have duplicates, at key %q", entries.Entries[i].Key)
+		}
+	}
+
+	return &Dataset{
+		Hash:    expectedHash,
+		entries: entries.Entries,
+	}, nil
+}
+
+// Len returns the number of entries of the dataset.
+func (d *Dataset) Len() int {
+	return len(d.entries)
+}
+
+// Get returns the entry with `key`, nil if there is none.
+func (d *Dataset) Get(key string) *pbsubstreams.StoreEntry {
+	idx := sort.Search(len(d.entries), func(i int) bool { return d.entries[i].Key >= key })
+	if idx < len(d.entries) && d.entries[idx].Key == key {
+		return d.entries[idx]
+	}
+	return nil
+}
+
+// Floor returns the entry with the greatest key lower or equal to `key`, nil if there is
+// none. With keys suffixed by zero-padded block numbers, it returns the latest value at a
+// given block.
+func (d *Dataset) Floor(key string) *pbsubstreams.StoreEntry {
+	idx := sort.Search(len(d.entries), func(i int) bool { return d.entries[i].Key > key })
+	if idx == 0 {
+		return nil
+	}
+	return d.entries[idx-1]
+}
@@ -0,0 +1,85 @@
+package dataset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+)
+
+func TestEncode(t *testing.T) {
+	content, err := Encode([]*pbsubstreams.StoreEntry{
+		{Key: "b", Value: []byte("2")},
+		{Key: "a", Value: []byte("1")},
+	})
+	require.NoError(t, err)
+
+	reversed, err := Encode([]*pbsubstreams.StoreEntry{
+		{Key: "a", Value: []byte("1")},
+		{Key: "b", Value: []byte("2")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, content, reversed, "content should not depend on the entries order")
+
+	_, err = Encode([]*pbsubstreams.StoreEntry{
+		{Key: "a", Value: []byte("1")},
+		{Key: "a", Value: []byte("2")},
+	})
+	assert.ErrorContains(t, err, `duplicate key "a"`)
+}
+
+func TestDecode(t *testing.T) {
+	content, err := Encode([]*pbsubstreams.StoreEntry{{Key: "a", Value: []byte("1")}})
+	require.NoError(t, err)
+
+	ds, err := Decode(content, Hash(content))
+	require.NoError(t, err)
+	assert.Equal(t, 1, ds.Len())
+
+	_, err = Decode(content, Hash([]byte("other")))
+	assert.ErrorContains(t, err, "does not match expected hash")
+}
+
+func TestDataset_Lookups(t *testing.T) {
+	content, err := Encode([]*pbsubstreams.StoreEntry{
+		{Key: "price:eth:00000100", Value: []byte("1000")},
+		{Key: "price:eth:00000200", Value: []byte("2000")},
+		{Key: "price:eth:00000300", Value: []byte("3000")},
+	})
+	require.NoError(t, err)
+	ds, err := Decode(content, Hash(content))
+	require.NoError(t, err)
+
+	tests := []struct {
+		key           string
+		expectGet     string
+		expectFloor   string
+		expectNoFloor bool
+	}{
+		{key: "price:eth:00000050", expectNoFloor: true},
+		{key: "price:eth:00000100", expectGet: "1000", expectFloor: "1000"},
+		{key: "price:eth:00000150", expectFloor: "1000"},
+		{key: "price:eth:00000300", expectGet: "3000", expectFloor: "3000"},
+		{key: "price:eth:00000400", expectFloor: "3000"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.key, func(t *testing.T) {
+			if entry := ds.Get(test.key); test.expectGet == "" {
+				assert.Nil(t, entry)
+			} else {
+				require.NotNil(t, entry)
+				assert.Equal(t, test.expectGet, string(entry.Value))
+			}
+
+			if entry := ds.Floor(test.key); test.expectNoFloor {
+				assert.Nil(t, entry)
+			} else {
+				require.NotNil(t, entry)
+				assert.Equal(t, test.expectFloor, string(entry.Value))
+			}
+		})
+	}
+}
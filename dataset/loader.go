@@ -0,0 +1,117 @@
+package dataset
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/streamingfast/dstore"
+	"go.uber.org/zap"
+
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+)
+
+// Loader loads the datasets referenced by the requests, from the content packed in the
+// package or from their url. Datasets being content-addressed, the most recently used
+// ones are kept in memory by hash and shared between requests.
+type Loader struct {
+	maxCached int
+
+	mu      sync.Mutex
+	lru     *list.List // of *Dataset, most recently used first
+	byHash  map[string]*list.Element
+	loading map[string]*sync.Mutex
+}
+
+func NewLoader(maxCached int) *Loader {
+	return &Loader{
+		maxCached: maxCached,
+		lru:       list.New(),
+		byHash:    map[string]*list.Element{},
+		loading:   map[string]*sync.Mutex{},
+	}
+}
+
+// Load returns the decoded content of `def`, verified against its hash.
+func (l *Loader) Load(ctx context.Context, def *pbsubstreams.Dataset) (*Dataset, error) {
+	if def.Hash == "" {
+		return nil, fmt.Errorf("dataset %q has no hash", def.Name)
+	}
+
+	if ds := l.cached(def.Hash); ds != nil {
+		return ds, nil
+	}
+
+	// Only load a given dataset once when requests referencing it start concurrently
+	l.mu.Lock()
+	loading := l.loading[def.Hash]
+	if loading == nil {
+		loading = &sync.Mutex{}
+		l.loading[def.Hash] = loading
+	}
+	l.mu.Unlock()
+
+	loading.Lock()
+	defer loading.Unlock()
+
+	if ds := l.cached(def.Hash); ds != nil {
+		return ds, nil
+	}
+
+	content := def.Content
+	if len(content) == 0 {
+		if def.Url == "" {
+			return nil, fmt.Errorf("dataset %q has neither content nor url", def.Name)
+		}
+
+		var err error
+		content, err = fetch(ctx, def.Url)
+		if err != nil {
+			return nil, fmt.Errorf("fetching dataset %q: %w", def.Name, err)
+		}
+	}
+
+	ds, err := Decode(content, def.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("decoding dataset %q: %w", def.Name, err)
+	}
+	zlog.Debug("loaded dataset", zap.String("name", def.Name), zap.String("version", def.Version), zap.String("hash", def.Hash), zap.Int("entries", ds.Len()))
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.loading, def.Hash)
+	l.byHash[def.Hash] = l.lru.PushFront(ds)
+	for l.lru.Len() > l.maxCached {
+		evicted := l.lru.Remove(l.lru.Back()).(*Dataset)
+		delete(l.byHash, evicted.Hash)
+	}
+
+	return ds, nil
+}
+
+func (l *Loader) cached(hash string) *Dataset {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, found := l.byHash[hash]; found {
+		l.lru.MoveToFront(elem)
+		return elem.Value.(*Dataset)
+	}
+	return nil
+}
+
+func fetch(ctx context.Context, url string) ([]byte, error) {
+	reader, _, _, err := dstore.OpenObject(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("opening %q: %w", url, err)
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("reading %q: %w", url, err)
+	}
+	return content, nil
+}
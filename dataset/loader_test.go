@@ -0,0 +1,48 @@
+package dataset
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+)
+
+func TestLoader_Load(t *testing.T) {
+	ctx := context.Background()
+	content, err := Encode([]*pbsubstreams.StoreEntry{{Key: "a", Value: []byte("1")}})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "dataset.binpb")
+	require.NoError(t, os.WriteFile(path, content, 0644))
+
+	loader := NewLoader(1)
+	def := &pbsubstreams.Dataset{Name: "test", Hash: Hash(content), Url: "file://" + path}
+
+	ds, err := loader.Load(ctx, def)
+	require.NoError(t, err)
+	assert.Equal(t, "1", string(ds.Get("a").Value))
+
+	// served from the cache once loaded
+	require.NoError(t, os.Remove(path))
+	cached, err := loader.Load(ctx, def)
+	require.NoError(t, err)
+	assert.Same(t, ds, cached)
+
+	// the content packed in the package is verified as well
+	_, err = loader.Load(ctx, &pbsubstreams.Dataset{Name: "corrupted", Hash: Hash([]byte("other")), Content: content})
+	assert.ErrorContains(t, err, `decoding dataset "corrupted"`)
+
+	// evicted from the cache by another dataset
+	other, err := Encode([]*pbsubstreams.StoreEntry{{Key: "b", Value: []byte("2")}})
+	require.NoError(t, err)
+	_, err = loader.Load(ctx, &pbsubstreams.Dataset{Name: "other", Hash: Hash(other), Content: other})
+	require.NoError(t, err)
+
+	_, err = loader.Load(ctx, def)
+	assert.ErrorContains(t, err, `fetching dataset "test"`)
+}
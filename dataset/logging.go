@@ -0,0 +1,7 @@
+package dataset
+
+import (
+	"github.com/streamingfast/logging"
+)
+
+var zlog, _ = logging.PackageLogger("dataset", "github.com/streamingfast/substreams/dataset")
@@ -141,6 +141,9 @@ func Basic(pkg *pbsubstreams.Package, graph *manifest.ModuleGraph) (*BasicInfo,
 			modInfo.Kind = "store"
 			modInfo.ValueType = strPtr(v.KindStore.ValueType)
 			modInfo.UpdatePolicy = strPtr(v.KindStore.UpdatePolicy.Pretty())
+		case *pbsubstreams.Module_KindBlockIndex_:
+			modInfo.Kind = "blockIndex"
+			modInfo.OutputType = strPtr(v.KindBlockIndex.OutputType)
 		default:
 			modInfo.Kind = "unknown"
 		}
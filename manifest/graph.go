@@ -61,6 +61,12 @@ func NewModuleGraph(modules []*pbsubstreams.Module) (*ModuleGraph, error) {
 
 			g.inputOrderIndex[module.Name][moduleName] = j
 		}
+
+		if filter := module.BlockFilter; filter != nil {
+			if j, found := g.moduleIndex[filter.Module]; found {
+				g.AddCost(i, j, 1)
+			}
+		}
 	}
 
 	if !graph.Acyclic(g) {
@@ -342,7 +348,8 @@ func (g *ModuleGraph) HasStatefulDependencies(moduleName string) (bool, error) {
 	return false, nil
 }
 
-func (g *ModuleGraph) StoresDownTo(moduleName string) ([]*pbsubstreams.Module, error) {
+// StoresDownTo returns the stores required to run `moduleNames`, in reverse topological order.
+func (g *ModuleGraph) StoresDownTo(moduleNames ...string) ([]*pbsubstreams.Module, error) {
 	alreadyAdded := map[string]bool{}
 	topologicalIndex := map[string]int{}
 
@@ -356,25 +363,27 @@ func (g *ModuleGraph) StoresDownTo(moduleName string) ([]*pbsubstreams.Module, e
 	}
 
 	var res []*pbsubstreams.Module
-	if _, found := g.moduleIndex[moduleName]; !found {
-		return nil, fmt.Errorf("could not find module %s in graph", moduleName)
-	}
+	for _, moduleName := range moduleNames {
+		if _, found := g.moduleIndex[moduleName]; !found {
+			return nil, fmt.Errorf("could not find module %s in graph", moduleName)
+		}
 
-	_, distances := graph.ShortestPaths(g, g.moduleIndex[moduleName])
+		_, distances := graph.ShortestPaths(g, g.moduleIndex[moduleName])
 
-	for i, d := range distances {
-		if d >= 0 { // connected node or myself
-			module := g.indexIndex[i]
-			if module.GetKindStore() == nil {
-				continue
-			}
+		for i, d := range distances {
+			if d >= 0 { // connected node or myself
+				module := g.indexIndex[i]
+				if module.GetKindStore() == nil {
+					continue
+				}
 
-			if _, ok := alreadyAdded[module.Name]; ok {
-				continue
-			}
+				if _, ok := alreadyAdded[module.Name]; ok {
+					continue
+				}
 
-			res = append(res, module)
-			alreadyAdded[module.Name] = true
+				res = append(res, module)
+				alreadyAdded[module.Name] = true
+			}
 		}
 	}
 
@@ -423,7 +432,9 @@ func (g *ModuleGraph) ParentStoresOf(moduleName string) ([]*pbsubstreams.Modules
 	return nil, nil
 }
 
-func (g *ModuleGraph) ModulesDownTo(moduleName string) ([]*pbsubstreams.Module, error) {
+// ModulesDownTo returns `moduleNames` and all the modules required to run them, in
+// reverse topological order.
+func (g *ModuleGraph) ModulesDownTo(moduleNames ...string) ([]*pbsubstreams.Module, error) {
 	alreadyAdded := map[string]bool{}
 	topologicalIndex := map[string]int{}
 
@@ -437,21 +448,23 @@ func (g *ModuleGraph) ModulesDownTo(moduleName string) ([]*pbsubstreams.Module,
 	}
 
 	var res []*pbsubstreams.Module
-	if _, found := g.moduleIndex[moduleName]; !found {
-		return nil, fmt.Errorf("could not find module %s in graph", moduleName)
-	}
+	for _, moduleName := range moduleNames {
+		if _, found := g.moduleIndex[moduleName]; !found {
+			return nil, fmt.Errorf("could not find module %s in graph", moduleName)
+		}
 
-	_, distances := graph.ShortestPaths(g, g.moduleIndex[moduleName])
+		_, distances := graph.ShortestPaths(g, g.moduleIndex[moduleName])
 
-	for i, d := range distances {
-		if d >= 0 { // connected node or myself
-			module := g.indexIndex[i]
-			if _, ok := alreadyAdded[module.Name]; ok {
-				continue
-			}
+		for i, d := range distances {
+			if d >= 0 { // connected node or myself
+				module := g.indexIndex[i]
+				if _, ok := alreadyAdded[module.Name]; ok {
+					continue
+				}
 
-			res = append(res, module)
-			alreadyAdded[module.Name] = true
+				res = append(res, module)
+				alreadyAdded[module.Name] = true
+			}
 		}
 	}
 
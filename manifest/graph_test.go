@@ -122,6 +122,35 @@ func TestModuleGraph_ModulesDownTo(t *testing.T) {
 	assert.Equal(t, []string{"Am", "As", "B", "C", "D", "E", "G"}, res)
 }
 
+func TestModuleGraph_ModulesDownTo_Multiple(t *testing.T) {
+	g, err := NewModuleGraph(testModules)
+	assert.NoError(t, err)
+
+	mods, err := g.ModulesDownTo("D", "F")
+	assert.NoError(t, err)
+
+	var res []string
+	for _, p := range mods {
+		res = append(res, p.Name)
+	}
+
+	sort.Strings(res)
+
+	assert.Equal(t, []string{"Am", "As", "B", "C", "D", "F"}, res)
+
+	stores, err := g.StoresDownTo("D", "F")
+	assert.NoError(t, err)
+
+	res = nil
+	for _, p := range stores {
+		res = append(res, p.Name)
+	}
+
+	sort.Strings(res)
+
+	assert.Equal(t, []string{"As", "B", "F"}, res)
+}
+
 func TestModuleGraph_StoresDownTo(t *testing.T) {
 	g, err := NewModuleGraph(testModules)
 	assert.NoError(t, err)
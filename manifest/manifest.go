@@ -12,6 +12,7 @@ import (
 
 	"gopkg.in/yaml.v3"
 
+	"github.com/streamingfast/substreams/blockfilter"
 	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
 )
 
@@ -24,20 +25,25 @@ func init() {
 }
 
 const (
-	ModuleKindStore = "store"
-	ModuleKindMap   = "map"
+	ModuleKindStore      = "store"
+	ModuleKindMap        = "map"
+	ModuleKindBlockIndex = "blockIndex"
 )
 
+// BlockIndexOutputType is the output type of the block index modules.
+const BlockIndexOutputType = "proto:sf.substreams.index.v1.Keys"
+
 // Manifest is a YAML structure used to create a Package and its list
 // of Modules. The notion of a manifest does not live in protobuf definitions.
 type Manifest struct {
-	SpecVersion string            `yaml:"specVersion"` // check that it equals v0.1.0
-	Package     PackageMeta       `yaml:"package"`
-	Protobuf    Protobuf          `yaml:"protobuf"`
-	Imports     mapSlice          `yaml:"imports"`
-	Binaries    map[string]Binary `yaml:"binaries"`
-	Modules     []*Module         `yaml:"modules"`
-	Params      map[string]string `yaml:"params"`
+	SpecVersion string             `yaml:"specVersion"` // check that it equals v0.1.0
+	Package     PackageMeta        `yaml:"package"`
+	Protobuf    Protobuf           `yaml:"protobuf"`
+	Imports     mapSlice           `yaml:"imports"`
+	Binaries    map[string]Binary  `yaml:"binaries"`
+	Datasets    map[string]Dataset `yaml:"datasets"`
+	Modules     []*Module          `yaml:"modules"`
+	Params      map[string]string  `yaml:"params"`
 
 	Network  string                    `yaml:"network"`
 	Networks map[string]*NetworkParams `yaml:"networks"`
@@ -89,10 +95,20 @@ type Module struct {
 
 	UpdatePolicy string `yaml:"updatePolicy"`
 	ValueType    string `yaml:"valueType"`
+	TTLBlocks    uint64 `yaml:"ttlBlocks"`
 	Binary       string `yaml:"binary"`
 
-	Inputs []*Input     `yaml:"inputs"`
-	Output StreamOutput `yaml:"output"`
+	Inputs      []*Input     `yaml:"inputs"`
+	Output      StreamOutput `yaml:"output"`
+	Datasets    []string     `yaml:"datasets"` // names of the datasets the module looks up
+	BlockFilter *BlockFilter `yaml:"blockFilter"`
+}
+
+// BlockFilter restricts the blocks a module is executed on to those whose keys, emitted
+// by the block index module `module`, match `query`.
+type BlockFilter struct {
+	Module string `yaml:"module"`
+	Query  string `yaml:"query"`
 }
 
 type Input struct {
@@ -113,6 +129,20 @@ type Binary struct {
 	ProtoPackageMapping map[string]string `yaml:"protoPackageMapping"`
 }
 
+// Dataset is a static key-value dataset the modules referencing it can look up. Its
+// content is either read from `file` and packed in the package, or fetched from `url`
+// when the request is processed, in which case its `hash` is required.
+//
+// A `.json` file holds an object with string values, any other file holds a serialized
+// `sf.substreams.v1.StoreEntries`. The content fetched from `url` must be a serialized
+// `sf.substreams.v1.StoreEntries` sorted by key.
+type Dataset struct {
+	Version string `yaml:"version"`
+	File    string `yaml:"file"`
+	URL     string `yaml:"url"`
+	Hash    string `yaml:"hash"` // hex-encoded sha256 hash of the content (`sha256sum` of the file at `url`)
+}
+
 type StreamOutput struct {
 	// For 'map'
 	Type string `yaml:"type"`
@@ -179,6 +209,29 @@ func (i *Input) parse() error {
 	return fmt.Errorf("input has an unknown or mixed types; expect one, and only one of: 'params', 'map', 'store' or 'source'")
 }
 
+func (f *BlockFilter) validate(manif *Manifest) error {
+	if f.Module == "" {
+		return errors.New("missing 'module'")
+	}
+	found := false
+	for _, mod := range manif.Modules {
+		if mod.Name == f.Module {
+			if mod.Kind != ModuleKindBlockIndex {
+				return fmt.Errorf("module %q is not of kind 'blockIndex'", f.Module)
+			}
+			found = true
+		}
+	}
+	if !found && !strings.Contains(f.Module, ":") {
+		// modules of imported packages are validated once the package is assembled
+		return fmt.Errorf("module %q not found", f.Module)
+	}
+	if _, err := blockfilter.ParseQuery(f.Query); err != nil {
+		return err
+	}
+	return nil
+}
+
 func validateStoreBuilder(module *Module) error {
 	if module.UpdatePolicy == "" {
 		return errors.New("missing 'output.updatePolicy' for kind 'store'")
@@ -222,6 +275,16 @@ func validateStoreBuilder(module *Module) error {
 		"set_if_not_exists:float64",
 		"append:bytes",
 		"append:string",
+		"set_sum:bigint",
+		"set_sum:int64",
+		"set_sum:bigdecimal",
+		"set_sum:bigfloat",
+		"set_sum:float64",
+		"bitwise_or:bigint",
+		"bitwise_or:int64",
+		"bitwise_and:bigint",
+		"bitwise_and:int64",
+		"cardinality:bytes",
 	}
 	found := false
 	var lastCombination string
@@ -261,6 +324,12 @@ func (m *Module) ToProtoWASM(codeIndex uint32) (*pbsubstreams.Module, error) {
 
 	m.setOutputToProto(out)
 	m.setKindToProto(out)
+	if m.BlockFilter != nil {
+		out.BlockFilter = &pbsubstreams.Module_BlockFilter{
+			Module: m.BlockFilter.Module,
+			Query:  m.BlockFilter.Query,
+		}
+	}
 	err := m.setInputsToProto(out)
 	if err != nil {
 		return nil, fmt.Errorf("setting input for module, %s: %w", m.Name, err)
@@ -358,6 +427,10 @@ const (
 	UpdatePolicyMax            = "max"
 	UpdatePolicyMin            = "min"
 	UpdatePolicyAppend         = "append"
+	UpdatePolicySetSum         = "set_sum"
+	UpdatePolicyBitwiseOr      = "bitwise_or"
+	UpdatePolicyBitwiseAnd     = "bitwise_and"
+	UpdatePolicyCardinality    = "cardinality"
 )
 
 func (m *Module) setKindToProto(pbModule *pbsubstreams.Module) {
@@ -383,6 +456,14 @@ func (m *Module) setKindToProto(pbModule *pbsubstreams.Module) {
 			updatePolicy = pbsubstreams.Module_KindStore_UPDATE_POLICY_MIN
 		case UpdatePolicyAppend:
 			updatePolicy = pbsubstreams.Module_KindStore_UPDATE_POLICY_APPEND
+		case UpdatePolicySetSum:
+			updatePolicy = pbsubstreams.Module_KindStore_UPDATE_POLICY_SET_SUM
+		case UpdatePolicyBitwiseOr:
+			updatePolicy = pbsubstreams.Module_KindStore_UPDATE_POLICY_BITWISE_OR
+		case UpdatePolicyBitwiseAnd:
+			updatePolicy = pbsubstreams.Module_KindStore_UPDATE_POLICY_BITWISE_AND
+		case UpdatePolicyCardinality:
+			updatePolicy = pbsubstreams.Module_KindStore_UPDATE_POLICY_CARDINALITY
 		default:
 			panic(fmt.Sprintf("invalid update policy %s", m.UpdatePolicy))
 		}
@@ -390,6 +471,13 @@ func (m *Module) setKindToProto(pbModule *pbsubstreams.Module) {
 			KindStore: &pbsubstreams.Module_KindStore{
 				UpdatePolicy: updatePolicy,
 				ValueType:    m.ValueType,
+				TtlBlocks:    m.TTLBlocks,
+			},
+		}
+	case ModuleKindBlockIndex:
+		pbModule.Kind = &pbsubstreams.Module_KindBlockIndex_{
+			KindBlockIndex: &pbsubstreams.Module_KindBlockIndex{
+				OutputType: m.Output.Type,
 			},
 		}
 	}
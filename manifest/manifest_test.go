@@ -61,6 +61,63 @@ inputs:
 				Inputs:       []*Input{{Source: "proto:sf.ethereum.type.v1.Block"}, {Store: "pairs"}},
 			},
 		},
+		{
+			name: "store with ttl",
+			rawYamlInput: `---
+name: active_accounts
+kind: store
+updatePolicy: set
+valueType: string
+ttlBlocks: 50000
+inputs:
+  - source: proto:sf.ethereum.type.v1.Block
+`,
+			expectedOutput: Module{
+				Name:         "active_accounts",
+				Kind:         "store",
+				UpdatePolicy: "set",
+				ValueType:    "string",
+				TTLBlocks:    50000,
+				Inputs:       []*Input{{Source: "proto:sf.ethereum.type.v1.Block"}},
+			},
+		},
+		{
+			name: "block index",
+			rawYamlInput: `---
+name: index_events
+kind: blockIndex
+inputs:
+  - source: proto:sf.ethereum.type.v1.Block
+output:
+  type: proto:sf.substreams.index.v1.Keys
+`,
+			expectedOutput: Module{
+				Name:   "index_events",
+				Kind:   "blockIndex",
+				Inputs: []*Input{{Source: "proto:sf.ethereum.type.v1.Block"}},
+				Output: StreamOutput{Type: "proto:sf.substreams.index.v1.Keys"},
+			},
+		},
+		{
+			name: "mapper with block filter",
+			rawYamlInput: `---
+name: map_transfers
+kind: map
+inputs:
+  - source: proto:sf.ethereum.type.v1.Block
+blockFilter:
+  module: index_events
+  query: "type:transfer -from:0x0000"
+output:
+  type: proto:eth.types.v1.Transfers`,
+			expectedOutput: Module{
+				Name:        "map_transfers",
+				Kind:        "map",
+				Inputs:      []*Input{{Source: "proto:sf.ethereum.type.v1.Block"}},
+				BlockFilter: &BlockFilter{Module: "index_events", Query: "type:transfer -from:0x0000"},
+				Output:      StreamOutput{Type: "proto:eth.types.v1.Transfers"},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -51,6 +51,11 @@ func generateMermaidGraph(mods *pbsubstreams.Modules) string {
 			str.WriteString(fmt.Sprintf("  %s[map: %s];\n", s.Name, s.Name))
 		case *pbsubstreams.Module_KindStore_:
 			str.WriteString(fmt.Sprintf("  %s[store: %s];\n", s.Name, s.Name))
+		case *pbsubstreams.Module_KindBlockIndex_:
+			str.WriteString(fmt.Sprintf("  %s[blockIndex: %s];\n", s.Name, s.Name))
+		}
+		if filter := s.BlockFilter; filter != nil {
+			str.WriteString(fmt.Sprintf("  %s -. filter .-> %s;\n", filter.Module, s.Name))
 		}
 
 		for _, in := range s.Inputs {
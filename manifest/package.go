@@ -1,13 +1,19 @@
 package manifest
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 
 	"github.com/jhump/protoreflect/desc"
 	"github.com/jhump/protoreflect/dynamic"
+	"golang.org/x/exp/maps"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/streamingfast/substreams/dataset"
 	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
 )
 
@@ -73,10 +79,23 @@ func (r *manifestConverter) validateManifest(manif *Manifest) error {
 			if s.Output.Type == "" {
 				return fmt.Errorf("stream %q: missing 'output.type' for kind 'map'", s.Name)
 			}
+			if s.TTLBlocks != 0 {
+				return fmt.Errorf("stream %q: 'ttlBlocks' is only available for kind 'store'", s.Name)
+			}
 		case ModuleKindStore:
 			if err := validateStoreBuilder(s); err != nil {
 				return fmt.Errorf("stream %q: %w", s.Name, err)
 			}
+		case ModuleKindBlockIndex:
+			if s.Output.Type != BlockIndexOutputType {
+				return fmt.Errorf("stream %q: 'output.type' must be %q for kind 'blockIndex'", s.Name, BlockIndexOutputType)
+			}
+			if s.TTLBlocks != 0 {
+				return fmt.Errorf("stream %q: 'ttlBlocks' is only available for kind 'store'", s.Name)
+			}
+			if s.BlockFilter != nil {
+				return fmt.Errorf("stream %q: 'blockFilter' is not available for kind 'blockIndex'", s.Name)
+			}
 
 		default:
 			return fmt.Errorf("stream %q: invalid kind %q", s.Name, s.Kind)
@@ -86,6 +105,25 @@ func (r *manifestConverter) validateManifest(manif *Manifest) error {
 				return fmt.Errorf("module %q: invalid input [%d]: %w", s.Name, idx, err)
 			}
 		}
+		if s.BlockFilter != nil {
+			if err := s.BlockFilter.validate(manif); err != nil {
+				return fmt.Errorf("module %q: invalid 'blockFilter': %w", s.Name, err)
+			}
+		}
+		for _, name := range s.Datasets {
+			if _, found := manif.Datasets[name]; !found {
+				return fmt.Errorf("module %q refers to dataset %q, which is not defined in the 'datasets' section of the manifest", s.Name, name)
+			}
+		}
+	}
+
+	for name, ds := range manif.Datasets {
+		if (ds.File == "") == (ds.URL == "") {
+			return fmt.Errorf("dataset %q: exactly one of 'file' or 'url' must be set", name)
+		}
+		if ds.URL != "" && ds.Hash == "" {
+			return fmt.Errorf("dataset %q: 'hash' is required with 'url'", name)
+		}
 	}
 
 	return nil
@@ -156,6 +194,11 @@ func (r *manifestConverter) convertToPkg(m *Manifest) (pkg *pbsubstreams.Package
 		}
 	}
 
+	datasetIndexes, err := r.convertDatasets(m, pkg)
+	if err != nil {
+		return nil, err
+	}
+
 	moduleCodeIndexes := map[string]int{}
 	for _, mod := range m.Modules {
 		pbmeta := &pbsubstreams.ModuleMetadata{
@@ -193,12 +236,27 @@ func (r *manifestConverter) convertToPkg(m *Manifest) (pkg *pbsubstreams.Package
 				moduleCodeIndexes[binaryDef.File] = codeIndex
 			}
 			pbmod, err = mod.ToProtoWASM(uint32(codeIndex))
+		case "native/go-v1":
+			// the binary names handlers registered in-process, see the `wasm/native` package
+			if binaryDef.Native == "" {
+				return nil, fmt.Errorf("module %q: binary %q of type %q requires the name of its handlers in 'native'", mod.Name, binaryName, binaryDef.Type)
+			}
+			codeIndex, found := moduleCodeIndexes["native:"+binaryDef.Native]
+			if !found {
+				pkg.Modules.Binaries = append(pkg.Modules.Binaries, &pbsubstreams.Binary{Type: binaryDef.Type, Content: []byte(binaryDef.Native)})
+				codeIndex = len(pkg.Modules.Binaries) - 1
+				moduleCodeIndexes["native:"+binaryDef.Native] = codeIndex
+			}
+			pbmod, err = mod.ToProtoWASM(uint32(codeIndex))
 		default:
 			return nil, fmt.Errorf("module %q: invalid code type %q", mod.Name, binaryDef.Type)
 		}
 		if err != nil {
 			return nil, err
 		}
+		for _, name := range mod.Datasets {
+			pbmod.DatasetIndexes = append(pbmod.DatasetIndexes, datasetIndexes[name])
+		}
 
 		pkg.ModuleMeta = append(pkg.ModuleMeta, pbmeta)
 		pkg.Modules.Modules = append(pkg.Modules.Modules, pbmod)
@@ -226,3 +284,71 @@ func (r *manifestConverter) convertToPkg(m *Manifest) (pkg *pbsubstreams.Package
 
 	return
 }
+
+// convertDatasets adds the datasets of the manifest to the package, sorted by name, and
+// returns their indexes by name.
+func (r *manifestConverter) convertDatasets(m *Manifest, pkg *pbsubstreams.Package) (map[string]uint32, error) {
+	names := maps.Keys(m.Datasets)
+	sort.Strings(names)
+
+	indexes := make(map[string]uint32, len(names))
+	for _, name := range names {
+		def := m.Datasets[name]
+		pbDataset := &pbsubstreams.Dataset{
+			Name:    name,
+			Version: def.Version,
+			Hash:    def.Hash,
+			Url:     def.URL,
+		}
+
+		if def.File != "" && !r.skipSourceCodeImportValidation {
+			content, err := readDatasetFile(m.resolvePath(def.File))
+			if err != nil {
+				return nil, fmt.Errorf("dataset %q: %w", name, err)
+			}
+			hash := dataset.Hash(content)
+			if def.Hash != "" && def.Hash != hash {
+				return nil, fmt.Errorf("dataset %q: content hash %s does not match 'hash' %s", name, hash, def.Hash)
+			}
+			pbDataset.Content = content
+			pbDataset.Hash = hash
+		}
+
+		indexes[name] = uint32(len(pkg.Modules.Datasets))
+		pkg.Modules.Datasets = append(pkg.Modules.Datasets, pbDataset)
+	}
+	return indexes, nil
+}
+
+// readDatasetFile reads the entries of a dataset file and returns them encoded as the
+// content of a dataset. `.json` files hold an object with string values, other files a
+// serialized `sf.substreams.v1.StoreEntries`.
+func readDatasetFile(path string) ([]byte, error) {
+	cnt, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %q: %w", path, err)
+	}
+
+	var entries []*pbsubstreams.StoreEntry
+	if filepath.Ext(path) == ".json" {
+		values := map[string]string{}
+		if err := json.Unmarshal(cnt, &values); err != nil {
+			return nil, fmt.Errorf("decoding %q, expecting an object with string values: %w", path, err)
+		}
+		for key, value := range values {
+			entries = append(entries, &pbsubstreams.StoreEntry{Key: key, Value: []byte(value)})
+		}
+	} else {
+		decoded := &pbsubstreams.StoreEntries{}
+		if err := proto.Unmarshal(cnt, decoded); err != nil {
+			return nil, fmt.Errorf("decoding %q as sf.substreams.v1.StoreEntries: %w", path, err)
+		}
+		entries = decoded.Entries
+	}
+
+	content, err := dataset.Encode(entries)
+	if err != nil {
+		return nil, fmt.Errorf("encoding %q: %w", path, err)
+	}
+	return content, nil
+}
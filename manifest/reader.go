@@ -21,6 +21,7 @@ import (
 
 	"github.com/jhump/protoreflect/desc"
 	"github.com/streamingfast/cli"
+	"github.com/streamingfast/substreams/blockfilter"
 	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
 	"go.uber.org/zap"
 	"gopkg.in/yaml.v2"
@@ -413,6 +414,10 @@ func validatePackage(pkg *pbsubstreams.Package, skipModuleOutputTypeValidation b
 					return fmt.Errorf("module %q: invalid valueType %q", mod.Name, valueType)
 				}
 			}
+		case *pbsubstreams.Module_KindBlockIndex_:
+			if outputType := i.KindBlockIndex.OutputType; outputType != BlockIndexOutputType {
+				return fmt.Errorf("module %q: block index modules must output %q, got %q", mod.Name, BlockIndexOutputType, outputType)
+			}
 		}
 
 		inputSeen := map[string]bool{}
@@ -562,6 +567,42 @@ func ValidateModules(mods *pbsubstreams.Modules) error {
 			return fmt.Errorf("limit of 30 inputs for a given module (%q) reached", mod.Name)
 		}
 
+		if filter := mod.BlockFilter; filter != nil {
+			if mod.GetKindBlockIndex() != nil {
+				return fmt.Errorf("module %q: block index modules cannot have a block filter", mod.Name)
+			}
+			var found bool
+			for _, mod2 := range mods.Modules {
+				if mod2.Name == filter.Module {
+					found = true
+					if mod2.GetKindBlockIndex() == nil {
+						return fmt.Errorf("module %q: block filter module %q not of 'blockIndex' kind", mod.Name, filter.Module)
+					}
+				}
+			}
+			if !found {
+				return fmt.Errorf("module %q: block filter module %q not found", mod.Name, filter.Module)
+			}
+			if _, err := blockfilter.ParseQuery(filter.Query); err != nil {
+				return fmt.Errorf("module %q: block filter: %w", mod.Name, err)
+			}
+		}
+
+		datasetNames := map[string]bool{}
+		for _, idx := range mod.DatasetIndexes {
+			if int(idx) >= len(mods.Datasets) {
+				return fmt.Errorf("module %q: dataset index %d out of range", mod.Name, idx)
+			}
+			ds := mods.Datasets[idx]
+			if ds.Hash == "" {
+				return fmt.Errorf("module %q: dataset %q has no hash", mod.Name, ds.Name)
+			}
+			if datasetNames[ds.Name] {
+				return fmt.Errorf("module %q: references more than one dataset named %q", mod.Name, ds.Name)
+			}
+			datasetNames[ds.Name] = true
+		}
+
 		for idx, in := range mod.Inputs {
 			switch i := in.Input.(type) {
 			case *pbsubstreams.Module_Input_Params_:
@@ -651,10 +692,23 @@ func LoadManifestFile(inputPath, workingDir string) (*Manifest, error) {
 			if s.Output.Type == "" {
 				return nil, fmt.Errorf("stream %q: missing 'output.type' for kind 'map'", s.Name)
 			}
+			if s.TTLBlocks != 0 {
+				return nil, fmt.Errorf("stream %q: 'ttlBlocks' is only available for kind 'store'", s.Name)
+			}
 		case ModuleKindStore:
 			if err := validateStoreBuilder(s); err != nil {
 				return nil, fmt.Errorf("stream %q: %w", s.Name, err)
 			}
+		case ModuleKindBlockIndex:
+			if s.Output.Type != BlockIndexOutputType {
+				return nil, fmt.Errorf("stream %q: 'output.type' must be %q for kind 'blockIndex'", s.Name, BlockIndexOutputType)
+			}
+			if s.TTLBlocks != 0 {
+				return nil, fmt.Errorf("stream %q: 'ttlBlocks' is only available for kind 'store'", s.Name)
+			}
+			if s.BlockFilter != nil {
+				return nil, fmt.Errorf("stream %q: 'blockFilter' is not available for kind 'blockIndex'", s.Name)
+			}
 
 		default:
 			return nil, fmt.Errorf("stream %q: invalid kind %q", s.Name, s.Kind)
@@ -664,6 +718,11 @@ func LoadManifestFile(inputPath, workingDir string) (*Manifest, error) {
 				return nil, fmt.Errorf("module %q: invalid input [%d]: %w", s.Name, idx, err)
 			}
 		}
+		if s.BlockFilter != nil {
+			if err := s.BlockFilter.validate(m); err != nil {
+				return nil, fmt.Errorf("module %q: invalid 'blockFilter': %w", s.Name, err)
+			}
+		}
 	}
 
 	return m, nil
@@ -742,6 +801,9 @@ func prefixModules(mods []*pbsubstreams.Module, prefix string) {
 				panic(fmt.Sprintf("module %q: input index %d: unsupported module input type %s", mod.Name, idx, inputIface.Input))
 			}
 		}
+		if mod.BlockFilter != nil {
+			mod.BlockFilter.Module = withPrefix(mod.BlockFilter.Module, prefix)
+		}
 	}
 }
 
@@ -750,15 +812,20 @@ func prefixModules(mods []*pbsubstreams.Module, prefix string) {
 func reindexAndMergePackage(src, dest *pbsubstreams.Package) {
 	newBasePackageIndex := len(dest.PackageMeta)
 	newBaseBinariesIndex := len(dest.Modules.Binaries)
+	newBaseDatasetsIndex := len(dest.Modules.Datasets)
 
 	for _, modMeta := range src.ModuleMeta {
 		modMeta.PackageIndex += uint64(newBasePackageIndex)
 	}
 	for _, mod := range src.Modules.Modules {
 		mod.BinaryIndex += uint32(newBaseBinariesIndex)
+		for i := range mod.DatasetIndexes {
+			mod.DatasetIndexes[i] += uint32(newBaseDatasetsIndex)
+		}
 	}
 	dest.Modules.Modules = append(dest.Modules.Modules, src.Modules.Modules...)
 	dest.Modules.Binaries = append(dest.Modules.Binaries, src.Modules.Binaries...)
+	dest.Modules.Datasets = append(dest.Modules.Datasets, src.Modules.Datasets...)
 	dest.ModuleMeta = append(dest.ModuleMeta, src.ModuleMeta...)
 	dest.PackageMeta = append(dest.PackageMeta, src.PackageMeta...)
 }
@@ -66,6 +66,15 @@ func (m *ModuleHashes) HashModule(modules *pbsubstreams.Modules, module *pbsubst
 		buf.WriteString("map")
 	case *pbsubstreams.Module_KindStore_:
 		buf.WriteString("store")
+		if ttlBlocks := module.GetKindStore().TtlBlocks; ttlBlocks != 0 {
+			// only written when set, so that hashes of stores without a ttl are unchanged
+			ttlBlocksBytes := make([]byte, 8)
+			binary.LittleEndian.PutUint64(ttlBlocksBytes, ttlBlocks)
+			buf.WriteString("ttl_blocks")
+			buf.Write(ttlBlocksBytes)
+		}
+	case *pbsubstreams.Module_KindBlockIndex_:
+		buf.WriteString("block_index")
 	default:
 		return nil, fmt.Errorf("invalid module file %T", module.Kind)
 	}
@@ -102,6 +111,27 @@ func (m *ModuleHashes) HashModule(modules *pbsubstreams.Modules, module *pbsubst
 	buf.WriteString("entrypoint")
 	buf.WriteString(module.BinaryEntrypoint)
 
+	if filter := module.BlockFilter; filter != nil {
+		// only written when set, so that hashes of modules without a block filter are
+		// unchanged, the hash of the filter module is part of the ancestors
+		buf.WriteString("block_filter")
+		buf.WriteString(filter.Module)
+		buf.WriteString(filter.Query)
+	}
+
+	if len(module.DatasetIndexes) != 0 {
+		// only written when set, so that hashes of modules without datasets are unchanged
+		buf.WriteString("datasets")
+		for _, idx := range module.DatasetIndexes {
+			if int(idx) >= len(modules.Datasets) {
+				return nil, fmt.Errorf("module %q: dataset index %d out of range", module.Name, idx)
+			}
+			ds := modules.Datasets[idx]
+			buf.WriteString(ds.Name)
+			buf.WriteString(ds.Hash)
+		}
+	}
+
 	h := sha1.New()
 	h.Write(buf.Bytes())
 
@@ -92,6 +92,14 @@ func Test_HashModule(t *testing.T) {
 				"mod2": "6aca30692dfa835efe09fbf51b0a1735ea3b3155",
 			},
 		},
+		{
+			file: "testdata/with-datasets.yaml",
+			hashes: map[string]string{
+				"mod1": "a9f22492be1fb13050c07f1502d5a6e78577dd80",
+				"mod2": "0cd236a794133703dabe5754b7ec1c6d6f400eaf",
+				"mod3": "7afa76e57932dd8001a607984d437eeeefa28151",
+			},
+		},
 	}
 
 	for _, test := range tests {
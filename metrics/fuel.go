@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"context"
+
+	"go.uber.org/atomic"
+)
+
+// FuelMeter accumulates the fuel consumed by the modules executed locally for a
+// request, read by the metering as deltas between two events. A nil FuelMeter
+// ignores the fuel added to it.
+type FuelMeter struct {
+	consumed atomic.Uint64
+	reported atomic.Uint64
+}
+
+func (m *FuelMeter) AddFuelConsumed(fuel uint64) {
+	if m == nil {
+		return
+	}
+	m.consumed.Add(fuel)
+}
+
+// FuelConsumedDelta returns the fuel consumed since the previous call.
+func (m *FuelMeter) FuelConsumedDelta() uint64 {
+	if m == nil {
+		return 0
+	}
+	consumed := m.consumed.Load()
+	return consumed - m.reported.Swap(consumed)
+}
+
+type fuelMeterKey struct{}
+
+func WithFuelMeter(ctx context.Context) context.Context {
+	return context.WithValue(ctx, fuelMeterKey{}, &FuelMeter{})
+}
+
+// GetFuelMeter returns the FuelMeter of `ctx`, nil if there is none.
+func GetFuelMeter(ctx context.Context) *FuelMeter {
+	meter, _ := ctx.Value(fuelMeterKey{}).(*FuelMeter)
+	return meter
+}
@@ -19,6 +19,14 @@ var SquashesLaunched = MetricSet.NewCounter("substreams_total_squashes_launched"
 var SquashersStarted = MetricSet.NewCounter("substreams_total_squash_processes_launched", "Counter for Total squash processes launched, used for rate")
 var SquashersEnded = MetricSet.NewCounter("substreams_total_squash_processes_closed", "Counter for Total squash processes closed, used for active processes")
 
+var WasmCompilationCacheHits = MetricSet.NewCounterVec("substreams_wasm_compilation_cache_hits", []string{"runtime"}, "Counter of WASM modules loaded from the compilation cache instead of being compiled")
+var WasmCompilationCacheMisses = MetricSet.NewCounterVec("substreams_wasm_compilation_cache_misses", []string{"runtime"}, "Counter of WASM modules compiled because they were not in the compilation cache")
+var WasmCompilationCacheEvictions = MetricSet.NewCounter("substreams_wasm_compilation_cache_evictions", "Counter of entries evicted from the WASM compilation cache to stay under its maximum size")
+var WasmCompilationCacheSize = MetricSet.NewGauge("substreams_wasm_compilation_cache_size_bytes", "Size of the compiled code held in the WASM compilation cache")
+
+var DeterminismChecks = MetricSet.NewCounter("substreams_determinism_checks", "Counter of module executions replayed to verify that they are deterministic")
+var DeterminismDivergences = MetricSet.NewCounterVec("substreams_determinism_divergences", []string{"module"}, "Counter of replayed module executions whose output differed from the first execution")
+
 var AppReadiness = MetricSet.NewAppReadiness("firehose")
 
 var registerOnce sync.Once
@@ -37,6 +37,9 @@ type Stats struct {
 	// counter is used to get the next jobIdx
 	counter uint64
 
+	// workerPoolSize is the number of jobs that can run in parallel, adapted to the load of tier2
+	workerPoolSize int
+
 	logger *zap.Logger
 }
 
@@ -52,6 +55,8 @@ func cloneStats(in *pbssinternal.ModuleStats) *pbssinternal.ModuleStats {
 		StoreWriteCount:        in.StoreWriteCount,
 		StoreDeleteprefixCount: in.StoreDeleteprefixCount,
 		StoreSizeBytes:         in.StoreSizeBytes,
+		FuelConsumed:           in.FuelConsumed,
+		WasmMemoryPeakBytes:    in.WasmMemoryPeakBytes,
 	}
 }
 
@@ -88,9 +93,13 @@ func mergeModuleStats(left, right *pbssinternal.ModuleStats) {
 	left.ExternalCallMetrics = mergeCallMetricsSlices(left.ExternalCallMetrics, right.ExternalCallMetrics)
 	left.StoreWriteCount += right.StoreWriteCount
 	left.StoreDeleteprefixCount += right.StoreDeleteprefixCount
+	left.FuelConsumed += right.FuelConsumed
 	if right.StoreSizeBytes > left.StoreSizeBytes {
 		left.StoreSizeBytes = right.StoreSizeBytes
 	}
+	if right.WasmMemoryPeakBytes > left.WasmMemoryPeakBytes {
+		left.WasmMemoryPeakBytes = right.WasmMemoryPeakBytes
+	}
 }
 
 // mergeMixedModuleStats merges right onto left
@@ -104,9 +113,13 @@ func mergeMixedModuleStats(left *pbsubstreamsrpc.ModuleStats, right *pbssinterna
 	left.ExternalCallMetrics = mergeMixedCallMetrics(left.ExternalCallMetrics, right.ExternalCallMetrics)
 	left.TotalStoreWriteCount += right.StoreWriteCount
 	left.TotalStoreDeleteprefixCount += right.StoreDeleteprefixCount
+	left.TotalFuelConsumed += right.FuelConsumed
 	if right.StoreSizeBytes > left.StoreSizeBytes {
 		left.StoreSizeBytes = right.StoreSizeBytes
 	}
+	if right.WasmMemoryPeakBytes > left.WasmMemoryPeakBytes {
+		left.WasmMemoryPeakBytes = right.WasmMemoryPeakBytes
+	}
 }
 
 type extendedJob struct {
@@ -221,6 +234,18 @@ func (s *Stats) Stages() []*pbsubstreamsrpc.Stage {
 	return s.stages
 }
 
+func (s *Stats) RecordWorkerPoolSize(size int) {
+	s.Lock()
+	defer s.Unlock()
+	s.workerPoolSize = size
+}
+
+func (s *Stats) WorkerPoolSize() int {
+	s.Lock()
+	defer s.Unlock()
+	return s.workerPoolSize
+}
+
 func (s *Stats) RecordNewSubrequest(stage uint32, startBlock, stopBlock uint64) (id uint64) {
 	s.Lock()
 	id = s.counter
@@ -344,6 +369,24 @@ func (s *Stats) RecordModuleWasmExternalCallEnd(moduleName string, extension str
 	delete(mod.inprocessCallMetrics, uniqueID)
 }
 
+// RecordModuleWasmFuel should be called once per module per block, with the fuel consumed by the execution of the WASM code.
+func (s *Stats) RecordModuleWasmFuel(moduleName string, fuel uint64) {
+	s.Lock()
+	defer s.Unlock()
+	mod := s.moduleStats(moduleName)
+	mod.FuelConsumed += fuel
+}
+
+// RecordModuleWasmMemory should be called once per module per block, with the size of the module's memory after the execution.
+func (s *Stats) RecordModuleWasmMemory(moduleName string, memorySize uint64) {
+	s.Lock()
+	defer s.Unlock()
+	mod := s.moduleStats(moduleName)
+	if memorySize > mod.WasmMemoryPeakBytes {
+		mod.WasmMemoryPeakBytes = memorySize
+	}
+}
+
 // RecordModuleWasmStoreRead can be called multiple times per module per block `elapsed` is the time spent in executing that operation.
 func (s *Stats) RecordModuleWasmStoreRead(moduleName string, elapsed time.Duration) {
 	s.Lock()
@@ -447,6 +490,8 @@ func (s *Stats) LocalModulesStats() []*pbssinternal.ModuleStats {
 			StoreWriteCount:        v.StoreWriteCount,
 			StoreDeleteprefixCount: v.StoreDeleteprefixCount,
 			StoreSizeBytes:         v.StoreSizeBytes,
+			FuelConsumed:           v.FuelConsumed,
+			WasmMemoryPeakBytes:    v.WasmMemoryPeakBytes,
 		}
 
 		i++
@@ -569,6 +614,8 @@ func (s *Stats) AggregatedModulesStats() []*pbsubstreamsrpc.ModuleStats {
 			TotalProcessedBlockCount:    v.processedBlocksInCompleteJobs + s.runningJobs.blocksProcessed() + s.localProcessedBlockCount,
 			TotalStoreMergingTimeMs:     uint64(v.mergingTime.Milliseconds()),
 			StoreCurrentlyMerging:       v.merging,
+			TotalFuelConsumed:           v.FuelConsumed,
+			WasmMemoryPeakBytes:         v.WasmMemoryPeakBytes,
 		}
 
 		mergeMixedModuleStats(out[i], s.runningJobs.ModuleStats(k))
@@ -615,6 +662,9 @@ func (s *Stats) getZapFields() []zap.Field {
 		zap.Duration("module_exec_duration", s.moduleExecDuration()),
 		zap.Duration("module_wasm_ext_duration", s.moduleWasmExtDuration()),
 	}
+	if !s.config.Tier2 {
+		out = append(out, zap.Int("worker_pool_size", s.workerPoolSize))
+	}
 
 	return out
 }
@@ -2,6 +2,7 @@ package execout
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -19,33 +20,36 @@ import (
 	"github.com/streamingfast/substreams/reqctx"
 	"github.com/streamingfast/substreams/storage/execout"
 	pboutput "github.com/streamingfast/substreams/storage/execout/pb"
+	"github.com/streamingfast/substreams/storage/integrity"
 )
 
+// Walker streams the execution outputs of the output modules, segment by segment. The
+// outputs of a segment are sent once the files of all the output modules are present.
 type Walker struct {
 	ctx context.Context
 	*block.Range
-	fileWalker *execout.FileWalker
-	streamOut  *response.Stream
-	module     *pbsubstreams.Module
-	logger     *zap.Logger
-	working    bool
+	fileWalkers []*execout.FileWalker // one per module, walking the same segments
+	streamOut   *response.Stream
+	modules     []*pbsubstreams.Module
+	logger      *zap.Logger
+	working     bool
 }
 
 func NewWalker(
 	ctx context.Context,
-	module *pbsubstreams.Module,
-	fileWalker *execout.FileWalker,
+	modules []*pbsubstreams.Module,
+	fileWalkers []*execout.FileWalker,
 	walkRange *block.Range,
 	stream *response.Stream,
 ) *Walker {
 	logger := reqctx.Logger(ctx)
 	return &Walker{
-		ctx:        ctx,
-		module:     module,
-		fileWalker: fileWalker,
-		Range:      walkRange,
-		streamOut:  stream,
-		logger:     logger,
+		ctx:         ctx,
+		modules:     modules,
+		fileWalkers: fileWalkers,
+		Range:       walkRange,
+		streamOut:   stream,
+		logger:      logger,
 	}
 }
 
@@ -62,21 +66,35 @@ func (r *Walker) IsWorking() bool {
 }
 
 func (r *Walker) CmdDownloadCurrentSegment(waitBefore time.Duration) loop.Cmd {
-	file := r.fileWalker.File()
+	files := make([]*execout.File, len(r.fileWalkers))
+	for i, fileWalker := range r.fileWalkers {
+		files[i] = fileWalker.File()
+	}
 
 	return func() loop.Msg {
 		time.Sleep(waitBefore)
 
-		err := file.Load(r.ctx)
-		if err == dstore.ErrNotFound {
-
-			return MsgFileNotPresent{NextWait: computeNewWait(waitBefore)}
-		}
-		if err != nil {
-			return loop.Quit(fmt.Errorf("loading %s cache %q: %w", file.ModuleName, file.Filename(), err))
+		itemsPerModule := make([][]*pboutput.Item, len(files))
+		for i, file := range files {
+			err := file.Load(r.ctx)
+			if err == dstore.ErrNotFound {
+
+				return MsgFileNotPresent{NextWait: computeNewWait(waitBefore)}
+			}
+			if errors.Is(err, integrity.ErrCorrupted) {
+				r.logger.Warn("execution output file is corrupted, deleting it to produce it again", zap.String("module", file.ModuleName), zap.String("filename", file.Filename()), zap.Error(err))
+				if err := file.Delete(r.ctx); err != nil {
+					return loop.Quit(fmt.Errorf("deleting corrupted %s cache %q: %w", file.ModuleName, file.Filename(), err))
+				}
+				return MsgFileCorrupted{Range: file.Range, NextWait: computeNewWait(waitBefore)}
+			}
+			if err != nil {
+				return loop.Quit(fmt.Errorf("loading %s cache %q: %w", file.ModuleName, file.Filename(), err))
+			}
+			itemsPerModule[i] = file.SortedItems()
 		}
 
-		if err := r.sendItems(file.SortedItems()); err != nil {
+		if err := r.sendItems(itemsPerModule); err != nil {
 			return loop.Quit(err)
 		}
 		return MsgFileDownloaded{}
@@ -94,16 +112,15 @@ func computeNewWait(previousWait time.Duration) time.Duration {
 	return newWait
 }
 
-func (r *Walker) sendItems(sortedItems []*pboutput.Item) error {
-	for _, item := range sortedItems {
-		if item == nil {
-			continue // why would that happen?!
-		}
-		if item.BlockNum < r.StartBlock {
+// sendItems sends the items of the output modules, `itemsPerModule` holding the sorted
+// items of each module of the walker, merged by block.
+func (r *Walker) sendItems(itemsPerModule [][]*pboutput.Item) error {
+	for _, blockItems := range mergeItems(itemsPerModule) {
+		if blockItems.first().BlockNum < r.StartBlock {
 			continue
 		}
 
-		blockScopedData, err := toBlockScopedData(r.module, item)
+		blockScopedData, err := toBlockScopedData(r.modules, blockItems)
 		if err != nil {
 			return fmt.Errorf("converting to block scoped data: %w", err)
 		}
@@ -123,20 +140,69 @@ func (r *Walker) sendItems(sortedItems []*pboutput.Item) error {
 	return nil
 }
 
+// blockItems holds the items of the output modules for a block, nil for the modules
+// without output on that block.
+type blockItems []*pboutput.Item
+
+func (b blockItems) first() *pboutput.Item {
+	for _, item := range b {
+		if item != nil {
+			return item
+		}
+	}
+	return nil
+}
+
+// mergeItems merges the sorted items of each module into the items of each block, in
+// block order.
+func mergeItems(itemsPerModule [][]*pboutput.Item) (out []blockItems) {
+	positions := make([]int, len(itemsPerModule))
+	for {
+		var next *pboutput.Item
+		for i, items := range itemsPerModule {
+			for positions[i] < len(items) && items[positions[i]] == nil {
+				positions[i]++ // why would that happen?!
+			}
+			if positions[i] == len(items) {
+				continue
+			}
+			if item := items[positions[i]]; next == nil || item.BlockNum < next.BlockNum {
+				next = item
+			}
+		}
+		if next == nil {
+			return out
+		}
+
+		current := make(blockItems, len(itemsPerModule))
+		for i, items := range itemsPerModule {
+			if positions[i] < len(items) && items[positions[i]].BlockNum == next.BlockNum {
+				current[i] = items[positions[i]]
+				positions[i]++
+			}
+		}
+		out = append(out, current)
+	}
+}
+
 func (r *Walker) Progress() (first, current, last int) {
-	return r.fileWalker.Progress()
+	return r.fileWalkers[0].Progress()
 }
 
 func (r *Walker) NextSegment() {
-	r.fileWalker.Next()
+	for _, fileWalker := range r.fileWalkers {
+		fileWalker.Next()
+	}
 }
 
 func (r *Walker) IsCompleted() bool {
-	return r.fileWalker.IsDone()
+	return r.fileWalkers[0].IsDone()
 }
 
-func toBlockScopedData(module *pbsubstreams.Module, cacheItem *pboutput.Item) (*pbsubstreamsrpc.BlockScopedData, error) {
-	clock := toClock(cacheItem)
+// toBlockScopedData returns the block scoped data of the items of a block: the output of
+// the first module in `Output`, the others in `Outputs`.
+func toBlockScopedData(modules []*pbsubstreams.Module, items blockItems) (*pbsubstreamsrpc.BlockScopedData, error) {
+	clock := toClock(items.first())
 	blockRef := bstream.NewBlockRef(clock.Id, clock.Number)
 	cursor := bstream.Cursor{
 		Step:      bstream.StepNewIrreversible,
@@ -150,11 +216,20 @@ func toBlockScopedData(module *pbsubstreams.Module, cacheItem *pboutput.Item) (*
 		FinalBlockHeight: blockRef.Num(),
 	}
 
-	m, err := toModuleOutput(module, cacheItem)
-	if err != nil {
-		return nil, fmt.Errorf("module output: %w", err)
+	for i, item := range items {
+		if item == nil {
+			continue
+		}
+		m, err := toModuleOutput(modules[i], item)
+		if err != nil {
+			return nil, fmt.Errorf("module output: %w", err)
+		}
+		if i == 0 {
+			out.Output = m
+		} else {
+			out.Outputs = append(out.Outputs, m)
+		}
 	}
-	out.Output = m
 
 	return out, nil
 }
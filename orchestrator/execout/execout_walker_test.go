@@ -0,0 +1,65 @@
+package execout
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pboutput "github.com/streamingfast/substreams/storage/execout/pb"
+)
+
+func TestMergeItems(t *testing.T) {
+	item := func(blockNum uint64) *pboutput.Item {
+		return &pboutput.Item{BlockNum: blockNum}
+	}
+	blockNums := func(merged []blockItems) (out [][]int64) {
+		for _, items := range merged {
+			var nums []int64
+			for _, item := range items {
+				if item == nil {
+					nums = append(nums, -1)
+					continue
+				}
+				nums = append(nums, int64(item.BlockNum))
+			}
+			out = append(out, nums)
+		}
+		return
+	}
+
+	tests := []struct {
+		name     string
+		in       [][]*pboutput.Item
+		expected [][]int64
+	}{
+		{
+			name:     "single module",
+			in:       [][]*pboutput.Item{{item(10), item(11), item(12)}},
+			expected: [][]int64{{10}, {11}, {12}},
+		},
+		{
+			name:     "aligned modules",
+			in:       [][]*pboutput.Item{{item(10), item(11)}, {item(10), item(11)}},
+			expected: [][]int64{{10, 10}, {11, 11}},
+		},
+		{
+			name:     "missing blocks",
+			in:       [][]*pboutput.Item{{item(10), item(12)}, {item(11), item(12), item(13)}},
+			expected: [][]int64{{10, -1}, {-1, 11}, {12, 12}, {-1, 13}},
+		},
+		{
+			name:     "empty module",
+			in:       [][]*pboutput.Item{{}, {item(10), nil, item(11)}},
+			expected: [][]int64{{-1, 10}, {-1, 11}},
+		},
+		{
+			name: "no items",
+			in:   [][]*pboutput.Item{{}, {}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, blockNums(mergeItems(test.in)))
+		})
+	}
+}
@@ -3,6 +3,7 @@ package execout
 import (
 	"time"
 
+	"github.com/streamingfast/substreams/block"
 	"github.com/streamingfast/substreams/orchestrator/loop"
 )
 
@@ -15,6 +16,14 @@ type MsgFileNotPresent struct {
 	NextWait time.Duration
 } // In which case, simply re-issue the CmdDownloadFile
 
+// MsgFileCorrupted is sent when the current segment's file failed its integrity
+// verification. It was deleted and needs to be produced again before re-issuing
+// the CmdDownloadFile.
+type MsgFileCorrupted struct {
+	Range    *block.Range
+	NextWait time.Duration
+}
+
 type MsgWalkerCompleted struct{}
 
 func CmdWalkerCompleted() loop.Cmd {
@@ -42,6 +42,12 @@ func BuildParallelProcessor(
 	stages := stage.NewStages(ctx, outputGraph, reqPlan, storeConfigs, traceID)
 	sched.Stages = stages
 
+	strategy, err := stage.NewSchedulingStrategy(runtimeConfig.SchedulingStrategy, runtimeConfig.MaxJobsAhead)
+	if err != nil {
+		return nil, err
+	}
+	sched.Strategy = strategy
+
 	// we may be here only for mapper, without stores
 	if reqPlan.BuildStores != nil {
 		err := stages.FetchStoresState(
@@ -86,18 +92,20 @@ func BuildParallelProcessor(
 
 	if reqPlan.WriteExecOut != nil {
 		execOutSegmenter := reqPlan.WriteOutSegmenter()
-		// note: since we are *NOT* in a sub-request and are setting up output module is a map
-		requestedModule := outputGraph.OutputModule()
-		if requestedModule.GetKindStore() != nil {
-			panic("logic error: should not get a store as outputModule on tier 1")
+		// note: since we are *NOT* in a sub-request and are setting up output modules are maps
+		requestedModules := outputGraph.OutputModules()
+		var walkers []*execout.FileWalker
+		for _, requestedModule := range requestedModules {
+			if requestedModule.GetKindStore() != nil {
+				panic("logic error: should not get a store as outputModule on tier 1")
+			}
+			walkers = append(walkers, execoutStorage.NewFileWalker(requestedModule.Name, execOutSegmenter))
 		}
 
-		walker := execoutStorage.NewFileWalker(requestedModule.Name, execOutSegmenter)
-
 		sched.ExecOutWalker = orchestratorExecout.NewWalker(
 			ctx,
-			requestedModule,
-			walker,
+			requestedModules,
+			walkers,
 			reqPlan.ReadExecOut,
 			stream,
 		)
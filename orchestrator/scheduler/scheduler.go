@@ -24,6 +24,7 @@ type Scheduler struct {
 	stream *response.Stream
 
 	Stages        *stage.Stages
+	Strategy      stage.SchedulingStrategy
 	WorkerPool    *work.WorkerPool
 	ExecOutWalker *execout.Walker
 
@@ -37,9 +38,10 @@ type Scheduler struct {
 func New(ctx context.Context, stream *response.Stream) *Scheduler {
 	logger := reqctx.Logger(ctx)
 	s := &Scheduler{
-		ctx:    ctx,
-		stream: stream,
-		logger: logger,
+		ctx:      ctx,
+		stream:   stream,
+		Strategy: stage.BreadthFirstStrategy{},
+		logger:   logger,
 	}
 	s.EventLoop = loop.NewEventLoop(s.Update)
 	return s
@@ -57,6 +59,7 @@ func (s *Scheduler) Init() loop.Cmd {
 	}
 
 	cmds = append(cmds, work.CmdScheduleNextJob())
+	reqctx.ReqStats(s.ctx).RecordWorkerPoolSize(s.WorkerPool.Size())
 
 	if s.Stages.AllStoresCompleted() {
 		cmds = append(cmds, func() loop.Msg { return stage.MsgAllStoresCompleted{} })
@@ -82,6 +85,8 @@ func (s *Scheduler) Update(msg loop.Msg) loop.Cmd {
 	case work.MsgJobSucceeded:
 		s.Stages.MarkSegmentPartialPresent(msg.Unit)
 		s.WorkerPool.Return(msg.Worker)
+		s.WorkerPool.RecordJobFeedback(msg.Unit.Stage, msg.Feedback)
+		reqctx.ReqStats(s.ctx).RecordWorkerPoolSize(s.WorkerPool.Size())
 
 		cmds = append(cmds,
 			s.Stages.CmdTryMerge(msg.Unit.Stage),
@@ -100,7 +105,7 @@ func (s *Scheduler) Update(msg loop.Msg) loop.Cmd {
 			cmds = append(cmds, loop.Tick(time.Second, func() loop.Msg { return work.MsgScheduleNextJob{} }))
 			break
 		}
-		workUnit, workRange := s.Stages.NextJob()
+		workUnit, workRange := s.Strategy.NextJob(s.Stages)
 		if workRange == nil {
 			return nil
 		}
@@ -115,7 +120,18 @@ func (s *Scheduler) Update(msg loop.Msg) loop.Cmd {
 		)
 
 	case work.MsgJobFailed:
-		cmds = append(cmds, loop.Quit(msg.Error))
+		snapshotErr, ok := store.AsCorruptedSnapshotError(msg.Error)
+		if !ok {
+			cmds = append(cmds, loop.Quit(msg.Error))
+			break
+		}
+		s.logger.Warn("store snapshot loaded by job is corrupted, producing it again", zap.Object("unit", msg.Unit), zap.Error(msg.Error))
+		if err := s.rescheduleStoreSnapshot(snapshotErr); err != nil {
+			return loop.Quit(err)
+		}
+		s.Stages.MarkSegmentPending(msg.Unit)
+		s.WorkerPool.Return(msg.Worker)
+		cmds = append(cmds, work.CmdScheduleNextJob())
 
 	case stage.MsgMergeFinished:
 		s.Stages.MergeCompleted(msg.Unit)
@@ -134,10 +150,33 @@ func (s *Scheduler) Update(msg loop.Msg) loop.Cmd {
 	case stage.MsgMergeFailed:
 		cmds = append(cmds, loop.Quit(msg.Error))
 
+	case stage.MsgMergeCorruptedSnapshot:
+		s.logger.Warn("store snapshot is corrupted, producing it again", zap.Object("unit", msg.Unit), zap.Error(msg.Error))
+		s.Stages.MarkSegmentPartialPresent(msg.Unit)
+		if err := s.rescheduleStoreSnapshot(msg.Error); err != nil {
+			return loop.Quit(err)
+		}
+		cmds = append(cmds, work.CmdScheduleNextJob())
+
+	case stage.MsgMergeCorruptedPartial:
+		s.logger.Warn("partial stores are corrupted, re-running their job", zap.Object("unit", msg.Unit), zap.Error(msg.Error))
+		s.Stages.MarkSegmentPending(msg.Unit)
+		cmds = append(cmds, work.CmdScheduleNextJob())
+
 	case execout.MsgFileNotPresent:
 		s.ExecOutWalker.MarkNotWorking()
 		cmds = append(cmds, execout.CmdDownloadSegment(msg.NextWait))
 
+	case execout.MsgFileCorrupted:
+		s.ExecOutWalker.MarkNotWorking()
+		if err := s.Stages.RescheduleOutputSegment(msg.Range); err != nil {
+			return loop.Quit(fmt.Errorf("producing corrupted outputs again: %w", err))
+		}
+		cmds = append(cmds,
+			work.CmdScheduleNextJob(),
+			execout.CmdDownloadSegment(msg.NextWait),
+		)
+
 	case execout.MsgFileDownloaded:
 		s.ExecOutWalker.NextSegment()
 		s.ExecOutWalker.MarkNotWorking()
@@ -165,6 +204,16 @@ func (s *Scheduler) Update(msg loop.Msg) loop.Cmd {
 	return loop.Batch(cmds...)
 }
 
+// rescheduleStoreSnapshot re-runs the unit producing the corrupted snapshot of
+// `snapshotErr`. The stores are no longer completed until it is merged again.
+func (s *Scheduler) rescheduleStoreSnapshot(snapshotErr *store.CorruptedSnapshotError) error {
+	if err := s.Stages.RescheduleStoreSnapshot(snapshotErr.ModuleName, snapshotErr.ExclusiveEndBlock); err != nil {
+		return fmt.Errorf("producing corrupted store snapshot again: %w", err)
+	}
+	s.storesSyncCompleted = false
+	return nil
+}
+
 func (s *Scheduler) cmdShutdownWhenComplete() loop.Cmd {
 	if s.outputStreamCompleted && s.storesSyncCompleted {
 
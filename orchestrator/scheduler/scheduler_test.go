@@ -1,12 +1,27 @@
 package scheduler
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 
+	"github.com/streamingfast/substreams/block"
+	"github.com/streamingfast/substreams/metrics"
 	"github.com/streamingfast/substreams/orchestrator/execout"
 	"github.com/streamingfast/substreams/orchestrator/loop"
+	"github.com/streamingfast/substreams/orchestrator/plan"
+	"github.com/streamingfast/substreams/orchestrator/response"
+	"github.com/streamingfast/substreams/orchestrator/stage"
+	"github.com/streamingfast/substreams/orchestrator/work"
+	"github.com/streamingfast/substreams/pipeline/outputmodules"
+	"github.com/streamingfast/substreams/reqctx"
+	"github.com/streamingfast/substreams/storage/integrity"
+	"github.com/streamingfast/substreams/storage/store"
 )
 
 func TestSched2_JobFinished(t *testing.T) {
@@ -35,3 +50,150 @@ func TestSched2_JobFinished(t *testing.T) {
 	//  * NextSegment()
 
 }
+
+func TestScheduler_SchedulingStrategies(t *testing.T) {
+	tests := []struct {
+		name           string
+		strategy       stage.SchedulingStrategy
+		expectSchedule []stage.Unit
+	}{
+		{
+			name:     "breadth first",
+			strategy: stage.BreadthFirstStrategy{},
+			expectSchedule: []stage.Unit{
+				unit(0, 2), unit(0, 1), unit(0, 0),
+				unit(1, 2), unit(1, 1), unit(1, 0),
+				unit(2, 2), unit(2, 1), unit(2, 0),
+				unit(3, 2), unit(3, 1), unit(3, 0),
+				unit(4, 2),
+			},
+		},
+		{
+			name:     "critical path",
+			strategy: stage.CriticalPathStrategy{MaxJobsAhead: 1},
+			expectSchedule: []stage.Unit{
+				unit(0, 0), unit(0, 1), unit(0, 2),
+				unit(1, 0), unit(1, 1), unit(1, 2),
+				unit(2, 0), unit(2, 1), unit(2, 2),
+				unit(3, 0), unit(3, 1), unit(3, 2),
+				unit(4, 2),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectSchedule, runTestScheduler(t, test.strategy))
+		})
+	}
+}
+
+func TestScheduler_JobFailedOnCorruptedSnapshot(t *testing.T) {
+	// the job of unit(2, 1) loads the snapshot of the stage 0 store ending at block 20,
+	// produced by unit(1, 0), which is re-run before unit(2, 1) is scheduled again.
+	corrupted := fmt.Errorf("rpc error: code = DataLoss desc = %w", &store.CorruptedSnapshotError{ExclusiveEndBlock: 20, Err: integrity.ErrCorrupted})
+
+	assert.Equal(t, []stage.Unit{
+		unit(0, 2), unit(0, 1), unit(0, 0),
+		unit(1, 2), unit(1, 1), unit(1, 0),
+		unit(2, 2), unit(2, 1),
+		unit(1, 0),
+		unit(2, 1), unit(2, 0),
+		unit(3, 2), unit(3, 1), unit(3, 0),
+		unit(4, 2),
+	}, runTestSchedulerWithFailures(t, stage.BreadthFirstStrategy{}, map[stage.Unit]error{unit(2, 1): corrupted}))
+}
+
+func TestScheduler_MergeFailedOnCorruptedSnapshot(t *testing.T) {
+	// the partial of unit(2, 0) is merged into the snapshot ending at block 20, produced
+	// by unit(1, 0), which is re-run before merging the partial again.
+	corrupted := &store.CorruptedSnapshotError{ExclusiveEndBlock: 20, Err: integrity.ErrCorrupted}
+
+	assert.Equal(t, []stage.Unit{
+		unit(0, 2), unit(0, 1), unit(0, 0),
+		unit(1, 2), unit(1, 1), unit(1, 0),
+		unit(2, 2), unit(2, 1), unit(2, 0),
+		unit(1, 0),
+		unit(3, 2), unit(3, 1), unit(3, 0),
+		unit(4, 2),
+	}, runTestSchedulerWithFailures(t, stage.BreadthFirstStrategy{}, map[stage.Unit]error{unit(2, 0): corrupted}))
+}
+
+func unit(segment, stageIdx int) stage.Unit {
+	return stage.Unit{Segment: segment, Stage: stageIdx}
+}
+
+// runTestScheduler runs the scheduler with a single worker on a request with
+// stages `S S M` over 5 segments, the jobs and merges succeeding right away,
+// and returns the units in the order they were scheduled.
+func runTestScheduler(t *testing.T, strategy stage.SchedulingStrategy) (scheduled []stage.Unit) {
+	t.Helper()
+	return runTestSchedulerWithFailures(t, strategy, nil)
+}
+
+// runTestSchedulerWithFailures is runTestScheduler, with the first job of the units
+// of `failures` failing with their error, or their first merge when the error is a
+// *store.CorruptedSnapshotError.
+func runTestSchedulerWithFailures(t *testing.T, strategy stage.SchedulingStrategy, failures map[stage.Unit]error) (scheduled []stage.Unit) {
+	t.Helper()
+
+	ctx := reqctx.WithReqStats(context.Background(), metrics.NewReqStats(&metrics.Config{}, zap.NewNop()))
+	reqPlan, err := plan.BuildTier1RequestPlan(true, 10, 5, 5, 50, 50, true)
+	require.NoError(t, err)
+
+	var worker work.Worker
+	s := New(ctx, nil)
+	s.Stages = stage.NewStages(ctx, outputmodules.TestGraphStagedModules(5, 5, 5, 5, 5), reqPlan, nil, "trace")
+	s.Strategy = strategy
+	s.WorkerPool = work.NewWorkerPool(ctx, 1, func(logger *zap.Logger) work.Worker {
+		worker = work.NewWorkerFactoryFromFunc(func(ctx context.Context, unit stage.Unit, workRange *block.Range, moduleNames []string, upstream *response.Stream) loop.Cmd {
+			scheduled = append(scheduled, unit)
+			return nil
+		})
+		return worker
+	})
+
+	for {
+		count := len(scheduled)
+		s.Update(work.MsgScheduleNextJob{})
+		if len(scheduled) == count {
+			return
+		}
+
+		if err := failures[scheduled[count]]; err != nil && !isMergeFailure(err) {
+			delete(failures, scheduled[count])
+			s.Update(work.MsgJobFailed{Unit: scheduled[count], Worker: worker, Error: err})
+			continue
+		}
+
+		s.Update(work.MsgJobSucceeded{Unit: scheduled[count], Worker: worker})
+		for units := mergingUnits(s.Stages); len(units) != 0; units = mergingUnits(s.Stages) {
+			for _, unit := range units {
+				if snapshotErr, ok := failures[unit].(*store.CorruptedSnapshotError); ok {
+					delete(failures, unit)
+					s.Update(stage.MsgMergeCorruptedSnapshot{Unit: unit, Error: snapshotErr})
+					continue
+				}
+				s.Update(stage.MsgMergeFinished{Unit: unit})
+			}
+		}
+	}
+}
+
+func isMergeFailure(err error) bool {
+	_, ok := err.(*store.CorruptedSnapshotError)
+	return ok
+}
+
+// mergingUnits returns the units in the merging state, as the merges
+// started by the scheduler are not run.
+func mergingUnits(stages *stage.Stages) (out []stage.Unit) {
+	for stageIdx, line := range strings.Split(strings.TrimSpace(stages.StatesString()), "\n") {
+		for segment, state := range line[2:] {
+			if state == 'M' {
+				out = append(out, stage.Unit{Stage: stageIdx, Segment: segment})
+			}
+		}
+	}
+	return
+}
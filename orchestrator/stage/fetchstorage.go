@@ -24,23 +24,18 @@ func (s *Stages) FetchStoresState(
 
 	upToBlock := segmenter.ExclusiveEndBlock()
 
-	var mapperName string
-	var mapperFiles execout.FileInfos
-	if lastStage := s.stages[len(s.stages)-1]; lastStage.kind == KindMap {
-		if len(lastStage.moduleStates) != 1 {
-			panic("assertion: mapper stage should contain a single module")
-		}
-
-		mapperName = lastStage.moduleStates[0].name
-		conf := execoutConfigs.ConfigMap[mapperName]
-		// TODO: OPTIMIZATION: get the actual needed range for execOutputs to optimize lookup
+	var mapperFiles map[string]execout.FileInfos
+	if lastStage := s.stages[len(s.stages)-1]; lastStage.kind == KindMap && upToBlock != 0 {
+		mapperFiles = make(map[string]execout.FileInfos)
+		for _, mod := range lastStage.moduleStates {
+			conf := execoutConfigs.ConfigMap[mod.name]
+			// TODO: OPTIMIZATION: get the actual needed range for execOutputs to optimize lookup
 
-		if upToBlock != 0 {
 			files, err := conf.ListSnapshotFiles(ctx, bstream.NewInclusiveRange(0, upToBlock))
 			if err != nil {
 				return fmt.Errorf("fetching mapper storage state: %w", err)
 			}
-			mapperFiles = files
+			mapperFiles[mod.name] = files
 		}
 	}
 
@@ -60,15 +55,18 @@ func (s *Stages) FetchStoresState(
 			if stageIdx != len(s.stages)-1 {
 				panic("assertion: mapper stage is not the last stage")
 			}
-			for _, outputFile := range mapperFiles {
-				segmentIdx := s.mapSegmenter.IndexForEndBlock(outputFile.BlockRange.ExclusiveEndBlock)
-				rng := s.mapSegmenter.Range(segmentIdx)
-				if rng == nil || rng.ExclusiveEndBlock != outputFile.BlockRange.ExclusiveEndBlock {
-					continue
-				}
-				unit := Unit{Stage: stageIdx, Segment: segmentIdx}
-				if allDone := markFound(completes, unit, mapperName, moduleCount); allDone {
-					s.markSegmentCompleted(unit)
+			// the unit is completed when the outputs of all the output modules are present
+			for mapperName, outputFiles := range mapperFiles {
+				for _, outputFile := range outputFiles {
+					segmentIdx := s.mapSegmenter.IndexForEndBlock(outputFile.BlockRange.ExclusiveEndBlock)
+					rng := s.mapSegmenter.Range(segmentIdx)
+					if rng == nil || rng.ExclusiveEndBlock != outputFile.BlockRange.ExclusiveEndBlock {
+						continue
+					}
+					unit := Unit{Stage: stageIdx, Segment: segmentIdx}
+					if allDone := markFound(completes, unit, mapperName, moduleCount); allDone {
+						s.markSegmentCompleted(unit)
+					}
 				}
 			}
 
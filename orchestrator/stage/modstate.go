@@ -2,11 +2,13 @@ package stage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"go.uber.org/zap"
 
 	"github.com/streamingfast/substreams/block"
+	"github.com/streamingfast/substreams/storage/integrity"
 	"github.com/streamingfast/substreams/storage/store"
 )
 
@@ -41,6 +43,16 @@ func (s *ModuleState) getStore(ctx context.Context, exclusiveEndBlock uint64) (*
 	if moduleInitBlock != exclusiveEndBlock {
 		fullKVFile := store.NewCompleteFileInfo(s.name, moduleInitBlock, exclusiveEndBlock)
 		err := loadStore.Load(ctx, fullKVFile)
+		if errors.Is(err, integrity.ErrCorrupted) {
+			// The snapshot is deleted, the scheduler produces it again from the previous one
+			s.logger.Warn("store snapshot is corrupted, deleting it", zap.String("module", s.name), zap.String("filename", fullKVFile.Filename), zap.Error(err))
+			_ = loadStore.DeleteStore(ctx, fullKVFile)
+			return nil, fmt.Errorf("load store %q: %w", s.name, &store.CorruptedSnapshotError{
+				ModuleName:        s.name,
+				ExclusiveEndBlock: exclusiveEndBlock,
+				Err:               err,
+			})
+		}
 		if err != nil {
 			return nil, fmt.Errorf("load store %q: %w", s.name, err)
 		}
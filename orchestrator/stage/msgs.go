@@ -1,6 +1,9 @@
 package stage
 
-import "github.com/streamingfast/substreams/orchestrator/loop"
+import (
+	"github.com/streamingfast/substreams/orchestrator/loop"
+	"github.com/streamingfast/substreams/storage/store"
+)
 
 // This means that this single Store has completed its full sync, up to the target block
 type MsgAllStoresCompleted struct {
@@ -22,6 +25,21 @@ type MsgMergeFailed struct {
 	Error error
 }
 
+// MsgMergeCorruptedPartial is sent when some partials of a unit failed their integrity
+// verification, the unit's job needs to be re-run.
+type MsgMergeCorruptedPartial struct {
+	Unit
+	Error error
+}
+
+// MsgMergeCorruptedSnapshot is sent when the store snapshot a unit's partials are
+// merged into failed its integrity verification. The snapshot was deleted, the unit
+// producing it needs to be re-run before merging the unit again.
+type MsgMergeCorruptedSnapshot struct {
+	Unit
+	Error *store.CorruptedSnapshotError
+}
+
 type MsgMergeNotReady struct {
 	Reason   string
 	NextUnit Unit
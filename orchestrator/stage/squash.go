@@ -2,12 +2,15 @@ package stage
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/streamingfast/substreams/reqctx"
+	"github.com/streamingfast/substreams/storage/integrity"
 	"github.com/streamingfast/substreams/storage/store"
 )
 
@@ -24,6 +27,45 @@ func (s *Stages) multiSquash(stage *Stage, mergeUnit Unit) error {
 		panic("multiSquash called on non-store stage")
 	}
 
+	// Load all the partials, and the stores they are merged into, before merging any
+	// of them, so that the job can be re-run when one of the partials is corrupted, or
+	// the previous segment produced again when one of the snapshots is, without any
+	// store having moved forward.
+	var lock sync.Mutex
+	partials := make(map[string]*loadedPartial)
+	var corrupted []error
+	for _, modState := range stage.moduleStates {
+		if mergeUnit.Segment < modState.segmenter.FirstIndex() {
+			continue
+		}
+
+		modState := modState // capture in loop
+		stage.syncWork.Go(func() error {
+			if _, err := modState.getStore(s.ctx, modState.segmenter.Range(mergeUnit.Segment).StartBlock); err != nil {
+				return fmt.Errorf("getting store stage %d module %q: %w", stage.idx, modState.name, err)
+			}
+
+			partial, err := s.loadPartial(modState, mergeUnit)
+			lock.Lock()
+			defer lock.Unlock()
+			if errors.Is(err, integrity.ErrCorrupted) {
+				corrupted = append(corrupted, fmt.Errorf("module %q: %w", modState.name, err))
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("load partial stage %d module %q: %w", stage.idx, modState.name, err)
+			}
+			partials[modState.name] = partial
+			return nil
+		})
+	}
+	if err := stage.syncWork.Wait(); err != nil {
+		return err
+	}
+	if len(corrupted) != 0 {
+		return &corruptedPartialsError{errors.Join(corrupted...)}
+	}
+
 	// Launch parallel jobs to merge all stages' stores.
 	for _, modState := range stage.moduleStates {
 		if mergeUnit.Segment < modState.segmenter.FirstIndex() {
@@ -31,11 +73,12 @@ func (s *Stages) multiSquash(stage *Stage, mergeUnit Unit) error {
 		}
 
 		modState := modState // capture in loop
+		partial := partials[modState.name]
 		stage.syncWork.Go(func() error {
 			stats := reqctx.ReqStats(s.ctx)
 			stats.RecordModuleMerging(modState.name)
 			defer stats.RecordModuleMergeComplete(modState.name)
-			err := s.singleSquash(stage, modState, mergeUnit)
+			err := s.singleSquash(stage, modState, mergeUnit, partial)
 			if err != nil {
 				return fmt.Errorf("squash stage %d module %q: %w", stage.idx, modState.name, err)
 			}
@@ -46,6 +89,41 @@ func (s *Stages) multiSquash(stage *Stage, mergeUnit Unit) error {
 	return stage.syncWork.Wait()
 }
 
+// corruptedPartialsError is returned by multiSquash when some partials failed their
+// integrity verification, in which case the job producing them needs to be re-run.
+type corruptedPartialsError struct {
+	err error
+}
+
+func (e *corruptedPartialsError) Error() string {
+	return fmt.Sprintf("corrupted partials: %s", e.err)
+}
+
+func (e *corruptedPartialsError) Unwrap() error {
+	return e.err
+}
+
+type loadedPartial struct {
+	file      *store.FileInfo
+	kv        *store.PartialKV
+	loadStart time.Time
+	loadEnd   time.Time
+}
+
+func (s *Stages) loadPartial(modState *ModuleState, mergeUnit Unit) (*loadedPartial, error) {
+	rng := modState.segmenter.Range(mergeUnit.Segment)
+	out := &loadedPartial{
+		file:      store.NewPartialFileInfo(modState.name, rng.StartBlock, rng.ExclusiveEndBlock, s.traceID),
+		kv:        modState.derivePartialKV(rng.StartBlock),
+		loadStart: time.Now(),
+	}
+	if err := out.kv.Load(s.ctx, out.file); err != nil {
+		return nil, fmt.Errorf("loading partial: %q: %w", out.file.Filename, err)
+	}
+	out.loadEnd = time.Now()
+	return out, nil
+}
+
 // The singleSquash operation's goal is to take the up-most contiguous unit
 // tha is compete, and take the very next partial, squash it and produce a FullKV
 // store.
@@ -53,13 +131,13 @@ func (s *Stages) multiSquash(stage *Stage, mergeUnit Unit) error {
 // to load that compete store, and squash the next partial segment.
 // We keep the cache of the latest FullKV store, to speed up things
 // if they are linear
-func (s *Stages) singleSquash(stage *Stage, modState *ModuleState, mergeUnit Unit) error {
+func (s *Stages) singleSquash(stage *Stage, modState *ModuleState, mergeUnit Unit, partial *loadedPartial) error {
 	metrics := mergeMetrics{}
-	metrics.start = time.Now()
+	metrics.start = partial.loadStart
 
 	rng := modState.segmenter.Range(mergeUnit.Segment)
-	partialFile := store.NewPartialFileInfo(modState.name, rng.StartBlock, rng.ExclusiveEndBlock, s.traceID)
-	partialKV := modState.derivePartialKV(rng.StartBlock)
+	partialFile := partial.file
+	partialKV := partial.kv
 	segmentEndsOnInterval := modState.segmenter.EndsOnInterval(mergeUnit.Segment)
 
 	// Retrieve store to merge, from cache or load from storage. Allows skipping of segments
@@ -69,12 +147,8 @@ func (s *Stages) singleSquash(stage *Stage, modState *ModuleState, mergeUnit Uni
 		return fmt.Errorf("getting store: %w", err)
 	}
 
-	// Load
-	metrics.loadStart = time.Now()
-	if err := partialKV.Load(s.ctx, partialFile); err != nil {
-		return fmt.Errorf("loading partial: %q: %w", partialFile.Filename, err)
-	}
-	metrics.loadEnd = time.Now()
+	metrics.loadStart = partial.loadStart
+	metrics.loadEnd = partial.loadEnd
 
 	// Merge
 	metrics.mergeStart = time.Now()
@@ -91,6 +165,8 @@ func (s *Stages) singleSquash(stage *Stage, modState *ModuleState, mergeUnit Uni
 
 	// Flush full store
 	if segmentEndsOnInterval {
+		fullKV.EvictExpired(rng.ExclusiveEndBlock)
+
 		metrics.saveStart = time.Now()
 		_, writer, err := fullKV.Save(rng.ExclusiveEndBlock)
 		if err != nil {
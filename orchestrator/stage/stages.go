@@ -2,6 +2,7 @@ package stage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -91,6 +92,11 @@ func NewStages(
 		if kind == KindMap && reqPlan.WriteExecOut == nil {
 			continue
 		}
+		if kind == KindMap {
+			// the map stage produces the outputs of all the output modules, some of
+			// them possibly in layers or stages before the last one
+			layer = outputMappers(outputGraph)
+		}
 		if kind == KindStore && reqPlan.BuildStores == nil {
 			continue
 		}
@@ -123,6 +129,15 @@ func NewStages(
 	return out
 }
 
+func outputMappers(outputGraph *outputmodules.Graph) (out outputmodules.LayerModules) {
+	for _, mod := range outputGraph.OutputModules() {
+		if mod.GetKindStore() == nil {
+			out = append(out, mod)
+		}
+	}
+	return
+}
+
 func layerKind(layer outputmodules.LayerModules) Kind {
 	if layer.IsStoreLayer() {
 		return KindStore
@@ -251,6 +266,14 @@ func (s *Stages) CmdTryMerge(stageIdx int) loop.Cmd {
 
 	return func() loop.Msg {
 		if err := s.multiSquash(stage, mergeUnit); err != nil {
+			var snapshotErr *store.CorruptedSnapshotError
+			if errors.As(err, &snapshotErr) {
+				return MsgMergeCorruptedSnapshot{Unit: mergeUnit, Error: snapshotErr}
+			}
+			var corruptedErr *corruptedPartialsError
+			if errors.As(err, &corruptedErr) {
+				return MsgMergeCorruptedPartial{Unit: mergeUnit, Error: err}
+			}
 			return MsgMergeFailed{Unit: mergeUnit, Error: err}
 		}
 		return MsgMergeFinished{Unit: mergeUnit}
@@ -309,6 +332,64 @@ func (s *Stages) initSegmentsOffset(reqPlan *plan.RequestPlan) {
 	}
 }
 
+// RescheduleOutputSegment makes the map stage unit producing the execution outputs of
+// `rng` pending again, after these outputs were found corrupted, so that its job is
+// re-run. Units already scheduled are left as is, their job will overwrite the outputs.
+func (s *Stages) RescheduleOutputSegment(rng *block.Range) error {
+	if s.mapSegmenter == nil {
+		return fmt.Errorf("no map stage to produce outputs for range %s", rng)
+	}
+	stageIdx := len(s.stages) - 1
+	stage := s.stages[stageIdx]
+	if stage.kind != KindMap {
+		return fmt.Errorf("no map stage to produce outputs for range %s", rng)
+	}
+
+	unit := Unit{Segment: s.mapSegmenter.IndexForEndBlock(rng.ExclusiveEndBlock), Stage: stageIdx}
+	if unit.Segment < s.segmentOffset || unit.Segment < stage.segmenter.FirstIndex() || unit.Segment > stage.segmenter.LastIndex() {
+		return fmt.Errorf("outputs for range %s are not produced by this request", rng)
+	}
+
+	if s.getState(unit) == UnitScheduled {
+		return nil
+	}
+	s.markSegmentCorrupted(unit)
+	return nil
+}
+
+// RescheduleStoreSnapshot makes the store stage unit producing the snapshot of
+// `moduleName` ending at `exclusiveEndBlock` pending again, after the snapshot was found
+// corrupted and deleted, so that its job is re-run and its partials merged again from
+// the previous snapshot. Units not completed are left as is, they are already on their
+// way to produce the snapshot.
+func (s *Stages) RescheduleStoreSnapshot(moduleName string, exclusiveEndBlock uint64) error {
+	for stageIdx, stage := range s.stages {
+		if stage.kind != KindStore {
+			continue
+		}
+		for _, modState := range stage.moduleStates {
+			if modState.name != moduleName {
+				continue
+			}
+
+			unit := Unit{Segment: modState.segmenter.IndexForEndBlock(exclusiveEndBlock), Stage: stageIdx}
+			if unit.Segment < s.segmentOffset || unit.Segment < stage.segmenter.FirstIndex() || unit.Segment > stage.segmenter.LastIndex() {
+				return fmt.Errorf("store snapshot of module %q ending at block %d is not produced by this request", moduleName, exclusiveEndBlock)
+			}
+
+			if s.getState(unit) != UnitCompleted {
+				return nil
+			}
+			s.markSegmentCorrupted(unit)
+			if stage.segmentCompleted >= unit.Segment {
+				stage.segmentCompleted = unit.Segment - 1
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("no store stage to produce the snapshot of module %q ending at block %d", moduleName, exclusiveEndBlock)
+}
+
 func (s *Stages) getState(u Unit) UnitState {
 	index := u.Segment - s.segmentOffset
 	if index >= len(s.segmentStates) {
@@ -348,40 +429,44 @@ func (s *Stages) NextJob() (Unit, *block.Range) {
 
 	for segmentIdx := s.globalSegmenter.FirstIndex(); segmentIdx <= s.globalSegmenter.LastIndex(); segmentIdx++ {
 		for stageIdx := len(s.stages) - 1; stageIdx >= 0; stageIdx-- {
-			stage := s.stages[stageIdx]
 			unit := Unit{Segment: segmentIdx, Stage: stageIdx}
-			segmentState := s.getState(unit)
-			if segmentState != UnitPending {
-				continue
-			}
-			if segmentState == UnitNoOp {
-				continue
-			}
-			if segmentIdx < stage.segmenter.FirstIndex() {
-				// Don't process stages where all modules' initial blocks are only later
-				continue
-			}
-			if segmentIdx > stage.segmenter.LastIndex() {
-				break
-			}
-			if !s.dependenciesCompleted(unit) {
-				continue
-			}
-
-			r := stage.segmenter.Range(unit.Segment)
-			if r.Len() == 0 {
-				// empty units get marked as completed automatically
-				s.markSegmentCompleted(unit)
-				continue
+			if r := s.scheduleUnit(unit); r != nil {
+				return unit, r
 			}
-
-			s.markSegmentScheduled(unit)
-			return unit, r
 		}
 	}
 	return Unit{}, nil
 }
 
+// scheduleUnit marks `unit` as scheduled and returns its block range if it is
+// pending and its dependencies are completed, or returns nil otherwise.
+func (s *Stages) scheduleUnit(unit Unit) *block.Range {
+	stage := s.stages[unit.Stage]
+	if s.getState(unit) != UnitPending {
+		return nil
+	}
+	if unit.Segment < stage.segmenter.FirstIndex() {
+		// Don't process stages where all modules' initial blocks are only later
+		return nil
+	}
+	if unit.Segment > stage.segmenter.LastIndex() {
+		return nil
+	}
+	if !s.dependenciesCompleted(unit) {
+		return nil
+	}
+
+	r := stage.segmenter.Range(unit.Segment)
+	if r.Len() == 0 {
+		// empty units get marked as completed automatically
+		s.markSegmentCompleted(unit)
+		return nil
+	}
+
+	s.markSegmentScheduled(unit)
+	return r
+}
+
 func (s *Stages) allocSegments(segmentIdx int) {
 	segmentsNeeded := segmentIdx - s.segmentOffset
 	if len(s.segmentStates) > segmentsNeeded {
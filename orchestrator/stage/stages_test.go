@@ -257,3 +257,57 @@ func TestStages_allocSegments(t *testing.T) {
 		})
 	}
 }
+
+func TestStages_RescheduleOutputSegment(t *testing.T) {
+	reqPlan, err := plan.BuildTier1RequestPlan(true, 10, 5, 5, 50, 50, true)
+	assert.NoError(t, err)
+	stages := NewStages(
+		context.Background(),
+		outputmodules.TestGraphStagedModules(5, 5, 5, 5, 5),
+		reqPlan,
+		nil,
+		"trace",
+	)
+
+	stages.allocSegments(2)
+	stages.forceTransition(1, 2, UnitCompleted)
+	stages.forceTransition(2, 2, UnitScheduled)
+
+	assert.NoError(t, stages.RescheduleOutputSegment(block.ParseRange("10-20")))
+	assert.Equal(t, UnitPending, stages.getState(Unit{Stage: 2, Segment: 1}))
+
+	assert.NoError(t, stages.RescheduleOutputSegment(block.ParseRange("20-30")))
+	assert.Equal(t, UnitScheduled, stages.getState(Unit{Stage: 2, Segment: 2}), "already scheduled job overwrites the outputs")
+
+	assert.Error(t, stages.RescheduleOutputSegment(block.ParseRange("60-70")))
+}
+
+func TestStages_RescheduleStoreSnapshot(t *testing.T) {
+	reqPlan, err := plan.BuildTier1RequestPlan(true, 10, 5, 5, 50, 50, true)
+	assert.NoError(t, err)
+	stages := NewStages(
+		context.Background(),
+		outputmodules.TestGraphStagedModules(5, 5, 5, 5, 5),
+		reqPlan,
+		nil,
+		"trace",
+	)
+
+	stages.allocSegments(3)
+	stages.forceTransition(0, 0, UnitCompleted)
+	stages.forceTransition(1, 0, UnitCompleted)
+	stages.forceTransition(2, 0, UnitCompleted)
+	stages.MoveSegmentCompletedForward(0)
+	assert.Equal(t, 2, stages.stages[0].segmentCompleted)
+
+	assert.NoError(t, stages.RescheduleStoreSnapshot("", 20))
+	assert.Equal(t, UnitPending, stages.getState(Unit{Stage: 0, Segment: 1}))
+	assert.Equal(t, UnitCompleted, stages.getState(Unit{Stage: 0, Segment: 2}))
+	assert.Equal(t, 0, stages.stages[0].segmentCompleted, "merged again from the previous snapshot")
+
+	assert.NoError(t, stages.RescheduleStoreSnapshot("", 20), "already on its way to be produced")
+	assert.Equal(t, UnitPending, stages.getState(Unit{Stage: 0, Segment: 1}))
+
+	assert.Error(t, stages.RescheduleStoreSnapshot("", 70))
+	assert.Error(t, stages.RescheduleStoreSnapshot("unknown", 20))
+}
@@ -0,0 +1,86 @@
+package stage
+
+import (
+	"fmt"
+
+	"github.com/streamingfast/substreams/block"
+)
+
+const (
+	SchedulingStrategyBreadthFirst = "breadth-first"
+	SchedulingStrategyCriticalPath = "critical-path"
+)
+
+// SchedulingStrategy picks the next unit of work to send to a worker. It
+// marks the unit it returns as scheduled in the Stages, and returns a nil
+// range when no unit can be scheduled at the moment.
+type SchedulingStrategy interface {
+	NextJob(stages *Stages) (Unit, *block.Range)
+}
+
+// NewSchedulingStrategy returns the strategy registered under `name`,
+// BreadthFirstStrategy when empty. `maxJobsAhead` is only used by the
+// CriticalPathStrategy.
+func NewSchedulingStrategy(name string, maxJobsAhead uint64) (SchedulingStrategy, error) {
+	switch name {
+	case "", SchedulingStrategyBreadthFirst:
+		return BreadthFirstStrategy{}, nil
+	case SchedulingStrategyCriticalPath:
+		return CriticalPathStrategy{MaxJobsAhead: maxJobsAhead}, nil
+	}
+	return nil, fmt.Errorf("unknown scheduling strategy %q, expected one of %q or %q", name, SchedulingStrategyBreadthFirst, SchedulingStrategyCriticalPath)
+}
+
+// BreadthFirstStrategy schedules the units segment by segment, the last
+// stage first, as soon as their dependencies are completed. It keeps all
+// the workers busy, but can run the first stages far ahead of the ones
+// depending on them.
+type BreadthFirstStrategy struct{}
+
+func (BreadthFirstStrategy) NextJob(stages *Stages) (Unit, *block.Range) {
+	return stages.NextJob()
+}
+
+// CriticalPathStrategy prioritizes the units blocking the last stage: the
+// one producing the output module's outputs, or the last stores needed for
+// the linear handoff. Every unit of the segments before the first segment
+// of the last stage not yet scheduled is one of its dependencies, so they
+// go first, the first stages before the others as they unblock the most
+// units of the next segment. When MaxJobsAhead is not 0, units more than
+// that many segments after that blocking segment are not scheduled, keeping
+// the workers available for the critical ones.
+type CriticalPathStrategy struct {
+	MaxJobsAhead uint64
+}
+
+func (c CriticalPathStrategy) NextJob(stages *Stages) (Unit, *block.Range) {
+	blockingSegment := stages.blockingSegment()
+
+	for segmentIdx := stages.globalSegmenter.FirstIndex(); segmentIdx <= stages.globalSegmenter.LastIndex(); segmentIdx++ {
+		if c.MaxJobsAhead != 0 && segmentIdx > blockingSegment+int(c.MaxJobsAhead) {
+			break
+		}
+		for stageIdx := 0; stageIdx < len(stages.stages); stageIdx++ {
+			unit := Unit{Segment: segmentIdx, Stage: stageIdx}
+			if r := stages.scheduleUnit(unit); r != nil {
+				return unit, r
+			}
+		}
+	}
+	return Unit{}, nil
+}
+
+// blockingSegment returns the first segment of the last stage that is pending
+// or scheduled, or the segment after its last one when there is none.
+func (s *Stages) blockingSegment() int {
+	stageIdx := len(s.stages) - 1
+	stage := s.stages[stageIdx]
+	segmentIdx := max(stage.segmenter.FirstIndex(), s.segmentOffset)
+	for ; segmentIdx <= stage.segmenter.LastIndex(); segmentIdx++ {
+		state := s.getState(Unit{Segment: segmentIdx, Stage: stageIdx})
+		if state == UnitPending || state == UnitScheduled {
+			return segmentIdx
+		}
+	}
+	return segmentIdx
+}
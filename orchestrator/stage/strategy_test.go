@@ -0,0 +1,88 @@
+package stage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/streamingfast/substreams/orchestrator/plan"
+	"github.com/streamingfast/substreams/pipeline/outputmodules"
+)
+
+func TestCriticalPathStrategy_NextJob(t *testing.T) {
+	reqPlan, err := plan.BuildTier1RequestPlan(true, 10, 5, 5, 50, 50, true)
+	require.NoError(t, err)
+	stages := NewStages(
+		context.Background(),
+		outputmodules.TestGraphStagedModules(5, 5, 5, 5, 5),
+		reqPlan,
+		nil,
+		"trace",
+	)
+	strategy := CriticalPathStrategy{MaxJobsAhead: 1}
+
+	u, _ := strategy.NextJob(stages)
+	assert.Equal(t, id(0, 0), u, "first stages first")
+	strategy.NextJob(stages)
+	strategy.NextJob(stages)
+	strategy.NextJob(stages)
+
+	segmentStateEquals(t, stages, `
+S:SS
+S:S.
+M:S.`)
+
+	_, r := strategy.NextJob(stages)
+	assert.Nil(t, r, "no job more than one segment ahead of the output")
+
+	stages.MarkSegmentPartialPresent(id(0, 2))
+	u, _ = strategy.NextJob(stages)
+	assert.Equal(t, id(2, 0), u)
+
+	segmentStateEquals(t, stages, `
+S:SSS
+S:S..
+M:P..`)
+}
+
+func TestBreadthFirstStrategy_NextJob(t *testing.T) {
+	reqPlan, err := plan.BuildTier1RequestPlan(true, 10, 5, 5, 50, 50, true)
+	require.NoError(t, err)
+	stages := NewStages(
+		context.Background(),
+		outputmodules.TestGraphStagedModules(5, 5, 5, 5, 5),
+		reqPlan,
+		nil,
+		"trace",
+	)
+	strategy := BreadthFirstStrategy{}
+
+	u, _ := strategy.NextJob(stages)
+	assert.Equal(t, id(0, 2), u, "last stage first")
+	for i := 0; i < 5; i++ {
+		strategy.NextJob(stages)
+	}
+
+	segmentStateEquals(t, stages, `
+S:SSSS
+S:S...
+M:S...`)
+
+	_, r := strategy.NextJob(stages)
+	assert.Nil(t, r)
+}
+
+func TestNewSchedulingStrategy(t *testing.T) {
+	strategy, err := NewSchedulingStrategy("", 10)
+	require.NoError(t, err)
+	assert.Equal(t, BreadthFirstStrategy{}, strategy)
+
+	strategy, err = NewSchedulingStrategy("critical-path", 10)
+	require.NoError(t, err)
+	assert.Equal(t, CriticalPathStrategy{MaxJobsAhead: 10}, strategy)
+
+	_, err = NewSchedulingStrategy("depth-first", 10)
+	assert.Error(t, err)
+}
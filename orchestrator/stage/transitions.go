@@ -51,9 +51,10 @@ stateDiagram-v2
     %%  For now, we'll require that partials be merged linearly, and not support the discovery
     %%  of a complete store, when the Partial has chances to be merged in just a moment.
 
-    %%NO: Scheduled --> Pending
+    Scheduled --> Pending: job failed on a\ncorrupted store snapshot
     %%  if a job has been scheduled, it either completes, or retries on its own, but doesn't
-    %%  come back to Pending.
+    %%  come back to Pending, unless a store snapshot it loaded was found corrupted: it is
+    %%  re-run once the snapshot was produced again.
     Scheduled --> PartialPresent: job done,\npartial on disk
     %%  two ways we get to a PartialPresent state:
     %%  1. the job finishes and reports that a new Partial is awaiting merging
@@ -66,8 +67,10 @@ stateDiagram-v2
     %%  not the job scheduler, so again, transit through PartialPresent --> Merging --> Completed
 
     Merging --> Pending: squasher didn't\nfind partial
-    %%NO: Merging --> PartialPresent
-    %%  no, you're merging that partial, if you can't, we go back to Pending
+    Merging --> PartialPresent: store snapshot\nfound corrupted
+    %%  no, you're merging that partial, if you can't, we go back to Pending, unless the
+    %%  snapshot it is merged into was found corrupted: the partial is merged again once
+    %%  the snapshot was produced again.
     %%NO: Merging --> Scheduled
     %%  no
     Merging --> Completed: squasher finished\nfinal store ready for segment
@@ -103,7 +106,16 @@ func (s *Stages) MarkSegmentMerging(u Unit) {
 
 func (s *Stages) MarkSegmentPending(u Unit) {
 	s.transition(u, UnitPending,
-		UnitMerging, // Squasher didn't find the partials, so asking for the job to re-run
+		UnitMerging,   // Squasher didn't find the partials, so asking for the job to re-run
+		UnitScheduled, // job failed on a corrupted store snapshot, re-run once produced again
+	)
+}
+
+func (s *Stages) markSegmentCorrupted(u Unit) {
+	s.transition(u, UnitPending,
+		UnitCompleted,      // output files found on storage turned out corrupted
+		UnitPartialPresent, // output files written by a job turned out corrupted
+		UnitPending,        // already waiting for a job
 	)
 }
 
@@ -111,6 +123,7 @@ func (s *Stages) MarkSegmentPartialPresent(u Unit) {
 	s.transition(u, UnitPartialPresent,
 		UnitScheduled, // reported by working completing its generation of a partial
 		UnitPending,   // from initial storage state snapshot
+		UnitMerging,   // merge aborted on a corrupted store snapshot, the partials are still present
 	)
 }
 
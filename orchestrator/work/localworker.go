@@ -0,0 +1,134 @@
+package work
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/streamingfast/substreams"
+	"github.com/streamingfast/substreams/block"
+	"github.com/streamingfast/substreams/orchestrator/loop"
+	"github.com/streamingfast/substreams/orchestrator/response"
+	"github.com/streamingfast/substreams/orchestrator/stage"
+	pbssinternal "github.com/streamingfast/substreams/pb/sf/substreams/intern/v2"
+	"github.com/streamingfast/substreams/reqctx"
+)
+
+// ProcessRangeFunc processes a tier2 request in the current process, sending its
+// responses to `respFunc`. It is implemented by `service.Tier2Service.ProcessLocalRange`.
+type ProcessRangeFunc func(ctx context.Context, request *pbssinternal.ProcessRangeRequest, respFunc substreams.ResponseFunc) error
+
+// LocalWorker runs the jobs in the current process with a ProcessRangeFunc instead
+// of sending them to a tier2 server, so that a single process can serve production
+// mode requests. The local workers created by the same factory share a budget of
+// jobs running at the same time, whatever the request they work for.
+type LocalWorker struct {
+	processRange ProcessRangeFunc
+	slots        chan struct{}
+	logger       *zap.Logger
+	id           uint64
+}
+
+// NewLocalWorkerFactory returns a WorkerFactory creating LocalWorkers running at
+// most `maxConcurrentJobs` jobs at the same time across all requests.
+func NewLocalWorkerFactory(processRange ProcessRangeFunc, maxConcurrentJobs int) WorkerFactory {
+	if maxConcurrentJobs <= 0 {
+		panic("local workers need a budget of at least one job")
+	}
+	slots := make(chan struct{}, maxConcurrentJobs)
+	return func(logger *zap.Logger) Worker {
+		return NewLocalWorker(processRange, slots, logger)
+	}
+}
+
+func NewLocalWorker(processRange ProcessRangeFunc, slots chan struct{}, logger *zap.Logger) *LocalWorker {
+	return &LocalWorker{
+		processRange: processRange,
+		slots:        slots,
+		logger:       logger,
+		id:           atomic.AddUint64(&lastWorkerID, 1),
+	}
+}
+
+func (w *LocalWorker) ID() string {
+	return fmt.Sprintf("%d", w.id)
+}
+
+func (w *LocalWorker) Work(ctx context.Context, unit stage.Unit, workRange *block.Range, moduleNames []string, upstream *response.Stream) loop.Cmd {
+	request := NewRequest(reqctx.Details(ctx), unit.Stage, workRange)
+	logger := reqctx.Logger(ctx)
+
+	return func() loop.Msg {
+		select {
+		case w.slots <- struct{}{}:
+		case <-ctx.Done():
+			return MsgJobFailed{Unit: unit, Worker: w, Error: ctx.Err()}
+		}
+		defer func() { <-w.slots }()
+
+		startTime := time.Now()
+		if err := w.work(ctx, request, upstream); err != nil {
+			if errors.Is(err, context.Canceled) {
+				logger.Debug("job canceled", zap.Object("unit", unit), zap.Error(err))
+			} else {
+				logger.Warn("job failed", zap.Object("unit", unit), zap.Error(err))
+			}
+			return MsgJobFailed{Unit: unit, Worker: w, Error: err}
+		}
+
+		if err := ctx.Err(); err != nil {
+			logger.Warn("job not completed", zap.Object("unit", unit), zap.Error(err))
+			return MsgJobFailed{Unit: unit, Worker: w, Error: err}
+		}
+
+		timeTook := time.Since(startTime)
+		logger.Debug(
+			"local job completed",
+			zap.Object("unit", unit),
+			zap.Strings("module_name", moduleNames),
+			zap.Float64("duration", timeTook.Seconds()),
+			zap.Float64("processing_time_per_block", timeTook.Seconds()/float64(request.StopBlockNum-request.StartBlockNum)),
+		)
+		return MsgJobSucceeded{
+			Unit:   unit,
+			Worker: w,
+			Feedback: JobFeedback{
+				Duration:   timeTook,
+				BlockCount: request.StopBlockNum - request.StartBlockNum,
+			},
+		}
+	}
+}
+
+func (w *LocalWorker) work(ctx context.Context, request *pbssinternal.ProcessRangeRequest, upstream *response.Stream) error {
+	w.logger.Info("launching local worker",
+		zap.Uint64("start_block_num", request.StartBlockNum),
+		zap.Uint64("stop_block_num", request.StopBlockNum),
+		zap.String("output_module", request.OutputModule),
+	)
+
+	stats := reqctx.ReqStats(ctx)
+	jobIdx := stats.RecordNewSubrequest(request.Stage, request.StartBlockNum, request.StopBlockNum)
+	defer stats.RecordEndSubrequest(jobIdx)
+
+	var failure error
+	err := w.processRange(ctx, request, func(respAny substreams.ResponseFromAnyTier) error {
+		switch r := respAny.(*pbssinternal.ProcessRangeResponse).Type.(type) {
+		case *pbssinternal.ProcessRangeResponse_Update:
+			stats.RecordJobUpdate(jobIdx, r.Update)
+
+		case *pbssinternal.ProcessRangeResponse_Failed:
+			upstream.RPCFailedProgressResponse(r.Failed.Reason, r.Failed.Logs, r.Failed.LogsTruncated)
+			failure = fmt.Errorf("work failed locally: %s", r.Failed.Reason)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return failure
+}
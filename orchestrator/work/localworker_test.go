@@ -0,0 +1,75 @@
+package work
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+
+	"github.com/streamingfast/substreams"
+	"github.com/streamingfast/substreams/block"
+	"github.com/streamingfast/substreams/metrics"
+	"github.com/streamingfast/substreams/orchestrator/response"
+	"github.com/streamingfast/substreams/orchestrator/stage"
+	pbssinternal "github.com/streamingfast/substreams/pb/sf/substreams/intern/v2"
+	pbsubstreamsrpc "github.com/streamingfast/substreams/pb/sf/substreams/rpc/v2"
+	"github.com/streamingfast/substreams/reqctx"
+)
+
+func TestLocalWorker_Work(t *testing.T) {
+	stats := metrics.NewReqStats(&metrics.Config{}, zap.NewNop())
+	stats.RecordStages([]*pbsubstreamsrpc.Stage{{Modules: []string{"A"}}})
+	ctx := reqctx.WithReqStats(context.Background(), stats)
+	ctx = reqctx.WithRequest(ctx, &reqctx.RequestDetails{OutputModule: "A"})
+
+	var upstreamResponses []substreams.ResponseFromAnyTier
+	upstream := response.New(func(resp substreams.ResponseFromAnyTier) error {
+		upstreamResponses = append(upstreamResponses, resp)
+		return nil
+	})
+
+	var running, maxRunning atomic.Int64
+	factory := NewLocalWorkerFactory(func(ctx context.Context, request *pbssinternal.ProcessRangeRequest, respFunc substreams.ResponseFunc) error {
+		if current := running.Inc(); current > maxRunning.Load() {
+			maxRunning.Store(current)
+		}
+		defer running.Dec()
+		time.Sleep(10 * time.Millisecond)
+
+		assert.Equal(t, "A", request.OutputModule)
+		if request.StartBlockNum == 20 {
+			return respFunc(&pbssinternal.ProcessRangeResponse{Type: &pbssinternal.ProcessRangeResponse_Failed{Failed: &pbssinternal.Failed{Reason: "boom"}}})
+		}
+		require.NoError(t, respFunc(&pbssinternal.ProcessRangeResponse{Type: &pbssinternal.ProcessRangeResponse_Update{Update: &pbssinternal.Update{ProcessedBlocks: 10}}}))
+		return respFunc(&pbssinternal.ProcessRangeResponse{Type: &pbssinternal.ProcessRangeResponse_Completed{Completed: &pbssinternal.Completed{}}})
+	}, 1)
+
+	worker1 := factory(zap.NewNop())
+	worker2 := factory(zap.NewNop())
+	assert.NotEqual(t, worker1.ID(), worker2.ID())
+
+	done := make(chan any)
+	go func() {
+		done <- worker1.Work(ctx, stage.Unit{Segment: 0}, block.NewRange(0, 10), []string{"A"}, upstream)()
+	}()
+	go func() {
+		done <- worker2.Work(ctx, stage.Unit{Segment: 1}, block.NewRange(10, 20), []string{"A"}, upstream)()
+	}()
+
+	for i := 0; i < 2; i++ {
+		msg := (<-done).(MsgJobSucceeded)
+		assert.Equal(t, uint64(10), msg.Feedback.BlockCount)
+	}
+	assert.Equal(t, int64(1), maxRunning.Load(), "jobs over the budget wait")
+
+	msg := worker1.Work(ctx, stage.Unit{Segment: 2}, block.NewRange(20, 30), []string{"A"}, upstream)()
+	failed, ok := msg.(MsgJobFailed)
+	require.True(t, ok)
+	assert.EqualError(t, failed.Error, "work failed locally: boom")
+	require.Len(t, upstreamResponses, 1)
+	assert.Equal(t, "boom", upstreamResponses[0].(*pbsubstreamsrpc.Response).GetFatalError().Reason)
+}
@@ -8,13 +8,15 @@ import (
 // Messages
 
 type MsgJobFailed struct {
-	Unit  stage.Unit
-	Error error
+	Unit   stage.Unit
+	Worker Worker
+	Error  error
 }
 
 type MsgJobSucceeded struct {
-	Unit   stage.Unit
-	Worker Worker
+	Unit     stage.Unit
+	Worker   Worker
+	Feedback JobFeedback
 }
 
 type MsgScheduleNextJob struct{}
@@ -34,6 +34,7 @@ var lastWorkerID uint64
 type Result struct {
 	PartialFilesWritten store.FileInfos
 	Error               error
+	LoadHint            float32 // last load reported by tier2, 0 if not reported
 }
 
 type Worker interface {
@@ -85,13 +86,17 @@ func (w *RemoteWorker) ID() string {
 }
 
 func NewRequest(req *reqctx.RequestDetails, stageIndex int, workRange *block.Range) *pbssinternal.ProcessRangeRequest {
-	return &pbssinternal.ProcessRangeRequest{
+	out := &pbssinternal.ProcessRangeRequest{
 		StartBlockNum: workRange.StartBlock,
 		StopBlockNum:  workRange.ExclusiveEndBlock,
 		Modules:       req.Modules,
 		OutputModule:  req.OutputModule,
 		Stage:         uint32(stageIndex),
 	}
+	if len(req.OutputModules) > 1 {
+		out.OutputModules = req.OutputModules
+	}
+	return out
 }
 
 func (w *RemoteWorker) Work(ctx context.Context, unit stage.Unit, workRange *block.Range, moduleNames []string, upstream *response.Stream) loop.Cmd {
@@ -134,12 +139,12 @@ func (w *RemoteWorker) Work(ctx context.Context, unit stage.Unit, workRange *blo
 				zap.Duration("duration", timeTook),
 				zap.Float64("num_of_blocks_per_sec", float64(request.StopBlockNum-request.StartBlockNum)/timeTook.Seconds()),
 			)
-			return MsgJobFailed{Unit: unit, Error: err}
+			return MsgJobFailed{Unit: unit, Worker: w, Error: err}
 		}
 
 		if err := ctx.Err(); err != nil {
 			logger.Warn("job not completed", zap.Object("unit", unit), zap.Error(err))
-			return MsgJobFailed{Unit: unit, Error: err}
+			return MsgJobFailed{Unit: unit, Worker: w, Error: err}
 		}
 
 		timeTook := time.Since(startTime)
@@ -154,6 +159,12 @@ func (w *RemoteWorker) Work(ctx context.Context, unit stage.Unit, workRange *blo
 		return MsgJobSucceeded{
 			Unit:   unit,
 			Worker: w,
+			Feedback: JobFeedback{
+				Duration:   timeTook,
+				BlockCount: request.StopBlockNum - request.StartBlockNum,
+				Retries:    retryIdx,
+				LoadHint:   res.LoadHint,
+			},
 			// TODO: Clean the PartialFilesWritten from the res because it's not needed anymore.
 			//Files:  res.PartialFilesWritten,
 		}
@@ -226,6 +237,7 @@ func (w *RemoteWorker) work(ctx context.Context, request *pbssinternal.ProcessRa
 
 	span.SetAttributes(attribute.String("substreams.remote_hostname", remoteHostname))
 
+	var loadHint float32
 	for {
 		resp, err := stream.Recv()
 
@@ -237,6 +249,7 @@ func (w *RemoteWorker) work(ctx context.Context, request *pbssinternal.ProcessRa
 		}
 
 		if resp != nil {
+			loadHint = resp.LoadHint
 			switch r := resp.Type.(type) {
 			case *pbssinternal.ProcessRangeResponse_Update:
 				stats.RecordJobUpdate(jobIdx, r.Update)
@@ -256,18 +269,20 @@ func (w *RemoteWorker) work(ctx context.Context, request *pbssinternal.ProcessRa
 				logger.Debug("worker done")
 				return &Result{
 					PartialFilesWritten: toRPCPartialFiles(r.Completed),
+					LoadHint:            loadHint,
 				}
 			}
 		}
 
 		if err != nil {
 			if err == io.EOF {
-				return &Result{}
+				return &Result{LoadHint: loadHint}
 			}
 			if ctx.Err() != nil {
 				return &Result{Error: ctx.Err()}
 			}
-			if grpcErr := dgrpc.AsGRPCError(err); grpcErr.Code() == codes.InvalidArgument {
+			switch dgrpc.AsGRPCError(err).Code() {
+			case codes.InvalidArgument, codes.DataLoss:
 				return &Result{Error: err}
 			}
 			return &Result{
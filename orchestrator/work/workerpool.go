@@ -9,9 +9,40 @@ import (
 	"github.com/streamingfast/substreams/reqctx"
 )
 
+// Jobs are signs of an overloaded tier2 when they were retried, when tier2
+// reported a load above overloadedLoadHint, or when their processing time per
+// block is more than overloadedLatencyFactor times the average of their stage.
+const (
+	overloadedLoadHint      = 1.0
+	overloadedLatencyFactor = 3.0
+
+	// sizeDecreaseFactor is applied to the pool size on signs of overload
+	sizeDecreaseFactor = 0.5
+	// latencySmoothing is the weight of the last job in the average processing time per block
+	latencySmoothing = 0.2
+)
+
+// WorkerPool lends its workers to the jobs, up to its size, which adapts to the
+// load of tier2 (additive increase, multiplicative decrease): it is halved when
+// a job shows signs of overload, and grows back by one worker every `size` jobs
+// completing without, up to the number of workers.
 type WorkerPool struct {
 	workers []*WorkerStatus
 	started *time.Time
+
+	size         float64
+	lastDecrease time.Time
+	blockLatency map[int]time.Duration // average processing time per block of the jobs, by stage
+
+	logger *zap.Logger
+}
+
+// JobFeedback is what a completed job tells about the load of tier2.
+type JobFeedback struct {
+	Duration   time.Duration // time the job took, retries included
+	BlockCount uint64
+	Retries    int     // number of retryable errors the job went through
+	LoadHint   float32 // last load reported by tier2, 0 if not reported
 }
 
 type WorkerState int
@@ -46,8 +77,11 @@ func NewWorkerPool(ctx context.Context, workerCount int, workerFactory WorkerFac
 
 	now := time.Now()
 	return &WorkerPool{
-		workers: workers,
-		started: &now,
+		workers:      workers,
+		started:      &now,
+		size:         float64(workerCount),
+		blockLatency: make(map[int]time.Duration),
+		logger:       logger,
 	}
 }
 
@@ -72,6 +106,9 @@ func (p *WorkerPool) WorkerAvailable() (avail bool, shouldRetry bool) {
 	if p.inRampupPhase() {
 		p.rampupWorkers()
 	}
+	if p.working() >= p.Size() {
+		return false, p.inRampupPhase()
+	}
 	for _, w := range p.workers {
 		if w.State == WorkerFree {
 			return true, false
@@ -101,3 +138,72 @@ func (p *WorkerPool) Return(worker Worker) {
 		}
 	}
 }
+
+// Size returns the number of workers that can be working at the same time.
+func (p *WorkerPool) Size() int {
+	return max(1, int(p.size))
+}
+
+func (p *WorkerPool) working() (count int) {
+	for _, w := range p.workers {
+		if w.State == WorkerWorking {
+			count++
+		}
+	}
+	return
+}
+
+// RecordJobFeedback adapts the size of the pool to the feedback of a job of
+// `stage` that completed.
+func (p *WorkerPool) RecordJobFeedback(stage int, feedback JobFeedback) {
+	now := time.Now()
+	if reason := p.overloadReason(stage, feedback); reason != "" {
+		// jobs started before the last decrease ran with more workers in parallel, they
+		// don't tell anything about the current size
+		if now.Add(-feedback.Duration).Before(p.lastDecrease) {
+			return
+		}
+		p.lastDecrease = now
+
+		previousSize := p.Size()
+		p.size = max(1, p.size*sizeDecreaseFactor)
+		p.logger.Info("tier2 overloaded, decreasing worker pool size",
+			zap.String("reason", reason),
+			zap.Int("previous_size", previousSize),
+			zap.Int("size", p.Size()),
+		)
+		return
+	}
+
+	if p.size < float64(len(p.workers)) {
+		previousSize := p.Size()
+		p.size = min(float64(len(p.workers)), p.size+1/p.size)
+		if p.Size() != previousSize {
+			p.logger.Debug("increasing worker pool size", zap.Int("size", p.Size()))
+		}
+	}
+}
+
+func (p *WorkerPool) overloadReason(stage int, feedback JobFeedback) string {
+	if feedback.Retries != 0 {
+		return "job retried"
+	}
+	if feedback.LoadHint > overloadedLoadHint {
+		return "load reported by tier2"
+	}
+	if feedback.BlockCount == 0 {
+		return ""
+	}
+
+	latency := feedback.Duration / time.Duration(feedback.BlockCount)
+	average, found := p.blockLatency[stage]
+	if !found {
+		p.blockLatency[stage] = latency
+		return ""
+	}
+	p.blockLatency[stage] = time.Duration(latencySmoothing*float64(latency) + (1-latencySmoothing)*float64(average))
+	if float64(latency) > overloadedLatencyFactor*float64(average) {
+		return "job latency"
+	}
+	return ""
+}
@@ -55,3 +55,53 @@ func Test_workerPoolPool_Borrow_Return(t *testing.T) {
 	pi.Return(worker1)
 	assert.Panics(t, func() { pi.Return(worker1) })
 }
+
+func TestWorkerPool_RecordJobFeedback(t *testing.T) {
+	ctx := context.Background()
+	pi := NewWorkerPool(ctx, 4, func(logger *zap.Logger) Worker {
+		return NewWorkerFactoryFromFunc(func(ctx context.Context, unit stage.Unit, workRange *block.Range, moduleNames []string, upstream *response.Stream) loop.Cmd {
+			return nil
+		})
+	})
+	newStarted := (*pi.started).Add(-5 * time.Second)
+	pi.started = &newStarted
+	assert.Equal(t, 4, pi.Size())
+
+	healthy := JobFeedback{Duration: 10 * time.Second, BlockCount: 100}
+
+	pi.RecordJobFeedback(0, JobFeedback{Retries: 1})
+	assert.Equal(t, 2, pi.Size(), "retried job")
+
+	pi.RecordJobFeedback(0, JobFeedback{Duration: time.Hour, Retries: 1})
+	assert.Equal(t, 2, pi.Size(), "job started before the last decrease")
+
+	pi.RecordJobFeedback(0, JobFeedback{LoadHint: 1.5})
+	assert.Equal(t, 1, pi.Size(), "tier2 overloaded")
+
+	pi.RecordJobFeedback(0, JobFeedback{LoadHint: 1.5})
+	assert.Equal(t, 1, pi.Size(), "never under one worker")
+
+	worker := pi.Borrow()
+	avail, _ := pi.WorkerAvailable()
+	assert.False(t, avail, "free workers over the size are not available")
+	pi.Return(worker)
+
+	pi.RecordJobFeedback(0, healthy)
+	assert.Equal(t, 2, pi.Size())
+	pi.RecordJobFeedback(0, healthy)
+	assert.Equal(t, 2, pi.Size(), "grows by one worker every `size` jobs")
+	pi.RecordJobFeedback(0, healthy)
+	pi.RecordJobFeedback(0, JobFeedback{Duration: 10 * time.Second, BlockCount: 100, LoadHint: 0.9})
+	assert.Equal(t, 3, pi.Size())
+	for i := 0; i < 10; i++ {
+		pi.RecordJobFeedback(0, healthy)
+	}
+	assert.Equal(t, 4, pi.Size(), "never over the number of workers")
+
+	pi.RecordJobFeedback(1, JobFeedback{Duration: 50 * time.Second, BlockCount: 100})
+	assert.Equal(t, 4, pi.Size(), "latency compared to the jobs of the same stage")
+
+	pi.lastDecrease = pi.lastDecrease.Add(-time.Hour)
+	pi.RecordJobFeedback(0, JobFeedback{Duration: 50 * time.Second, BlockCount: 100})
+	assert.Equal(t, 2, pi.Size(), "job latency")
+}
@@ -73,6 +73,64 @@ func (StoreDelta_Operation) EnumDescriptor() ([]byte, []int) {
 	return file_sf_substreams_intern_v2_deltas_proto_rawDescGZIP(), []int{1, 0}
 }
 
+type ModuleLog_Level int32
+
+const (
+	ModuleLog_UNSET ModuleLog_Level = 0 // emitted through `logger.println`
+	ModuleLog_TRACE ModuleLog_Level = 1
+	ModuleLog_DEBUG ModuleLog_Level = 2
+	ModuleLog_INFO  ModuleLog_Level = 3
+	ModuleLog_WARN  ModuleLog_Level = 4
+	ModuleLog_ERROR ModuleLog_Level = 5
+)
+
+// Enum value maps for ModuleLog_Level.
+var (
+	ModuleLog_Level_name = map[int32]string{
+		0: "UNSET",
+		1: "TRACE",
+		2: "DEBUG",
+		3: "INFO",
+		4: "WARN",
+		5: "ERROR",
+	}
+	ModuleLog_Level_value = map[string]int32{
+		"UNSET": 0,
+		"TRACE": 1,
+		"DEBUG": 2,
+		"INFO":  3,
+		"WARN":  4,
+		"ERROR": 5,
+	}
+)
+
+func (x ModuleLog_Level) Enum() *ModuleLog_Level {
+	p := new(ModuleLog_Level)
+	*p = x
+	return p
+}
+
+func (x ModuleLog_Level) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ModuleLog_Level) Descriptor() protoreflect.EnumDescriptor {
+	return file_sf_substreams_intern_v2_deltas_proto_enumTypes[1].Descriptor()
+}
+
+func (ModuleLog_Level) Type() protoreflect.EnumType {
+	return &file_sf_substreams_intern_v2_deltas_proto_enumTypes[1]
+}
+
+func (x ModuleLog_Level) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ModuleLog_Level.Descriptor instead.
+func (ModuleLog_Level) EnumDescriptor() ([]byte, []int) {
+	return file_sf_substreams_intern_v2_deltas_proto_rawDescGZIP(), []int{3, 0}
+}
+
 type StoreDeltas struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -213,6 +271,9 @@ type ModuleOutput struct {
 	Logs               []string            `protobuf:"bytes,4,rep,name=logs,proto3" json:"logs,omitempty"`
 	DebugLogsTruncated bool                `protobuf:"varint,5,opt,name=debug_logs_truncated,json=debugLogsTruncated,proto3" json:"debug_logs_truncated,omitempty"`
 	Cached             bool                `protobuf:"varint,6,opt,name=cached,proto3" json:"cached,omitempty"`
+	// Structured logs hold all the logs of the execution, in the order of `logs`,
+	// when the module emitted at least one through the `logger.log` import.
+	StructuredLogs []*ModuleLog `protobuf:"bytes,7,rep,name=structured_logs,json=structuredLogs,proto3" json:"structured_logs,omitempty"`
 }
 
 func (x *ModuleOutput) Reset() {
@@ -296,6 +357,13 @@ func (x *ModuleOutput) GetCached() bool {
 	return false
 }
 
+func (x *ModuleOutput) GetStructuredLogs() []*ModuleLog {
+	if x != nil {
+		return x.StructuredLogs
+	}
+	return nil
+}
+
 type isModuleOutput_Data interface {
 	isModuleOutput_Data()
 }
@@ -312,6 +380,124 @@ func (*ModuleOutput_MapOutput) isModuleOutput_Data() {}
 
 func (*ModuleOutput_StoreDeltas) isModuleOutput_Data() {}
 
+type ModuleLog struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Level   ModuleLog_Level `protobuf:"varint,1,opt,name=level,proto3,enum=sf.substreams.internal.v2.ModuleLog_Level" json:"level,omitempty"`
+	Message string          `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Fields  []*LogField     `protobuf:"bytes,3,rep,name=fields,proto3" json:"fields,omitempty"`
+}
+
+func (x *ModuleLog) Reset() {
+	*x = ModuleLog{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sf_substreams_intern_v2_deltas_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ModuleLog) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModuleLog) ProtoMessage() {}
+
+func (x *ModuleLog) ProtoReflect() protoreflect.Message {
+	mi := &file_sf_substreams_intern_v2_deltas_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModuleLog.ProtoReflect.Descriptor instead.
+func (*ModuleLog) Descriptor() ([]byte, []int) {
+	return file_sf_substreams_intern_v2_deltas_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ModuleLog) GetLevel() ModuleLog_Level {
+	if x != nil {
+		return x.Level
+	}
+	return ModuleLog_UNSET
+}
+
+func (x *ModuleLog) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ModuleLog) GetFields() []*LogField {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+type LogField struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key   string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *LogField) Reset() {
+	*x = LogField{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sf_substreams_intern_v2_deltas_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LogField) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogField) ProtoMessage() {}
+
+func (x *LogField) ProtoReflect() protoreflect.Message {
+	mi := &file_sf_substreams_intern_v2_deltas_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogField.ProtoReflect.Descriptor instead.
+func (*LogField) Descriptor() ([]byte, []int) {
+	return file_sf_substreams_intern_v2_deltas_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *LogField) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *LogField) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
 var File_sf_substreams_intern_v2_deltas_proto protoreflect.FileDescriptor
 
 var file_sf_substreams_intern_v2_deltas_proto_rawDesc = []byte{
@@ -342,7 +528,7 @@ var file_sf_substreams_intern_v2_deltas_proto_rawDesc = []byte{
 	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x09, 0x0a, 0x05, 0x55, 0x4e, 0x53, 0x45, 0x54, 0x10,
 	0x00, 0x12, 0x0a, 0x0a, 0x06, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45, 0x10, 0x01, 0x12, 0x0a, 0x0a,
 	0x06, 0x55, 0x50, 0x44, 0x41, 0x54, 0x45, 0x10, 0x02, 0x12, 0x0a, 0x0a, 0x06, 0x44, 0x45, 0x4c,
-	0x45, 0x54, 0x45, 0x10, 0x03, 0x22, 0x99, 0x02, 0x0a, 0x0c, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65,
+	0x45, 0x54, 0x45, 0x10, 0x03, 0x22, 0xe8, 0x02, 0x0a, 0x0c, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65,
 	0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65,
 	0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6d, 0x6f, 0x64,
 	0x75, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x35, 0x0a, 0x0a, 0x6d, 0x61, 0x70, 0x5f, 0x6f,
@@ -359,13 +545,36 @@ var file_sf_substreams_intern_v2_deltas_proto_rawDesc = []byte{
 	0x75, 0x6e, 0x63, 0x61, 0x74, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x12, 0x64,
 	0x65, 0x62, 0x75, 0x67, 0x4c, 0x6f, 0x67, 0x73, 0x54, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65,
 	0x64, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x61, 0x63, 0x68, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28,
-	0x08, 0x52, 0x06, 0x63, 0x61, 0x63, 0x68, 0x65, 0x64, 0x42, 0x06, 0x0a, 0x04, 0x64, 0x61, 0x74,
-	0x61, 0x42, 0x4d, 0x5a, 0x4b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
-	0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x69, 0x6e, 0x67, 0x66, 0x61, 0x73, 0x74, 0x2f, 0x73, 0x75,
-	0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2f, 0x70, 0x62, 0x2f, 0x73, 0x66, 0x2f, 0x73,
-	0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e,
-	0x2f, 0x76, 0x32, 0x3b, 0x70, 0x62, 0x73, 0x73, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c,
-	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x08, 0x52, 0x06, 0x63, 0x61, 0x63, 0x68, 0x65, 0x64, 0x12, 0x4d, 0x0a, 0x0f, 0x73, 0x74, 0x72,
+	0x75, 0x63, 0x74, 0x75, 0x72, 0x65, 0x64, 0x5f, 0x6c, 0x6f, 0x67, 0x73, 0x18, 0x07, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x24, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x73, 0x2e, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2e, 0x76, 0x32, 0x2e, 0x4d,
+	0x6f, 0x64, 0x75, 0x6c, 0x65, 0x4c, 0x6f, 0x67, 0x52, 0x0e, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74,
+	0x75, 0x72, 0x65, 0x64, 0x4c, 0x6f, 0x67, 0x73, 0x42, 0x06, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61,
+	0x22, 0xed, 0x01, 0x0a, 0x09, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x4c, 0x6f, 0x67, 0x12, 0x40,
+	0x0a, 0x05, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x2a, 0x2e,
+	0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x69, 0x6e,
+	0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2e, 0x76, 0x32, 0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65,
+	0x4c, 0x6f, 0x67, 0x2e, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x52, 0x05, 0x6c, 0x65, 0x76, 0x65, 0x6c,
+	0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x3b, 0x0a, 0x06, 0x66, 0x69,
+	0x65, 0x6c, 0x64, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x73, 0x66, 0x2e,
+	0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x69, 0x6e, 0x74, 0x65, 0x72,
+	0x6e, 0x61, 0x6c, 0x2e, 0x76, 0x32, 0x2e, 0x4c, 0x6f, 0x67, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x52,
+	0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x22, 0x47, 0x0a, 0x05, 0x4c, 0x65, 0x76, 0x65, 0x6c,
+	0x12, 0x09, 0x0a, 0x05, 0x55, 0x4e, 0x53, 0x45, 0x54, 0x10, 0x00, 0x12, 0x09, 0x0a, 0x05, 0x54,
+	0x52, 0x41, 0x43, 0x45, 0x10, 0x01, 0x12, 0x09, 0x0a, 0x05, 0x44, 0x45, 0x42, 0x55, 0x47, 0x10,
+	0x02, 0x12, 0x08, 0x0a, 0x04, 0x49, 0x4e, 0x46, 0x4f, 0x10, 0x03, 0x12, 0x08, 0x0a, 0x04, 0x57,
+	0x41, 0x52, 0x4e, 0x10, 0x04, 0x12, 0x09, 0x0a, 0x05, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x05,
+	0x22, 0x32, 0x0a, 0x08, 0x4c, 0x6f, 0x67, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x12, 0x10, 0x0a, 0x03,
+	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14,
+	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x42, 0x4d, 0x5a, 0x4b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63,
+	0x6f, 0x6d, 0x2f, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x69, 0x6e, 0x67, 0x66, 0x61, 0x73, 0x74,
+	0x2f, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2f, 0x70, 0x62, 0x2f, 0x73,
+	0x66, 0x2f, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2f, 0x69, 0x6e, 0x74,
+	0x65, 0x72, 0x6e, 0x2f, 0x76, 0x32, 0x3b, 0x70, 0x62, 0x73, 0x73, 0x69, 0x6e, 0x74, 0x65, 0x72,
+	0x6e, 0x61, 0x6c, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -380,25 +589,31 @@ func file_sf_substreams_intern_v2_deltas_proto_rawDescGZIP() []byte {
 	return file_sf_substreams_intern_v2_deltas_proto_rawDescData
 }
 
-var file_sf_substreams_intern_v2_deltas_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_sf_substreams_intern_v2_deltas_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_sf_substreams_intern_v2_deltas_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_sf_substreams_intern_v2_deltas_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
 var file_sf_substreams_intern_v2_deltas_proto_goTypes = []interface{}{
 	(StoreDelta_Operation)(0), // 0: sf.substreams.internal.v2.StoreDelta.Operation
-	(*StoreDeltas)(nil),       // 1: sf.substreams.internal.v2.StoreDeltas
-	(*StoreDelta)(nil),        // 2: sf.substreams.internal.v2.StoreDelta
-	(*ModuleOutput)(nil),      // 3: sf.substreams.internal.v2.ModuleOutput
-	(*anypb.Any)(nil),         // 4: google.protobuf.Any
+	(ModuleLog_Level)(0),      // 1: sf.substreams.internal.v2.ModuleLog.Level
+	(*StoreDeltas)(nil),       // 2: sf.substreams.internal.v2.StoreDeltas
+	(*StoreDelta)(nil),        // 3: sf.substreams.internal.v2.StoreDelta
+	(*ModuleOutput)(nil),      // 4: sf.substreams.internal.v2.ModuleOutput
+	(*ModuleLog)(nil),         // 5: sf.substreams.internal.v2.ModuleLog
+	(*LogField)(nil),          // 6: sf.substreams.internal.v2.LogField
+	(*anypb.Any)(nil),         // 7: google.protobuf.Any
 }
 var file_sf_substreams_intern_v2_deltas_proto_depIdxs = []int32{
-	2, // 0: sf.substreams.internal.v2.StoreDeltas.store_deltas:type_name -> sf.substreams.internal.v2.StoreDelta
+	3, // 0: sf.substreams.internal.v2.StoreDeltas.store_deltas:type_name -> sf.substreams.internal.v2.StoreDelta
 	0, // 1: sf.substreams.internal.v2.StoreDelta.operation:type_name -> sf.substreams.internal.v2.StoreDelta.Operation
-	4, // 2: sf.substreams.internal.v2.ModuleOutput.map_output:type_name -> google.protobuf.Any
-	1, // 3: sf.substreams.internal.v2.ModuleOutput.store_deltas:type_name -> sf.substreams.internal.v2.StoreDeltas
-	4, // [4:4] is the sub-list for method output_type
-	4, // [4:4] is the sub-list for method input_type
-	4, // [4:4] is the sub-list for extension type_name
-	4, // [4:4] is the sub-list for extension extendee
-	0, // [0:4] is the sub-list for field type_name
+	7, // 2: sf.substreams.internal.v2.ModuleOutput.map_output:type_name -> google.protobuf.Any
+	2, // 3: sf.substreams.internal.v2.ModuleOutput.store_deltas:type_name -> sf.substreams.internal.v2.StoreDeltas
+	5, // 4: sf.substreams.internal.v2.ModuleOutput.structured_logs:type_name -> sf.substreams.internal.v2.ModuleLog
+	1, // 5: sf.substreams.internal.v2.ModuleLog.level:type_name -> sf.substreams.internal.v2.ModuleLog.Level
+	6, // 6: sf.substreams.internal.v2.ModuleLog.fields:type_name -> sf.substreams.internal.v2.LogField
+	7, // [7:7] is the sub-list for method output_type
+	7, // [7:7] is the sub-list for method input_type
+	7, // [7:7] is the sub-list for extension type_name
+	7, // [7:7] is the sub-list for extension extendee
+	0, // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_sf_substreams_intern_v2_deltas_proto_init() }
@@ -443,6 +658,30 @@ func file_sf_substreams_intern_v2_deltas_proto_init() {
 				return nil
 			}
 		}
+		file_sf_substreams_intern_v2_deltas_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ModuleLog); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sf_substreams_intern_v2_deltas_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LogField); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	file_sf_substreams_intern_v2_deltas_proto_msgTypes[2].OneofWrappers = []interface{}{
 		(*ModuleOutput_MapOutput)(nil),
@@ -453,8 +692,8 @@ func file_sf_substreams_intern_v2_deltas_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_sf_substreams_intern_v2_deltas_proto_rawDesc,
-			NumEnums:      1,
-			NumMessages:   3,
+			NumEnums:      2,
+			NumMessages:   5,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -2,8 +2,6 @@ package pbssinternal
 
 import (
 	"fmt"
-
-	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
 )
 
 func (r *ProcessRangeRequest) Validate() error {
@@ -19,19 +17,28 @@ func (r *ProcessRangeRequest) Validate() error {
 		return fmt.Errorf("no output module defined in request")
 	}
 
-	seenStores := map[string]bool{}
-	outputModuleFound := false
+	if len(r.OutputModules) != 0 && r.OutputModules[0] != r.OutputModule {
+		return fmt.Errorf("first output module %q should be the output module %q", r.OutputModules[0], r.OutputModule)
+	}
+
+	seenModules := map[string]bool{}
 	for _, mod := range r.Modules.Modules {
-		if _, ok := mod.Kind.(*pbsubstreams.Module_KindStore_); ok {
-			seenStores[mod.Name] = true
-		}
-		if mod.Name == r.OutputModule { // internal request can have store or module output
-			outputModuleFound = true
-		}
+		seenModules[mod.Name] = true
 	}
-	if !outputModuleFound {
-		return fmt.Errorf("output module %q not found in modules", r.OutputModule)
+	for _, name := range r.OutputModuleNames() { // internal request can have store or module output
+		if !seenModules[name] {
+			return fmt.Errorf("output module %q not found in modules", name)
+		}
 	}
 
 	return nil
 }
+
+// OutputModuleNames returns the output modules of the tier1 request, `OutputModule`
+// being the first one.
+func (r *ProcessRangeRequest) OutputModuleNames() []string {
+	if len(r.OutputModules) != 0 {
+		return r.OutputModules
+	}
+	return []string{r.OutputModule}
+}
@@ -2,6 +2,7 @@ package pbsubstreamsrpc
 
 import (
 	"fmt"
+	"strings"
 
 	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
 )
@@ -54,6 +55,9 @@ func (s *StoreModuleOutput) ToAny() *AnyModuleOutput {
 
 func (bd *BlockScopedData) AllModuleOutputs() (out []*AnyModuleOutput) {
 	out = append(out, bd.Output.ToAny())
+	for _, mapOut := range bd.Outputs {
+		out = append(out, mapOut.ToAny())
+	}
 	for _, mapOut := range bd.DebugMapOutputs {
 		out = append(out, mapOut.ToAny())
 	}
@@ -63,6 +67,62 @@ func (bd *BlockScopedData) AllModuleOutputs() (out []*AnyModuleOutput) {
 	return
 }
 
+// ParseLogLevel parses a log level name, case-insensitively: `trace`, `debug`, `info`,
+// `warn` or `error`. An empty name is UNSET, which filters no log.
+func ParseLogLevel(in string) (ModuleLog_Level, error) {
+	if in == "" {
+		return ModuleLog_UNSET, nil
+	}
+	level, ok := ModuleLog_Level_value[strings.ToUpper(in)]
+	if !ok || level == int32(ModuleLog_UNSET) {
+		return ModuleLog_UNSET, fmt.Errorf("invalid log level %q, must be one of trace, debug, info, warn or error", in)
+	}
+	return ModuleLog_Level(level), nil
+}
+
+// LogLevel returns the level of the log at `index` in `Logs`, INFO for the logs
+// emitted without a level.
+func (d *OutputDebugInfo) LogLevel(index int) ModuleLog_Level {
+	if len(d.StructuredLogs) != len(d.Logs) || d.StructuredLogs[index].Level == ModuleLog_UNSET {
+		return ModuleLog_INFO
+	}
+	return d.StructuredLogs[index].Level
+}
+
+// FilterLogs returns the indexes in `Logs` of the logs at `minLevel` or above, all of
+// them if `minLevel` is UNSET.
+func (d *OutputDebugInfo) FilterLogs(minLevel ModuleLog_Level) (indexes []int) {
+	for i := range d.Logs {
+		if minLevel == ModuleLog_UNSET || d.LogLevel(i) >= minLevel {
+			indexes = append(indexes, i)
+		}
+	}
+	return
+}
+
+// AllOutputs returns the outputs of the output modules of the request: `Output` followed
+// by `Outputs`.
+func (bd *BlockScopedData) AllOutputs() (out []*MapModuleOutput) {
+	if bd.Output != nil {
+		out = append(out, bd.Output)
+	}
+	return append(out, bd.Outputs...)
+}
+
+// OutputModuleNames returns the output modules of the request: `OutputModule` if set,
+// followed by the `OutputModules` not already listed.
+func (req *Request) OutputModuleNames() (out []string) {
+	seen := map[string]bool{}
+	for _, name := range append([]string{req.OutputModule}, req.OutputModules...) {
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		out = append(out, name)
+	}
+	return
+}
+
 func (req *Request) Validate() error {
 	seenStores := map[string]bool{}
 
@@ -70,7 +130,8 @@ func (req *Request) Validate() error {
 		return fmt.Errorf("no modules found in request")
 	}
 
-	if req.OutputModule == "" {
+	outputModules := req.OutputModuleNames()
+	if len(outputModules) == 0 {
 		return fmt.Errorf("no output module defined in request")
 	}
 
@@ -78,20 +139,21 @@ func (req *Request) Validate() error {
 		return fmt.Errorf("cannot set 'debug-modules-initial-snapshot' in 'production-mode'")
 	}
 
-	outputModuleFound := false
+	modules := map[string]*pbsubstreams.Module{}
 	for _, mod := range req.Modules.Modules {
 		if _, ok := mod.Kind.(*pbsubstreams.Module_KindStore_); ok {
 			seenStores[mod.Name] = true
 		}
-		if mod.Name == req.OutputModule {
-			if _, ok := mod.Kind.(*pbsubstreams.Module_KindStore_); ok {
-				return fmt.Errorf("output module must be of kind 'map'")
-			}
-			outputModuleFound = true
-		}
+		modules[mod.Name] = mod
 	}
-	if !outputModuleFound {
-		return fmt.Errorf("output module %q not found in modules", req.OutputModule)
+	for _, name := range outputModules {
+		mod, found := modules[name]
+		if !found {
+			return fmt.Errorf("output module %q not found in modules", name)
+		}
+		if _, ok := mod.Kind.(*pbsubstreams.Module_KindStore_); ok {
+			return fmt.Errorf("output module must be of kind 'map'")
+		}
 	}
 
 	for _, storeSnapshot := range req.DebugInitialStoreSnapshotForModules {
@@ -19,6 +19,11 @@ func Test_ValidateRequest(t *testing.T) {
 		{"negative start block num", TestNewRequest(-1, withTestOutputModule("output_mod_1"), withTestMapModule("output_mod_1")), nil},
 		{"no modules found in request", &Request{StartBlockNum: 1}, fmt.Errorf("no modules found in request")},
 		{"store output module is accepted for sub-request", TestNewRequest(1, withTestOutputModule("output_mod_1"), withTestStoreModule("output_mod_1")), fmt.Errorf("output module must be of kind 'map'")},
+		{"multiple output modules", TestNewRequest(1, withTestOutputModules("output_mod_1", "output_mod_2"), withTestMapModule("output_mod_1"), withTestMapModule("output_mod_2"), withProductionMode()), nil},
+		{"multiple output modules with output module", TestNewRequest(1, withTestOutputModule("output_mod_1"), withTestOutputModules("output_mod_2"), withTestMapModule("output_mod_1"), withTestMapModule("output_mod_2")), nil},
+		{"no output module", TestNewRequest(1, withTestMapModule("output_mod_1")), fmt.Errorf("no output module defined in request")},
+		{"one of output modules not found", TestNewRequest(1, withTestOutputModules("output_mod_1", "output_mod_2"), withTestMapModule("output_mod_1")), fmt.Errorf("output module \"output_mod_2\" not found in modules")},
+		{"store in output modules", TestNewRequest(1, withTestOutputModules("output_mod_1", "store_mod"), withTestMapModule("output_mod_1"), withTestStoreModule("store_mod")), fmt.Errorf("output module must be of kind 'map'")},
 		{"production mode should fail with debug flag", TestNewRequest(1, withTestOutputModule("output_mod_1"), withTestMapModule("output_mod_1"), withProductionMode(), withDebugSnapshotsModule("output_mod_1")), fmt.Errorf("cannot set 'debug-modules-initial-snapshot' in 'production-mode'")},
 	}
 
@@ -34,3 +39,45 @@ func Test_ValidateRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestRequest_OutputModuleNames(t *testing.T) {
+	assert.Equal(t, []string{"a"}, (&Request{OutputModule: "a"}).OutputModuleNames())
+	assert.Equal(t, []string{"a", "b"}, (&Request{OutputModules: []string{"a", "b"}}).OutputModuleNames())
+	assert.Equal(t, []string{"a", "b", "c"}, (&Request{OutputModule: "a", OutputModules: []string{"b", "a", "c", "b"}}).OutputModuleNames())
+	assert.Nil(t, (&Request{}).OutputModuleNames())
+}
+
+func TestOutputDebugInfo_FilterLogs(t *testing.T) {
+	debugInfo := &OutputDebugInfo{
+		Logs: []string{"plain", "[DEBUG] details", "[ERROR] failure"},
+		StructuredLogs: []*ModuleLog{
+			{Message: "plain"},
+			{Level: ModuleLog_DEBUG, Message: "details"},
+			{Level: ModuleLog_ERROR, Message: "failure"},
+		},
+	}
+
+	assert.Equal(t, []int{0, 1, 2}, debugInfo.FilterLogs(ModuleLog_UNSET))
+	assert.Equal(t, []int{0, 1, 2}, debugInfo.FilterLogs(ModuleLog_TRACE))
+	assert.Equal(t, []int{0, 2}, debugInfo.FilterLogs(ModuleLog_INFO))
+	assert.Equal(t, []int{2}, debugInfo.FilterLogs(ModuleLog_ERROR))
+
+	// without structured logs, all logs are INFO
+	assert.Len(t, (&OutputDebugInfo{Logs: debugInfo.Logs}).FilterLogs(ModuleLog_INFO), 3)
+	assert.Empty(t, (&OutputDebugInfo{Logs: debugInfo.Logs}).FilterLogs(ModuleLog_WARN))
+}
+
+func TestParseLogLevel(t *testing.T) {
+	level, err := ParseLogLevel("")
+	require.NoError(t, err)
+	assert.Equal(t, ModuleLog_UNSET, level)
+
+	level, err = ParseLogLevel("Warn")
+	require.NoError(t, err)
+	assert.Equal(t, ModuleLog_WARN, level)
+
+	_, err = ParseLogLevel("unset")
+	assert.Error(t, err)
+	_, err = ParseLogLevel("verbose")
+	assert.Error(t, err)
+}
@@ -36,18 +36,32 @@ const (
 	Module_KindStore_UPDATE_POLICY_MAX Module_KindStore_UpdatePolicy = 5
 	// Provides a store where you can `append()` keys, where two stores merge by concatenating the bytes in order.
 	Module_KindStore_UPDATE_POLICY_APPEND Module_KindStore_UpdatePolicy = 6
+	// Provides a store where you can `set_sum_*()` keys, with values prefixed by `set:` to reset the key or by `sum:` to add to it.
+	// Two stores merge by replacing the value when the later one holds a `set:` value, and by summing them otherwise.
+	Module_KindStore_UPDATE_POLICY_SET_SUM Module_KindStore_UpdatePolicy = 7
+	// Provides a store where you can `bitwise_or_*()` keys, where two stores merge by OR-ing their values.
+	Module_KindStore_UPDATE_POLICY_BITWISE_OR Module_KindStore_UpdatePolicy = 8
+	// Provides a store where you can `bitwise_and_*()` keys, where two stores merge by AND-ing their values.
+	Module_KindStore_UPDATE_POLICY_BITWISE_AND Module_KindStore_UpdatePolicy = 9
+	// Provides a store where you can `cardinality_add()` items to keys, each holding a HyperLogLog sketch estimating
+	// the number of distinct items added, where two stores merge by keeping the maximum of each sketch register.
+	Module_KindStore_UPDATE_POLICY_CARDINALITY Module_KindStore_UpdatePolicy = 10
 )
 
 // Enum value maps for Module_KindStore_UpdatePolicy.
 var (
 	Module_KindStore_UpdatePolicy_name = map[int32]string{
-		0: "UPDATE_POLICY_UNSET",
-		1: "UPDATE_POLICY_SET",
-		2: "UPDATE_POLICY_SET_IF_NOT_EXISTS",
-		3: "UPDATE_POLICY_ADD",
-		4: "UPDATE_POLICY_MIN",
-		5: "UPDATE_POLICY_MAX",
-		6: "UPDATE_POLICY_APPEND",
+		0:  "UPDATE_POLICY_UNSET",
+		1:  "UPDATE_POLICY_SET",
+		2:  "UPDATE_POLICY_SET_IF_NOT_EXISTS",
+		3:  "UPDATE_POLICY_ADD",
+		4:  "UPDATE_POLICY_MIN",
+		5:  "UPDATE_POLICY_MAX",
+		6:  "UPDATE_POLICY_APPEND",
+		7:  "UPDATE_POLICY_SET_SUM",
+		8:  "UPDATE_POLICY_BITWISE_OR",
+		9:  "UPDATE_POLICY_BITWISE_AND",
+		10: "UPDATE_POLICY_CARDINALITY",
 	}
 	Module_KindStore_UpdatePolicy_value = map[string]int32{
 		"UPDATE_POLICY_UNSET":             0,
@@ -57,6 +71,10 @@ var (
 		"UPDATE_POLICY_MIN":               4,
 		"UPDATE_POLICY_MAX":               5,
 		"UPDATE_POLICY_APPEND":            6,
+		"UPDATE_POLICY_SET_SUM":           7,
+		"UPDATE_POLICY_BITWISE_OR":        8,
+		"UPDATE_POLICY_BITWISE_AND":       9,
+		"UPDATE_POLICY_CARDINALITY":       10,
 	}
 )
 
@@ -84,7 +102,7 @@ func (x Module_KindStore_UpdatePolicy) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use Module_KindStore_UpdatePolicy.Descriptor instead.
 func (Module_KindStore_UpdatePolicy) EnumDescriptor() ([]byte, []int) {
-	return file_sf_substreams_v1_modules_proto_rawDescGZIP(), []int{2, 1, 0}
+	return file_sf_substreams_v1_modules_proto_rawDescGZIP(), []int{3, 3, 0}
 }
 
 type Module_Input_Store_Mode int32
@@ -133,7 +151,7 @@ func (x Module_Input_Store_Mode) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use Module_Input_Store_Mode.Descriptor instead.
 func (Module_Input_Store_Mode) EnumDescriptor() ([]byte, []int) {
-	return file_sf_substreams_v1_modules_proto_rawDescGZIP(), []int{2, 2, 2, 0}
+	return file_sf_substreams_v1_modules_proto_rawDescGZIP(), []int{3, 4, 2, 0}
 }
 
 type Modules struct {
@@ -141,8 +159,9 @@ type Modules struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Modules  []*Module `protobuf:"bytes,1,rep,name=modules,proto3" json:"modules,omitempty"`
-	Binaries []*Binary `protobuf:"bytes,2,rep,name=binaries,proto3" json:"binaries,omitempty"`
+	Modules  []*Module  `protobuf:"bytes,1,rep,name=modules,proto3" json:"modules,omitempty"`
+	Binaries []*Binary  `protobuf:"bytes,2,rep,name=binaries,proto3" json:"binaries,omitempty"`
+	Datasets []*Dataset `protobuf:"bytes,3,rep,name=datasets,proto3" json:"datasets,omitempty"`
 }
 
 func (x *Modules) Reset() {
@@ -191,6 +210,13 @@ func (x *Modules) GetBinaries() []*Binary {
 	return nil
 }
 
+func (x *Modules) GetDatasets() []*Dataset {
+	if x != nil {
+		return x.Datasets
+	}
+	return nil
+}
+
 // Binary represents some code compiled to its binary form.
 type Binary struct {
 	state         protoimpl.MessageState
@@ -247,6 +273,93 @@ func (x *Binary) GetContent() []byte {
 	return nil
 }
 
+// Dataset is a static key-value dataset, content-addressed by the hash of its
+// content. Modules referencing it can look it up with the functions of the
+// `dataset` wasm extension namespace.
+type Dataset struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Name by which the modules look the dataset up
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Informative version of the dataset, set by its author
+	Version string `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	// Hex-encoded sha256 hash of the content
+	Hash string `protobuf:"bytes,3,opt,name=hash,proto3" json:"hash,omitempty"`
+	// Serialized `sf.substreams.v1.StoreEntries`, sorted by key without duplicates.
+	// When empty, the content is fetched from `url` when the request is processed.
+	Content []byte `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"`
+	Url     string `protobuf:"bytes,5,opt,name=url,proto3" json:"url,omitempty"`
+}
+
+func (x *Dataset) Reset() {
+	*x = Dataset{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sf_substreams_v1_modules_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Dataset) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Dataset) ProtoMessage() {}
+
+func (x *Dataset) ProtoReflect() protoreflect.Message {
+	mi := &file_sf_substreams_v1_modules_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Dataset.ProtoReflect.Descriptor instead.
+func (*Dataset) Descriptor() ([]byte, []int) {
+	return file_sf_substreams_v1_modules_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Dataset) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Dataset) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *Dataset) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *Dataset) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *Dataset) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
 type Module struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -257,18 +370,24 @@ type Module struct {
 	//
 	//	*Module_KindMap_
 	//	*Module_KindStore_
+	//	*Module_KindBlockIndex_
 	Kind             isModule_Kind   `protobuf_oneof:"kind"`
 	BinaryIndex      uint32          `protobuf:"varint,4,opt,name=binary_index,json=binaryIndex,proto3" json:"binary_index,omitempty"`
 	BinaryEntrypoint string          `protobuf:"bytes,5,opt,name=binary_entrypoint,json=binaryEntrypoint,proto3" json:"binary_entrypoint,omitempty"`
 	Inputs           []*Module_Input `protobuf:"bytes,6,rep,name=inputs,proto3" json:"inputs,omitempty"`
 	Output           *Module_Output  `protobuf:"bytes,7,opt,name=output,proto3" json:"output,omitempty"`
 	InitialBlock     uint64          `protobuf:"varint,8,opt,name=initial_block,json=initialBlock,proto3" json:"initial_block,omitempty"`
+	// Indexes in `Modules.datasets` of the datasets the module can look up
+	DatasetIndexes []uint32 `protobuf:"varint,9,rep,packed,name=dataset_indexes,json=datasetIndexes,proto3" json:"dataset_indexes,omitempty"`
+	// When set, the module is only executed on the blocks whose keys, emitted by
+	// a block index module, match the filter's query.
+	BlockFilter *Module_BlockFilter `protobuf:"bytes,11,opt,name=block_filter,json=blockFilter,proto3" json:"block_filter,omitempty"`
 }
 
 func (x *Module) Reset() {
 	*x = Module{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_sf_substreams_v1_modules_proto_msgTypes[2]
+		mi := &file_sf_substreams_v1_modules_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -281,7 +400,7 @@ func (x *Module) String() string {
 func (*Module) ProtoMessage() {}
 
 func (x *Module) ProtoReflect() protoreflect.Message {
-	mi := &file_sf_substreams_v1_modules_proto_msgTypes[2]
+	mi := &file_sf_substreams_v1_modules_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -294,7 +413,7 @@ func (x *Module) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Module.ProtoReflect.Descriptor instead.
 func (*Module) Descriptor() ([]byte, []int) {
-	return file_sf_substreams_v1_modules_proto_rawDescGZIP(), []int{2}
+	return file_sf_substreams_v1_modules_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *Module) GetName() string {
@@ -325,6 +444,13 @@ func (x *Module) GetKindStore() *Module_KindStore {
 	return nil
 }
 
+func (x *Module) GetKindBlockIndex() *Module_KindBlockIndex {
+	if x, ok := x.GetKind().(*Module_KindBlockIndex_); ok {
+		return x.KindBlockIndex
+	}
+	return nil
+}
+
 func (x *Module) GetBinaryIndex() uint32 {
 	if x != nil {
 		return x.BinaryIndex
@@ -360,6 +486,20 @@ func (x *Module) GetInitialBlock() uint64 {
 	return 0
 }
 
+func (x *Module) GetDatasetIndexes() []uint32 {
+	if x != nil {
+		return x.DatasetIndexes
+	}
+	return nil
+}
+
+func (x *Module) GetBlockFilter() *Module_BlockFilter {
+	if x != nil {
+		return x.BlockFilter
+	}
+	return nil
+}
+
 type isModule_Kind interface {
 	isModule_Kind()
 }
@@ -372,10 +512,74 @@ type Module_KindStore_ struct {
 	KindStore *Module_KindStore `protobuf:"bytes,3,opt,name=kind_store,json=kindStore,proto3,oneof"`
 }
 
+type Module_KindBlockIndex_ struct {
+	KindBlockIndex *Module_KindBlockIndex `protobuf:"bytes,10,opt,name=kind_block_index,json=kindBlockIndex,proto3,oneof"`
+}
+
 func (*Module_KindMap_) isModule_Kind() {}
 
 func (*Module_KindStore_) isModule_Kind() {}
 
+func (*Module_KindBlockIndex_) isModule_Kind() {}
+
+type Module_BlockFilter struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Name of the block index module emitting the keys of each block
+	Module string `protobuf:"bytes,1,opt,name=module,proto3" json:"module,omitempty"`
+	// Query over the keys of a block, combining keys with `&&`, `||`, `-` (not)
+	// and parentheses. Keys separated by spaces are combined with `&&`.
+	Query string `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`
+}
+
+func (x *Module_BlockFilter) Reset() {
+	*x = Module_BlockFilter{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sf_substreams_v1_modules_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Module_BlockFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Module_BlockFilter) ProtoMessage() {}
+
+func (x *Module_BlockFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_sf_substreams_v1_modules_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Module_BlockFilter.ProtoReflect.Descriptor instead.
+func (*Module_BlockFilter) Descriptor() ([]byte, []int) {
+	return file_sf_substreams_v1_modules_proto_rawDescGZIP(), []int{3, 0}
+}
+
+func (x *Module_BlockFilter) GetModule() string {
+	if x != nil {
+		return x.Module
+	}
+	return ""
+}
+
+func (x *Module_BlockFilter) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
 type Module_KindMap struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -387,7 +591,7 @@ type Module_KindMap struct {
 func (x *Module_KindMap) Reset() {
 	*x = Module_KindMap{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_sf_substreams_v1_modules_proto_msgTypes[3]
+		mi := &file_sf_substreams_v1_modules_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -400,7 +604,7 @@ func (x *Module_KindMap) String() string {
 func (*Module_KindMap) ProtoMessage() {}
 
 func (x *Module_KindMap) ProtoReflect() protoreflect.Message {
-	mi := &file_sf_substreams_v1_modules_proto_msgTypes[3]
+	mi := &file_sf_substreams_v1_modules_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -413,7 +617,7 @@ func (x *Module_KindMap) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Module_KindMap.ProtoReflect.Descriptor instead.
 func (*Module_KindMap) Descriptor() ([]byte, []int) {
-	return file_sf_substreams_v1_modules_proto_rawDescGZIP(), []int{2, 0}
+	return file_sf_substreams_v1_modules_proto_rawDescGZIP(), []int{3, 1}
 }
 
 func (x *Module_KindMap) GetOutputType() string {
@@ -423,6 +627,55 @@ func (x *Module_KindMap) GetOutputType() string {
 	return ""
 }
 
+// KindBlockIndex modules emit the keys of each block, as a `sf.substreams.index.v1.Keys`,
+// used by the block filters of other modules to skip the blocks they do not match.
+type Module_KindBlockIndex struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	OutputType string `protobuf:"bytes,1,opt,name=output_type,json=outputType,proto3" json:"output_type,omitempty"`
+}
+
+func (x *Module_KindBlockIndex) Reset() {
+	*x = Module_KindBlockIndex{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sf_substreams_v1_modules_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Module_KindBlockIndex) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Module_KindBlockIndex) ProtoMessage() {}
+
+func (x *Module_KindBlockIndex) ProtoReflect() protoreflect.Message {
+	mi := &file_sf_substreams_v1_modules_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Module_KindBlockIndex.ProtoReflect.Descriptor instead.
+func (*Module_KindBlockIndex) Descriptor() ([]byte, []int) {
+	return file_sf_substreams_v1_modules_proto_rawDescGZIP(), []int{3, 2}
+}
+
+func (x *Module_KindBlockIndex) GetOutputType() string {
+	if x != nil {
+		return x.OutputType
+	}
+	return ""
+}
+
 type Module_KindStore struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -438,12 +691,16 @@ type Module_KindStore struct {
 	// two stores according to this policy.
 	UpdatePolicy Module_KindStore_UpdatePolicy `protobuf:"varint,1,opt,name=update_policy,json=updatePolicy,proto3,enum=sf.substreams.v1.Module_KindStore_UpdatePolicy" json:"update_policy,omitempty"`
 	ValueType    string                        `protobuf:"bytes,2,opt,name=value_type,json=valueType,proto3" json:"value_type,omitempty"`
+	// When non-zero, keys that were not written in the last `ttl_blocks` blocks are
+	// evicted from the store at store boundaries. Eviction happens at the same
+	// boundaries in linear and parallel processing, so snapshots are deterministic.
+	TtlBlocks uint64 `protobuf:"varint,3,opt,name=ttl_blocks,json=ttlBlocks,proto3" json:"ttl_blocks,omitempty"`
 }
 
 func (x *Module_KindStore) Reset() {
 	*x = Module_KindStore{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_sf_substreams_v1_modules_proto_msgTypes[4]
+		mi := &file_sf_substreams_v1_modules_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -456,7 +713,7 @@ func (x *Module_KindStore) String() string {
 func (*Module_KindStore) ProtoMessage() {}
 
 func (x *Module_KindStore) ProtoReflect() protoreflect.Message {
-	mi := &file_sf_substreams_v1_modules_proto_msgTypes[4]
+	mi := &file_sf_substreams_v1_modules_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -469,7 +726,7 @@ func (x *Module_KindStore) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Module_KindStore.ProtoReflect.Descriptor instead.
 func (*Module_KindStore) Descriptor() ([]byte, []int) {
-	return file_sf_substreams_v1_modules_proto_rawDescGZIP(), []int{2, 1}
+	return file_sf_substreams_v1_modules_proto_rawDescGZIP(), []int{3, 3}
 }
 
 func (x *Module_KindStore) GetUpdatePolicy() Module_KindStore_UpdatePolicy {
@@ -486,6 +743,13 @@ func (x *Module_KindStore) GetValueType() string {
 	return ""
 }
 
+func (x *Module_KindStore) GetTtlBlocks() uint64 {
+	if x != nil {
+		return x.TtlBlocks
+	}
+	return 0
+}
+
 type Module_Input struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -503,7 +767,7 @@ type Module_Input struct {
 func (x *Module_Input) Reset() {
 	*x = Module_Input{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_sf_substreams_v1_modules_proto_msgTypes[5]
+		mi := &file_sf_substreams_v1_modules_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -516,7 +780,7 @@ func (x *Module_Input) String() string {
 func (*Module_Input) ProtoMessage() {}
 
 func (x *Module_Input) ProtoReflect() protoreflect.Message {
-	mi := &file_sf_substreams_v1_modules_proto_msgTypes[5]
+	mi := &file_sf_substreams_v1_modules_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -529,7 +793,7 @@ func (x *Module_Input) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Module_Input.ProtoReflect.Descriptor instead.
 func (*Module_Input) Descriptor() ([]byte, []int) {
-	return file_sf_substreams_v1_modules_proto_rawDescGZIP(), []int{2, 2}
+	return file_sf_substreams_v1_modules_proto_rawDescGZIP(), []int{3, 4}
 }
 
 func (m *Module_Input) GetInput() isModule_Input_Input {
@@ -606,7 +870,7 @@ type Module_Output struct {
 func (x *Module_Output) Reset() {
 	*x = Module_Output{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_sf_substreams_v1_modules_proto_msgTypes[6]
+		mi := &file_sf_substreams_v1_modules_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -619,7 +883,7 @@ func (x *Module_Output) String() string {
 func (*Module_Output) ProtoMessage() {}
 
 func (x *Module_Output) ProtoReflect() protoreflect.Message {
-	mi := &file_sf_substreams_v1_modules_proto_msgTypes[6]
+	mi := &file_sf_substreams_v1_modules_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -632,7 +896,7 @@ func (x *Module_Output) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Module_Output.ProtoReflect.Descriptor instead.
 func (*Module_Output) Descriptor() ([]byte, []int) {
-	return file_sf_substreams_v1_modules_proto_rawDescGZIP(), []int{2, 3}
+	return file_sf_substreams_v1_modules_proto_rawDescGZIP(), []int{3, 5}
 }
 
 func (x *Module_Output) GetType() string {
@@ -653,7 +917,7 @@ type Module_Input_Source struct {
 func (x *Module_Input_Source) Reset() {
 	*x = Module_Input_Source{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_sf_substreams_v1_modules_proto_msgTypes[7]
+		mi := &file_sf_substreams_v1_modules_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -666,7 +930,7 @@ func (x *Module_Input_Source) String() string {
 func (*Module_Input_Source) ProtoMessage() {}
 
 func (x *Module_Input_Source) ProtoReflect() protoreflect.Message {
-	mi := &file_sf_substreams_v1_modules_proto_msgTypes[7]
+	mi := &file_sf_substreams_v1_modules_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -679,7 +943,7 @@ func (x *Module_Input_Source) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Module_Input_Source.ProtoReflect.Descriptor instead.
 func (*Module_Input_Source) Descriptor() ([]byte, []int) {
-	return file_sf_substreams_v1_modules_proto_rawDescGZIP(), []int{2, 2, 0}
+	return file_sf_substreams_v1_modules_proto_rawDescGZIP(), []int{3, 4, 0}
 }
 
 func (x *Module_Input_Source) GetType() string {
@@ -700,7 +964,7 @@ type Module_Input_Map struct {
 func (x *Module_Input_Map) Reset() {
 	*x = Module_Input_Map{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_sf_substreams_v1_modules_proto_msgTypes[8]
+		mi := &file_sf_substreams_v1_modules_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -713,7 +977,7 @@ func (x *Module_Input_Map) String() string {
 func (*Module_Input_Map) ProtoMessage() {}
 
 func (x *Module_Input_Map) ProtoReflect() protoreflect.Message {
-	mi := &file_sf_substreams_v1_modules_proto_msgTypes[8]
+	mi := &file_sf_substreams_v1_modules_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -726,7 +990,7 @@ func (x *Module_Input_Map) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Module_Input_Map.ProtoReflect.Descriptor instead.
 func (*Module_Input_Map) Descriptor() ([]byte, []int) {
-	return file_sf_substreams_v1_modules_proto_rawDescGZIP(), []int{2, 2, 1}
+	return file_sf_substreams_v1_modules_proto_rawDescGZIP(), []int{3, 4, 1}
 }
 
 func (x *Module_Input_Map) GetModuleName() string {
@@ -748,7 +1012,7 @@ type Module_Input_Store struct {
 func (x *Module_Input_Store) Reset() {
 	*x = Module_Input_Store{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_sf_substreams_v1_modules_proto_msgTypes[9]
+		mi := &file_sf_substreams_v1_modules_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -761,7 +1025,7 @@ func (x *Module_Input_Store) String() string {
 func (*Module_Input_Store) ProtoMessage() {}
 
 func (x *Module_Input_Store) ProtoReflect() protoreflect.Message {
-	mi := &file_sf_substreams_v1_modules_proto_msgTypes[9]
+	mi := &file_sf_substreams_v1_modules_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -774,7 +1038,7 @@ func (x *Module_Input_Store) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Module_Input_Store.ProtoReflect.Descriptor instead.
 func (*Module_Input_Store) Descriptor() ([]byte, []int) {
-	return file_sf_substreams_v1_modules_proto_rawDescGZIP(), []int{2, 2, 2}
+	return file_sf_substreams_v1_modules_proto_rawDescGZIP(), []int{3, 4, 2}
 }
 
 func (x *Module_Input_Store) GetModuleName() string {
@@ -802,7 +1066,7 @@ type Module_Input_Params struct {
 func (x *Module_Input_Params) Reset() {
 	*x = Module_Input_Params{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_sf_substreams_v1_modules_proto_msgTypes[10]
+		mi := &file_sf_substreams_v1_modules_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -815,7 +1079,7 @@ func (x *Module_Input_Params) String() string {
 func (*Module_Input_Params) ProtoMessage() {}
 
 func (x *Module_Input_Params) ProtoReflect() protoreflect.Message {
-	mi := &file_sf_substreams_v1_modules_proto_msgTypes[10]
+	mi := &file_sf_substreams_v1_modules_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -828,7 +1092,7 @@ func (x *Module_Input_Params) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Module_Input_Params.ProtoReflect.Descriptor instead.
 func (*Module_Input_Params) Descriptor() ([]byte, []int) {
-	return file_sf_substreams_v1_modules_proto_rawDescGZIP(), []int{2, 2, 3}
+	return file_sf_substreams_v1_modules_proto_rawDescGZIP(), []int{3, 4, 3}
 }
 
 func (x *Module_Input_Params) GetValue() string {
@@ -844,105 +1108,145 @@ var file_sf_substreams_v1_modules_proto_rawDesc = []byte{
 	0x0a, 0x1e, 0x73, 0x66, 0x2f, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2f,
 	0x76, 0x31, 0x2f, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
 	0x12, 0x10, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e,
-	0x76, 0x31, 0x22, 0x73, 0x0a, 0x07, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x73, 0x12, 0x32, 0x0a,
-	0x07, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18,
-	0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x76,
-	0x31, 0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x07, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65,
-	0x73, 0x12, 0x34, 0x0a, 0x08, 0x62, 0x69, 0x6e, 0x61, 0x72, 0x69, 0x65, 0x73, 0x18, 0x02, 0x20,
-	0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65,
-	0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x69, 0x6e, 0x61, 0x72, 0x79, 0x52, 0x08, 0x62,
-	0x69, 0x6e, 0x61, 0x72, 0x69, 0x65, 0x73, 0x22, 0x36, 0x0a, 0x06, 0x42, 0x69, 0x6e, 0x61, 0x72,
-	0x79, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x22,
-	0xa3, 0x0a, 0x0a, 0x06, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61,
-	0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x3d,
-	0x0a, 0x08, 0x6b, 0x69, 0x6e, 0x64, 0x5f, 0x6d, 0x61, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x20, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73,
-	0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x2e, 0x4b, 0x69, 0x6e, 0x64, 0x4d,
-	0x61, 0x70, 0x48, 0x00, 0x52, 0x07, 0x6b, 0x69, 0x6e, 0x64, 0x4d, 0x61, 0x70, 0x12, 0x43, 0x0a,
-	0x0a, 0x6b, 0x69, 0x6e, 0x64, 0x5f, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x22, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d,
+	0x76, 0x31, 0x22, 0xaa, 0x01, 0x0a, 0x07, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x73, 0x12, 0x32,
+	0x0a, 0x07, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x18, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e,
+	0x76, 0x31, 0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x07, 0x6d, 0x6f, 0x64, 0x75, 0x6c,
+	0x65, 0x73, 0x12, 0x34, 0x0a, 0x08, 0x62, 0x69, 0x6e, 0x61, 0x72, 0x69, 0x65, 0x73, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x69, 0x6e, 0x61, 0x72, 0x79, 0x52, 0x08,
+	0x62, 0x69, 0x6e, 0x61, 0x72, 0x69, 0x65, 0x73, 0x12, 0x35, 0x0a, 0x08, 0x64, 0x61, 0x74, 0x61,
+	0x73, 0x65, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x73, 0x66, 0x2e,
+	0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x61,
+	0x74, 0x61, 0x73, 0x65, 0x74, 0x52, 0x08, 0x64, 0x61, 0x74, 0x61, 0x73, 0x65, 0x74, 0x73, 0x22,
+	0x36, 0x0a, 0x06, 0x42, 0x69, 0x6e, 0x61, 0x72, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x18, 0x0a,
+	0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07,
+	0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x22, 0x77, 0x0a, 0x07, 0x44, 0x61, 0x74, 0x61, 0x73,
+	0x65, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x68, 0x61, 0x73, 0x68, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x12, 0x10,
+	0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c,
+	0x22, 0xf0, 0x0d, 0x0a, 0x06, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12,
+	0x3d, 0x0a, 0x08, 0x6b, 0x69, 0x6e, 0x64, 0x5f, 0x6d, 0x61, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x20, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d,
 	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x2e, 0x4b, 0x69, 0x6e, 0x64,
-	0x53, 0x74, 0x6f, 0x72, 0x65, 0x48, 0x00, 0x52, 0x09, 0x6b, 0x69, 0x6e, 0x64, 0x53, 0x74, 0x6f,
-	0x72, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x62, 0x69, 0x6e, 0x61, 0x72, 0x79, 0x5f, 0x69, 0x6e, 0x64,
-	0x65, 0x78, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x62, 0x69, 0x6e, 0x61, 0x72, 0x79,
-	0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x2b, 0x0a, 0x11, 0x62, 0x69, 0x6e, 0x61, 0x72, 0x79, 0x5f,
-	0x65, 0x6e, 0x74, 0x72, 0x79, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x10, 0x62, 0x69, 0x6e, 0x61, 0x72, 0x79, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x70, 0x6f, 0x69,
-	0x6e, 0x74, 0x12, 0x36, 0x0a, 0x06, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x73, 0x18, 0x06, 0x20, 0x03,
-	0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61,
-	0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x2e, 0x49, 0x6e, 0x70,
-	0x75, 0x74, 0x52, 0x06, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x73, 0x12, 0x37, 0x0a, 0x06, 0x6f, 0x75,
-	0x74, 0x70, 0x75, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x73, 0x66, 0x2e,
-	0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x6f,
-	0x64, 0x75, 0x6c, 0x65, 0x2e, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x52, 0x06, 0x6f, 0x75, 0x74,
-	0x70, 0x75, 0x74, 0x12, 0x23, 0x0a, 0x0d, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c, 0x5f, 0x62,
-	0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x08, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x69, 0x6e, 0x69, 0x74,
-	0x69, 0x61, 0x6c, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x1a, 0x2a, 0x0a, 0x07, 0x4b, 0x69, 0x6e, 0x64,
-	0x4d, 0x61, 0x70, 0x12, 0x1f, 0x0a, 0x0b, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x5f, 0x74, 0x79,
-	0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74,
-	0x54, 0x79, 0x70, 0x65, 0x1a, 0xc5, 0x02, 0x0a, 0x09, 0x4b, 0x69, 0x6e, 0x64, 0x53, 0x74, 0x6f,
-	0x72, 0x65, 0x12, 0x54, 0x0a, 0x0d, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x5f, 0x70, 0x6f, 0x6c,
-	0x69, 0x63, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x2f, 0x2e, 0x73, 0x66, 0x2e, 0x73,
-	0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x6f, 0x64,
-	0x75, 0x6c, 0x65, 0x2e, 0x4b, 0x69, 0x6e, 0x64, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x2e, 0x55, 0x70,
-	0x64, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x0c, 0x75, 0x70, 0x64, 0x61,
-	0x74, 0x65, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x1d, 0x0a, 0x0a, 0x76, 0x61, 0x6c, 0x75,
-	0x65, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x76, 0x61,
-	0x6c, 0x75, 0x65, 0x54, 0x79, 0x70, 0x65, 0x22, 0xc2, 0x01, 0x0a, 0x0c, 0x55, 0x70, 0x64, 0x61,
-	0x74, 0x65, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x17, 0x0a, 0x13, 0x55, 0x50, 0x44, 0x41,
-	0x54, 0x45, 0x5f, 0x50, 0x4f, 0x4c, 0x49, 0x43, 0x59, 0x5f, 0x55, 0x4e, 0x53, 0x45, 0x54, 0x10,
-	0x00, 0x12, 0x15, 0x0a, 0x11, 0x55, 0x50, 0x44, 0x41, 0x54, 0x45, 0x5f, 0x50, 0x4f, 0x4c, 0x49,
-	0x43, 0x59, 0x5f, 0x53, 0x45, 0x54, 0x10, 0x01, 0x12, 0x23, 0x0a, 0x1f, 0x55, 0x50, 0x44, 0x41,
-	0x54, 0x45, 0x5f, 0x50, 0x4f, 0x4c, 0x49, 0x43, 0x59, 0x5f, 0x53, 0x45, 0x54, 0x5f, 0x49, 0x46,
-	0x5f, 0x4e, 0x4f, 0x54, 0x5f, 0x45, 0x58, 0x49, 0x53, 0x54, 0x53, 0x10, 0x02, 0x12, 0x15, 0x0a,
-	0x11, 0x55, 0x50, 0x44, 0x41, 0x54, 0x45, 0x5f, 0x50, 0x4f, 0x4c, 0x49, 0x43, 0x59, 0x5f, 0x41,
-	0x44, 0x44, 0x10, 0x03, 0x12, 0x15, 0x0a, 0x11, 0x55, 0x50, 0x44, 0x41, 0x54, 0x45, 0x5f, 0x50,
-	0x4f, 0x4c, 0x49, 0x43, 0x59, 0x5f, 0x4d, 0x49, 0x4e, 0x10, 0x04, 0x12, 0x15, 0x0a, 0x11, 0x55,
-	0x50, 0x44, 0x41, 0x54, 0x45, 0x5f, 0x50, 0x4f, 0x4c, 0x49, 0x43, 0x59, 0x5f, 0x4d, 0x41, 0x58,
-	0x10, 0x05, 0x12, 0x18, 0x0a, 0x14, 0x55, 0x50, 0x44, 0x41, 0x54, 0x45, 0x5f, 0x50, 0x4f, 0x4c,
-	0x49, 0x43, 0x59, 0x5f, 0x41, 0x50, 0x50, 0x45, 0x4e, 0x44, 0x10, 0x06, 0x1a, 0x80, 0x04, 0x0a,
-	0x05, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x12, 0x3f, 0x0a, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73,
-	0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65,
-	0x2e, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x2e, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x48, 0x00, 0x52,
-	0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x36, 0x0a, 0x03, 0x6d, 0x61, 0x70, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72,
-	0x65, 0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x2e, 0x49,
-	0x6e, 0x70, 0x75, 0x74, 0x2e, 0x4d, 0x61, 0x70, 0x48, 0x00, 0x52, 0x03, 0x6d, 0x61, 0x70, 0x12,
-	0x3c, 0x0a, 0x05, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x24,
-	0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x76,
-	0x31, 0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x2e, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x2e, 0x53,
-	0x74, 0x6f, 0x72, 0x65, 0x48, 0x00, 0x52, 0x05, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x12, 0x3f, 0x0a,
-	0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x25, 0x2e,
+	0x4d, 0x61, 0x70, 0x48, 0x00, 0x52, 0x07, 0x6b, 0x69, 0x6e, 0x64, 0x4d, 0x61, 0x70, 0x12, 0x43,
+	0x0a, 0x0a, 0x6b, 0x69, 0x6e, 0x64, 0x5f, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x22, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x2e, 0x4b, 0x69, 0x6e,
+	0x64, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x48, 0x00, 0x52, 0x09, 0x6b, 0x69, 0x6e, 0x64, 0x53, 0x74,
+	0x6f, 0x72, 0x65, 0x12, 0x53, 0x0a, 0x10, 0x6b, 0x69, 0x6e, 0x64, 0x5f, 0x62, 0x6c, 0x6f, 0x63,
+	0x6b, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x27, 0x2e,
 	0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31,
-	0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x2e, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x2e, 0x50, 0x61,
-	0x72, 0x61, 0x6d, 0x73, 0x48, 0x00, 0x52, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x1a, 0x1c,
-	0x0a, 0x06, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x1a, 0x26, 0x0a, 0x03,
-	0x4d, 0x61, 0x70, 0x12, 0x1f, 0x0a, 0x0b, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x5f, 0x6e, 0x61,
-	0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65,
-	0x4e, 0x61, 0x6d, 0x65, 0x1a, 0x8f, 0x01, 0x0a, 0x05, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x12, 0x1f,
-	0x0a, 0x0b, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x0a, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12,
-	0x3d, 0x0a, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x29, 0x2e,
+	0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x2e, 0x4b, 0x69, 0x6e, 0x64, 0x42, 0x6c, 0x6f, 0x63,
+	0x6b, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x48, 0x00, 0x52, 0x0e, 0x6b, 0x69, 0x6e, 0x64, 0x42, 0x6c,
+	0x6f, 0x63, 0x6b, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x21, 0x0a, 0x0c, 0x62, 0x69, 0x6e, 0x61,
+	0x72, 0x79, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b,
+	0x62, 0x69, 0x6e, 0x61, 0x72, 0x79, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x2b, 0x0a, 0x11, 0x62,
+	0x69, 0x6e, 0x61, 0x72, 0x79, 0x5f, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x70, 0x6f, 0x69, 0x6e, 0x74,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x62, 0x69, 0x6e, 0x61, 0x72, 0x79, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x36, 0x0a, 0x06, 0x69, 0x6e, 0x70, 0x75,
+	0x74, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75,
+	0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x6f, 0x64, 0x75,
+	0x6c, 0x65, 0x2e, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x52, 0x06, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x73,
+	0x12, 0x37, 0x0a, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1f, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73,
+	0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x2e, 0x4f, 0x75, 0x74, 0x70, 0x75,
+	0x74, 0x52, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12, 0x23, 0x0a, 0x0d, 0x69, 0x6e, 0x69,
+	0x74, 0x69, 0x61, 0x6c, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x08, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x0c, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x27,
+	0x0a, 0x0f, 0x64, 0x61, 0x74, 0x61, 0x73, 0x65, 0x74, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x65,
+	0x73, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x0e, 0x64, 0x61, 0x74, 0x61, 0x73, 0x65, 0x74,
+	0x49, 0x6e, 0x64, 0x65, 0x78, 0x65, 0x73, 0x12, 0x47, 0x0a, 0x0c, 0x62, 0x6c, 0x6f, 0x63, 0x6b,
+	0x5f, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x24, 0x2e,
 	0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31,
-	0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x2e, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x2e, 0x53, 0x74,
-	0x6f, 0x72, 0x65, 0x2e, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x22, 0x26,
-	0x0a, 0x04, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x09, 0x0a, 0x05, 0x55, 0x4e, 0x53, 0x45, 0x54, 0x10,
-	0x00, 0x12, 0x07, 0x0a, 0x03, 0x47, 0x45, 0x54, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x44, 0x45,
-	0x4c, 0x54, 0x41, 0x53, 0x10, 0x02, 0x1a, 0x1e, 0x0a, 0x06, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73,
-	0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x42, 0x07, 0x0a, 0x05, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x1a,
-	0x1c, 0x0a, 0x06, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70,
-	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x42, 0x06, 0x0a,
-	0x04, 0x6b, 0x69, 0x6e, 0x64, 0x42, 0x46, 0x5a, 0x44, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e,
-	0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x69, 0x6e, 0x67, 0x66, 0x61, 0x73,
-	0x74, 0x2f, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2f, 0x70, 0x62, 0x2f,
-	0x73, 0x66, 0x2f, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2f, 0x76, 0x31,
-	0x3b, 0x70, 0x62, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x62, 0x06, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x46, 0x69, 0x6c,
+	0x74, 0x65, 0x72, 0x52, 0x0b, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72,
+	0x1a, 0x3b, 0x0a, 0x0b, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12,
+	0x16, 0x0a, 0x06, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x1a, 0x2a, 0x0a,
+	0x07, 0x4b, 0x69, 0x6e, 0x64, 0x4d, 0x61, 0x70, 0x12, 0x1f, 0x0a, 0x0b, 0x6f, 0x75, 0x74, 0x70,
+	0x75, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6f,
+	0x75, 0x74, 0x70, 0x75, 0x74, 0x54, 0x79, 0x70, 0x65, 0x1a, 0x31, 0x0a, 0x0e, 0x4b, 0x69, 0x6e,
+	0x64, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x1f, 0x0a, 0x0b, 0x6f,
+	0x75, 0x74, 0x70, 0x75, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0a, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x54, 0x79, 0x70, 0x65, 0x1a, 0xdb, 0x03, 0x0a,
+	0x09, 0x4b, 0x69, 0x6e, 0x64, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x12, 0x54, 0x0a, 0x0d, 0x75, 0x70,
+	0x64, 0x61, 0x74, 0x65, 0x5f, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x2f, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d,
+	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x2e, 0x4b, 0x69, 0x6e, 0x64,
+	0x53, 0x74, 0x6f, 0x72, 0x65, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x6c, 0x69,
+	0x63, 0x79, 0x52, 0x0c, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79,
+	0x12, 0x1d, 0x0a, 0x0a, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12,
+	0x1d, 0x0a, 0x0a, 0x74, 0x74, 0x6c, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x09, 0x74, 0x74, 0x6c, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x22, 0xb9,
+	0x02, 0x0a, 0x0c, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12,
+	0x17, 0x0a, 0x13, 0x55, 0x50, 0x44, 0x41, 0x54, 0x45, 0x5f, 0x50, 0x4f, 0x4c, 0x49, 0x43, 0x59,
+	0x5f, 0x55, 0x4e, 0x53, 0x45, 0x54, 0x10, 0x00, 0x12, 0x15, 0x0a, 0x11, 0x55, 0x50, 0x44, 0x41,
+	0x54, 0x45, 0x5f, 0x50, 0x4f, 0x4c, 0x49, 0x43, 0x59, 0x5f, 0x53, 0x45, 0x54, 0x10, 0x01, 0x12,
+	0x23, 0x0a, 0x1f, 0x55, 0x50, 0x44, 0x41, 0x54, 0x45, 0x5f, 0x50, 0x4f, 0x4c, 0x49, 0x43, 0x59,
+	0x5f, 0x53, 0x45, 0x54, 0x5f, 0x49, 0x46, 0x5f, 0x4e, 0x4f, 0x54, 0x5f, 0x45, 0x58, 0x49, 0x53,
+	0x54, 0x53, 0x10, 0x02, 0x12, 0x15, 0x0a, 0x11, 0x55, 0x50, 0x44, 0x41, 0x54, 0x45, 0x5f, 0x50,
+	0x4f, 0x4c, 0x49, 0x43, 0x59, 0x5f, 0x41, 0x44, 0x44, 0x10, 0x03, 0x12, 0x15, 0x0a, 0x11, 0x55,
+	0x50, 0x44, 0x41, 0x54, 0x45, 0x5f, 0x50, 0x4f, 0x4c, 0x49, 0x43, 0x59, 0x5f, 0x4d, 0x49, 0x4e,
+	0x10, 0x04, 0x12, 0x15, 0x0a, 0x11, 0x55, 0x50, 0x44, 0x41, 0x54, 0x45, 0x5f, 0x50, 0x4f, 0x4c,
+	0x49, 0x43, 0x59, 0x5f, 0x4d, 0x41, 0x58, 0x10, 0x05, 0x12, 0x18, 0x0a, 0x14, 0x55, 0x50, 0x44,
+	0x41, 0x54, 0x45, 0x5f, 0x50, 0x4f, 0x4c, 0x49, 0x43, 0x59, 0x5f, 0x41, 0x50, 0x50, 0x45, 0x4e,
+	0x44, 0x10, 0x06, 0x12, 0x19, 0x0a, 0x15, 0x55, 0x50, 0x44, 0x41, 0x54, 0x45, 0x5f, 0x50, 0x4f,
+	0x4c, 0x49, 0x43, 0x59, 0x5f, 0x53, 0x45, 0x54, 0x5f, 0x53, 0x55, 0x4d, 0x10, 0x07, 0x12, 0x1c,
+	0x0a, 0x18, 0x55, 0x50, 0x44, 0x41, 0x54, 0x45, 0x5f, 0x50, 0x4f, 0x4c, 0x49, 0x43, 0x59, 0x5f,
+	0x42, 0x49, 0x54, 0x57, 0x49, 0x53, 0x45, 0x5f, 0x4f, 0x52, 0x10, 0x08, 0x12, 0x1d, 0x0a, 0x19,
+	0x55, 0x50, 0x44, 0x41, 0x54, 0x45, 0x5f, 0x50, 0x4f, 0x4c, 0x49, 0x43, 0x59, 0x5f, 0x42, 0x49,
+	0x54, 0x57, 0x49, 0x53, 0x45, 0x5f, 0x41, 0x4e, 0x44, 0x10, 0x09, 0x12, 0x1d, 0x0a, 0x19, 0x55,
+	0x50, 0x44, 0x41, 0x54, 0x45, 0x5f, 0x50, 0x4f, 0x4c, 0x49, 0x43, 0x59, 0x5f, 0x43, 0x41, 0x52,
+	0x44, 0x49, 0x4e, 0x41, 0x4c, 0x49, 0x54, 0x59, 0x10, 0x0a, 0x1a, 0x80, 0x04, 0x0a, 0x05, 0x49,
+	0x6e, 0x70, 0x75, 0x74, 0x12, 0x3f, 0x0a, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x2e, 0x49,
+	0x6e, 0x70, 0x75, 0x74, 0x2e, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x48, 0x00, 0x52, 0x06, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x36, 0x0a, 0x03, 0x6d, 0x61, 0x70, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x22, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x2e, 0x49, 0x6e, 0x70,
+	0x75, 0x74, 0x2e, 0x4d, 0x61, 0x70, 0x48, 0x00, 0x52, 0x03, 0x6d, 0x61, 0x70, 0x12, 0x3c, 0x0a,
+	0x05, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x73,
+	0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e,
+	0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x2e, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x2e, 0x53, 0x74, 0x6f,
+	0x72, 0x65, 0x48, 0x00, 0x52, 0x05, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x12, 0x3f, 0x0a, 0x06, 0x70,
+	0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x73, 0x66,
+	0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4d,
+	0x6f, 0x64, 0x75, 0x6c, 0x65, 0x2e, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x2e, 0x50, 0x61, 0x72, 0x61,
+	0x6d, 0x73, 0x48, 0x00, 0x52, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x1a, 0x1c, 0x0a, 0x06,
+	0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x1a, 0x26, 0x0a, 0x03, 0x4d, 0x61,
+	0x70, 0x12, 0x1f, 0x0a, 0x0b, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x4e, 0x61,
+	0x6d, 0x65, 0x1a, 0x8f, 0x01, 0x0a, 0x05, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x12, 0x1f, 0x0a, 0x0b,
+	0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0a, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x3d, 0x0a,
+	0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x29, 0x2e, 0x73, 0x66,
+	0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4d,
+	0x6f, 0x64, 0x75, 0x6c, 0x65, 0x2e, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x2e, 0x53, 0x74, 0x6f, 0x72,
+	0x65, 0x2e, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x22, 0x26, 0x0a, 0x04,
+	0x4d, 0x6f, 0x64, 0x65, 0x12, 0x09, 0x0a, 0x05, 0x55, 0x4e, 0x53, 0x45, 0x54, 0x10, 0x00, 0x12,
+	0x07, 0x0a, 0x03, 0x47, 0x45, 0x54, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x44, 0x45, 0x4c, 0x54,
+	0x41, 0x53, 0x10, 0x02, 0x1a, 0x1e, 0x0a, 0x06, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x14,
+	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x42, 0x07, 0x0a, 0x05, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x1a, 0x1c, 0x0a,
+	0x06, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x42, 0x06, 0x0a, 0x04, 0x6b,
+	0x69, 0x6e, 0x64, 0x42, 0x46, 0x5a, 0x44, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f,
+	0x6d, 0x2f, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x69, 0x6e, 0x67, 0x66, 0x61, 0x73, 0x74, 0x2f,
+	0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2f, 0x70, 0x62, 0x2f, 0x73, 0x66,
+	0x2f, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2f, 0x76, 0x31, 0x3b, 0x70,
+	0x62, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
 }
 
 var (
@@ -958,40 +1262,46 @@ func file_sf_substreams_v1_modules_proto_rawDescGZIP() []byte {
 }
 
 var file_sf_substreams_v1_modules_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_sf_substreams_v1_modules_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_sf_substreams_v1_modules_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
 var file_sf_substreams_v1_modules_proto_goTypes = []interface{}{
 	(Module_KindStore_UpdatePolicy)(0), // 0: sf.substreams.v1.Module.KindStore.UpdatePolicy
 	(Module_Input_Store_Mode)(0),       // 1: sf.substreams.v1.Module.Input.Store.Mode
 	(*Modules)(nil),                    // 2: sf.substreams.v1.Modules
 	(*Binary)(nil),                     // 3: sf.substreams.v1.Binary
-	(*Module)(nil),                     // 4: sf.substreams.v1.Module
-	(*Module_KindMap)(nil),             // 5: sf.substreams.v1.Module.KindMap
-	(*Module_KindStore)(nil),           // 6: sf.substreams.v1.Module.KindStore
-	(*Module_Input)(nil),               // 7: sf.substreams.v1.Module.Input
-	(*Module_Output)(nil),              // 8: sf.substreams.v1.Module.Output
-	(*Module_Input_Source)(nil),        // 9: sf.substreams.v1.Module.Input.Source
-	(*Module_Input_Map)(nil),           // 10: sf.substreams.v1.Module.Input.Map
-	(*Module_Input_Store)(nil),         // 11: sf.substreams.v1.Module.Input.Store
-	(*Module_Input_Params)(nil),        // 12: sf.substreams.v1.Module.Input.Params
+	(*Dataset)(nil),                    // 4: sf.substreams.v1.Dataset
+	(*Module)(nil),                     // 5: sf.substreams.v1.Module
+	(*Module_BlockFilter)(nil),         // 6: sf.substreams.v1.Module.BlockFilter
+	(*Module_KindMap)(nil),             // 7: sf.substreams.v1.Module.KindMap
+	(*Module_KindBlockIndex)(nil),      // 8: sf.substreams.v1.Module.KindBlockIndex
+	(*Module_KindStore)(nil),           // 9: sf.substreams.v1.Module.KindStore
+	(*Module_Input)(nil),               // 10: sf.substreams.v1.Module.Input
+	(*Module_Output)(nil),              // 11: sf.substreams.v1.Module.Output
+	(*Module_Input_Source)(nil),        // 12: sf.substreams.v1.Module.Input.Source
+	(*Module_Input_Map)(nil),           // 13: sf.substreams.v1.Module.Input.Map
+	(*Module_Input_Store)(nil),         // 14: sf.substreams.v1.Module.Input.Store
+	(*Module_Input_Params)(nil),        // 15: sf.substreams.v1.Module.Input.Params
 }
 var file_sf_substreams_v1_modules_proto_depIdxs = []int32{
-	4,  // 0: sf.substreams.v1.Modules.modules:type_name -> sf.substreams.v1.Module
+	5,  // 0: sf.substreams.v1.Modules.modules:type_name -> sf.substreams.v1.Module
 	3,  // 1: sf.substreams.v1.Modules.binaries:type_name -> sf.substreams.v1.Binary
-	5,  // 2: sf.substreams.v1.Module.kind_map:type_name -> sf.substreams.v1.Module.KindMap
-	6,  // 3: sf.substreams.v1.Module.kind_store:type_name -> sf.substreams.v1.Module.KindStore
-	7,  // 4: sf.substreams.v1.Module.inputs:type_name -> sf.substreams.v1.Module.Input
-	8,  // 5: sf.substreams.v1.Module.output:type_name -> sf.substreams.v1.Module.Output
-	0,  // 6: sf.substreams.v1.Module.KindStore.update_policy:type_name -> sf.substreams.v1.Module.KindStore.UpdatePolicy
-	9,  // 7: sf.substreams.v1.Module.Input.source:type_name -> sf.substreams.v1.Module.Input.Source
-	10, // 8: sf.substreams.v1.Module.Input.map:type_name -> sf.substreams.v1.Module.Input.Map
-	11, // 9: sf.substreams.v1.Module.Input.store:type_name -> sf.substreams.v1.Module.Input.Store
-	12, // 10: sf.substreams.v1.Module.Input.params:type_name -> sf.substreams.v1.Module.Input.Params
-	1,  // 11: sf.substreams.v1.Module.Input.Store.mode:type_name -> sf.substreams.v1.Module.Input.Store.Mode
-	12, // [12:12] is the sub-list for method output_type
-	12, // [12:12] is the sub-list for method input_type
-	12, // [12:12] is the sub-list for extension type_name
-	12, // [12:12] is the sub-list for extension extendee
-	0,  // [0:12] is the sub-list for field type_name
+	4,  // 2: sf.substreams.v1.Modules.datasets:type_name -> sf.substreams.v1.Dataset
+	7,  // 3: sf.substreams.v1.Module.kind_map:type_name -> sf.substreams.v1.Module.KindMap
+	9,  // 4: sf.substreams.v1.Module.kind_store:type_name -> sf.substreams.v1.Module.KindStore
+	8,  // 5: sf.substreams.v1.Module.kind_block_index:type_name -> sf.substreams.v1.Module.KindBlockIndex
+	10, // 6: sf.substreams.v1.Module.inputs:type_name -> sf.substreams.v1.Module.Input
+	11, // 7: sf.substreams.v1.Module.output:type_name -> sf.substreams.v1.Module.Output
+	6,  // 8: sf.substreams.v1.Module.block_filter:type_name -> sf.substreams.v1.Module.BlockFilter
+	0,  // 9: sf.substreams.v1.Module.KindStore.update_policy:type_name -> sf.substreams.v1.Module.KindStore.UpdatePolicy
+	12, // 10: sf.substreams.v1.Module.Input.source:type_name -> sf.substreams.v1.Module.Input.Source
+	13, // 11: sf.substreams.v1.Module.Input.map:type_name -> sf.substreams.v1.Module.Input.Map
+	14, // 12: sf.substreams.v1.Module.Input.store:type_name -> sf.substreams.v1.Module.Input.Store
+	15, // 13: sf.substreams.v1.Module.Input.params:type_name -> sf.substreams.v1.Module.Input.Params
+	1,  // 14: sf.substreams.v1.Module.Input.Store.mode:type_name -> sf.substreams.v1.Module.Input.Store.Mode
+	15, // [15:15] is the sub-list for method output_type
+	15, // [15:15] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
 }
 
 func init() { file_sf_substreams_v1_modules_proto_init() }
@@ -1025,7 +1335,7 @@ func file_sf_substreams_v1_modules_proto_init() {
 			}
 		}
 		file_sf_substreams_v1_modules_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Module); i {
+			switch v := v.(*Dataset); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1037,7 +1347,7 @@ func file_sf_substreams_v1_modules_proto_init() {
 			}
 		}
 		file_sf_substreams_v1_modules_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Module_KindMap); i {
+			switch v := v.(*Module); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1049,7 +1359,7 @@ func file_sf_substreams_v1_modules_proto_init() {
 			}
 		}
 		file_sf_substreams_v1_modules_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Module_KindStore); i {
+			switch v := v.(*Module_BlockFilter); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1061,7 +1371,7 @@ func file_sf_substreams_v1_modules_proto_init() {
 			}
 		}
 		file_sf_substreams_v1_modules_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Module_Input); i {
+			switch v := v.(*Module_KindMap); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1073,7 +1383,7 @@ func file_sf_substreams_v1_modules_proto_init() {
 			}
 		}
 		file_sf_substreams_v1_modules_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Module_Output); i {
+			switch v := v.(*Module_KindBlockIndex); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1085,7 +1395,7 @@ func file_sf_substreams_v1_modules_proto_init() {
 			}
 		}
 		file_sf_substreams_v1_modules_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Module_Input_Source); i {
+			switch v := v.(*Module_KindStore); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1097,7 +1407,7 @@ func file_sf_substreams_v1_modules_proto_init() {
 			}
 		}
 		file_sf_substreams_v1_modules_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Module_Input_Map); i {
+			switch v := v.(*Module_Input); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1109,7 +1419,7 @@ func file_sf_substreams_v1_modules_proto_init() {
 			}
 		}
 		file_sf_substreams_v1_modules_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Module_Input_Store); i {
+			switch v := v.(*Module_Output); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1121,6 +1431,42 @@ func file_sf_substreams_v1_modules_proto_init() {
 			}
 		}
 		file_sf_substreams_v1_modules_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Module_Input_Source); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sf_substreams_v1_modules_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Module_Input_Map); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sf_substreams_v1_modules_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Module_Input_Store); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sf_substreams_v1_modules_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Module_Input_Params); i {
 			case 0:
 				return &v.state
@@ -1133,11 +1479,12 @@ func file_sf_substreams_v1_modules_proto_init() {
 			}
 		}
 	}
-	file_sf_substreams_v1_modules_proto_msgTypes[2].OneofWrappers = []interface{}{
+	file_sf_substreams_v1_modules_proto_msgTypes[3].OneofWrappers = []interface{}{
 		(*Module_KindMap_)(nil),
 		(*Module_KindStore_)(nil),
+		(*Module_KindBlockIndex_)(nil),
 	}
-	file_sf_substreams_v1_modules_proto_msgTypes[5].OneofWrappers = []interface{}{
+	file_sf_substreams_v1_modules_proto_msgTypes[8].OneofWrappers = []interface{}{
 		(*Module_Input_Source_)(nil),
 		(*Module_Input_Map_)(nil),
 		(*Module_Input_Store_)(nil),
@@ -1149,7 +1496,7 @@ func file_sf_substreams_v1_modules_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_sf_substreams_v1_modules_proto_rawDesc,
 			NumEnums:      2,
-			NumMessages:   11,
+			NumMessages:   14,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
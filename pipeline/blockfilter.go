@@ -0,0 +1,187 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/streamingfast/dstore"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/streamingfast/substreams/block"
+	"github.com/streamingfast/substreams/blockfilter"
+	pbindex "github.com/streamingfast/substreams/pb/sf/substreams/index/v1"
+	"github.com/streamingfast/substreams/reqctx"
+	"github.com/streamingfast/substreams/storage/execout"
+	"github.com/streamingfast/substreams/storage/index"
+	"github.com/streamingfast/substreams/storage/integrity"
+)
+
+type moduleBlockFilter struct {
+	indexModule string
+	query       *blockfilter.Query
+
+	// skipsRange is set when the index of the range of the request is loaded and no block
+	// of the range matches the query
+	skipsRange bool
+}
+
+// setupBlockFilters parses the block filters of the modules to execute.
+func (p *Pipeline) setupBlockFilters() error {
+	p.blockFilters = map[string]*moduleBlockFilter{}
+	for _, stage := range p.executionStages {
+		for _, layer := range stage {
+			for _, module := range layer {
+				if module.BlockFilter == nil {
+					continue
+				}
+				query, err := blockfilter.ParseQuery(module.BlockFilter.Query)
+				if err != nil {
+					return fmt.Errorf("module %q: block filter: %w", module.Name, err)
+				}
+				p.blockFilters[module.Name] = &moduleBlockFilter{
+					indexModule: module.BlockFilter.Module,
+					query:       query,
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// setupBlockIndexes loads the index files of the block index modules over the range of
+// the tier2 request, written by previous requests on the same range. The modules it
+// filters are then matched against the index, and the index module is not executed
+// unless other modules take it as an input or it is an output module of the request.
+// The indexes that are not found are written at the end of the request.
+func (p *Pipeline) setupBlockIndexes(ctx context.Context) error {
+	if p.blockIndexConfigs == nil {
+		return nil
+	}
+	logger := reqctx.Logger(ctx)
+	reqDetails := reqctx.Details(ctx)
+	indexRange := block.NewRange(reqDetails.ResolvedStartBlockNum, reqDetails.StopBlockNum)
+
+	p.blockIndexes = map[string]*index.File{}
+	p.skippedIndexes = map[string]bool{}
+	p.blockIndexWriters = map[string]*index.File{}
+	for _, filter := range p.blockFilters {
+		name := filter.indexModule
+		if p.blockIndexes[name] != nil || p.blockIndexWriters[name] != nil {
+			continue
+		}
+		conf, found := p.blockIndexConfigs.ConfigMap[name]
+		if !found {
+			return fmt.Errorf("block index module %q not found", name)
+		}
+
+		file := conf.NewFile(indexRange)
+		err := file.Load(ctx)
+		switch {
+		case err == nil:
+			p.blockIndexes[name] = file
+			p.skippedIndexes[name] = !reqDetails.IsOutputModule(name) && !p.hasModuleInput(name)
+		case errors.Is(err, dstore.ErrNotFound):
+			p.blockIndexWriters[name] = file
+		case errors.Is(err, integrity.ErrCorrupted):
+			logger.Warn("block index file is corrupted, producing it again", zap.String("module", name), zap.String("filename", file.Filename()), zap.Error(err))
+			p.blockIndexWriters[name] = conf.NewFile(indexRange)
+		default:
+			return fmt.Errorf("loading block index of %q: %w", name, err)
+		}
+	}
+
+	for moduleName, filter := range p.blockFilters {
+		if file := p.blockIndexes[filter.indexModule]; file != nil && !file.MatchesAny(filter.query) {
+			logger.Info("block filter matches no block of the range, skipping module", zap.String("module", moduleName), zap.Stringer("block_range", indexRange))
+			filter.skipsRange = true
+		}
+	}
+	return nil
+}
+
+// skipsBlock returns true if `moduleName` must not be executed on the current block of
+// `execOutput`: its block filter does not match the block, or it is a block index module
+// whose index is already loaded and that only block filters consume.
+func (p *Pipeline) skipsBlock(moduleName string, execOutput execout.ExecutionOutputGetter) (bool, error) {
+	if p.skippedIndexes[moduleName] {
+		return true, nil
+	}
+
+	filter := p.blockFilters[moduleName]
+	if filter == nil {
+		return false, nil
+	}
+	if filter.skipsRange {
+		return true, nil
+	}
+
+	blockNum := execOutput.Clock().Number
+	if file := p.blockIndexes[filter.indexModule]; file != nil {
+		return !file.Matches(filter.query, blockNum), nil
+	}
+
+	keys, err := blockIndexKeys(filter.indexModule, execOutput)
+	if err != nil {
+		return false, err
+	}
+	return !filter.query.MatchesKeys(keys), nil
+}
+
+// recordBlockIndex adds the keys emitted by `moduleName` to the index written at the end
+// of the request, if it is a block index module whose index was not found.
+func (p *Pipeline) recordBlockIndex(moduleName string, execOutput execout.ExecutionOutputGetter) error {
+	writer := p.blockIndexWriters[moduleName]
+	if writer == nil {
+		return nil
+	}
+	keys, err := blockIndexKeys(moduleName, execOutput)
+	if err != nil {
+		return err
+	}
+	writer.Add(execOutput.Clock().Number, keys)
+	return nil
+}
+
+// hasModuleInput returns true if a module to execute takes the output of `moduleName` as
+// an input.
+func (p *Pipeline) hasModuleInput(moduleName string) bool {
+	for _, stage := range p.executionStages {
+		for _, layer := range stage {
+			for _, module := range layer {
+				for _, input := range module.Inputs {
+					if input.GetMap().GetModuleName() == moduleName {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+func (p *Pipeline) saveBlockIndexes(ctx context.Context) error {
+	for name, writer := range p.blockIndexWriters {
+		if err := writer.Save(ctx); err != nil {
+			return fmt.Errorf("saving block index of %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func blockIndexKeys(indexModule string, execOutput execout.ExecutionOutputGetter) ([]string, error) {
+	data, _, err := execOutput.Get(indexModule)
+	if err == execout.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting output of block index module %q: %w", indexModule, err)
+	}
+
+	keys := &pbindex.Keys{}
+	if err := proto.Unmarshal(data, keys); err != nil {
+		return nil, fmt.Errorf("unmarshalling keys of block index module %q: %w", indexModule, err)
+	}
+	return keys.Keys, nil
+}
@@ -0,0 +1,115 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/streamingfast/dstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/streamingfast/substreams/block"
+	pbindex "github.com/streamingfast/substreams/pb/sf/substreams/index/v1"
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/streamingfast/substreams/pipeline/outputmodules"
+	"github.com/streamingfast/substreams/reqctx"
+	"github.com/streamingfast/substreams/storage/index"
+)
+
+func TestPipeline_skipsBlock_IndexModuleConsumedByMap(t *testing.T) {
+	indexModule := &pbsubstreams.Module{
+		Name: "index_events",
+		Kind: &pbsubstreams.Module_KindBlockIndex_{KindBlockIndex: &pbsubstreams.Module_KindBlockIndex{}},
+	}
+	filteredMap := &pbsubstreams.Module{
+		Name:        "filtered_events",
+		Kind:        &pbsubstreams.Module_KindMap_{KindMap: &pbsubstreams.Module_KindMap{}},
+		BlockFilter: &pbsubstreams.Module_BlockFilter{Module: "index_events", Query: "transfer"},
+	}
+	consumerMap := &pbsubstreams.Module{
+		Name: "count_events",
+		Kind: &pbsubstreams.Module_KindMap_{KindMap: &pbsubstreams.Module_KindMap{}},
+		Inputs: []*pbsubstreams.Module_Input{
+			{Input: &pbsubstreams.Module_Input_Map_{Map: &pbsubstreams.Module_Input_Map{ModuleName: "index_events"}}},
+		},
+	}
+
+	baseStore := dstore.NewMockStore(nil)
+	conf, err := index.NewConfig("index_events", "abc", baseStore, zap.NewNop())
+	require.NoError(t, err)
+	file := conf.NewFile(block.NewRange(100, 120))
+	file.Add(105, []string{"transfer"})
+	require.NoError(t, file.Save(context.Background()))
+
+	tests := []struct {
+		name          string
+		tier2         bool
+		withConsumer  bool
+		expectSkipped bool
+	}{
+		{name: "tier1 with consumer", withConsumer: true},
+		{name: "tier2 with consumer", tier2: true, withConsumer: true},
+		{name: "tier1 only filters"},
+		{name: "tier2 only filters", tier2: true, expectSkipped: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			layer := outputmodules.LayerModules{filteredMap}
+			if test.withConsumer {
+				layer = append(layer, consumerMap)
+			}
+			p := &Pipeline{
+				executionStages: outputmodules.ExecutionStages{
+					outputmodules.StageLayers{outputmodules.LayerModules{indexModule}, layer},
+				},
+			}
+			if test.tier2 {
+				p.blockIndexConfigs = &index.Configs{ConfigMap: map[string]*index.Config{"index_events": conf}}
+			}
+			ctx := reqctx.WithRequest(context.Background(), &reqctx.RequestDetails{
+				OutputModule:          "filtered_events",
+				ResolvedStartBlockNum: 100,
+				StopBlockNum:          120,
+				IsTier2Request:        test.tier2,
+			})
+			require.NoError(t, p.setupBlockFilters())
+			require.NoError(t, p.setupBlockIndexes(ctx))
+
+			for _, blockNum := range []uint64{105, 106} {
+				keys := &pbindex.Keys{}
+				if blockNum == 105 {
+					keys.Keys = []string{"transfer"}
+				}
+				keysBytes, err := proto.Marshal(keys)
+				require.NoError(t, err)
+
+				execOutput := &ExecOutputTesting{
+					Values: map[string][]byte{},
+					clock:  &pbsubstreams.Clock{Number: blockNum},
+				}
+				skipped, err := p.skipsBlock("index_events", execOutput)
+				require.NoError(t, err)
+				assert.Equal(t, test.expectSkipped, skipped, "index module at block %d", blockNum)
+				if !skipped {
+					require.NoError(t, execOutput.Set("index_events", keysBytes))
+				}
+
+				skipped, err = p.skipsBlock("filtered_events", execOutput)
+				require.NoError(t, err)
+				assert.Equal(t, blockNum != 105, skipped, "filtered module at block %d", blockNum)
+
+				if test.withConsumer {
+					skipped, err = p.skipsBlock("count_events", execOutput)
+					require.NoError(t, err)
+					assert.False(t, skipped)
+
+					data, _, err := execOutput.Get("index_events")
+					require.NoError(t, err, "the consumer must receive the output of the index module")
+					assert.Equal(t, keysBytes, data)
+				}
+			}
+		})
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"fmt"
 
 	ttrace "go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 
+	"github.com/streamingfast/substreams/metrics"
 	"github.com/streamingfast/substreams/reqctx"
 	"github.com/streamingfast/substreams/storage/execout"
 	"github.com/streamingfast/substreams/wasm"
@@ -25,9 +27,11 @@ type BaseExecutor struct {
 
 	instanceCacheEnabled bool
 	cachedInstance       wasm.Instance
+	replayStats          *metrics.Stats // throwaway stats of the replayed executions, see replayCall
 
 	// Results
 	logs           []string
+	structuredLogs []wasm.LogEntry
 	logsTruncated  bool
 	executionStack []string
 }
@@ -48,9 +52,51 @@ func NewBaseExecutor(ctx context.Context, moduleName string, wasmModule wasm.Mod
 
 func (e *BaseExecutor) wasmCall(outputGetter execout.ExecutionOutputGetter) (call *wasm.Call, err error) {
 	e.logs = nil
+	e.structuredLogs = nil
 	e.logsTruncated = false
 	e.executionStack = nil
 
+	stats := reqctx.ReqStats(e.ctx)
+	call, inst, err := e.executeCall(outputGetter, e.cachedInstance, stats)
+	if call == nil || err != nil {
+		return nil, err
+	}
+	if e.instanceCacheEnabled {
+		if err := inst.Cleanup(e.ctx); err != nil {
+			return nil, fmt.Errorf("block %d: module %q: failed to cleanup module: %w", call.Clock.Number, e.moduleName, err)
+		}
+		e.cachedInstance = inst
+	} else {
+		if err := inst.Close(e.ctx); err != nil {
+			return nil, fmt.Errorf("block %d: module %q: failed to close module: %w", call.Clock.Number, e.moduleName, err)
+		}
+	}
+	e.logs = call.Logs
+	e.structuredLogs = call.StructuredLogs
+	e.logsTruncated = call.ReachedLogsMaxByteCount()
+	e.executionStack = call.ExecutionStack
+	return call, nil
+}
+
+// replayCall executes the module again on the same inputs, in a fresh instance that is
+// closed afterwards. It leaves the logs, the stats and the fuel meter of the request untouched.
+func (e *BaseExecutor) replayCall(outputGetter execout.ExecutionOutputGetter) (*wasm.Call, error) {
+	if e.replayStats == nil {
+		e.replayStats = metrics.NewReqStats(&metrics.Config{}, zap.NewNop())
+	}
+	call, inst, err := e.executeCall(outputGetter, nil, e.replayStats)
+	if call == nil || err != nil {
+		return nil, err
+	}
+	if err := inst.Close(e.ctx); err != nil {
+		return nil, fmt.Errorf("block %d: module %q: failed to close module: %w", call.Clock.Number, e.moduleName, err)
+	}
+	return call, nil
+}
+
+// executeCall runs the module on the inputs found in `outputGetter`, reusing
+// `cachedInstance` if not nil. It returns a nil call when the module has no input.
+func (e *BaseExecutor) executeCall(outputGetter execout.ExecutionOutputGetter, cachedInstance wasm.Instance, stats *metrics.Stats) (call *wasm.Call, inst wasm.Instance, err error) {
 	hasInput := false
 	for _, input := range e.wasmArguments {
 		switch v := input.(type) {
@@ -63,7 +109,7 @@ func (e *BaseExecutor) wasmCall(outputGetter execout.ExecutionOutputGetter) (cal
 			hasInput = true
 			data, _, err := outputGetter.Get(v.Name())
 			if err != nil {
-				return nil, fmt.Errorf("input data for %q: %w", v.Name(), err)
+				return nil, nil, fmt.Errorf("input data for %q: %w", v.Name(), err)
 			}
 			v.SetValue(data)
 		default:
@@ -74,43 +120,48 @@ func (e *BaseExecutor) wasmCall(outputGetter execout.ExecutionOutputGetter) (cal
 	// This assumption should either be configurable by the manifest, or clearly documented:
 	//  state builders will not be called if their input streams are 0 bytes length (and there is no
 	//  state store in read mode)
-	if hasInput {
-		clock := outputGetter.Clock()
-		var inst wasm.Instance
-
-		stats := reqctx.ReqStats(e.ctx)
-		//t0 := time.Now()
-		call = wasm.NewCall(clock, e.moduleName, e.entrypoint, stats, e.wasmArguments)
-		inst, err = e.wasmModule.ExecuteNewCall(e.ctx, call, e.cachedInstance, e.wasmArguments)
-		//Timer += time.Since(t0)
-		if panicErr := call.Err(); panicErr != nil {
-			errExecutor := &ErrorExecutor{
-				message:    panicErr.Error(),
-				stackTrace: call.ExecutionStack,
-			}
-			return nil, fmt.Errorf("block %d: module %q: general wasm execution panicked: %w: %s", clock.Number, e.moduleName, ErrWasmDeterministicExec, errExecutor.Error())
+	if !hasInput {
+		return nil, nil, nil
+	}
+
+	clock := outputGetter.Clock()
+	//t0 := time.Now()
+	call = wasm.NewCall(clock, e.moduleName, e.entrypoint, stats, e.wasmArguments)
+	inst, err = e.wasmModule.ExecuteNewCall(e.ctx, call, cachedInstance, e.wasmArguments)
+	//Timer += time.Since(t0)
+	if call.FuelConsumed != 0 {
+		stats.RecordModuleWasmFuel(e.moduleName, call.FuelConsumed)
+		if stats != e.replayStats {
+			metrics.GetFuelMeter(e.ctx).AddFuelConsumed(call.FuelConsumed)
 		}
-		if err != nil {
-			if err := e.ctx.Err(); err != nil {
-				return nil, fmt.Errorf("block %d: module %q: general wasm execution failed: %w", clock.Number, e.moduleName, err)
-			}
-			return nil, fmt.Errorf("block %d: module %q: general wasm execution failed: %w: %s", clock.Number, e.moduleName, ErrWasmDeterministicExec, err)
+	}
+	if call.MemorySize != 0 {
+		stats.RecordModuleWasmMemory(e.moduleName, call.MemorySize)
+	}
+	if storeErr := call.StoreErr(); storeErr != nil {
+		return nil, nil, fmt.Errorf("block %d: module %q: reading store: %w", clock.Number, e.moduleName, storeErr)
+	}
+	if panicErr := call.Err(); panicErr != nil {
+		errExecutor := &ErrorExecutor{
+			message:    panicErr.Error(),
+			stackTrace: call.ExecutionStack,
 		}
-		if e.instanceCacheEnabled {
-			if err := inst.Cleanup(e.ctx); err != nil {
-				return nil, fmt.Errorf("block %d: module %q: failed to cleanup module: %w", clock.Number, e.moduleName, err)
-			}
-			e.cachedInstance = inst
-		} else {
-			if err := inst.Close(e.ctx); err != nil {
-				return nil, fmt.Errorf("block %d: module %q: failed to close module: %w", clock.Number, e.moduleName, err)
-			}
+		return nil, nil, fmt.Errorf("block %d: module %q: general wasm execution panicked: %w: %s", clock.Number, e.moduleName, ErrWasmDeterministicExec, errExecutor.Error())
+	}
+	if errors.Is(err, wasm.ErrMemoryLimitExceeded) {
+		errExecutor := &ErrorExecutor{
+			message:    err.Error(),
+			stackTrace: call.ExecutionStack,
 		}
-		e.logs = call.Logs
-		e.logsTruncated = call.ReachedLogsMaxByteCount()
-		e.executionStack = call.ExecutionStack
+		return nil, nil, fmt.Errorf("block %d: module %q: wasm execution exceeded the memory limit: %w: %s", clock.Number, e.moduleName, ErrWasmDeterministicExec, errExecutor.Error())
 	}
-	return
+	if err != nil {
+		if err := e.ctx.Err(); err != nil {
+			return nil, nil, fmt.Errorf("block %d: module %q: general wasm execution failed: %w", clock.Number, e.moduleName, err)
+		}
+		return nil, nil, fmt.Errorf("block %d: module %q: general wasm execution failed: %w: %s", clock.Number, e.moduleName, ErrWasmDeterministicExec, err)
+	}
+	return call, inst, nil
 }
 
 func (e *BaseExecutor) Close(ctx context.Context) error {
@@ -123,6 +174,9 @@ func (e *BaseExecutor) Close(ctx context.Context) error {
 func (e *BaseExecutor) lastExecutionLogs() (logs []string, truncated bool) {
 	return e.logs, e.logsTruncated
 }
+func (e *BaseExecutor) lastExecutionStructuredLogs() []wasm.LogEntry {
+	return e.structuredLogs
+}
 func (e *BaseExecutor) lastExecutionStack() []string {
 	return e.executionStack
 }
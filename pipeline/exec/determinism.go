@@ -0,0 +1,75 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+
+	pbssinternal "github.com/streamingfast/substreams/pb/sf/substreams/intern/v2"
+	"github.com/streamingfast/substreams/storage/execout"
+)
+
+// NonDeterministicError is returned by VerifyDeterminism when executing a module twice
+// on the same block gave different results.
+type NonDeterministicError struct {
+	Module   string
+	BlockNum uint64
+	Reason   string
+}
+
+func (e *NonDeterministicError) Error() string {
+	return fmt.Sprintf("block %d: module %q: non-deterministic execution: %s", e.BlockNum, e.Module, e.Reason)
+}
+
+// VerifyDeterminism executes the module of `executor` again on the current block of
+// `execOutput`, in a fresh instance, and compares the result byte for byte with
+// `outputBytes`, the output (or the store deltas) of its first execution.
+func VerifyDeterminism(ctx context.Context, executor ModuleExecutor, execOutput execout.ExecutionOutputGetter, outputBytes []byte) error {
+	replayed, err := executor.replay(ctx, execOutput)
+	if err != nil {
+		return &NonDeterministicError{
+			Module:   executor.Name(),
+			BlockNum: execOutput.Clock().Number,
+			Reason:   fmt.Sprintf("the first execution succeeded but replaying it failed: %s", err),
+		}
+	}
+	if bytes.Equal(outputBytes, replayed) {
+		return nil
+	}
+
+	reason := outputsDivergence(outputBytes, replayed)
+	if _, ok := executor.(*StoreModuleExecutor); ok {
+		reason = storeDeltasDivergence(outputBytes, replayed)
+	}
+	return &NonDeterministicError{
+		Module:   executor.Name(),
+		BlockNum: execOutput.Clock().Number,
+		Reason:   reason,
+	}
+}
+
+func outputsDivergence(first, replayed []byte) string {
+	offset := 0
+	for offset < len(first) && offset < len(replayed) && first[offset] == replayed[offset] {
+		offset++
+	}
+	return fmt.Sprintf("outputs differ from byte %d (%d bytes then %d bytes)", offset, len(first), len(replayed))
+}
+
+func storeDeltasDivergence(first, replayed []byte) string {
+	firstDeltas := &pbssinternal.StoreDeltas{}
+	replayedDeltas := &pbssinternal.StoreDeltas{}
+	if proto.Unmarshal(first, firstDeltas) != nil || proto.Unmarshal(replayed, replayedDeltas) != nil {
+		return "store deltas differ"
+	}
+
+	a, b := firstDeltas.StoreDeltas, replayedDeltas.StoreDeltas
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if !proto.Equal(a[i], b[i]) {
+			return fmt.Sprintf("store deltas differ at delta %d (key %q then %q)", i, a[i].Key, b[i].Key)
+		}
+	}
+	return fmt.Sprintf("store deltas differ, %d deltas then %d deltas", len(a), len(b))
+}
@@ -0,0 +1,112 @@
+package exec
+
+import (
+	"context"
+	"testing"
+
+	"github.com/streamingfast/dstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/streamingfast/substreams/metrics"
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/streamingfast/substreams/reqctx"
+	"github.com/streamingfast/substreams/storage/store"
+	"github.com/streamingfast/substreams/wasm"
+	"github.com/streamingfast/substreams/wasm/native"
+	_ "github.com/streamingfast/substreams/wasm/wazero"
+)
+
+func TestVerifyDeterminism(t *testing.T) {
+	var calls int64
+	native.Register("determinism_test", "map_stable", func(ctx context.Context, call *wasm.Call, arguments []wasm.Argument) error {
+		call.SetReturnValue([]byte("stable"))
+		return nil
+	})
+	native.Register("determinism_test", "map_unstable", func(ctx context.Context, call *wasm.Call, arguments []wasm.Argument) error {
+		calls++
+		call.SetReturnValue([]byte{byte(calls)})
+		return nil
+	})
+	native.Register("determinism_test", "store_stable", func(ctx context.Context, call *wasm.Call, arguments []wasm.Argument) error {
+		call.DoAddInt64(0, "total", 1)
+		return nil
+	})
+	native.Register("determinism_test", "store_unstable", func(ctx context.Context, call *wasm.Call, arguments []wasm.Argument) error {
+		calls++
+		call.DoAddInt64(0, "total", calls)
+		return nil
+	})
+	defer native.Unregister("determinism_test")
+
+	ctx := reqctx.WithReqStats(context.Background(), metrics.NewReqStats(&metrics.Config{}, zap.NewNop()))
+	registry := wasm.NewRegistryWithRuntime("wazero", nil, 0)
+	module, err := registry.NewModuleForBinary(ctx, &pbsubstreams.Binary{Type: wasm.NativeBinaryType, Content: []byte("determinism_test")})
+	require.NoError(t, err)
+
+	execOutput := &MockExecOutput{
+		clockFunc: func() *pbsubstreams.Clock { return &pbsubstreams.Clock{Number: 42} },
+		cacheMap:  map[string][]byte{"sf.substreams.v1.Clock": {0x01}},
+	}
+
+	newStore := func() store.Store {
+		config, err := store.NewConfig("totals", 0, "totals.hash", pbsubstreams.Module_KindStore_UPDATE_POLICY_ADD, "int64", dstore.NewMockStore(nil), "")
+		require.NoError(t, err)
+		return config.NewFullKV(zap.NewNop())
+	}
+	newExecutor := func(name string, outputStore store.Store) ModuleExecutor {
+		arguments := []wasm.Argument{wasm.NewSourceInput("sf.substreams.v1.Clock")}
+		if outputStore == nil {
+			return NewMapperModuleExecutor(NewBaseExecutor(ctx, name, module, false, arguments, name, nil), "bytes")
+		}
+		arguments = append(arguments, wasm.NewStoreWriterOutput(name, outputStore, pbsubstreams.Module_KindStore_UPDATE_POLICY_ADD, "int64"))
+		return NewStoreModuleExecutor(NewBaseExecutor(ctx, name, module, false, arguments, name, nil), outputStore)
+	}
+
+	tests := []struct {
+		name        string
+		isStore     bool
+		expectedErr string
+	}{
+		{"map_stable", false, ""},
+		{"map_unstable", false, `block 42: module "map_unstable": non-deterministic execution: outputs differ from byte 0 (1 bytes then 1 bytes)`},
+		{"store_stable", true, ""},
+		{"store_unstable", true, `block 42: module "store_unstable": non-deterministic execution: store deltas differ at delta 0 (key "total" then "total")`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var outputStore store.Store
+			if test.isStore {
+				outputStore = newStore()
+			}
+			executor := newExecutor(test.name, outputStore)
+
+			out, _, err := executor.run(ctx, execOutput)
+			require.NoError(t, err)
+			var before []byte
+			if outputStore != nil {
+				before, _ = outputStore.GetLast("total")
+			}
+
+			err = VerifyDeterminism(ctx, executor, execOutput, out)
+			if test.expectedErr == "" {
+				require.NoError(t, err)
+			} else {
+				var divergence *NonDeterministicError
+				require.ErrorAs(t, err, &divergence)
+				assert.Equal(t, test.name, divergence.Module)
+				assert.Equal(t, test.expectedErr, err.Error())
+			}
+
+			if outputStore != nil {
+				// the replay leaves the store as after the first execution
+				after, _ := outputStore.GetLast("total")
+				assert.Equal(t, before, after)
+				deltas, _, err := executor.(*StoreModuleExecutor).wrapDeltas()
+				require.NoError(t, err)
+				assert.Equal(t, out, deltas)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	pbssinternal "github.com/streamingfast/substreams/pb/sf/substreams/intern/v2"
 	"github.com/streamingfast/substreams/storage/execout"
+	"github.com/streamingfast/substreams/wasm"
 )
 
 type ModuleExecutor interface {
@@ -15,8 +16,12 @@ type ModuleExecutor interface {
 	run(ctx context.Context, reader execout.ExecutionOutputGetter) (out []byte, moduleOutputData *pbssinternal.ModuleOutput, err error)
 	applyCachedOutput(value []byte) error
 	toModuleOutput(data []byte) (*pbssinternal.ModuleOutput, error)
+	// replay executes the module again on the current block, in a fresh instance, and
+	// returns its output without changing the state of the executor.
+	replay(ctx context.Context, reader execout.ExecutionOutputGetter) ([]byte, error)
 	HasValidOutput() bool
 
 	lastExecutionLogs() (logs []string, truncated bool)
+	lastExecutionStructuredLogs() []wasm.LogEntry
 	lastExecutionStack() []string
 }
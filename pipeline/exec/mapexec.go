@@ -54,6 +54,17 @@ func (e *MapperModuleExecutor) run(ctx context.Context, reader execout.Execution
 	return out, modOut, nil
 }
 
+func (e *MapperModuleExecutor) replay(ctx context.Context, reader execout.ExecutionOutputGetter) ([]byte, error) {
+	call, err := e.replayCall(reader)
+	if err != nil {
+		return nil, fmt.Errorf("maps wasm call: %w", err)
+	}
+	if call == nil {
+		return nil, nil
+	}
+	return call.Output(), nil
+}
+
 func (e *MapperModuleExecutor) toModuleOutput(data []byte) (*pbssinternal.ModuleOutput, error) {
 	return &pbssinternal.ModuleOutput{
 		Data: &pbssinternal.ModuleOutput_MapOutput{
@@ -10,6 +10,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/streamingfast/substreams/reqctx"
+	"github.com/streamingfast/substreams/wasm"
 
 	pbssinternal "github.com/streamingfast/substreams/pb/sf/substreams/intern/v2"
 )
@@ -61,6 +62,18 @@ func RunModule(ctx context.Context, executor ModuleExecutor, execOutput execout.
 	return moduleOutput, outputBytes, nil
 }
 
+// SkipModule returns the output of a module that is not executed on the current block,
+// because its block filter does not match it: an empty output for a map, no deltas for
+// a store.
+func SkipModule(executor ModuleExecutor) (*pbssinternal.ModuleOutput, []byte, error) {
+	moduleOutput, err := executor.toModuleOutput(nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("converting empty output to module output: %w", err)
+	}
+	moduleOutput.ModuleName = executor.Name()
+	return moduleOutput, nil, nil
+}
+
 func getCachedOutput(execOutput execout.ExecutionOutputGetter, executor ModuleExecutor) (bool, []byte, error) {
 	output, cached, err := execOutput.Get(executor.Name())
 	if err != nil && err != execout.NotFound {
@@ -74,6 +87,26 @@ func fillModuleOutputMetadata(executor ModuleExecutor, in *pbssinternal.ModuleOu
 
 	in.ModuleName = executor.Name()
 	in.Logs = logs
+	in.StructuredLogs = toModuleLogs(executor.lastExecutionStructuredLogs())
 	in.DebugLogsTruncated = truncated
 	return
 }
+
+func toModuleLogs(entries []wasm.LogEntry) []*pbssinternal.ModuleLog {
+	if entries == nil {
+		return nil
+	}
+
+	out := make([]*pbssinternal.ModuleLog, len(entries))
+	for i, entry := range entries {
+		log := &pbssinternal.ModuleLog{
+			Level:   pbssinternal.ModuleLog_Level(entry.Level),
+			Message: entry.Message,
+		}
+		for _, field := range entry.Fields {
+			log.Fields = append(log.Fields, &pbssinternal.LogField{Key: field.Key, Value: field.Value})
+		}
+		out[i] = log
+	}
+	return out
+}
@@ -13,6 +13,7 @@ import (
 	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
 	"github.com/streamingfast/substreams/reqctx"
 	"github.com/streamingfast/substreams/storage/execout"
+	"github.com/streamingfast/substreams/wasm"
 )
 
 type MockExecOutput struct {
@@ -42,12 +43,14 @@ type MockModuleExecutor struct {
 	name       string
 	outputType string
 
-	RunFunc      func(ctx context.Context, reader execout.ExecutionOutputGetter) (out []byte, moduleOutputData *pbssinternal.ModuleOutput, err error)
-	ApplyFunc    func(value []byte) error
-	LogsFunc     func() (logs []string, truncated bool)
-	StackFunc    func() []string
-	ToOutputFunc func(data []byte) (*pbssinternal.ModuleOutput, error)
-	cacheable    bool
+	RunFunc            func(ctx context.Context, reader execout.ExecutionOutputGetter) (out []byte, moduleOutputData *pbssinternal.ModuleOutput, err error)
+	ApplyFunc          func(value []byte) error
+	LogsFunc           func() (logs []string, truncated bool)
+	StructuredLogsFunc func() []wasm.LogEntry
+	StackFunc          func() []string
+	ToOutputFunc       func(data []byte) (*pbssinternal.ModuleOutput, error)
+	ReplayFunc         func(ctx context.Context, reader execout.ExecutionOutputGetter) ([]byte, error)
+	cacheable          bool
 }
 
 var _ ModuleExecutor = (*MockModuleExecutor)(nil)
@@ -78,6 +81,13 @@ func (t *MockModuleExecutor) toModuleOutput(data []byte) (*pbssinternal.ModuleOu
 	return nil, fmt.Errorf("not implemented")
 }
 
+func (t *MockModuleExecutor) replay(ctx context.Context, reader execout.ExecutionOutputGetter) ([]byte, error) {
+	if t.ReplayFunc != nil {
+		return t.ReplayFunc(ctx, reader)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
 func (t *MockModuleExecutor) lastExecutionLogs() (logs []string, truncated bool) {
 	if t.LogsFunc != nil {
 		return t.LogsFunc()
@@ -85,6 +95,13 @@ func (t *MockModuleExecutor) lastExecutionLogs() (logs []string, truncated bool)
 	return nil, false
 }
 
+func (t *MockModuleExecutor) lastExecutionStructuredLogs() []wasm.LogEntry {
+	if t.StructuredLogsFunc != nil {
+		return t.StructuredLogsFunc()
+	}
+	return nil
+}
+
 func (t *MockModuleExecutor) lastExecutionStack() []string {
 	if t.StackFunc != nil {
 		return t.StackFunc()
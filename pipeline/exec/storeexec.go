@@ -47,6 +47,35 @@ func (e *StoreModuleExecutor) run(ctx context.Context, reader execout.ExecutionO
 	return e.wrapDeltas()
 }
 
+// replay rolls the store back to its state before the block, runs the module again and
+// returns the deltas it produced, before restoring the deltas of the first execution.
+func (e *StoreModuleExecutor) replay(ctx context.Context, reader execout.ExecutionOutputGetter) ([]byte, error) {
+	resettable, ok := e.outputStore.(store.Resettable)
+	if !ok {
+		return nil, fmt.Errorf("store %q cannot be reset", e.moduleName)
+	}
+
+	deltas := e.outputStore.GetDeltas()
+	e.outputStore.ApplyDeltasReverse(deltas)
+	resettable.Reset()
+
+	_, replayErr := e.replayCall(reader)
+	replayed := e.outputStore.GetDeltas()
+
+	e.outputStore.ApplyDeltasReverse(replayed)
+	resettable.Reset()
+	e.outputStore.SetDeltas(deltas)
+
+	if replayErr != nil {
+		return nil, fmt.Errorf("store wasm call: %w", replayErr)
+	}
+	data, err := proto.Marshal(&pbssinternal.StoreDeltas{StoreDeltas: replayed})
+	if err != nil {
+		return nil, fmt.Errorf("marshalling delta: %w", err)
+	}
+	return data, nil
+}
+
 func (e *StoreModuleExecutor) HasValidOutput() bool {
 	_, ok := e.outputStore.(*store.FullKV)
 	return ok
@@ -5,6 +5,7 @@ import (
 
 	"github.com/streamingfast/substreams"
 	pbsubstreamsrpc "github.com/streamingfast/substreams/pb/sf/substreams/rpc/v2"
+	"github.com/streamingfast/substreams/storage/index"
 )
 
 type PipelineOptioner interface {
@@ -50,3 +51,11 @@ func WithHighestStage(stage uint32) Option {
 		p.highestStage = &s
 	}
 }
+
+// WithBlockIndexes enables the index files of the block index modules, loaded over the
+// range of a tier2 request when previous requests wrote them, written otherwise.
+func WithBlockIndexes(configs *index.Configs) Option {
+	return func(p *Pipeline) {
+		p.blockIndexConfigs = configs
+	}
+}
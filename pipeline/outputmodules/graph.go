@@ -15,39 +15,59 @@ type Graph struct {
 	stores            []*pbsubstreams.Module // subset of allModules: only the stores
 	lowestInitBlock   uint64
 
-	outputModule *pbsubstreams.Module
+	outputModules []*pbsubstreams.Module
 
 	schedulableModules      []*pbsubstreams.Module // stores and output mappers needed to execute to produce output for all `output_modules`.
 	schedulableAncestorsMap map[string][]string    // modules that are ancestors (therefore dependencies) of a given module
 }
 
-func (g *Graph) OutputModule() *pbsubstreams.Module   { return g.outputModule }
-func (g *Graph) Stores() []*pbsubstreams.Module       { return g.stores }
-func (g *Graph) UsedModules() []*pbsubstreams.Module  { return g.usedModules }
-func (g *Graph) StagedUsedModules() ExecutionStages   { return g.stagedUsedModules }
-func (g *Graph) IsOutputModule(name string) bool      { return g.outputModule.Name == name }
-func (g *Graph) ModuleHashes() *manifest.ModuleHashes { return g.moduleHashes }
-func (g *Graph) LowestInitBlock() uint64              { return g.lowestInitBlock }
+func (g *Graph) OutputModule() *pbsubstreams.Module    { return g.outputModules[0] }
+func (g *Graph) OutputModules() []*pbsubstreams.Module { return g.outputModules }
+func (g *Graph) Stores() []*pbsubstreams.Module        { return g.stores }
+func (g *Graph) UsedModules() []*pbsubstreams.Module   { return g.usedModules }
+func (g *Graph) StagedUsedModules() ExecutionStages    { return g.stagedUsedModules }
+func (g *Graph) IsOutputModule(name string) bool       { return g.OutputModuleIndex(name) != -1 }
+func (g *Graph) ModuleHashes() *manifest.ModuleHashes  { return g.moduleHashes }
+func (g *Graph) LowestInitBlock() uint64               { return g.lowestInitBlock }
+
+// OutputModuleIndex returns the index of `name` in the output modules, -1 if it is not
+// an output module.
+func (g *Graph) OutputModuleIndex(name string) int {
+	for i, mod := range g.outputModules {
+		if mod.Name == name {
+			return i
+		}
+	}
+	return -1
+}
 
 func NewOutputModuleGraph(outputModule string, productionMode bool, modules *pbsubstreams.Modules) (out *Graph, err error) {
+	return NewOutputModulesGraph([]string{outputModule}, productionMode, modules)
+}
+
+// NewOutputModulesGraph builds the graph of the modules required to produce the outputs
+// of `outputModules`, the first one being the `OutputModule()` of the graph.
+func NewOutputModulesGraph(outputModules []string, productionMode bool, modules *pbsubstreams.Modules) (out *Graph, err error) {
+	if len(outputModules) == 0 {
+		return nil, fmt.Errorf("module graph: no output module")
+	}
 	out = &Graph{
 		requestModules: modules,
 	}
-	if err := out.computeGraph(outputModule, productionMode, modules); err != nil {
+	if err := out.computeGraph(outputModules, productionMode, modules); err != nil {
 		return nil, fmt.Errorf("module graph: %w", err)
 	}
 
 	return out, nil
 }
 
-func (g *Graph) computeGraph(outputModule string, productionMode bool, modules *pbsubstreams.Modules) error {
+func (g *Graph) computeGraph(outputModuleNames []string, productionMode bool, modules *pbsubstreams.Modules) error {
 	graph, err := manifest.NewModuleGraph(modules.Modules)
 	if err != nil {
 		return fmt.Errorf("compute graph: %w", err)
 	}
-	outputModuleName := outputModule
 
-	processModules, err := graph.ModulesDownTo(outputModuleName)
+	processModules, err := graph.ModulesDownTo(outputModuleNames...)
 	if err != nil {
 		return fmt.Errorf("building execution moduleGraph: %w", err)
 	}
@@ -59,15 +79,17 @@ func (g *Graph) computeGraph(outputModule string, productionMode bool, modules *
 		return fmt.Errorf("cannot hash module: %w", err)
 	}
 
-	g.outputModule = computeOutputModule(g.usedModules, outputModuleName)
+	for _, name := range outputModuleNames {
+		g.outputModules = append(g.outputModules, computeOutputModule(g.usedModules, name))
+	}
 
-	storeModules, err := graph.StoresDownTo(g.outputModule.Name)
+	storeModules, err := graph.StoresDownTo(outputModuleNames...)
 	if err != nil {
 		return fmt.Errorf("stores down: %w", err)
 	}
 	g.stores = storeModules
 
-	g.schedulableModules = computeSchedulableModules(storeModules, g.outputModule, productionMode)
+	g.schedulableModules = computeSchedulableModules(storeModules, g.outputModules, productionMode)
 
 	ancestorsMap, err := computeSchedulableAncestors(graph, g.schedulableModules)
 	if err != nil {
@@ -129,7 +151,7 @@ func computeStages(mods []*pbsubstreams.Module) (stages ExecutionStages) {
 	modLoop:
 		for _, mod := range mods {
 			switch mod.Kind.(type) {
-			case *pbsubstreams.Module_KindMap_:
+			case *pbsubstreams.Module_KindMap_, *pbsubstreams.Module_KindBlockIndex_:
 				if i%2 == 0 {
 					continue
 				}
@@ -161,6 +183,9 @@ func computeStages(mods []*pbsubstreams.Module) (stages ExecutionStages) {
 					continue modLoop
 				}
 			}
+			if filter := mod.BlockFilter; filter != nil && !seen[filter.Module] {
+				continue modLoop
+			}
 
 			layer = append(layer, mod)
 		}
@@ -198,16 +223,19 @@ func computeOutputModule(mods []*pbsubstreams.Module, outputModule string) *pbsu
 
 }
 
-func computeSchedulableModules(stores []*pbsubstreams.Module, outputModule *pbsubstreams.Module, productionMode bool) []*pbsubstreams.Module {
+func computeSchedulableModules(stores []*pbsubstreams.Module, outputModules []*pbsubstreams.Module, productionMode bool) []*pbsubstreams.Module {
 	if !productionMode { // dev never schedules maps, all stores are in there
 		return stores
 	}
 
-	if outputModule.GetKindStore() != nil {
-		return stores
+	out := stores
+	for _, outputModule := range outputModules {
+		if outputModule.GetKindStore() != nil {
+			continue
+		}
+		out = append(out, outputModule)
 	}
-
-	return append(stores, outputModule)
+	return out
 }
 
 func computeSchedulableAncestors(graph *manifest.ModuleGraph, schedulableModules []*pbsubstreams.Module) (out map[string][]string, err error) {
@@ -248,8 +276,10 @@ func (g *Graph) hashModules(graph *manifest.ModuleGraph) error {
 }
 
 func (g *Graph) ValidateRequestStartBlock(requestStartBlockNum uint64) error {
-	if requestStartBlockNum < g.outputModule.InitialBlock {
-		return fmt.Errorf("start block %d smaller than request outputs for module %q with start block %d", requestStartBlockNum, g.outputModule.Name, g.outputModule.InitialBlock)
+	for _, outputModule := range g.outputModules {
+		if requestStartBlockNum < outputModule.InitialBlock {
+			return fmt.Errorf("start block %d smaller than request outputs for module %q with start block %d", requestStartBlockNum, outputModule.Name, outputModule.InitialBlock)
+		}
 	}
 	return nil
 }
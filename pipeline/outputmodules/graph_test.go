@@ -42,6 +42,16 @@ func TestGraph_computeStages(t *testing.T) {
 			input:  "Ma Mb:Ma Sc:Mb Md:Sc Se:Md,Sg Mf:Ma Sg:Mf Mh:Se,Ma",
 			expect: "[[Ma] [Mb Mf] [Sc Sg]] [[Md] [Se]] [[Mh]]",
 		},
+		{
+			name:   "block filter",
+			input:  "Ma Ib:Ma Mc:Ma,Fb Sd:Mc",
+			expect: "[[Ma] [Ib] [Mc] [Sd]]",
+		},
+		{
+			name:   "block filter on store",
+			input:  "Ia:R Sb:R,Fa Mc:Sb",
+			expect: "[[Ia] [Sb]] [[Mc]]",
+		},
 	}
 
 	for _, test := range tests {
@@ -67,6 +77,9 @@ func computeStagesInput(in string) (out []*pbsubstreams.Module) {
 		case 'M':
 			newMod.Kind = &pbsubstreams.Module_KindMap_{KindMap: &pbsubstreams.Module_KindMap{}}
 			newMod.Name = modName[1:]
+		case 'I':
+			newMod.Kind = &pbsubstreams.Module_KindBlockIndex_{KindBlockIndex: &pbsubstreams.Module_KindBlockIndex{}}
+			newMod.Name = modName[1:]
 		default:
 			panic("invalid prefix in word: " + modName)
 		}
@@ -82,6 +95,8 @@ func computeStagesInput(in string) (out []*pbsubstreams.Module) {
 					newMod.Inputs = append(newMod.Inputs, &pbsubstreams.Module_Input{Input: &pbsubstreams.Module_Input_Params_{}})
 				case 'R':
 					newMod.Inputs = append(newMod.Inputs, &pbsubstreams.Module_Input{Input: &pbsubstreams.Module_Input_Source_{}})
+				case 'F':
+					newMod.BlockFilter = &pbsubstreams.Module_BlockFilter{Module: inputName, Query: "key"}
 				default:
 					panic("invalid input prefix: " + input)
 				}
@@ -102,6 +117,8 @@ func computeStagesOutput(in ExecutionStages) string {
 				modKind := "S"
 				if l3.GetKindMap() != nil {
 					modKind = "M"
+				} else if l3.GetKindBlockIndex() != nil {
+					modKind = "I"
 				}
 				level3 = append(level3, modKind+l3.Name)
 			}
@@ -116,44 +133,79 @@ func TestGraph_computeSchedulableModules(t *testing.T) {
 	tests := []struct {
 		name           string
 		stores         []*pbsubstreams.Module
-		outputModule   *pbsubstreams.Module
+		outputModules  []*pbsubstreams.Module
 		productionMode bool
 		expect         []*pbsubstreams.Module
 	}{
 
 		{
-			name:         "dev mode with output module map",
-			stores:       []*pbsubstreams.Module{pbsubstreamsrpc.TestNewStoreModule("store_a"), pbsubstreamsrpc.TestNewStoreModule("store_b")},
-			outputModule: pbsubstreamsrpc.TestNewMapModule("map_a"),
-			expect:       []*pbsubstreams.Module{pbsubstreamsrpc.TestNewStoreModule("store_a"), pbsubstreamsrpc.TestNewStoreModule("store_b")},
+			name:          "dev mode with output module map",
+			stores:        []*pbsubstreams.Module{pbsubstreamsrpc.TestNewStoreModule("store_a"), pbsubstreamsrpc.TestNewStoreModule("store_b")},
+			outputModules: []*pbsubstreams.Module{pbsubstreamsrpc.TestNewMapModule("map_a")},
+			expect:        []*pbsubstreams.Module{pbsubstreamsrpc.TestNewStoreModule("store_a"), pbsubstreamsrpc.TestNewStoreModule("store_b")},
 		},
 		{
-			name:         "dev mode with output module store",
-			stores:       []*pbsubstreams.Module{pbsubstreamsrpc.TestNewStoreModule("store_a"), pbsubstreamsrpc.TestNewStoreModule("store_b")},
-			outputModule: pbsubstreamsrpc.TestNewStoreModule("store_b"),
-			expect:       []*pbsubstreams.Module{pbsubstreamsrpc.TestNewStoreModule("store_a"), pbsubstreamsrpc.TestNewStoreModule("store_b")},
+			name:          "dev mode with output module store",
+			stores:        []*pbsubstreams.Module{pbsubstreamsrpc.TestNewStoreModule("store_a"), pbsubstreamsrpc.TestNewStoreModule("store_b")},
+			outputModules: []*pbsubstreams.Module{pbsubstreamsrpc.TestNewStoreModule("store_b")},
+			expect:        []*pbsubstreams.Module{pbsubstreamsrpc.TestNewStoreModule("store_a"), pbsubstreamsrpc.TestNewStoreModule("store_b")},
 		},
 		{
 			name:           "prod mode with output module map",
 			stores:         []*pbsubstreams.Module{pbsubstreamsrpc.TestNewStoreModule("store_a"), pbsubstreamsrpc.TestNewStoreModule("store_b")},
-			outputModule:   pbsubstreamsrpc.TestNewMapModule("map_a"),
+			outputModules:  []*pbsubstreams.Module{pbsubstreamsrpc.TestNewMapModule("map_a")},
 			productionMode: true,
 			expect:         []*pbsubstreams.Module{pbsubstreamsrpc.TestNewStoreModule("store_a"), pbsubstreamsrpc.TestNewStoreModule("store_b"), pbsubstreamsrpc.TestNewMapModule("map_a")},
 		},
 		{
 			name:           "prod mode with output module store",
 			stores:         []*pbsubstreams.Module{pbsubstreamsrpc.TestNewStoreModule("store_a"), pbsubstreamsrpc.TestNewStoreModule("store_b")},
-			outputModule:   pbsubstreamsrpc.TestNewStoreModule("store_b"),
+			outputModules:  []*pbsubstreams.Module{pbsubstreamsrpc.TestNewStoreModule("store_b")},
 			productionMode: true,
 			expect:         []*pbsubstreams.Module{pbsubstreamsrpc.TestNewStoreModule("store_a"), pbsubstreamsrpc.TestNewStoreModule("store_b")},
 		},
+		{
+			name:           "prod mode with multiple output modules",
+			stores:         []*pbsubstreams.Module{pbsubstreamsrpc.TestNewStoreModule("store_a")},
+			outputModules:  []*pbsubstreams.Module{pbsubstreamsrpc.TestNewMapModule("map_a"), pbsubstreamsrpc.TestNewMapModule("map_b")},
+			productionMode: true,
+			expect:         []*pbsubstreams.Module{pbsubstreamsrpc.TestNewStoreModule("store_a"), pbsubstreamsrpc.TestNewMapModule("map_a"), pbsubstreamsrpc.TestNewMapModule("map_b")},
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			out := computeSchedulableModules(test.stores, test.outputModule, test.productionMode)
+			out := computeSchedulableModules(test.stores, test.outputModules, test.productionMode)
 
 			assert.Equal(t, test.expect, out)
 		})
 	}
 }
+
+func TestNewOutputModulesGraph(t *testing.T) {
+	modules := &pbsubstreams.Modules{
+		Modules:  computeStagesInput("Ma:R Sb:Ma Mc:Sb Md:Ma Me:R"),
+		Binaries: []*pbsubstreams.Binary{{}},
+	}
+	for _, mod := range modules.Modules {
+		for _, input := range mod.Inputs {
+			if source, ok := input.Input.(*pbsubstreams.Module_Input_Source_); ok {
+				source.Source = &pbsubstreams.Module_Input_Source{Type: "sf.substreams.v1.Clock"}
+			}
+		}
+	}
+
+	graph, err := NewOutputModulesGraph([]string{"c", "d"}, true, modules)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "c", graph.OutputModule().Name)
+	assert.Equal(t, []string{"c", "d"}, moduleNames(graph.OutputModules()))
+	assert.Equal(t, 1, graph.OutputModuleIndex("d"))
+	assert.Equal(t, -1, graph.OutputModuleIndex("a"))
+	assert.True(t, graph.IsOutputModule("d"))
+	assert.False(t, graph.IsOutputModule("e"))
+	assert.Equal(t, []string{"b"}, moduleNames(graph.Stores()))
+	assert.ElementsMatch(t, []string{"a", "b", "c", "d"}, moduleNames(graph.UsedModules()))
+	assert.ElementsMatch(t, []string{"b", "c", "d"}, graph.SchedulableModuleNames())
+	assert.Equal(t, "[[Ma] [Sb]] [[Md Mc]]", computeStagesOutput(graph.StagedUsedModules()))
+}
@@ -6,9 +6,9 @@ import (
 
 func TestNew() *Graph {
 	return &Graph{
-		outputModule: &pbsubstreams.Module{
+		outputModules: []*pbsubstreams.Module{{
 			Name: "",
-		},
+		}},
 	}
 }
 
@@ -18,8 +18,13 @@ func TestGraphStagedModules(initialBlock1, ib2, ib3, ib4, ib5 uint64) *Graph {
 	lowest = min(lowest, ib3)
 	lowest = min(lowest, ib4)
 	lowest = min(lowest, ib5)
+	outputModule := &pbsubstreams.Module{
+		Kind:         &pbsubstreams.Module_KindMap_{KindMap: &pbsubstreams.Module_KindMap{}},
+		InitialBlock: ib5,
+	}
 	return &Graph{
 		lowestInitBlock: lowest,
+		outputModules:   []*pbsubstreams.Module{outputModule},
 		stagedUsedModules: ExecutionStages{
 			{
 				{
@@ -50,10 +55,7 @@ func TestGraphStagedModules(initialBlock1, ib2, ib3, ib4, ib5 uint64) *Graph {
 			},
 			{
 				{
-					&pbsubstreams.Module{
-						Kind:         &pbsubstreams.Module_KindMap_{KindMap: &pbsubstreams.Module_KindMap{}},
-						InitialBlock: ib5,
-					},
+					outputModule,
 				},
 			},
 		},
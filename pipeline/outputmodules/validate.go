@@ -7,6 +7,7 @@ import (
 	pbssinternal "github.com/streamingfast/substreams/pb/sf/substreams/intern/v2"
 	pbsubstreamsrpc "github.com/streamingfast/substreams/pb/sf/substreams/rpc/v2"
 	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/streamingfast/substreams/wasm"
 )
 
 // Deprecated: use ValidateTier1Request
@@ -18,7 +19,7 @@ func ValidateTier1Request(request *pbsubstreamsrpc.Request, blockType string) er
 		return fmt.Errorf("validate tier1 request: %s", err)
 	}
 
-	err := validateRequest(request.Modules.Binaries, request.Modules, request.OutputModule, blockType)
+	err := validateRequest(request.Modules.Binaries, request.Modules, request.OutputModuleNames(), blockType)
 	if err != nil {
 		return err
 	}
@@ -31,7 +32,7 @@ func ValidateTier2Request(request *pbssinternal.ProcessRangeRequest, blockType s
 		return fmt.Errorf("validate tier2 request: %s", err)
 	}
 
-	err := validateRequest(request.Modules.Binaries, request.Modules, request.OutputModule, blockType)
+	err := validateRequest(request.Modules.Binaries, request.Modules, request.OutputModuleNames(), blockType)
 	if err != nil {
 		return err
 	}
@@ -39,7 +40,7 @@ func ValidateTier2Request(request *pbssinternal.ProcessRangeRequest, blockType s
 	return nil
 }
 
-func validateRequest(binaries []*pbsubstreams.Binary, modules *pbsubstreams.Modules, outputModule string, blockType string) error {
+func validateRequest(binaries []*pbsubstreams.Binary, modules *pbsubstreams.Modules, outputModules []string, blockType string) error {
 	if err := validateBinaryTypes(binaries); err != nil {
 		return err
 	}
@@ -48,8 +49,10 @@ func validateRequest(binaries []*pbsubstreams.Binary, modules *pbsubstreams.Modu
 		return fmt.Errorf("modules validation failed: %w", err)
 	}
 
-	if err := validateModuleGraph(modules.Modules, outputModule, blockType); err != nil {
-		return err
+	for _, outputModule := range outputModules {
+		if err := validateModuleGraph(modules.Modules, outputModule, blockType); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -84,6 +87,9 @@ func validateModuleGraph(mods []*pbsubstreams.Module, outputModule string, block
 
 func validateBinaryTypes(bins []*pbsubstreams.Binary) error {
 	for _, binary := range bins {
+		if binary.Type == wasm.NativeBinaryType && wasm.NativeRuntimeRegistered() {
+			continue
+		}
 		if binary.Type != "wasm/rust-v1" {
 			return fmt.Errorf(`unsupported binary type: %q, please use "wasm/rust-v1"`, binary.Type)
 		}
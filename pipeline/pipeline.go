@@ -2,6 +2,7 @@ package pipeline
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -24,6 +25,8 @@ import (
 	"github.com/streamingfast/substreams/reqctx"
 	"github.com/streamingfast/substreams/service/config"
 	"github.com/streamingfast/substreams/storage/execout"
+	"github.com/streamingfast/substreams/storage/index"
+	"github.com/streamingfast/substreams/storage/integrity"
 	"github.com/streamingfast/substreams/storage/store"
 	"github.com/streamingfast/substreams/wasm"
 )
@@ -48,7 +51,7 @@ type Pipeline struct {
 	moduleExecutors [][]exec.ModuleExecutor // Staged module executors
 	executionStages outputmodules.ExecutionStages
 
-	mapModuleOutput         *pbsubstreamsrpc.MapModuleOutput
+	mapModuleOutputs        []*pbsubstreamsrpc.MapModuleOutput // outputs of the output modules of the block, in the order of the request
 	extraMapModuleOutputs   []*pbsubstreamsrpc.MapModuleOutput
 	extraStoreModuleOutputs []*pbsubstreamsrpc.StoreModuleOutput
 
@@ -71,6 +74,12 @@ type Pipeline struct {
 
 	execOutputCache *cache.Engine
 
+	blockFilters      map[string]*moduleBlockFilter // by name of the filtered module
+	blockIndexConfigs *index.Configs                // only set on tier2
+	blockIndexes      map[string]*index.File        // loaded indexes over the range of the request, by index module name
+	skippedIndexes    map[string]bool               // index modules not executed, their index being loaded and only consumed by block filters
+	blockIndexWriters map[string]*index.File        // indexes written at the end of the request, by index module name
+
 	// lastFinalClock should always be either THE `stopBlock` or a block beyond that point
 	// (for chains with potential block skips)
 	lastFinalClock *pbsubstreams.Clock
@@ -132,6 +141,10 @@ func (p *Pipeline) init(ctx context.Context) (err error) {
 	}
 	p.executionStages = stagedModules
 
+	if err := p.setupBlockFilters(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -150,6 +163,10 @@ func (p *Pipeline) InitTier2Stores(ctx context.Context) (err error) {
 	logger := reqctx.Logger(ctx)
 	logger.Debug("stores loaded", zap.Object("stores", p.stores.StoreMap), zap.Int("stage", reqctx.Details(ctx).Tier2Stage))
 
+	if err := p.setupBlockIndexes(ctx); err != nil {
+		return fmt.Errorf("block indexes setup failed: %w", err)
+	}
+
 	return nil
 }
 
@@ -177,7 +194,7 @@ func (p *Pipeline) GetStoreMap() store.Map {
 
 func (p *Pipeline) setupProcessingModule(reqDetails *reqctx.RequestDetails) {
 	for _, module := range reqDetails.Modules.Modules {
-		if reqDetails.IsOutputModule(module.Name) {
+		if module.Name == reqDetails.OutputModule {
 			p.processingModule = &processingModule{
 				name:            module.GetName(),
 				initialBlockNum: reqDetails.ResolvedStartBlockNum,
@@ -218,7 +235,19 @@ func (p *Pipeline) setupSubrequestStores(ctx context.Context) (storeMap store.Ma
 					// `request.Stage == 1 && request.StartBlockNum == 20`
 					// in tier2.go: on the call to InitTier2Stores.
 					// Things stall in this LOAD command:
-					if err := fullStore.Load(ctx, file); err != nil {
+					err := fullStore.Load(ctx, file)
+					if errors.Is(err, integrity.ErrCorrupted) {
+						// The snapshot is deleted and the request fails with a CorruptedSnapshotError,
+						// for the tier1 to produce the snapshot again before re-running this job.
+						logger.Warn("store snapshot is corrupted, deleting it", zap.String("module", storeConfig.Name()), zap.String("filename", file.Filename), zap.Error(err))
+						_ = fullStore.DeleteStore(ctx, file)
+						return nil, fmt.Errorf("load full store %s (%s): %w", storeConfig.Name(), storeConfig.ModuleHash(), &store.CorruptedSnapshotError{
+							ModuleName:        storeConfig.Name(),
+							ExclusiveEndBlock: reqDetails.ResolvedStartBlockNum,
+							Err:               err,
+						})
+					}
+					if err != nil {
 						return nil, fmt.Errorf("load full store %s (%s): %w", storeConfig.Name(), storeConfig.ModuleHash(), err)
 					}
 				}
@@ -301,10 +330,6 @@ func (p *Pipeline) runParallelProcess(ctx context.Context, reqPlan *plan.Request
 	return storeMap, nil
 }
 
-func (p *Pipeline) isOutputModule(name string) bool {
-	return p.outputGraph.IsOutputModule(name)
-}
-
 func (p *Pipeline) runPostJobHooks(ctx context.Context, clock *pbsubstreams.Clock) {
 	for _, hook := range p.postJobHooks {
 		if err := hook(ctx, clock); err != nil {
@@ -333,9 +358,10 @@ func toRPCStoreModuleOutputs(in *pbssinternal.ModuleOutput) (out *pbsubstreamsrp
 		Name:             in.ModuleName,
 		DebugStoreDeltas: toRPCDeltas(deltas),
 		DebugInfo: &pbsubstreamsrpc.OutputDebugInfo{
-			Logs:          in.Logs,
-			LogsTruncated: in.DebugLogsTruncated,
-			Cached:        in.Cached,
+			Logs:           in.Logs,
+			LogsTruncated:  in.DebugLogsTruncated,
+			Cached:         in.Cached,
+			StructuredLogs: toRPCLogs(in.StructuredLogs),
 		},
 	}
 }
@@ -358,6 +384,24 @@ func toRPCDeltas(in *pbssinternal.StoreDeltas) (out []*pbsubstreamsrpc.StoreDelt
 	return
 }
 
+func toRPCLogs(in []*pbssinternal.ModuleLog) (out []*pbsubstreamsrpc.ModuleLog) {
+	if len(in) == 0 {
+		return nil
+	}
+
+	out = make([]*pbsubstreamsrpc.ModuleLog, len(in))
+	for i, log := range in {
+		out[i] = &pbsubstreamsrpc.ModuleLog{
+			Level:   pbsubstreamsrpc.ModuleLog_Level(log.Level),
+			Message: log.Message,
+		}
+		for _, field := range log.Fields {
+			out[i].Fields = append(out[i].Fields, &pbsubstreamsrpc.LogField{Key: field.Key, Value: field.Value})
+		}
+	}
+	return
+}
+
 func toRPCOperation(in pbssinternal.StoreDelta_Operation) (out pbsubstreamsrpc.StoreDelta_Operation) {
 	switch in {
 	case pbssinternal.StoreDelta_UPDATE:
@@ -379,9 +423,10 @@ func toRPCMapModuleOutputs(in *pbssinternal.ModuleOutput) (out *pbsubstreamsrpc.
 		Name:      in.ModuleName,
 		MapOutput: data,
 		DebugInfo: &pbsubstreamsrpc.OutputDebugInfo{
-			Logs:          in.Logs,
-			LogsTruncated: in.DebugLogsTruncated,
-			Cached:        in.Cached,
+			Logs:           in.Logs,
+			LogsTruncated:  in.DebugLogsTruncated,
+			Cached:         in.Cached,
+			StructuredLogs: toRPCLogs(in.StructuredLogs),
 		},
 	}
 }
@@ -455,7 +500,7 @@ func (p *Pipeline) buildModuleExecutors(ctx context.Context) ([][]exec.ModuleExe
 					continue
 				}
 				code := reqModules.Binaries[module.BinaryIndex]
-				m, err := p.wasmRuntime.NewModule(ctx, code.Content)
+				m, err := p.wasmRuntime.NewModuleForBinary(ctx, code)
 				if err != nil {
 					return nil, fmt.Errorf("new wasm module: %w", err)
 				}
@@ -480,7 +525,7 @@ func (p *Pipeline) buildModuleExecutors(ctx context.Context) ([][]exec.ModuleExe
 				mod := loadedModules[module.BinaryIndex]
 
 				switch kind := module.Kind.(type) {
-				case *pbsubstreams.Module_KindMap_:
+				case *pbsubstreams.Module_KindMap_, *pbsubstreams.Module_KindBlockIndex_:
 					outType := strings.TrimPrefix(module.Output.Type, "proto:")
 					baseExecutor := exec.NewBaseExecutor(
 						ctx,
@@ -547,14 +592,26 @@ func (p *Pipeline) cleanUpModuleExecutors(ctx context.Context) error {
 func returnModuleDataOutputs(
 	clock *pbsubstreams.Clock,
 	cursor *bstream.Cursor,
-	mapModuleOutput *pbsubstreamsrpc.MapModuleOutput,
+	mapModuleOutputs []*pbsubstreamsrpc.MapModuleOutput,
 	extraMapModuleOutputs []*pbsubstreamsrpc.MapModuleOutput,
 	extraStoreModuleOutputs []*pbsubstreamsrpc.StoreModuleOutput,
 	respFunc substreams.ResponseFunc,
 ) error {
+	var mapModuleOutput *pbsubstreamsrpc.MapModuleOutput
+	var otherMapModuleOutputs []*pbsubstreamsrpc.MapModuleOutput
+	for i, output := range mapModuleOutputs {
+		switch {
+		case i == 0:
+			mapModuleOutput = output
+		case output != nil:
+			otherMapModuleOutputs = append(otherMapModuleOutputs, output)
+		}
+	}
+
 	out := &pbsubstreamsrpc.BlockScopedData{
 		Clock:             clock,
 		Output:            mapModuleOutput,
+		Outputs:           otherMapModuleOutputs,
 		DebugMapOutputs:   extraMapModuleOutputs,
 		DebugStoreOutputs: extraStoreModuleOutputs,
 		Cursor:            cursor.ToOpaque(),
@@ -15,6 +15,7 @@ import (
 	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/streamingfast/substreams"
 	"github.com/streamingfast/substreams/manifest"
 	"github.com/streamingfast/substreams/metrics"
 	pbsubstreamsrpc "github.com/streamingfast/substreams/pb/sf/substreams/rpc/v2"
@@ -262,3 +263,31 @@ func (cr *testCursorResolver) resolveCursor(_ context.Context, cursor *bstream.C
 	}
 	return resp.lastValid, resp.currentHead, resp.err
 }
+
+func TestReturnModuleDataOutputs(t *testing.T) {
+	clock := &pbsubstreams.Clock{Number: 10, Id: "10a"}
+	cursor := &bstream.Cursor{
+		Step:      bstream.StepNew,
+		Block:     bstream.NewBlockRef("10a", 10),
+		LIB:       bstream.NewBlockRef("9a", 9),
+		HeadBlock: bstream.NewBlockRef("10a", 10),
+	}
+	outputA := &pbsubstreamsrpc.MapModuleOutput{Name: "a"}
+	outputC := &pbsubstreamsrpc.MapModuleOutput{Name: "c"}
+
+	var sent *pbsubstreamsrpc.BlockScopedData
+	respFunc := func(resp substreams.ResponseFromAnyTier) error {
+		sent = resp.(*pbsubstreamsrpc.Response).GetBlockScopedData()
+		return nil
+	}
+
+	require.NoError(t, returnModuleDataOutputs(clock, cursor, []*pbsubstreamsrpc.MapModuleOutput{outputA, nil, outputC}, nil, nil, respFunc))
+	assert.Equal(t, outputA, sent.Output)
+	assert.Equal(t, []*pbsubstreamsrpc.MapModuleOutput{outputC}, sent.Outputs)
+	assert.Equal(t, []*pbsubstreamsrpc.MapModuleOutput{outputA, outputC}, sent.AllOutputs())
+	assert.Equal(t, uint64(9), sent.FinalBlockHeight)
+
+	require.NoError(t, returnModuleDataOutputs(clock, cursor, []*pbsubstreamsrpc.MapModuleOutput{nil, outputC}, nil, nil, respFunc))
+	assert.Nil(t, sent.Output)
+	assert.Equal(t, []*pbsubstreamsrpc.MapModuleOutput{outputC}, sent.AllOutputs())
+}
@@ -217,6 +217,8 @@ func (p *Pipeline) handleStepNew(ctx context.Context, block *pbbstream.Block, cl
 		if err := p.stores.flushStores(ctx, p.executionStages, clock.Number); err != nil {
 			return fmt.Errorf("step new irr: stores end of stream: %w", err)
 		}
+	} else {
+		p.stores.evictStores(clock.Number)
 	}
 
 	// note: if we start on a forked cursor, the undo signal will appear BEFORE we send the snapshot
@@ -248,11 +250,12 @@ func (p *Pipeline) handleStepNew(ctx context.Context, block *pbbstream.Block, cl
 			}
 		}
 		p.pendingUndoMessage = nil
-		if err = returnModuleDataOutputs(clock, cursor, p.mapModuleOutput, p.extraMapModuleOutputs, p.extraStoreModuleOutputs, p.respFunc); err != nil {
+		if err = returnModuleDataOutputs(clock, cursor, p.mapModuleOutputs, p.extraMapModuleOutputs, p.extraStoreModuleOutputs, p.respFunc); err != nil {
 			return fmt.Errorf("failed to return module data output: %w", err)
 		}
 	}
 
+	p.stores.markStoresWritten(clock.Number)
 	p.stores.resetStores()
 	logger.Debug("block processed", zap.Uint64("block_num", block.Number))
 	return nil
@@ -262,7 +265,7 @@ func (p *Pipeline) executeModules(ctx context.Context, execOutput execout.Execut
 	ctx, span := reqctx.WithModuleExecutionSpan(ctx, "modules_executions")
 	defer span.EndWithErr(&err)
 
-	p.mapModuleOutput = nil
+	p.mapModuleOutputs = make([]*pbsubstreamsrpc.MapModuleOutput, len(p.outputGraph.OutputModules()))
 	p.extraMapModuleOutputs = nil
 	p.extraStoreModuleOutputs = nil
 	moduleExecutors, err := p.buildModuleExecutors(ctx)
@@ -323,12 +326,36 @@ func (p *Pipeline) execute(ctx context.Context, executor exec.ModuleExecutor, ex
 	logger := reqctx.Logger(ctx)
 
 	executorName := executor.Name()
+
+	skip, err := p.skipsBlock(executorName, execOutput)
+	if err != nil {
+		return resultObj{err: fmt.Errorf("block filter: %w", err)}
+	}
+	if skip {
+		logger.Debug("skipping", zap.Uint64("block", execOutput.Clock().Number), zap.String("module_name", executorName))
+		moduleOutput, outputBytes, err := exec.SkipModule(executor)
+		return resultObj{moduleOutput, outputBytes, err}
+	}
+
 	logger.Debug("executing", zap.Uint64("block", execOutput.Clock().Number), zap.String("module_name", executorName))
 
 	moduleOutput, outputBytes, runError := exec.RunModule(ctx, executor, execOutput)
+	if runError == nil && moduleOutput != nil && !moduleOutput.Cached && p.shouldCheckDeterminism(execOutput.Clock().Number) {
+		metrics.DeterminismChecks.Inc()
+		if err := exec.VerifyDeterminism(ctx, executor, execOutput, outputBytes); err != nil {
+			metrics.DeterminismDivergences.Inc(executorName)
+			logger.Error("module execution is not deterministic", zap.Uint64("block", execOutput.Clock().Number), zap.String("module_name", executorName), zap.Error(err))
+			runError = err
+		}
+	}
 	return resultObj{moduleOutput, outputBytes, runError}
 }
 
+func (p *Pipeline) shouldCheckDeterminism(blockNum uint64) bool {
+	interval := p.runtimeConfig.DeterminismCheckInterval
+	return interval != 0 && blockNum%interval == 0
+}
+
 func (p *Pipeline) applyExecutionResult(ctx context.Context, executor exec.ModuleExecutor, res resultObj, execOutput execout.ExecutionOutput) (err error) {
 	executorName := executor.Name()
 	hasValidOutput := executor.HasValidOutput()
@@ -348,6 +375,9 @@ func (p *Pipeline) applyExecutionResult(ctx context.Context, executor exec.Modul
 	if err := execOutput.Set(executorName, outputBytes); err != nil {
 		return fmt.Errorf("set output cache: %w", err)
 	}
+	if err := p.recordBlockIndex(executorName, execOutput); err != nil {
+		return fmt.Errorf("record block index: %w", err)
+	}
 	if moduleOutput != nil {
 		p.forkHandler.addReversibleOutput(moduleOutput, execOutput.Clock().Id)
 	}
@@ -355,8 +385,8 @@ func (p *Pipeline) applyExecutionResult(ctx context.Context, executor exec.Modul
 }
 
 func (p *Pipeline) saveModuleOutput(output *pbssinternal.ModuleOutput, moduleName string, isProduction bool) {
-	if p.isOutputModule(moduleName) {
-		p.mapModuleOutput = toRPCMapModuleOutputs(output)
+	if idx := p.outputGraph.OutputModuleIndex(moduleName); idx != -1 {
+		p.mapModuleOutputs[idx] = toRPCMapModuleOutputs(output)
 		return
 	}
 	if isProduction {
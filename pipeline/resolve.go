@@ -28,14 +28,19 @@ func BuildRequestDetails(
 	getRecentFinalBlock getBlockFunc,
 	resolveCursor CursorResolver,
 	getHeadBlock getBlockFunc) (req *reqctx.RequestDetails, undoSignal *pbsubstreamsrpc.BlockUndoSignal, err error) {
+	outputModules := request.OutputModuleNames()
 	req = &reqctx.RequestDetails{
 		Modules:                             request.Modules,
 		OutputModule:                        request.OutputModule,
+		OutputModules:                       outputModules,
 		DebugInitialStoreSnapshotForModules: request.DebugInitialStoreSnapshotForModules,
 		ProductionMode:                      request.ProductionMode,
 		StopBlockNum:                        request.StopBlockNum,
 		UniqueID:                            nextUniqueID(),
 	}
+	if len(outputModules) != 0 {
+		req.OutputModule = outputModules[0]
+	}
 
 	req.ResolvedStartBlockNum, req.ResolvedCursor, undoSignal, err = resolveStartBlockNum(ctx, request, resolveCursor, getHeadBlock)
 
@@ -50,9 +55,13 @@ func BuildRequestDetails(
 			return nil, nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid modules: %w", err))
 		}
 
-		moduleHasStatefulDependencies, err = graph.HasStatefulDependencies(request.OutputModule)
-		if err != nil {
-			return nil, nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid output module: %w", err))
+		moduleHasStatefulDependencies = false
+		for _, outputModule := range outputModules {
+			hasStatefulDependencies, err := graph.HasStatefulDependencies(outputModule)
+			if err != nil {
+				return nil, nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid output module: %w", err))
+			}
+			moduleHasStatefulDependencies = moduleHasStatefulDependencies || hasStatefulDependencies
 		}
 	}
 
@@ -75,6 +84,7 @@ func BuildRequestDetailsFromSubrequest(request *pbssinternal.ProcessRangeRequest
 	req = &reqctx.RequestDetails{
 		Modules:               request.Modules,
 		OutputModule:          request.OutputModule,
+		OutputModules:         request.OutputModuleNames(),
 		ProductionMode:        true,
 		IsTier2Request:        true,
 		Tier2Stage:            int(request.Stage),
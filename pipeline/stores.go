@@ -56,6 +56,34 @@ func (s *Stores) resetStores() {
 	}
 }
 
+// markStoresWritten records the block at which the keys of the current deltas
+// were written, for stores with a ttl. It is to be called before resetStores.
+func (s *Stores) markStoresWritten(blockNum uint64) {
+	for _, s := range s.StoreMap.All() {
+		if writeMarker, ok := s.(store.WriteMarker); ok {
+			writeMarker.MarkWritten(blockNum)
+		}
+	}
+}
+
+// evictStores is called only for Tier1 request, evicting the expired keys of
+// stores with a ttl when crossing store boundaries, like they are when snapshots
+// are saved by Tier2 requests and squashing. It runs before the modules of the
+// block, the evictions being sent with the deltas of their stores.
+func (s *Stores) evictStores(blockNum uint64) {
+	if s.StoreMap == nil {
+		return
+	}
+
+	for _, boundaryBlock := range s.bounder.GetStoreFlushRanges(false, s.bounder.requestStopBlock, blockNum) {
+		for _, st := range s.StoreMap.All() {
+			if evictable, ok := st.(store.Evictable); ok {
+				evictable.EvictExpired(boundaryBlock)
+			}
+		}
+	}
+}
+
 // flushStores is called only for Tier2 request, as to not save reversible stores.
 func (s *Stores) flushStores(ctx context.Context, executionStages outputmodules.ExecutionStages, blockNum uint64) (err error) {
 	if s.StoreMap == nil {
@@ -77,6 +105,9 @@ func (s *Stores) flushStores(ctx context.Context, executionStages outputmodules.
 
 func (s *Stores) saveStoresSnapshots(ctx context.Context, lastLayer outputmodules.LayerModules, stage int, boundaryBlock uint64) (err error) {
 	for _, mod := range lastLayer {
+		if evictable, ok := s.StoreMap[mod.Name].(store.Evictable); ok && boundaryBlock%s.bounder.interval == 0 {
+			evictable.EvictExpired(boundaryBlock)
+		}
 		store := s.StoreMap[mod.Name]
 		s.logger.Info("flushing store at boundary", zap.Uint64("boundary", boundaryBlock), zap.String("store", mod.Name), zap.Int("stage", stage))
 		if err := s.saveStoreSnapshot(ctx, store, boundaryBlock); err != nil {
@@ -47,6 +47,10 @@ func (p *Pipeline) OnStreamTerminated(ctx context.Context, err error) error {
 		return fmt.Errorf("end of stream: %w", err)
 	}
 
+	if err := p.saveBlockIndexes(ctx); err != nil {
+		return fmt.Errorf("end of stream: %w", err)
+	}
+
 	// WARN/FIXME: calling flushStores once at the end of a process
 	// is super risky, as this function was made to b e called at each
 	// block to flush stores supporting holes in chains.
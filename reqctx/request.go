@@ -12,7 +12,9 @@ type RequestDetails struct {
 	Modules *pbsubstreams.Modules
 
 	DebugInitialStoreSnapshotForModules []string
-	OutputModule                        string
+	// OutputModule is the first of the OutputModules
+	OutputModule  string
+	OutputModules []string
 	// What the user requested, derived from either the Request.StartBlockNum or Request.Cursor
 	ResolvedStartBlockNum uint64
 	ResolvedCursor        string
@@ -34,7 +36,15 @@ func (d *RequestDetails) UniqueIDString() string {
 }
 
 func (d *RequestDetails) IsOutputModule(modName string) bool {
-	return modName == d.OutputModule
+	if len(d.OutputModules) == 0 {
+		return modName == d.OutputModule
+	}
+	for _, name := range d.OutputModules {
+		if name == modName {
+			return true
+		}
+	}
+	return false
 }
 
 func (d *RequestDetails) ShouldReturnWrittenPartials(modName string) bool {
@@ -4,6 +4,8 @@ import (
 	"github.com/streamingfast/dstore"
 
 	"github.com/streamingfast/substreams/orchestrator/work"
+	"github.com/streamingfast/substreams/storage/compression"
+	"github.com/streamingfast/substreams/wasm"
 )
 
 // RuntimeConfig is a global configuration for the service.
@@ -11,9 +13,15 @@ import (
 type RuntimeConfig struct {
 	StateBundleSize uint64
 
-	MaxWasmFuel                uint64 // if not 0, enable fuel consumption monitoring to stop runaway wasm module processing forever
-	MaxJobsAhead               uint64 // limit execution of depencency jobs so they don't go too far ahead of the modules that depend on them (ex: module X is 2 million blocks ahead of module Y that depends on it, we don't want to schedule more module X jobs until Y caught up a little bit)
-	DefaultParallelSubrequests uint64 // how many sub-jobs to launch for a given user
+	WasmRuntime                string                 // name of the wasm runtime executing the modules, wasm.DefaultRuntime() if empty, can be overridden per request by the auth layer
+	MaxWasmFuel                uint64                 // if not 0, enable fuel consumption monitoring to stop runaway wasm module processing forever
+	WasmFuelMetering           bool                   // if true, the fuel consumed by each module is measured and reported in the module stats and metering events
+	MaxWasmMemoryPages         uint32                 // if not 0, limit the linear memory of each module instance to that many pages of 64KiB
+	WasmCompilationCache       *wasm.CompilationCache // if set, the compiled modules are kept there across requests
+	DeterminismCheckInterval   uint64                 // if not 0, the modules executed on blocks multiple of it are replayed in fresh instances, failing the request if their output differs
+	MaxJobsAhead               uint64                 // limit execution of depencency jobs so they don't go too far ahead of the modules that depend on them (ex: module X is 2 million blocks ahead of module Y that depends on it, we don't want to schedule more module X jobs until Y caught up a little bit), in segments, only enforced by the critical-path scheduling strategy
+	SchedulingStrategy         string                 // name of the strategy picking the next job of parallel processing, stage.SchedulingStrategyBreadthFirst if empty
+	DefaultParallelSubrequests uint64                 // how many sub-jobs to launch for a given user
 	// derives substores `states/`, for `store` modules snapshots (full and partial)
 	// and `outputs/` for execution output of both `map` and `store` module kinds
 	BaseObjectStore dstore.Store
@@ -21,6 +29,8 @@ type RuntimeConfig struct {
 	WorkerFactory   work.WorkerFactory
 
 	ModuleExecutionTracing bool
+	SortedStoreSnapshots   bool              // if true, full stores snapshots are written in the sorted format, which is loaded lazily instead of fully decoded in memory
+	CacheCompression       compression.Codec // codec used to compress the stores snapshots and execution outputs written to BaseObjectStore, readers detect it from the files content
 }
 
 func NewRuntimeConfig(
@@ -41,6 +51,14 @@ func NewRuntimeConfig(
 		DefaultCacheTag:            defaultCacheTag,
 		WorkerFactory:              workerFactory,
 		// overridden by Tier Options
-		ModuleExecutionTracing: false,
+		ModuleExecutionTracing:   false,
+		SortedStoreSnapshots:     false,
+		CacheCompression:         compression.None,
+		WasmRuntime:              "",
+		WasmFuelMetering:         false,
+		MaxWasmMemoryPages:       0,
+		WasmCompilationCache:     nil,
+		DeterminismCheckInterval: 0,
+		SchedulingStrategy:       "",
 	}
 }
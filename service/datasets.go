@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/streamingfast/substreams/dataset"
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/streamingfast/substreams/wasm"
+)
+
+// maxCachedDatasets is the number of datasets kept in memory between requests
+const maxCachedDatasets = 64
+
+// requestWASMExtensions returns the wasm extensions of a request: the `configured` ones,
+// plus the dataset extension when the used modules reference datasets.
+func requestWASMExtensions(ctx context.Context, configured []wasm.WASMExtensioner, loader *dataset.Loader, modules *pbsubstreams.Modules, usedModules []*pbsubstreams.Module) ([]wasm.WASMExtensioner, error) {
+	ext, err := newDatasetExtension(ctx, loader, modules, usedModules)
+	if err != nil {
+		return nil, fmt.Errorf("loading datasets: %w", err)
+	}
+	if ext == nil {
+		return configured, nil
+	}
+
+	out := make([]wasm.WASMExtensioner, 0, len(configured)+1)
+	out = append(out, configured...)
+	return append(out, ext), nil
+}
+
+// datasetExtension exposes the datasets of a request to its modules in the `dataset`
+// namespace, each module can only look up the datasets it references, whose hashes are
+// part of its module hash. Both functions take a serialized `sf.substreams.v1.DatasetQuery`
+// and return a serialized `sf.substreams.v1.StoreEntries` holding the matching entry, if any:
+//
+//   - `get` returns the entry with the queried key,
+//   - `get_floor` returns the entry with the greatest key lower or equal to the queried key.
+type datasetExtension struct {
+	datasets map[string]map[string]*dataset.Dataset // module name -> dataset name -> dataset
+}
+
+// newDatasetExtension loads the datasets referenced by `usedModules`, declared in `modules`.
+// It returns nil when none of them references a dataset.
+func newDatasetExtension(ctx context.Context, loader *dataset.Loader, modules *pbsubstreams.Modules, usedModules []*pbsubstreams.Module) (*datasetExtension, error) {
+	ext := &datasetExtension{datasets: map[string]map[string]*dataset.Dataset{}}
+
+	for _, module := range usedModules {
+		if len(module.DatasetIndexes) == 0 {
+			continue
+		}
+
+		byName := map[string]*dataset.Dataset{}
+		for _, idx := range module.DatasetIndexes {
+			if int(idx) >= len(modules.Datasets) {
+				return nil, fmt.Errorf("module %q: dataset index %d out of range", module.Name, idx)
+			}
+			def := modules.Datasets[idx]
+
+			ds, err := loader.Load(ctx, def)
+			if err != nil {
+				return nil, fmt.Errorf("module %q: %w", module.Name, err)
+			}
+			byName[def.Name] = ds
+		}
+		ext.datasets[module.Name] = byName
+	}
+
+	if len(ext.datasets) == 0 {
+		return nil, nil
+	}
+	return ext, nil
+}
+
+func (e *datasetExtension) WASMExtensions() map[string]map[string]wasm.WASMExtension {
+	return map[string]map[string]wasm.WASMExtension{
+		"dataset": {
+			"get":       e.lookup((*dataset.Dataset).Get),
+			"get_floor": e.lookup((*dataset.Dataset).Floor),
+		},
+	}
+}
+
+func (e *datasetExtension) lookup(find func(*dataset.Dataset, string) *pbsubstreams.StoreEntry) wasm.WASMExtension {
+	return func(ctx context.Context, _ string, _ *pbsubstreams.Clock, in []byte) ([]byte, error) {
+		query := &pbsubstreams.DatasetQuery{}
+		if err := proto.Unmarshal(in, query); err != nil {
+			return nil, fmt.Errorf("unmarshalling dataset query: %w", err)
+		}
+
+		moduleName := wasm.FromContext(ctx).ModuleName
+		ds := e.datasets[moduleName][query.Dataset]
+		if ds == nil {
+			return nil, fmt.Errorf("module %q does not reference dataset %q", moduleName, query.Dataset)
+		}
+
+		out := &pbsubstreams.StoreEntries{}
+		if entry := find(ds, query.Key); entry != nil {
+			out.Entries = append(out.Entries, entry)
+		}
+		return proto.MarshalOptions{Deterministic: true}.Marshal(out)
+	}
+}
@@ -1,6 +1,45 @@
 package service
 
 import (
-	//_ "github.com/streamingfast/substreams/wasm/wasmtime"
+	"context"
+	"fmt"
+
+	"github.com/streamingfast/dauth"
+
+	"github.com/streamingfast/substreams/service/config"
+	"github.com/streamingfast/substreams/wasm"
 	_ "github.com/streamingfast/substreams/wasm/wazero"
 )
+
+// wasmRuntimeName returns the name of the wasm runtime executing the modules of the
+// request: the one selected with the X-Sf-Substreams-Wasm-Runtime header, if any, else
+// the `configured` one, else the default one.
+func wasmRuntimeName(ctx context.Context, configured string) (string, error) {
+	runtimeName := configured
+	if runtimeName == "" {
+		runtimeName = wasm.DefaultRuntime()
+	}
+	if auth := dauth.FromContext(ctx); auth != nil {
+		if selected := auth.Get("X-Sf-Substreams-Wasm-Runtime"); selected != "" {
+			runtimeName = selected
+		}
+	}
+	if err := wasm.ValidateRuntime(runtimeName); err != nil {
+		return "", fmt.Errorf("selecting wasm runtime: %w", err)
+	}
+	return runtimeName, nil
+}
+
+// registryOptions returns the options of the wasm registries created for the requests.
+func registryOptions(runtimeConfig config.RuntimeConfig) (out []wasm.RegistryOption) {
+	if runtimeConfig.WasmFuelMetering {
+		out = append(out, wasm.WithFuelMetering())
+	}
+	if runtimeConfig.MaxWasmMemoryPages != 0 {
+		out = append(out, wasm.WithMaxMemoryPages(runtimeConfig.MaxWasmMemoryPages))
+	}
+	if runtimeConfig.WasmCompilationCache != nil {
+		out = append(out, wasm.WithCompilationCache(runtimeConfig.WasmCompilationCache))
+	}
+	return
+}
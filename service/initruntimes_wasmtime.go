@@ -0,0 +1,8 @@
+//go:build cgo
+
+package service
+
+import (
+	// wasmtime is linked against its C library, it can only be selected in binaries built with cgo
+	_ "github.com/streamingfast/substreams/wasm/wasmtime"
+)
@@ -6,9 +6,11 @@ import (
 
 	"github.com/streamingfast/dmetering"
 	"google.golang.org/protobuf/proto"
+
+	"github.com/streamingfast/substreams/metrics"
 )
 
-func sendMetering(meter dmetering.Meter, userID, apiKeyID, ip, userMeta, endpoint string, resp proto.Message) {
+func sendMetering(meter dmetering.Meter, fuelMeter *metrics.FuelMeter, userID, apiKeyID, ip, userMeta, endpoint string, resp proto.Message) {
 	bytesRead := meter.BytesReadDelta()
 	bytesWritten := meter.BytesWrittenDelta()
 
@@ -24,6 +26,7 @@ func sendMetering(meter dmetering.Meter, userID, apiKeyID, ip, userMeta, endpoin
 			"written_bytes": float64(bytesWritten),
 			"read_bytes":    float64(bytesRead),
 			"message_count": 1,
+			"fuel_consumed": float64(fuelMeter.FuelConsumedDelta()),
 		},
 		Timestamp: time.Now(),
 	}
@@ -1,7 +1,9 @@
 package service
 
 import (
+	"github.com/streamingfast/substreams/orchestrator/work"
 	"github.com/streamingfast/substreams/pipeline"
+	"github.com/streamingfast/substreams/storage/compression"
 	"github.com/streamingfast/substreams/wasm"
 )
 
@@ -45,6 +47,135 @@ func WithMaxWasmFuelPerBlockModule(maxFuel uint64) Option {
 	}
 }
 
+// WithSortedStoreSnapshots makes full stores snapshots written in the sorted,
+// block-indexed format so that they can be loaded lazily. Snapshots already in
+// that format are loaded lazily whether this option is used or not.
+func WithSortedStoreSnapshots() Option {
+	return func(a anyTierService) {
+		switch s := a.(type) {
+		case *Tier1Service:
+			s.runtimeConfig.SortedStoreSnapshots = true
+		case *Tier2Service:
+			s.runtimeConfig.SortedStoreSnapshots = true
+		}
+	}
+}
+
+// WithCacheCompression compresses the stores snapshots and execution outputs
+// written to the cache with `codec`. Files are read back whatever the codec
+// they were written with, so it can be changed on a live deployment.
+func WithCacheCompression(codec compression.Codec) Option {
+	return func(a anyTierService) {
+		switch s := a.(type) {
+		case *Tier1Service:
+			s.runtimeConfig.CacheCompression = codec
+		case *Tier2Service:
+			s.runtimeConfig.CacheCompression = codec
+		}
+	}
+}
+
+// WithWasmRuntime selects the wasm runtime executing the modules, by the name it
+// was registered with. It must have been validated with wasm.ValidateRuntime.
+func WithWasmRuntime(runtimeName string) Option {
+	return func(a anyTierService) {
+		switch s := a.(type) {
+		case *Tier1Service:
+			s.runtimeConfig.WasmRuntime = runtimeName
+		case *Tier2Service:
+			s.runtimeConfig.WasmRuntime = runtimeName
+		}
+	}
+}
+
+// WithWasmFuelMetering measures the fuel consumed by each module execution, reported
+// in the module stats and the metering events. It slows down the execution.
+func WithWasmFuelMetering() Option {
+	return func(a anyTierService) {
+		switch s := a.(type) {
+		case *Tier1Service:
+			s.runtimeConfig.WasmFuelMetering = true
+		case *Tier2Service:
+			s.runtimeConfig.WasmFuelMetering = true
+		}
+	}
+}
+
+// WithWasmCompilationCache makes the wasm runtimes keep the modules they compile in
+// `cache`, so that they are only compiled once across requests.
+func WithWasmCompilationCache(cache *wasm.CompilationCache) Option {
+	return func(a anyTierService) {
+		switch s := a.(type) {
+		case *Tier1Service:
+			s.runtimeConfig.WasmCompilationCache = cache
+		case *Tier2Service:
+			s.runtimeConfig.WasmCompilationCache = cache
+		}
+	}
+}
+
+// WithMaxWasmMemoryPages limits the linear memory of each module instance to `maxPages`
+// pages of 64KiB, executions going over it fail. It must not be over wasm.MaxMemoryPages.
+func WithMaxWasmMemoryPages(maxPages uint32) Option {
+	return func(a anyTierService) {
+		switch s := a.(type) {
+		case *Tier1Service:
+			s.runtimeConfig.MaxWasmMemoryPages = maxPages
+		case *Tier2Service:
+			s.runtimeConfig.MaxWasmMemoryPages = maxPages
+		}
+	}
+}
+
+// WithDeterminismCheck replays the modules executed on the blocks multiple of `interval`
+// in fresh instances, failing the request when the outputs or store deltas of the two
+// executions differ.
+func WithDeterminismCheck(interval uint64) Option {
+	return func(a anyTierService) {
+		switch s := a.(type) {
+		case *Tier1Service:
+			s.runtimeConfig.DeterminismCheckInterval = interval
+		case *Tier2Service:
+			s.runtimeConfig.DeterminismCheckInterval = interval
+		}
+	}
+}
+
+// WithSchedulingStrategy selects the strategy picking the next job of parallel
+// processing on tier1, by name. It must have been validated with stage.NewSchedulingStrategy.
+func WithSchedulingStrategy(name string) Option {
+	return func(a anyTierService) {
+		switch s := a.(type) {
+		case *Tier1Service:
+			s.runtimeConfig.SchedulingStrategy = name
+		}
+	}
+}
+
+// WithRequestsCapacity makes tier2 report its load to tier1 in its responses, as its
+// number of concurrent requests over `capacity`, so that tier1 sends it fewer jobs
+// when it is overloaded.
+func WithRequestsCapacity(capacity uint64) Option {
+	return func(a anyTierService) {
+		switch s := a.(type) {
+		case *Tier2Service:
+			s.requestsCapacity = capacity
+		}
+	}
+}
+
+// WithWorkerFactory replaces the workers running the jobs of parallel processing on tier1,
+// by default sending them to tier2 over gRPC. See work.NewLocalWorkerFactory to run them
+// in the current process.
+func WithWorkerFactory(factory work.WorkerFactory) Option {
+	return func(a anyTierService) {
+		switch s := a.(type) {
+		case *Tier1Service:
+			s.runtimeConfig.WorkerFactory = factory
+		}
+	}
+}
+
 func WithModuleExecutionTracing() Option {
 	return func(a anyTierService) {
 		switch s := a.(type) {
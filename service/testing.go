@@ -40,7 +40,7 @@ func TestNewService(runtimeConfig config.RuntimeConfig, linearHandoffBlockNum ui
 }
 
 func (s *Tier1Service) TestBlocks(ctx context.Context, isSubRequest bool, request *pbsubstreamsrpc.Request, respFunc substreams.ResponseFunc) error {
-	outputGraph, err := outputmodules.NewOutputModuleGraph(request.OutputModule, request.ProductionMode, request.Modules)
+	outputGraph, err := outputmodules.NewOutputModulesGraph(request.OutputModuleNames(), request.ProductionMode, request.Modules)
 	if err != nil {
 		return stream.NewErrInvalidArg(err.Error())
 	}
@@ -29,6 +29,7 @@ import (
 	"connectrpc.com/connect"
 	"github.com/streamingfast/substreams"
 	"github.com/streamingfast/substreams/client"
+	"github.com/streamingfast/substreams/dataset"
 	"github.com/streamingfast/substreams/metrics"
 	"github.com/streamingfast/substreams/orchestrator/plan"
 	"github.com/streamingfast/substreams/orchestrator/work"
@@ -59,6 +60,7 @@ type Tier1Service struct {
 
 	blockType          string
 	wasmExtensions     []wasm.WASMExtensioner
+	datasetLoader      *dataset.Loader
 	pipelineOptions    []pipeline.PipelineOptioner
 	failedRequestsLock sync.RWMutex
 	failedRequests     map[string]*recordedFailure
@@ -145,6 +147,7 @@ func NewTier1(
 		tracer:         tracing.GetTracer(),
 		failedRequests: make(map[string]*recordedFailure),
 		resolveCursor:  pipeline.NewCursorResolver(hub, mergedBlocksStore, forkedBlocksStore),
+		datasetLoader:  dataset.NewLoader(maxCachedDatasets),
 		logger:         logger,
 	}
 
@@ -176,6 +179,7 @@ func (s *Tier1Service) Blocks(
 	ctx = logging.WithLogger(ctx, logger)
 	ctx = reqctx.WithTracer(ctx, s.tracer)
 	ctx = dmetering.WithBytesMeter(ctx)
+	ctx = metrics.WithFuelMeter(ctx)
 
 	ctx, span := reqctx.WithSpan(ctx, "substreams/tier1/request")
 	defer span.EndWithErr(&err)
@@ -207,7 +211,7 @@ func (s *Tier1Service) Blocks(
 		zap.Uint64("stop_block", request.StopBlockNum),
 		zap.String("cursor", request.StartCursor),
 		zap.Strings("modules", moduleNames),
-		zap.String("output_module", request.OutputModule),
+		zap.Strings("output_modules", request.OutputModuleNames()),
 	}
 	fields = append(fields, zap.Bool("production_mode", request.ProductionMode))
 	if auth := dauth.FromContext(ctx); auth != nil {
@@ -232,13 +236,17 @@ func (s *Tier1Service) Blocks(
 		return connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("validate request: %w", err))
 	}
 
-	outputGraph, err := outputmodules.NewOutputModuleGraph(request.OutputModule, request.ProductionMode, request.Modules)
+	outputGraph, err := outputmodules.NewOutputModulesGraph(request.OutputModuleNames(), request.ProductionMode, request.Modules)
 	if err != nil {
 		return bsstream.NewErrInvalidArg(err.Error())
 	}
 
+	var outputModuleHashes []string
+	for _, outputModule := range outputGraph.OutputModules() {
+		outputModuleHashes = append(outputModuleHashes, outputGraph.ModuleHashes().Get(outputModule.Name))
+	}
 	requestID := fmt.Sprintf("%s:%d:%d:%s:%t:%t:%s",
-		outputGraph.ModuleHashes().Get(request.OutputModule),
+		strings.Join(outputModuleHashes, ","),
 		request.StartBlockNum,
 		request.StopBlockNum,
 		request.StartCursor,
@@ -297,7 +305,7 @@ func (s *Tier1Service) writePackage(ctx context.Context, request *pbsubstreamsrp
 		return fmt.Errorf("marshalling package: %w", err)
 	}
 
-	modulePath := filepath.Join(reqctx.Details(ctx).CacheTag, outputGraph.ModuleHashes().Get(request.OutputModule))
+	modulePath := filepath.Join(reqctx.Details(ctx).CacheTag, outputGraph.ModuleHashes().Get(outputGraph.OutputModule().Name))
 	moduleStore, err := s.runtimeConfig.BaseObjectStore.SubStore(modulePath)
 	if err != nil {
 		return fmt.Errorf("getting substore: %w", err)
@@ -382,7 +390,15 @@ func (s *Tier1Service) blocks(ctx context.Context, request *pbsubstreamsrpc.Requ
 		return stream.NewErrInvalidArg(err.Error())
 	}
 
-	wasmRuntime := wasm.NewRegistry(s.wasmExtensions, s.runtimeConfig.MaxWasmFuel)
+	runtimeName, err := wasmRuntimeName(ctx, s.runtimeConfig.WasmRuntime)
+	if err != nil {
+		return stream.NewErrInvalidArg(err.Error())
+	}
+	wasmExtensions, err := requestWASMExtensions(ctx, s.wasmExtensions, s.datasetLoader, request.Modules, outputGraph.UsedModules())
+	if err != nil {
+		return err
+	}
+	wasmRuntime := wasm.NewRegistryWithRuntime(runtimeName, wasmExtensions, s.runtimeConfig.MaxWasmFuel, registryOptions(s.runtimeConfig)...)
 
 	cacheStore, err := s.runtimeConfig.BaseObjectStore.SubStore(requestDetails.CacheTag)
 	if err != nil {
@@ -393,11 +409,14 @@ func (s *Tier1Service) blocks(ctx context.Context, request *pbsubstreamsrpc.Requ
 	if err != nil {
 		return fmt.Errorf("new config map: %w", err)
 	}
+	execOutputConfigs.SetCompression(s.runtimeConfig.CacheCompression)
 
 	storeConfigs, err := store.NewConfigMap(cacheStore, outputGraph.Stores(), outputGraph.ModuleHashes(), tracing.GetTraceID(ctx).String())
 	if err != nil {
 		return fmt.Errorf("configuring stores: %w", err)
 	}
+	storeConfigs.SetSortedSnapshots(s.runtimeConfig.SortedStoreSnapshots)
+	storeConfigs.SetCompression(s.runtimeConfig.CacheCompression)
 
 	stores := pipeline.NewStores(ctx, storeConfigs, s.runtimeConfig.StateBundleSize, requestDetails.LinearHandoffBlockNum, request.StopBlockNum, false)
 
@@ -460,7 +479,7 @@ func (s *Tier1Service) blocks(ctx context.Context, request *pbsubstreamsrpc.Requ
 		zap.String("request_start_cursor", request.StartCursor),
 		zap.String("resolved_cursor", requestDetails.ResolvedCursor),
 		zap.Uint64("max_parallel_jobs", requestDetails.MaxParallelJobs),
-		zap.String("output_module", request.OutputModule),
+		zap.Strings("output_modules", requestDetails.OutputModules),
 	)
 
 	if err := pipe.InitTier1StoresAndBackprocess(ctx, reqPlan); err != nil {
@@ -522,6 +541,7 @@ func tier1ResponseHandler(ctx context.Context, mut *sync.Mutex, logger *zap.Logg
 	userMeta := auth.Meta()
 	ip := auth.RealIP()
 	meter := dmetering.GetBytesMeter(ctx)
+	fuelMeter := metrics.GetFuelMeter(ctx)
 
 	return func(respAny substreams.ResponseFromAnyTier) error {
 		resp := respAny.(*pbsubstreamsrpc.Response)
@@ -537,7 +557,7 @@ func tier1ResponseHandler(ctx context.Context, mut *sync.Mutex, logger *zap.Logg
 			return connect.NewError(connect.CodeUnavailable, err)
 		}
 
-		sendMetering(meter, userID, apiKeyID, ip, userMeta, "sf.substreams.rpc.v2/Blocks", resp)
+		sendMetering(meter, fuelMeter, userID, apiKeyID, ip, userMeta, "sf.substreams.rpc.v2/Blocks", resp)
 		return nil
 	}
 }
@@ -20,6 +20,7 @@ import (
 
 	pbbstream "github.com/streamingfast/bstream/pb/sf/bstream/v1"
 	"github.com/streamingfast/substreams"
+	"github.com/streamingfast/substreams/dataset"
 	"github.com/streamingfast/substreams/metrics"
 	pbssinternal "github.com/streamingfast/substreams/pb/sf/substreams/intern/v2"
 	"github.com/streamingfast/substreams/pipeline"
@@ -29,10 +30,12 @@ import (
 	"github.com/streamingfast/substreams/reqctx"
 	"github.com/streamingfast/substreams/service/config"
 	"github.com/streamingfast/substreams/storage/execout"
+	"github.com/streamingfast/substreams/storage/index"
 	"github.com/streamingfast/substreams/storage/store"
 	"github.com/streamingfast/substreams/wasm"
 	"go.opentelemetry.io/otel/attribute"
 	ttrace "go.opentelemetry.io/otel/trace"
+	"go.uber.org/atomic"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
@@ -42,11 +45,15 @@ import (
 type Tier2Service struct {
 	blockType         string
 	wasmExtensions    []wasm.WASMExtensioner
+	datasetLoader     *dataset.Loader
 	pipelineOptions   []pipeline.PipelineOptioner
 	streamFactoryFunc StreamFactoryFunc
 	runtimeConfig     config.RuntimeConfig
 	tracer            ttrace.Tracer
 	logger            *zap.Logger
+
+	activeRequests   *atomic.Int64
+	requestsCapacity uint64 // number of concurrent requests the server is sized for, its load is not reported to tier1 if 0
 }
 
 const protoPkfPrefix = "type.googleapis.com/"
@@ -94,10 +101,12 @@ func NewTier2(
 
 	logger.Debug("launching tier2 service", zap.String("block_type", blockType))
 	s := &Tier2Service{
-		runtimeConfig: runtimeConfig,
-		blockType:     blockType,
-		tracer:        tracing.GetTracer(),
-		logger:        logger,
+		runtimeConfig:  runtimeConfig,
+		blockType:      blockType,
+		tracer:         tracing.GetTracer(),
+		datasetLoader:  dataset.NewLoader(maxCachedDatasets),
+		logger:         logger,
+		activeRequests: atomic.NewInt64(0),
 	}
 
 	sf := &StreamFactory{
@@ -121,6 +130,9 @@ func (s *Tier2Service) ProcessRange(request *pbssinternal.ProcessRangeRequest, s
 	var err error
 	ctx := streamSrv.Context()
 
+	s.activeRequests.Inc()
+	defer s.activeRequests.Dec()
+
 	// TODO: use stage and segment numbers when implemented
 	stage := request.OutputModule
 	segment := fmt.Sprintf("%d:%d",
@@ -134,6 +146,7 @@ func (s *Tier2Service) ProcessRange(request *pbssinternal.ProcessRangeRequest, s
 
 	ctx = logging.WithLogger(ctx, logger)
 	ctx = dmetering.WithBytesMeter(ctx)
+	ctx = metrics.WithFuelMeter(ctx)
 	ctx = reqctx.WithTracer(ctx, s.tracer)
 
 	ctx, span := reqctx.WithSpan(ctx, "substreams/tier2/request")
@@ -174,7 +187,7 @@ func (s *Tier2Service) ProcessRange(request *pbssinternal.ProcessRangeRequest, s
 
 	logger.Info("incoming substreams ProcessRange request", fields...)
 
-	respFunc := tier2ResponseHandler(ctx, logger, streamSrv)
+	respFunc := tier2ResponseHandler(ctx, logger, streamSrv, s.loadHint)
 	err = s.processRange(ctx, request, respFunc, tracing.GetTraceID(ctx).String())
 	grpcError = toGRPCError(ctx, err)
 
@@ -186,6 +199,21 @@ func (s *Tier2Service) ProcessRange(request *pbssinternal.ProcessRangeRequest, s
 	return grpcError
 }
 
+// ProcessLocalRange processes `request` in the current process instead of serving it
+// over gRPC, sending the responses to `respFunc`. It is used by the tools running
+// modules against a merged blocks store, and by the tier1 local workers. The fuel consumed
+// is added to the FuelMeter of `ctx` when it has one, so that the tier1 request running
+// the job meters it.
+func (s *Tier2Service) ProcessLocalRange(ctx context.Context, request *pbssinternal.ProcessRangeRequest, respFunc substreams.ResponseFunc) error {
+	ctx = logging.WithLogger(ctx, s.logger)
+	if metrics.GetFuelMeter(ctx) == nil {
+		ctx = metrics.WithFuelMeter(ctx)
+	}
+	ctx = reqctx.WithTracer(ctx, s.tracer)
+
+	return s.processRange(ctx, request, respFunc, tracing.GetTraceID(ctx).String())
+}
+
 func (s *Tier2Service) processRange(ctx context.Context, request *pbssinternal.ProcessRangeRequest, respFunc substreams.ResponseFunc, traceID string) error {
 	logger := reqctx.Logger(ctx)
 
@@ -195,7 +223,7 @@ func (s *Tier2Service) processRange(ctx context.Context, request *pbssinternal.P
 
 	// FIXME: here, we validate that we have only modules on the same
 	// stage, otherwise we fall back.
-	outputGraph, err := outputmodules.NewOutputModuleGraph(request.OutputModule, true, request.Modules)
+	outputGraph, err := outputmodules.NewOutputModulesGraph(request.OutputModuleNames(), true, request.Modules)
 	if err != nil {
 		return stream.NewErrInvalidArg(err.Error())
 	}
@@ -225,7 +253,15 @@ func (s *Tier2Service) processRange(ctx context.Context, request *pbssinternal.P
 		return stream.NewErrInvalidArg(err.Error())
 	}
 
-	wasmRuntime := wasm.NewRegistry(s.wasmExtensions, s.runtimeConfig.MaxWasmFuel)
+	runtimeName, err := wasmRuntimeName(ctx, s.runtimeConfig.WasmRuntime)
+	if err != nil {
+		return stream.NewErrInvalidArg(err.Error())
+	}
+	wasmExtensions, err := requestWASMExtensions(ctx, s.wasmExtensions, s.datasetLoader, request.Modules, outputGraph.UsedModules())
+	if err != nil {
+		return err
+	}
+	wasmRuntime := wasm.NewRegistryWithRuntime(runtimeName, wasmExtensions, s.runtimeConfig.MaxWasmFuel, registryOptions(s.runtimeConfig)...)
 
 	cacheStore, err := s.runtimeConfig.BaseObjectStore.SubStore(requestDetails.CacheTag)
 	if err != nil {
@@ -236,21 +272,35 @@ func (s *Tier2Service) processRange(ctx context.Context, request *pbssinternal.P
 	if err != nil {
 		return fmt.Errorf("new config map: %w", err)
 	}
+	execOutputConfigs.SetCompression(s.runtimeConfig.CacheCompression)
+
+	indexConfigs, err := index.NewConfigs(cacheStore, outputGraph.UsedModules(), outputGraph.ModuleHashes(), logger)
+	if err != nil {
+		return fmt.Errorf("new index config map: %w", err)
+	}
+	indexConfigs.SetCompression(s.runtimeConfig.CacheCompression)
 
 	storeConfigs, err := store.NewConfigMap(cacheStore, outputGraph.Stores(), outputGraph.ModuleHashes(), traceID)
 	if err != nil {
 		return fmt.Errorf("configuring stores: %w", err)
 	}
+	storeConfigs.SetSortedSnapshots(s.runtimeConfig.SortedStoreSnapshots)
+	storeConfigs.SetCompression(s.runtimeConfig.CacheCompression)
 	stores := pipeline.NewStores(ctx, storeConfigs, s.runtimeConfig.StateBundleSize, requestDetails.ResolvedStartBlockNum, request.StopBlockNum, true)
 
-	outputModule := outputGraph.OutputModule()
-
 	var execOutWriter *execout.Writer
 	if !outputGraph.StagedUsedModules()[request.Stage].LastLayer().IsStoreLayer() {
+		// the map stage executes all the stages, so the output modules of earlier stages are written as well
+		var outputModules []string
+		for _, outputModule := range outputGraph.OutputModules() {
+			if outputModule.GetKindStore() == nil {
+				outputModules = append(outputModules, outputModule.Name)
+			}
+		}
 		execOutWriter = execout.NewWriter(
 			requestDetails.ResolvedStartBlockNum,
 			requestDetails.StopBlockNum,
-			outputModule.Name,
+			outputModules,
 			execOutputConfigs,
 		)
 	}
@@ -263,6 +313,7 @@ func (s *Tier2Service) processRange(ctx context.Context, request *pbssinternal.P
 	opts := s.buildPipelineOptions(ctx, request)
 	opts = append(opts, pipeline.WithFinalBlocksOnly())
 	opts = append(opts, pipeline.WithHighestStage(request.Stage))
+	opts = append(opts, pipeline.WithBlockIndexes(indexConfigs))
 
 	pipe := pipeline.New(
 		ctx,
@@ -318,8 +369,18 @@ func (s *Tier2Service) buildPipelineOptions(ctx context.Context, request *pbssin
 	return
 }
 
-func tier2ResponseHandler(ctx context.Context, logger *zap.Logger, streamSrv pbssinternal.Substreams_ProcessRangeServer) substreams.ResponseFunc {
+// loadHint returns the load reported to tier1 in the responses, the number of requests
+// being processed over the number the server is sized for, 0 if it is not known.
+func (s *Tier2Service) loadHint() float32 {
+	if s.requestsCapacity == 0 {
+		return 0
+	}
+	return float32(s.activeRequests.Load()) / float32(s.requestsCapacity)
+}
+
+func tier2ResponseHandler(ctx context.Context, logger *zap.Logger, streamSrv pbssinternal.Substreams_ProcessRangeServer, loadHint func() float32) substreams.ResponseFunc {
 	meter := dmetering.GetBytesMeter(ctx)
+	fuelMeter := metrics.GetFuelMeter(ctx)
 	auth := dauth.FromContext(ctx)
 	userID := auth.UserID()
 	apiKeyID := auth.APIKeyID()
@@ -328,12 +389,13 @@ func tier2ResponseHandler(ctx context.Context, logger *zap.Logger, streamSrv pbs
 
 	return func(respAny substreams.ResponseFromAnyTier) error {
 		resp := respAny.(*pbssinternal.ProcessRangeResponse)
+		resp.LoadHint = loadHint()
 		if err := streamSrv.Send(resp); err != nil {
 			logger.Info("unable to send block probably due to client disconnecting", zap.Error(err))
 			return connect.NewError(connect.CodeUnavailable, err)
 		}
 
-		sendMetering(meter, userID, apiKeyID, ip, userMeta, "sf.substreams.internal.v2/ProcessRange", resp)
+		sendMetering(meter, fuelMeter, userID, apiKeyID, ip, userMeta, "sf.substreams.internal.v2/ProcessRange", resp)
 		return nil
 	}
 }
@@ -410,6 +472,10 @@ func toGRPCError(ctx context.Context, err error) error {
 	if errors.Is(err, exec.ErrWasmDeterministicExec) {
 		return status.Error(codes.InvalidArgument, err.Error())
 	}
+	if _, ok := store.AsCorruptedSnapshotError(err); ok {
+		// not retried by the tier1, which produces the snapshot again first
+		return status.Error(codes.DataLoss, err.Error())
+	}
 
 	var errInvalidArg *stream.ErrInvalidArg
 	if errors.As(err, &errInvalidArg) {
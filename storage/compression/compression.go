@@ -0,0 +1,192 @@
+// Package compression compresses the content of the files written to the cache
+// (store snapshots, partial stores and execution outputs). Compressed content is
+// prefixed with a header recording the codec, so readers detect it automatically
+// and files written without compression stay readable as is.
+package compression
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+type Codec byte
+
+const (
+	None Codec = iota
+	Zstd
+	Snappy
+)
+
+// header is followed by one byte holding the codec, then by the compressed content.
+var header = []byte("sfcz")
+
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+// ParseCodec returns the codec named `name`, an empty name meaning no compression.
+func ParseCodec(name string) (Codec, error) {
+	switch name {
+	case "", "none":
+		return None, nil
+	case "zstd":
+		return Zstd, nil
+	case "snappy":
+		return Snappy, nil
+	}
+	return None, fmt.Errorf("unknown compression codec %q, expected one of 'none', 'zstd' or 'snappy'", name)
+}
+
+func (c Codec) String() string {
+	switch c {
+	case None:
+		return "none"
+	case Zstd:
+		return "zstd"
+	case Snappy:
+		return "snappy"
+	}
+	return fmt.Sprintf("unknown(%d)", byte(c))
+}
+
+// Compress returns `data` compressed with `codec`, prefixed with the header recording
+// it. Data is returned untouched with the `None` codec.
+func Compress(codec Codec, data []byte) ([]byte, error) {
+	var compressed []byte
+	switch codec {
+	case None:
+		return data, nil
+	case Zstd:
+		compressed = zstdEncoder.EncodeAll(data, make([]byte, 0, len(data)/4))
+	case Snappy:
+		compressed = snappy.Encode(nil, data)
+	default:
+		return nil, fmt.Errorf("unknown compression codec %s", codec)
+	}
+
+	out := make([]byte, 0, len(header)+1+len(compressed))
+	out = append(out, header...)
+	out = append(out, byte(codec))
+	return append(out, compressed...), nil
+}
+
+// Detect returns the codec `data` was compressed with, `None` when it has no header.
+func Detect(data []byte) Codec {
+	if len(data) <= len(header) || !bytes.Equal(data[:len(header)], header) {
+		return None
+	}
+	return Codec(data[len(header)])
+}
+
+// Decompress returns the content of `data`, decompressing it with the codec recorded
+// in its header. Data without a header is returned untouched.
+func Decompress(data []byte) ([]byte, error) {
+	codec := Detect(data)
+	if codec == None {
+		return data, nil
+	}
+
+	compressed := data[len(header)+1:]
+	switch codec {
+	case Zstd:
+		out, err := zstdDecoder.DecodeAll(compressed, nil)
+		if err != nil {
+			return nil, fmt.Errorf("zstd: %w", err)
+		}
+		return out, nil
+	case Snappy:
+		out, err := snappy.Decode(nil, compressed)
+		if err != nil {
+			return nil, fmt.Errorf("snappy: %w", err)
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("unknown compression codec %s", codec)
+}
+
+// NewWriter returns a writer compressing what is written to it with `codec` into `w`,
+// in the format of Compress. Close must be called to flush it, it does not close `w`.
+// Snappy has no streaming block format, what is written is buffered and compressed on
+// Close.
+func NewWriter(codec Codec, w io.Writer) (io.WriteCloser, error) {
+	switch codec {
+	case None:
+		return nopWriteCloser{w}, nil
+	case Zstd, Snappy:
+	default:
+		return nil, fmt.Errorf("unknown compression codec %s", codec)
+	}
+
+	if _, err := w.Write(append(append([]byte{}, header...), byte(codec))); err != nil {
+		return nil, err
+	}
+	if codec == Snappy {
+		return &snappyWriter{w: w}, nil
+	}
+	return zstd.NewWriter(w)
+}
+
+// NewReader returns a reader of the content of the data read from `r`, decompressing it
+// with the codec recorded in its header. Data without a header is read untouched. Snappy
+// content, which has no streaming block format, is decompressed in memory.
+func NewReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	prefix, err := br.Peek(len(header) + 1)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	codec := Detect(prefix)
+	if codec == None {
+		return io.NopCloser(br), nil
+	}
+	if _, err := br.Discard(len(prefix)); err != nil {
+		return nil, err
+	}
+
+	switch codec {
+	case Zstd:
+		decoder, err := zstd.NewReader(br, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, fmt.Errorf("zstd: %w", err)
+		}
+		return decoder.IOReadCloser(), nil
+	case Snappy:
+		compressed, err := io.ReadAll(br)
+		if err != nil {
+			return nil, err
+		}
+		out, err := snappy.Decode(nil, compressed)
+		if err != nil {
+			return nil, fmt.Errorf("snappy: %w", err)
+		}
+		return io.NopCloser(bytes.NewReader(out)), nil
+	}
+	return nil, fmt.Errorf("unknown compression codec %s", codec)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+type snappyWriter struct {
+	w   io.Writer
+	buf bytes.Buffer
+}
+
+func (s *snappyWriter) Write(p []byte) (int, error) {
+	return s.buf.Write(p)
+}
+
+func (s *snappyWriter) Close() error {
+	_, err := s.w.Write(snappy.Encode(nil, s.buf.Bytes()))
+	return err
+}
@@ -0,0 +1,81 @@
+package compression
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressDecompress(t *testing.T) {
+	data := bytes.Repeat([]byte("highly compressible protobuf content "), 1000)
+
+	for _, codec := range []Codec{None, Zstd, Snappy} {
+		t.Run(codec.String(), func(t *testing.T) {
+			compressed, err := Compress(codec, data)
+			require.NoError(t, err)
+			assert.Equal(t, codec, Detect(compressed))
+			if codec != None {
+				assert.Less(t, len(compressed), len(data))
+			}
+
+			out, err := Decompress(compressed)
+			require.NoError(t, err)
+			assert.Equal(t, data, out)
+		})
+	}
+}
+
+func TestWriterReader(t *testing.T) {
+	data := bytes.Repeat([]byte("highly compressible protobuf content "), 1000)
+
+	for _, codec := range []Codec{None, Zstd, Snappy} {
+		t.Run(codec.String(), func(t *testing.T) {
+			buf := bytes.NewBuffer(nil)
+			w, err := NewWriter(codec, buf)
+			require.NoError(t, err)
+			_, err = w.Write(data[:100])
+			require.NoError(t, err)
+			_, err = w.Write(data[100:])
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+
+			out, err := Decompress(buf.Bytes())
+			require.NoError(t, err)
+			assert.Equal(t, data, out)
+
+			compressed, err := Compress(codec, data)
+			require.NoError(t, err)
+			r, err := NewReader(bytes.NewReader(compressed))
+			require.NoError(t, err)
+			out, err = io.ReadAll(r)
+			require.NoError(t, err)
+			assert.Equal(t, data, out)
+			require.NoError(t, r.Close())
+		})
+	}
+}
+
+func TestDecompress_Uncompressed(t *testing.T) {
+	for _, data := range [][]byte{nil, []byte("sfcz"), []byte("\x0a\x03key")} {
+		out, err := Decompress(data)
+		require.NoError(t, err)
+		assert.Equal(t, data, out)
+	}
+
+	_, err := Decompress([]byte("sfcz\x09data"))
+	require.Error(t, err)
+}
+
+func TestParseCodec(t *testing.T) {
+	for _, codec := range []Codec{None, Zstd, Snappy} {
+		parsed, err := ParseCodec(codec.String())
+		require.NoError(t, err)
+		assert.Equal(t, codec, parsed)
+	}
+
+	_, err := ParseCodec("lz4")
+	require.Error(t, err)
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/streamingfast/substreams/block"
 	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/streamingfast/substreams/storage/compression"
 	pboutput "github.com/streamingfast/substreams/storage/execout/pb"
 )
 
@@ -22,6 +23,10 @@ type Config struct {
 	modKind            pbsubstreams.ModuleKind
 	moduleInitialBlock uint64
 
+	// compression is the codec used to compress the content of the output files
+	// written for this module. Readers detect the codec from the content itself.
+	compression compression.Codec
+
 	logger *zap.Logger
 }
 
@@ -43,14 +48,20 @@ func NewConfig(name string, moduleInitialBlock uint64, modKind pbsubstreams.Modu
 
 func (c *Config) NewFile(targetRange *block.Range) *File {
 	return &File{
-		kv:         make(map[string]*pboutput.Item),
-		ModuleName: c.name,
-		store:      c.objStore,
-		Range:      targetRange,
-		logger:     c.logger,
+		kv:          make(map[string]*pboutput.Item),
+		ModuleName:  c.name,
+		store:       c.objStore,
+		Range:       targetRange,
+		compression: c.compression,
+		logger:      c.logger,
 	}
 }
 
+// SetCompression sets the codec used to compress the output files written for this module.
+func (c *Config) SetCompression(codec compression.Codec) {
+	c.compression = codec
+}
+
 func (c *Config) Name() string                        { return c.name }
 func (c *Config) ModuleKind() pbsubstreams.ModuleKind { return c.modKind }
 func (c *Config) ModuleInitialBlock() uint64          { return c.moduleInitialBlock }
@@ -11,6 +11,7 @@ import (
 
 	"github.com/streamingfast/substreams/manifest"
 	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/streamingfast/substreams/storage/compression"
 )
 
 type Configs struct {
@@ -43,6 +44,12 @@ func NewConfigs(baseObjectStore dstore.Store, allRequestedModules []*pbsubstream
 	}, nil
 }
 
+func (c *Configs) SetCompression(codec compression.Codec) {
+	for _, conf := range c.ConfigMap {
+		conf.SetCompression(codec)
+	}
+}
+
 func (c *Configs) NewFile(moduleName string, targetRange *block.Range) *File {
 	return c.ConfigMap[moduleName].NewFile(targetRange)
 }
@@ -21,6 +21,8 @@ import (
 
 	"github.com/streamingfast/substreams/block"
 	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/streamingfast/substreams/storage/compression"
+	"github.com/streamingfast/substreams/storage/integrity"
 )
 
 // A File in `execout` stores, for a given module (with a given hash), the outputs of module execution
@@ -29,10 +31,11 @@ type File struct {
 	sync.RWMutex
 	*block.Range
 
-	ModuleName string
-	kv         map[string]*pboutput.Item
-	store      dstore.Store
-	logger     *zap.Logger
+	ModuleName  string
+	kv          map[string]*pboutput.Item
+	store       dstore.Store
+	compression compression.Codec
+	logger      *zap.Logger
 }
 
 func (c *File) Filename() string {
@@ -116,6 +119,16 @@ func (c *File) Load(ctx context.Context) error {
 			return fmt.Errorf("reading store file %s: %w", filename, err)
 		}
 
+		bytes, _, err = integrity.Open(bytes)
+		if err != nil {
+			return derr.NewFatalError(fmt.Errorf("verifying file %s: %w", filename, err))
+		}
+
+		bytes, err = compression.Decompress(bytes)
+		if err != nil {
+			return derr.NewFatalError(fmt.Errorf("decompressing file %s: %w", filename, err))
+		}
+
 		outputData := &pboutput.Map{}
 		if err = outputData.UnmarshalFast(bytes); err != nil {
 			return fmt.Errorf("unmarshalling file %s: %w", filename, err)
@@ -137,6 +150,12 @@ func (c *File) Save(ctx context.Context) error {
 		return fmt.Errorf("unmarshalling file %s: %w", filename, err)
 	}
 
+	cnt, err = compression.Compress(c.compression, cnt)
+	if err != nil {
+		return fmt.Errorf("compressing file %s: %w", filename, err)
+	}
+	cnt = integrity.Seal(cnt)
+
 	c.logger.Info("writing execution output file", zap.String("filename", filename))
 	return derr.RetryContext(ctx, 5, func(ctx context.Context) error {
 		reader := bytes.NewReader(cnt)
@@ -145,6 +164,15 @@ func (c *File) Save(ctx context.Context) error {
 	})
 }
 
+// Delete removes the file from the store, for example after finding it corrupted.
+func (c *File) Delete(ctx context.Context) error {
+	filename := c.Filename()
+	c.logger.Info("deleting execution output file", zap.String("filename", filename))
+	return derr.RetryContext(ctx, 3, func(ctx context.Context) error {
+		return c.store.DeleteObject(ctx, filename)
+	})
+}
+
 func (c *File) String() string {
 	return c.store.ObjectURL("")
 }
@@ -0,0 +1,85 @@
+package index
+
+import (
+	"fmt"
+
+	"github.com/streamingfast/dstore"
+	"go.uber.org/zap"
+
+	"github.com/streamingfast/substreams/block"
+	"github.com/streamingfast/substreams/manifest"
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/streamingfast/substreams/storage/compression"
+)
+
+type Config struct {
+	name       string
+	moduleHash string
+	objStore   dstore.Store
+
+	// compression is the codec used to compress the content of the index files written
+	// for this module. Readers detect the codec from the content itself.
+	compression compression.Codec
+
+	logger *zap.Logger
+}
+
+func NewConfig(name string, moduleHash string, baseStore dstore.Store, logger *zap.Logger) (*Config, error) {
+	subStore, err := baseStore.SubStore(fmt.Sprintf("%s/index", moduleHash))
+	if err != nil {
+		return nil, fmt.Errorf("creating sub store: %w", err)
+	}
+
+	return &Config{
+		name:       name,
+		moduleHash: moduleHash,
+		objStore:   subStore,
+		logger:     logger.With(zap.String("module", name)),
+	}, nil
+}
+
+func (c *Config) NewFile(targetRange *block.Range) *File {
+	return &File{
+		ModuleName:  c.name,
+		Range:       targetRange,
+		bitmaps:     make(map[string][]byte),
+		store:       c.objStore,
+		compression: c.compression,
+		logger:      c.logger,
+	}
+}
+
+// SetCompression sets the codec used to compress the index files written for this module.
+func (c *Config) SetCompression(codec compression.Codec) {
+	c.compression = codec
+}
+
+func (c *Config) Name() string { return c.name }
+
+// Configs holds the index configs of the block index modules of a request.
+type Configs struct {
+	ConfigMap map[string]*Config
+}
+
+// NewConfigs returns the configs of the block index modules amongst `allRequestedModules`.
+func NewConfigs(baseObjectStore dstore.Store, allRequestedModules []*pbsubstreams.Module, moduleHashes *manifest.ModuleHashes, logger *zap.Logger) (*Configs, error) {
+	out := make(map[string]*Config)
+	for _, mod := range allRequestedModules {
+		if mod.GetKindBlockIndex() == nil {
+			continue
+		}
+		conf, err := NewConfig(mod.Name, moduleHashes.Get(mod.Name), baseObjectStore, logger)
+		if err != nil {
+			return nil, fmt.Errorf("new index config for %q: %w", mod.Name, err)
+		}
+		out[mod.Name] = conf
+	}
+
+	return &Configs{ConfigMap: out}, nil
+}
+
+func (c *Configs) SetCompression(codec compression.Codec) {
+	for _, conf := range c.ConfigMap {
+		conf.SetCompression(codec)
+	}
+}
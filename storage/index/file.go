@@ -0,0 +1,175 @@
+package index
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/streamingfast/derr"
+	"github.com/streamingfast/dstore"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/streamingfast/substreams/block"
+	"github.com/streamingfast/substreams/blockfilter"
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/streamingfast/substreams/storage/compression"
+	"github.com/streamingfast/substreams/storage/integrity"
+)
+
+// File is the index of a block index module over a segment: for each key the module
+// emitted, a bitmap of the blocks of the segment it was emitted on, bit `i` standing
+// for block `Range.StartBlock + i`.
+//
+// It is written as a `sf.substreams.v1.StoreEntries`, with the bitmaps as values.
+type File struct {
+	ModuleName string
+	Range      *block.Range
+
+	bitmaps map[string][]byte
+
+	store       dstore.Store
+	compression compression.Codec
+	logger      *zap.Logger
+}
+
+func (f *File) Filename() string {
+	return computeFilename(f.Range.StartBlock, f.Range.ExclusiveEndBlock)
+}
+
+func computeFilename(startBlock, exclusiveEndBlock uint64) string {
+	return fmt.Sprintf("%010d-%010d.index", startBlock, exclusiveEndBlock)
+}
+
+// Add records the keys emitted by the module on `blockNum`, which must be in the range
+// of the file.
+func (f *File) Add(blockNum uint64, keys []string) {
+	if !f.Range.Contains(blockNum) {
+		panic(fmt.Errorf("block %d out of index range %s", blockNum, f.Range))
+	}
+
+	offset := blockNum - f.Range.StartBlock
+	for _, key := range keys {
+		bitmap := f.bitmaps[key]
+		if bitmap == nil {
+			bitmap = make([]byte, (f.Range.Len()+7)/8)
+			f.bitmaps[key] = bitmap
+		}
+		bitmap[offset/8] |= 1 << (offset % 8)
+	}
+}
+
+// Has returns true if the module emitted `key` on `blockNum`.
+func (f *File) Has(key string, blockNum uint64) bool {
+	if !f.Range.Contains(blockNum) {
+		return false
+	}
+	bitmap := f.bitmaps[key]
+	offset := blockNum - f.Range.StartBlock
+	if offset/8 >= uint64(len(bitmap)) {
+		return false
+	}
+	return bitmap[offset/8]&(1<<(offset%8)) != 0
+}
+
+// Matches returns true if the keys emitted on `blockNum` match `query`.
+func (f *File) Matches(query *blockfilter.Query, blockNum uint64) bool {
+	return query.Matches(func(key string) bool { return f.Has(key, blockNum) })
+}
+
+// MatchesAny returns true if the keys of any block of the segment match `query`.
+func (f *File) MatchesAny(query *blockfilter.Query) bool {
+	for blockNum := f.Range.StartBlock; blockNum < f.Range.ExclusiveEndBlock; blockNum++ {
+		if f.Matches(query, blockNum) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *File) Load(ctx context.Context) error {
+	filename := f.Filename()
+	f.logger.Debug("loading index file", zap.String("file_name", filename), zap.Object("block_range", f.Range))
+
+	return derr.RetryContext(ctx, 5, func(ctx context.Context) error {
+		objectReader, err := f.store.OpenObject(ctx, filename)
+		if err == dstore.ErrNotFound {
+			return derr.NewFatalError(err)
+		}
+		if err != nil {
+			return fmt.Errorf("opening index file %s: %w", filename, err)
+		}
+		defer objectReader.Close()
+
+		cnt, err := io.ReadAll(objectReader)
+		if err != nil {
+			return fmt.Errorf("reading index file %s: %w", filename, err)
+		}
+
+		cnt, _, err = integrity.Open(cnt)
+		if err != nil {
+			return derr.NewFatalError(fmt.Errorf("verifying file %s: %w", filename, err))
+		}
+
+		cnt, err = compression.Decompress(cnt)
+		if err != nil {
+			return derr.NewFatalError(fmt.Errorf("decompressing file %s: %w", filename, err))
+		}
+
+		entries := &pbsubstreams.StoreEntries{}
+		if err := proto.Unmarshal(cnt, entries); err != nil {
+			return derr.NewFatalError(fmt.Errorf("unmarshalling file %s: %w", filename, err))
+		}
+
+		f.bitmaps = make(map[string][]byte, len(entries.Entries))
+		for _, entry := range entries.Entries {
+			f.bitmaps[entry.Key] = entry.Value
+		}
+		f.logger.Debug("index loaded", zap.Int("key_count", len(f.bitmaps)), zap.Stringer("block_range", f.Range))
+		return nil
+	})
+}
+
+func (f *File) Save(ctx context.Context) error {
+	filename := f.Filename()
+
+	keys := make([]string, 0, len(f.bitmaps))
+	for key := range f.bitmaps {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	entries := &pbsubstreams.StoreEntries{Entries: make([]*pbsubstreams.StoreEntry, len(keys))}
+	for i, key := range keys {
+		entries.Entries[i] = &pbsubstreams.StoreEntry{Key: key, Value: f.bitmaps[key]}
+	}
+	cnt, err := proto.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("marshalling file %s: %w", filename, err)
+	}
+
+	cnt, err = compression.Compress(f.compression, cnt)
+	if err != nil {
+		return fmt.Errorf("compressing file %s: %w", filename, err)
+	}
+	cnt = integrity.Seal(cnt)
+
+	f.logger.Info("writing index file", zap.String("filename", filename))
+	return derr.RetryContext(ctx, 5, func(ctx context.Context) error {
+		return f.store.WriteObject(ctx, filename, bytes.NewReader(cnt))
+	})
+}
+
+func (f *File) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	if f == nil {
+		return nil
+	}
+	enc.AddString("module", f.ModuleName)
+	enc.AddUint64("start_block", f.Range.StartBlock)
+	enc.AddUint64("end_block", f.Range.ExclusiveEndBlock)
+	enc.AddInt("key_count", len(f.bitmaps))
+	return nil
+}
@@ -0,0 +1,63 @@
+package index
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/streamingfast/dstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/streamingfast/substreams/block"
+	"github.com/streamingfast/substreams/blockfilter"
+)
+
+func TestFile_Save_Load(t *testing.T) {
+	ctx := context.Background()
+	baseStore := dstore.NewMockStore(nil)
+	conf, err := NewConfig("index_events", "abc", baseStore, zap.NewNop())
+	require.NoError(t, err)
+
+	file := conf.NewFile(block.NewRange(100, 120))
+	assert.Equal(t, "0000000100-0000000120.index", file.Filename())
+
+	file.Add(100, []string{"a"})
+	file.Add(109, []string{"a", "b"})
+	file.Add(119, []string{"b"})
+	require.NoError(t, file.Save(ctx))
+
+	loaded := conf.NewFile(block.NewRange(100, 120))
+	require.NoError(t, loaded.Load(ctx))
+
+	assert.True(t, loaded.Has("a", 100))
+	assert.True(t, loaded.Has("a", 109))
+	assert.False(t, loaded.Has("a", 119))
+	assert.True(t, loaded.Has("b", 119))
+	assert.False(t, loaded.Has("b", 101))
+	assert.False(t, loaded.Has("c", 100))
+	assert.False(t, loaded.Has("a", 120))
+
+	query, err := blockfilter.ParseQuery("a b")
+	require.NoError(t, err)
+	assert.True(t, loaded.Matches(query, 109))
+	assert.False(t, loaded.Matches(query, 100))
+	assert.True(t, loaded.MatchesAny(query))
+
+	query, err = blockfilter.ParseQuery("c")
+	require.NoError(t, err)
+	assert.False(t, loaded.MatchesAny(query))
+}
+
+func TestFile_Load_NotFound(t *testing.T) {
+	baseStore := dstore.NewMockStore(nil)
+	baseStore.OpenObjectFunc = func(ctx context.Context, name string) (io.ReadCloser, error) {
+		return nil, dstore.ErrNotFound
+	}
+	conf, err := NewConfig("index_events", "abc", baseStore, zap.NewNop())
+	require.NoError(t, err)
+
+	file := conf.NewFile(block.NewRange(100, 120))
+	assert.ErrorIs(t, file.Load(context.Background()), dstore.ErrNotFound)
+}
@@ -0,0 +1,179 @@
+// Package integrity protects the files written to the cache (store snapshots, partial
+// stores and execution outputs) against truncation and corruption. Sealed content is
+// prefixed with a header holding a checksum of the content and the version of the
+// engine that wrote it, verified when the file is opened. Files written before the
+// header was introduced are opened as is, without verification.
+package integrity
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"runtime/debug"
+)
+
+// ErrCorrupted is returned, wrapped, when sealed content does not match its header.
+var ErrCorrupted = errors.New("corrupted content")
+
+// The header is laid out as:
+//
+//	magic (4 bytes) | format version (1 byte) | CRC-32C of the content (uint32 LE)
+//	| content length (uint64 LE) | engine version length (1 byte) | engine version
+var magic = []byte("sfck")
+
+const (
+	formatVersion   = 1
+	fixedHeaderSize = 4 + 1 + 4 + 8 + 1
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// EngineVersion is the version recorded in the header of the sealed content. It defaults
+// to the version of the substreams module found in the build information of the binary.
+var EngineVersion = defaultEngineVersion()
+
+// Header describes sealed content.
+type Header struct {
+	Checksum      uint32
+	Length        uint64
+	EngineVersion string
+}
+
+// IsSealed returns true if `data` starts with an integrity header.
+func IsSealed(data []byte) bool {
+	return len(data) >= len(magic) && bytes.Equal(data[:len(magic)], magic)
+}
+
+// Seal returns `data` prefixed with its integrity header.
+func Seal(data []byte) []byte {
+	header := sealHeader(crc32.Checksum(data, crcTable), uint64(len(data)))
+	out := make([]byte, len(header), len(header)+len(data))
+	copy(out, header)
+	return append(out, data...)
+}
+
+// Sealer computes the integrity header of the content written to it, for content
+// streamed to its destination instead of being sealed in memory with Seal.
+type Sealer struct {
+	checksum uint32
+	length   uint64
+}
+
+func (s *Sealer) Write(p []byte) (int, error) {
+	s.checksum = crc32.Update(s.checksum, crcTable, p)
+	s.length += uint64(len(p))
+	return len(p), nil
+}
+
+// Header returns the integrity header to write before the content written so far.
+func (s *Sealer) Header() []byte {
+	return sealHeader(s.checksum, s.length)
+}
+
+func sealHeader(checksum uint32, length uint64) []byte {
+	version := EngineVersion
+	if len(version) > 255 {
+		version = version[:255]
+	}
+	out := make([]byte, fixedHeaderSize, fixedHeaderSize+len(version))
+	copy(out, magic)
+	out[4] = formatVersion
+	binary.LittleEndian.PutUint32(out[5:], checksum)
+	binary.LittleEndian.PutUint64(out[9:], length)
+	out[17] = byte(len(version))
+	return append(out, version...)
+}
+
+// Open verifies sealed `data` and returns its content along with its header. Data without
+// an integrity header is returned untouched with a nil header. Errors wrap `ErrCorrupted`.
+func Open(data []byte) ([]byte, *Header, error) {
+	if !IsSealed(data) {
+		return data, nil, nil
+	}
+	header, headerSize, err := readHeader(data)
+	if err != nil {
+		return nil, header, err
+	}
+	content := data[headerSize:]
+	if uint64(len(content)) != header.Length {
+		return nil, header, fmt.Errorf("%w: expected %d bytes of content, got %d", ErrCorrupted, header.Length, len(content))
+	}
+	if checksum := crc32.Checksum(content, crcTable); checksum != header.Checksum {
+		return nil, header, fmt.Errorf("%w: checksum mismatch, expected %08x, got %08x", ErrCorrupted, header.Checksum, checksum)
+	}
+	return content, header, nil
+}
+
+// OpenReaderAt is Open for sealed data of `size` bytes read from `r`, streaming over it
+// to verify its checksum instead of holding it in memory. The returned section reads
+// the content from `r`.
+func OpenReaderAt(r io.ReaderAt, size int64) (*io.SectionReader, *Header, error) {
+	prefix := make([]byte, min(size, fixedHeaderSize+255))
+	if _, err := r.ReadAt(prefix, 0); err != nil && !errors.Is(err, io.EOF) {
+		return nil, nil, fmt.Errorf("reading header: %w", err)
+	}
+	if !IsSealed(prefix) {
+		return io.NewSectionReader(r, 0, size), nil, nil
+	}
+	header, headerSize, err := readHeader(prefix)
+	if err != nil {
+		return nil, header, err
+	}
+
+	content := io.NewSectionReader(r, int64(headerSize), size-int64(headerSize))
+	if uint64(content.Size()) != header.Length {
+		return nil, header, fmt.Errorf("%w: expected %d bytes of content, got %d", ErrCorrupted, header.Length, content.Size())
+	}
+	hash := crc32.New(crcTable)
+	if _, err := io.Copy(hash, content); err != nil {
+		return nil, header, fmt.Errorf("reading content: %w", err)
+	}
+	if checksum := hash.Sum32(); checksum != header.Checksum {
+		return nil, header, fmt.Errorf("%w: checksum mismatch, expected %08x, got %08x", ErrCorrupted, header.Checksum, checksum)
+	}
+	return io.NewSectionReader(r, int64(headerSize), content.Size()), header, nil
+}
+
+// readHeader parses the integrity header at the start of sealed `data`, returning it
+// along with its size.
+func readHeader(data []byte) (*Header, int, error) {
+	if len(data) < fixedHeaderSize {
+		return nil, 0, fmt.Errorf("%w: truncated header", ErrCorrupted)
+	}
+	if data[4] != formatVersion {
+		return nil, 0, fmt.Errorf("%w: unsupported format version %d", ErrCorrupted, data[4])
+	}
+	header := &Header{
+		Checksum: binary.LittleEndian.Uint32(data[5:]),
+		Length:   binary.LittleEndian.Uint64(data[9:]),
+	}
+	versionEnd := fixedHeaderSize + int(data[17])
+	if len(data) < versionEnd {
+		return nil, 0, fmt.Errorf("%w: truncated header", ErrCorrupted)
+	}
+	header.EngineVersion = string(data[fixedHeaderSize:versionEnd])
+	return header, versionEnd, nil
+}
+
+func defaultEngineVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	if info.Main.Path == "github.com/streamingfast/substreams" {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path != "github.com/streamingfast/substreams" {
+			continue
+		}
+		if dep.Replace != nil && dep.Replace.Version != "" {
+			return dep.Replace.Version
+		}
+		return dep.Version
+	}
+	return "unknown"
+}
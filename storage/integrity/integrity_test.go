@@ -0,0 +1,86 @@
+package integrity
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealOpen(t *testing.T) {
+	defer func(version string) { EngineVersion = version }(EngineVersion)
+	EngineVersion = "v1.2.3"
+
+	data := []byte("\x0a\x03key\x12\x05value")
+	sealed := Seal(data)
+	require.True(t, IsSealed(sealed))
+
+	out, header, err := Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, data, out)
+	assert.Equal(t, "v1.2.3", header.EngineVersion)
+	assert.Equal(t, uint64(len(data)), header.Length)
+}
+
+func TestOpen_Unsealed(t *testing.T) {
+	for _, data := range [][]byte{nil, []byte("sfc"), []byte("\x0a\x03key")} {
+		out, header, err := Open(data)
+		require.NoError(t, err)
+		assert.Nil(t, header)
+		assert.Equal(t, data, out)
+	}
+}
+
+func TestOpen_Corrupted(t *testing.T) {
+	sealed := Seal([]byte("some content"))
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"truncated header", sealed[:10]},
+		{"truncated content", sealed[:len(sealed)-1]},
+		{"flipped bit", append(append([]byte{}, sealed[:len(sealed)-1]...), sealed[len(sealed)-1]^0x01)},
+		{"unknown format", append([]byte("sfck\x09"), sealed[5:]...)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := Open(test.data)
+			assert.ErrorIs(t, err, ErrCorrupted)
+		})
+	}
+}
+
+func TestSealer(t *testing.T) {
+	data := []byte("\x0a\x03key\x12\x05value")
+
+	sealer := &Sealer{}
+	_, _ = sealer.Write(data[:4])
+	_, _ = sealer.Write(data[4:])
+	assert.Equal(t, Seal(data), append(sealer.Header(), data...))
+}
+
+func TestOpenReaderAt(t *testing.T) {
+	data := []byte("\x0a\x03key\x12\x05value")
+
+	for _, in := range [][]byte{data, Seal(data)} {
+		content, _, err := OpenReaderAt(bytes.NewReader(in), int64(len(in)))
+		require.NoError(t, err)
+		out, err := io.ReadAll(content)
+		require.NoError(t, err)
+		assert.Equal(t, data, out)
+	}
+
+	sealed := Seal(data)
+	for _, corrupted := range [][]byte{
+		sealed[:10],
+		sealed[:len(sealed)-1],
+		append(append([]byte{}, sealed[:len(sealed)-1]...), sealed[len(sealed)-1]^0x01),
+	} {
+		_, _, err := OpenReaderAt(bytes.NewReader(corrupted), int64(len(corrupted)))
+		assert.ErrorIs(t, err, ErrCorrupted)
+	}
+}
@@ -14,11 +14,17 @@ type baseStore struct {
 	*Config
 
 	kv             map[string][]byte          // kv is the state, and assumes all deltas were already applied to it.
+	lazy           *marshaller.SortedReader   // lazy is the loaded sorted snapshot, kv then only holds the keys modified since (see kv.go)
+	tombstones     map[string]bool            // tombstones are the keys deleted from lazy
 	deltas         []*pbssinternal.StoreDelta // deltas are always deltas for the given block.
 	lastOrdinal    uint64
 	marshaller     marshaller.Marshaller
 	totalSizeBytes uint64
 
+	lazyKeyCountDelta int64
+	lazyErr           error             // lazyErr is the first error reading lazy, see Err
+	lastWrittenBlocks map[string]uint64 // lastWrittenBlocks is the block at which each key was last written, only tracked for stores with a ttl
+
 	logger *zap.Logger
 }
 
@@ -34,7 +40,7 @@ func (b *baseStore) MarshalLogObject(enc zapcore.ObjectEncoder) error {
 	enc.AddString("name", b.name)
 	enc.AddString("hash", b.moduleHash)
 	enc.AddUint64("module_initial_block", b.moduleInitialBlock)
-	enc.AddInt("key_count", len(b.kv))
+	enc.AddInt("key_count", b.keyCount())
 	enc.AddUint64("total_size_bytes", b.totalSizeBytes)
 
 	return nil
@@ -42,7 +48,7 @@ func (b *baseStore) MarshalLogObject(enc zapcore.ObjectEncoder) error {
 
 func (b *baseStore) Reset() {
 	if tracer.Enabled() {
-		b.logger.Debug("flushing store", zap.Int("delta_count", len(b.deltas)), zap.Int("entry_count", len(b.kv)), zap.Uint64("total_size_bytes", b.totalSizeBytes))
+		b.logger.Debug("flushing store", zap.Int("delta_count", len(b.deltas)), zap.Int("entry_count", b.keyCount()), zap.Uint64("total_size_bytes", b.totalSizeBytes))
 	}
 	b.deltas = nil
 	b.lastOrdinal = 0
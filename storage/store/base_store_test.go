@@ -91,3 +91,84 @@ func TestFileName(t *testing.T) {
 func fullStateFilePrefix(blockNum uint64) string {
 	return fmt.Sprintf("%010d", blockNum)
 }
+
+func TestStoreDeleteRange(t *testing.T) {
+	s := newTestBaseStore(t, pbsubstreams.Module_KindStore_UPDATE_POLICY_UNSET, "", nil)
+
+	s.Set(0, "a", "1")
+	s.Set(0, "b:1", "2")
+	s.Set(0, "b:2", "3")
+	s.Set(0, "c", "4")
+	s.Reset()
+
+	s.DeleteRange(1, "b", "c")
+	assert.False(t, s.HasLast("b:1"))
+	assert.False(t, s.HasLast("b:2"))
+	assert.True(t, s.HasLast("a"))
+	assert.True(t, s.HasLast("c"))
+	assert.True(t, s.HasAt(0, "b:1"))
+	require.Len(t, s.GetDeltas(), 2)
+	assert.Equal(t, "b:1", s.GetDeltas()[0].Key)
+	assert.Equal(t, "b:2", s.GetDeltas()[1].Key)
+
+	s.DeleteRange(2, "b", "")
+	assert.False(t, s.HasLast("c"))
+	assert.True(t, s.HasLast("a"))
+
+	s.ApplyDeltasReverse(s.GetDeltas())
+	assert.Len(t, s.kv, 4)
+}
+
+func TestStoreDeleteRangePointers(t *testing.T) {
+	s := newTestBaseStore(t, pbsubstreams.Module_KindStore_UPDATE_POLICY_UNSET, "", nil)
+
+	s.Set(0, "idx:1", "v:1,v:2")
+	s.Set(0, "idx:2", "v:3,v:missing")
+	s.Set(0, "v:1", "one")
+	s.Set(0, "v:2", "two")
+	s.Set(0, "v:3", "three")
+	s.Set(0, "v:4", "four")
+	s.Reset()
+
+	s.DeleteRangePointers(1, "idx:", "idx;", ",")
+	assert.Equal(t, map[string][]byte{"v:4": []byte("four")}, s.kv)
+	require.Len(t, s.GetDeltas(), 5)
+
+	s.ApplyDeltasReverse(s.GetDeltas())
+	assert.Len(t, s.kv, 6)
+	assert.Equal(t, "v:1,v:2", string(s.kv["idx:1"]))
+}
+
+func TestStoreScan(t *testing.T) {
+	s := newTestBaseStore(t, pbsubstreams.Module_KindStore_UPDATE_POLICY_UNSET, "", nil)
+
+	s.Set(0, "b:2", "v2")
+	s.Set(1, "a", "v0")
+	s.Set(2, "b:1", "v1")
+	s.Set(3, "b:3", "v3")
+	s.Set(4, "c", "v4")
+
+	collect := func(scan func(f func(key string, value []byte) error) error) (out []string) {
+		require.NoError(t, scan(func(key string, value []byte) error {
+			out = append(out, key+"="+string(value))
+			return nil
+		}))
+		return
+	}
+
+	assert.Equal(t, []string{"b:1=v1", "b:2=v2", "b:3=v3"}, collect(func(f func(string, []byte) error) error {
+		return s.ScanPrefix("b:", 0, f)
+	}))
+	assert.Equal(t, []string{"b:1=v1", "b:2=v2"}, collect(func(f func(string, []byte) error) error {
+		return s.ScanPrefix("b:", 2, f)
+	}))
+	assert.Equal(t, []string{"a=v0", "b:1=v1", "b:2=v2"}, collect(func(f func(string, []byte) error) error {
+		return s.ScanRange("a", "b:3", 0, f)
+	}))
+	assert.Equal(t, []string{"b:3=v3", "c=v4"}, collect(func(f func(string, []byte) error) error {
+		return s.ScanRange("b:3", "", 0, f)
+	}))
+	assert.Nil(t, collect(func(f func(string, []byte) error) error {
+		return s.ScanRange("d", "", 0, f)
+	}))
+}
@@ -1,18 +1,33 @@
 package store
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"os"
 
 	"github.com/streamingfast/dmetering"
 
 	"github.com/streamingfast/derr"
 	"github.com/streamingfast/dstore"
+
+	"github.com/streamingfast/substreams/storage/compression"
+	"github.com/streamingfast/substreams/storage/integrity"
+	"github.com/streamingfast/substreams/storage/store/marshaller"
 )
 
 func saveStore(ctx context.Context, store dstore.Store, filename string, content []byte) (err error) {
+	return saveStoreFrom(ctx, store, filename, func() (io.Reader, error) {
+		return bytes.NewReader(content), nil
+	})
+}
+
+// saveStoreFrom is saveStore for content streamed from the reader returned by
+// `open`, called again on each attempt.
+func saveStoreFrom(ctx context.Context, store dstore.Store, filename string, open func() (io.Reader, error)) (err error) {
 	if cloned, ok := store.(dstore.Clonable); ok {
 		store, err = cloned.Clone(ctx)
 		if err != nil {
@@ -22,7 +37,11 @@ func saveStore(ctx context.Context, store dstore.Store, filename string, content
 	}
 
 	return derr.RetryContext(ctx, 5, func(ctx context.Context) error {
-		return store.WriteObject(ctx, filename, bytes.NewReader(content))
+		content, err := open()
+		if err != nil {
+			return fmt.Errorf("opening content: %w", err)
+		}
+		return store.WriteObject(ctx, filename, content)
 	})
 }
 
@@ -47,8 +66,135 @@ func loadStore(ctx context.Context, store dstore.Store, filename string) (out []
 			return fmt.Errorf("reading data: %w", err)
 		}
 
-		out = data
+		content, _, err := integrity.Open(data)
+		if err != nil {
+			return derr.NewFatalError(fmt.Errorf("verifying data: %w", err))
+		}
+
+		out, err = compression.Decompress(content)
+		if err != nil {
+			return derr.NewFatalError(fmt.Errorf("decompressing data: %w", err))
+		}
 		return nil
 	})
 	return out, err
 }
+
+// spilledContent is content spilled to a temporary file instead of being held in
+// memory. The file is unlinked on creation, its space is released when it is closed.
+type spilledContent struct {
+	*io.SectionReader
+	file *os.File
+}
+
+func (s *spilledContent) Close() error {
+	return s.file.Close()
+}
+
+// headerProbeSize is enough bytes to detect both the compression and the sorted
+// format headers.
+const headerProbeSize = 8
+
+func newTempFile() (*os.File, error) {
+	file, err := os.CreateTemp("", "substreams-store-*")
+	if err != nil {
+		return nil, fmt.Errorf("creating temporary file: %w", err)
+	}
+	if err := os.Remove(file.Name()); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("unlinking temporary file: %w", err)
+	}
+	return file, nil
+}
+
+// loadFullStore reads the store file like loadStore, without holding content in the
+// sorted format in memory: it is spilled to a temporary file and returned as `sorted`,
+// to be read lazily. `data` is only set for the other formats, always decoded in memory.
+func loadFullStore(ctx context.Context, store dstore.Store, filename string) (data []byte, sorted *spilledContent, err error) {
+	if cloned, ok := store.(dstore.Clonable); ok {
+		store, err = cloned.Clone(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cloning store: %w", err)
+		}
+		store.SetMeter(dmetering.GetBytesMeter(ctx))
+	}
+
+	err = derr.RetryContext(ctx, 5, func(ctx context.Context) error {
+		file, size, err := spillObject(ctx, store, filename)
+		if err != nil {
+			return err
+		}
+
+		data, sorted, err = openFullStore(file, size)
+		if sorted == nil || sorted.file != file {
+			file.Close()
+		}
+		return err
+	})
+	return data, sorted, err
+}
+
+func spillObject(ctx context.Context, store dstore.Store, filename string) (*os.File, int64, error) {
+	r, err := store.OpenObject(ctx, filename)
+	if err != nil {
+		return nil, 0, fmt.Errorf("opening file: %w", err)
+	}
+	defer r.Close()
+
+	file, err := newTempFile()
+	if err != nil {
+		return nil, 0, err
+	}
+	size, err := io.Copy(file, r)
+	if err != nil {
+		file.Close()
+		return nil, 0, fmt.Errorf("reading data: %w", err)
+	}
+	return file, size, nil
+}
+
+// openFullStore verifies and decodes the store file spilled to `file`. Uncompressed
+// sorted content is read in place, compressed sorted content is decompressed to
+// another temporary file.
+func openFullStore(file *os.File, size int64) ([]byte, *spilledContent, error) {
+	content, _, err := integrity.OpenReaderAt(file, size)
+	if errors.Is(err, integrity.ErrCorrupted) {
+		return nil, nil, derr.NewFatalError(fmt.Errorf("verifying data: %w", err))
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("verifying data: %w", err)
+	}
+
+	r, err := compression.NewReader(content)
+	if err != nil {
+		return nil, nil, derr.NewFatalError(fmt.Errorf("decompressing data: %w", err))
+	}
+	defer r.Close()
+
+	br := bufio.NewReader(r)
+	prefix, _ := br.Peek(headerProbeSize)
+	if !marshaller.HasSortedHeader(prefix) {
+		data, err := io.ReadAll(br)
+		if err != nil {
+			return nil, nil, derr.NewFatalError(fmt.Errorf("decompressing data: %w", err))
+		}
+		return data, nil, nil
+	}
+
+	prefix = make([]byte, headerProbeSize)
+	n, _ := content.ReadAt(prefix, 0)
+	if compression.Detect(prefix[:n]) == compression.None {
+		return nil, &spilledContent{SectionReader: content, file: file}, nil
+	}
+
+	decompressed, err := newTempFile()
+	if err != nil {
+		return nil, nil, err
+	}
+	decompressedSize, err := io.Copy(decompressed, br)
+	if err != nil {
+		decompressed.Close()
+		return nil, nil, derr.NewFatalError(fmt.Errorf("decompressing data: %w", err))
+	}
+	return nil, &spilledContent{SectionReader: io.NewSectionReader(decompressed, 0, decompressedSize), file: decompressed}, nil
+}
@@ -10,6 +10,7 @@ import (
 	"go.uber.org/zap"
 
 	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/streamingfast/substreams/storage/compression"
 	"github.com/streamingfast/substreams/storage/store/marshaller"
 )
 
@@ -26,6 +27,18 @@ type Config struct {
 	totalSizeLimit uint64
 	itemSizeLimit  uint64
 
+	// ttlBlocks, when non-zero, is the number of blocks after which keys that
+	// were not written are evicted from full stores, at store boundaries.
+	ttlBlocks uint64
+
+	// sortedSnapshots makes full stores save their snapshots in the sorted
+	// format, which is loaded lazily instead of being fully decoded in memory.
+	sortedSnapshots bool
+
+	// compression is the codec used to compress the content of the snapshots
+	// written by the stores. Readers detect the codec from the content itself.
+	compression compression.Codec
+
 	// traceID uniquely identifies the connection ID so that store can be
 	// written to unique filename preventing some races when multiple Substreams
 	// request works on the same range.
@@ -89,6 +102,22 @@ func (c *Config) ModuleInitialBlock() uint64 {
 	return c.moduleInitialBlock
 }
 
+func (c *Config) TTLBlocks() uint64 {
+	return c.ttlBlocks
+}
+
+// SetSortedSnapshots controls whether full stores save their snapshots in the
+// sorted format. Snapshots in that format are always loaded lazily, whatever the
+// value of this setting.
+func (c *Config) SetSortedSnapshots(enabled bool) {
+	c.sortedSnapshots = enabled
+}
+
+// SetCompression sets the codec used to compress the snapshots written by the stores.
+func (c *Config) SetCompression(codec compression.Codec) {
+	c.compression = codec
+}
+
 func (c *Config) NewFullKV(logger *zap.Logger) *FullKV {
 	return &FullKV{c.newBaseStore(logger), "N/A"}
 }
@@ -98,6 +127,7 @@ func (c *Config) NewPartialKV(initialBlock uint64, logger *zap.Logger) *PartialK
 		baseStore:    c.newBaseStore(logger),
 		initialBlock: initialBlock,
 		seen:         make(map[string]bool),
+		seenRanges:   make(map[deletedRangeKey]bool),
 	}
 }
 
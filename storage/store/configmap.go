@@ -6,6 +6,7 @@ import (
 	"github.com/streamingfast/dstore"
 	"github.com/streamingfast/substreams/manifest"
 	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/streamingfast/substreams/storage/compression"
 )
 
 type ConfigMap map[string]*Config
@@ -25,7 +26,20 @@ func NewConfigMap(baseObjectStore dstore.Store, storeModules []*pbsubstreams.Mod
 		if err != nil {
 			return nil, fmt.Errorf("new store config for %q: %w", storeModule.Name, err)
 		}
+		c.ttlBlocks = storeModule.GetKindStore().TtlBlocks
 		out[storeModule.Name] = c
 	}
 	return out, nil
 }
+
+func (m ConfigMap) SetSortedSnapshots(enabled bool) {
+	for _, c := range m {
+		c.SetSortedSnapshots(enabled)
+	}
+}
+
+func (m ConfigMap) SetCompression(codec compression.Codec) {
+	for _, c := range m {
+		c.SetCompression(codec)
+	}
+}
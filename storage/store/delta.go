@@ -21,7 +21,7 @@ func (b *baseStore) ApplyDelta(delta *pbssinternal.StoreDelta) {
 	keySize := uint64(len(delta.Key))
 	switch delta.Operation {
 	case pbssinternal.StoreDelta_UPDATE:
-		b.kv[delta.Key] = delta.NewValue
+		b.putKV(delta.Key, delta.NewValue)
 		switch {
 		case newSize > oldSize:
 			b.totalSizeBytes += (newSize - oldSize)
@@ -30,12 +30,12 @@ func (b *baseStore) ApplyDelta(delta *pbssinternal.StoreDelta) {
 		}
 
 	case pbssinternal.StoreDelta_CREATE:
-		b.kv[delta.Key] = delta.NewValue
+		b.putKV(delta.Key, delta.NewValue)
 		b.totalSizeBytes += newSize
 		b.totalSizeBytes += keySize
 
 	case pbssinternal.StoreDelta_DELETE:
-		delete(b.kv, delta.Key)
+		b.deleteKV(delta.Key)
 		b.totalSizeBytes -= oldSize
 		b.totalSizeBytes -= keySize
 		return
@@ -61,7 +61,7 @@ func (b *baseStore) ApplyDeltasReverse(deltas []*pbssinternal.StoreDelta) {
 		keySize := uint64(len(delta.Key))
 		switch delta.Operation {
 		case pbssinternal.StoreDelta_UPDATE:
-			b.kv[delta.Key] = delta.OldValue
+			b.putKV(delta.Key, delta.OldValue)
 			switch {
 			case newSize > oldSize:
 				b.totalSizeBytes -= (newSize - oldSize)
@@ -70,15 +70,14 @@ func (b *baseStore) ApplyDeltasReverse(deltas []*pbssinternal.StoreDelta) {
 			}
 
 		case pbssinternal.StoreDelta_CREATE:
-			delete(b.kv, delta.Key)
+			b.deleteKV(delta.Key)
 			b.totalSizeBytes -= newSize
 			b.totalSizeBytes -= keySize
 
 		case pbssinternal.StoreDelta_DELETE:
-			b.kv[delta.Key] = delta.OldValue
+			b.putKV(delta.Key, delta.OldValue)
 			b.totalSizeBytes += oldSize
 			b.totalSizeBytes += keySize
-			return
 		}
 	}
 }
@@ -2,7 +2,11 @@ package store
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"regexp"
+	"strconv"
 
 	"go.uber.org/zap"
 
@@ -32,6 +36,7 @@ func (s *FullKV) DerivePartialStore(initialBlock uint64) *PartialKV {
 		baseStore:    b,
 		initialBlock: initialBlock,
 		seen:         make(map[string]bool),
+		seenRanges:   make(map[deletedRangeKey]bool),
 	}
 }
 
@@ -39,17 +44,43 @@ func (s *FullKV) Load(ctx context.Context, file *FileInfo) error {
 	s.loadedFrom = file.Filename
 	s.logger.Debug("loading full store state from file", zap.String("fileName", file.Filename))
 
-	data, err := loadStore(ctx, s.objStore, file.Filename)
+	data, sorted, err := loadFullStore(ctx, s.objStore, file.Filename)
 	if err != nil {
 		return fmt.Errorf("load full store %s at %s: %w", s.name, file.Filename, err)
 	}
 
+	if s.lazy != nil {
+		s.lazy.Close()
+	}
+	s.lazy = nil
+	s.tombstones = nil
+	s.lazyKeyCountDelta = 0
+	s.lazyErr = nil
+	s.lastWrittenBlocks = nil
+
+	if sorted != nil {
+		lazy, err := marshaller.NewSortedReader(sorted, sorted.Size())
+		if err != nil {
+			sorted.Close()
+			return fmt.Errorf("reading sorted store: %w", err)
+		}
+
+		s.lazy = lazy
+		s.tombstones = make(map[string]bool)
+		s.kv = make(map[string][]byte)
+		s.totalSizeBytes = lazy.DataSize()
+
+		s.logger.Debug("full store loaded lazily", zap.String("fileName", file.Filename), zap.Uint64("key_count", lazy.Length()), zap.Uint64("data_size", lazy.DataSize()))
+		return nil
+	}
+
 	storeData, size, err := s.marshaller.Unmarshal(data)
 	if err != nil {
 		return fmt.Errorf("unmarshal store: %w", err)
 	}
 
 	s.kv = storeData.Kv
+	s.lastWrittenBlocks = storeData.LastWrittenBlocks
 	s.totalSizeBytes = size
 	if s.kv == nil {
 		s.kv = make(map[string][]byte)
@@ -59,19 +90,55 @@ func (s *FullKV) Load(ctx context.Context, file *FileInfo) error {
 	return nil
 }
 
+var CorruptedSnapshotRegexp = regexp.MustCompile(`store snapshot of module "([^"]+)" ending at block ([0-9]+) is corrupted`)
+
+// CorruptedSnapshotError is returned when a full store snapshot failed its integrity
+// verification. The snapshot was deleted, the segment ending at `ExclusiveEndBlock`
+// needs to be processed again to produce it.
+type CorruptedSnapshotError struct {
+	ModuleName        string
+	ExclusiveEndBlock uint64
+	Err               error
+}
+
+func (e *CorruptedSnapshotError) Error() string {
+	return fmt.Sprintf("store snapshot of module %q ending at block %d is corrupted, deleted to be produced again: %s", e.ModuleName, e.ExclusiveEndBlock, e.Err)
+}
+
+func (e *CorruptedSnapshotError) Unwrap() error {
+	return e.Err
+}
+
+// AsCorruptedSnapshotError returns the CorruptedSnapshotError in the chain of `err`,
+// or parses it back from the message of an error received from a remote worker.
+func AsCorruptedSnapshotError(err error) (*CorruptedSnapshotError, bool) {
+	if err == nil {
+		return nil, false
+	}
+	var out *CorruptedSnapshotError
+	if errors.As(err, &out) {
+		return out, true
+	}
+
+	matches := CorruptedSnapshotRegexp.FindStringSubmatch(err.Error())
+	if matches == nil {
+		return nil, false
+	}
+	exclusiveEndBlock, parseErr := strconv.ParseUint(matches[2], 10, 64)
+	if parseErr != nil {
+		return nil, false
+	}
+	return &CorruptedSnapshotError{ModuleName: matches[1], ExclusiveEndBlock: exclusiveEndBlock, Err: err}, true
+}
+
 // Save is to be called ONLY when we just passed the
 // `nextExpectedBoundary` and processed nothing more after that
 // boundary.
 func (s *FullKV) Save(endBoundaryBlock uint64) (*FileInfo, *fileWriter, error) {
 	s.logger.Debug("writing full store state", zap.Object("store", s))
 
-	stateData := &marshaller.StoreData{
-		Kv: s.kv,
-	}
-
-	content, err := s.marshaller.Marshal(stateData)
-	if err != nil {
-		return nil, nil, fmt.Errorf("marshal kv state: %w", err)
+	if err := s.Err(); err != nil {
+		return nil, nil, fmt.Errorf("store state is incomplete: %w", err)
 	}
 
 	file := NewCompleteFileInfo(s.name, s.moduleInitialBlock, endBoundaryBlock)
@@ -81,23 +148,60 @@ func (s *FullKV) Save(endBoundaryBlock uint64) (*FileInfo, *fileWriter, error) {
 		zap.Object("block_range", file.Range),
 	)
 
+	// The sorted format does not hold the last written blocks, stores with a ttl
+	// are therefore never written in it. It is streamed to a temporary file, as
+	// it is used for stores too large to be held in memory.
+	if s.lazy != nil || (s.sortedSnapshots && s.ttlBlocks == 0) {
+		fw, err := newSpilledFileWriter(s.objStore, file.Filename, s.compression, func(w io.Writer) error {
+			sw := marshaller.NewSortedWriter(w)
+			if err := s.iterSortedKV("", nil, sw.Add); err != nil {
+				return err
+			}
+			return sw.Close()
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("write sorted kv state: %w", err)
+		}
+		return file, fw, nil
+	}
+
+	stateData := &marshaller.StoreData{
+		Kv:                s.kv,
+		LastWrittenBlocks: s.lastWrittenBlocks,
+	}
+	content, err := s.marshaller.Marshal(stateData)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal kv state: %w", err)
+	}
+
 	fw := &fileWriter{
-		store:    s.objStore,
-		filename: file.Filename,
-		content:  content,
+		store:       s.objStore,
+		filename:    file.Filename,
+		content:     content,
+		compression: s.compression,
 	}
 
 	return file, fw, nil
 }
 
+// DeleteStore removes a snapshot of the store, for example after finding it corrupted.
+func (s *FullKV) DeleteStore(ctx context.Context, file *FileInfo) (err error) {
+	zlog.Debug("deleting full store file", zap.String("file_name", file.Filename))
+
+	if err = s.objStore.DeleteObject(ctx, file.Filename); err != nil {
+		zlog.Warn("deleting file", zap.String("file_name", file.Filename), zap.Error(err))
+	}
+	return err
+}
+
 func (s *FullKV) Reset() {
 	if tracer.Enabled() {
-		s.logger.Debug("flushing store", zap.Int("delta_count", len(s.deltas)), zap.Int("entry_count", len(s.kv)))
+		s.logger.Debug("flushing store", zap.Int("delta_count", len(s.deltas)), zap.Int("entry_count", s.keyCount()))
 	}
 	s.deltas = nil
 	s.lastOrdinal = 0
 }
 
 func (s *FullKV) String() string {
-	return fmt.Sprintf("fullKV name %s moduleInitialBlock %d keyCount %d loadedFrom %s deltasCount %d", s.Name(), s.moduleInitialBlock, len(s.kv), s.loadedFrom, len(s.deltas))
+	return fmt.Sprintf("fullKV name %s moduleInitialBlock %d keyCount %d loadedFrom %s deltasCount %d", s.Name(), s.moduleInitialBlock, s.keyCount(), s.loadedFrom, len(s.deltas))
 }
@@ -3,11 +3,15 @@ package store
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"testing"
 
 	"github.com/streamingfast/dstore"
+	"github.com/streamingfast/substreams/storage/compression"
+	"github.com/streamingfast/substreams/storage/integrity"
 	"github.com/streamingfast/substreams/storage/store/marshaller"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
@@ -60,3 +64,262 @@ func TestFullKV_Save_Load_Empty_MapNotNil(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNilf(t, kvl.kv, "kvl.kv is nil")
 }
+
+func TestFullKV_Save_Load_Sorted_Lazy(t *testing.T) {
+	var writtenBytes []byte
+	store := dstore.NewMockStore(func(base string, f io.Reader) (err error) {
+		writtenBytes, err = io.ReadAll(f)
+		return err
+	})
+	store.OpenObjectFunc = func(ctx context.Context, name string) (out io.ReadCloser, err error) {
+		return io.NopCloser(bytes.NewBuffer(writtenBytes)), nil
+	}
+
+	newFullKV := func() *FullKV {
+		return &FullKV{
+			baseStore: &baseStore{
+				kv: map[string][]byte{},
+
+				logger:     zap.NewNop(),
+				marshaller: marshaller.Default(),
+
+				Config: &Config{
+					moduleInitialBlock: 0,
+					objStore:           store,
+					totalSizeLimit:     1_000_000_000,
+					itemSizeLimit:      1_000_000,
+					sortedSnapshots:    true,
+				},
+			},
+		}
+	}
+
+	kvs := newFullKV()
+	for i := 0; i < 10_000; i++ {
+		kvs.Set(uint64(i), fmt.Sprintf("key:%05d", i), fmt.Sprintf("value:%d", i))
+	}
+
+	file, writer, err := kvs.Save(123)
+	require.NoError(t, err)
+	require.NoError(t, writer.Write(context.Background()))
+	content, _, err := integrity.Open(writtenBytes)
+	require.NoError(t, err)
+	require.True(t, marshaller.IsSorted(content))
+
+	kvl := newFullKV()
+	require.NoError(t, kvl.Load(context.Background(), file))
+	require.NotNil(t, kvl.lazy)
+	require.Len(t, kvl.kv, 0)
+	assert.Equal(t, uint64(10_000), kvl.Length())
+	assert.Equal(t, kvs.SizeBytes(), kvl.SizeBytes())
+
+	val, found := kvl.GetLast("key:04242")
+	require.True(t, found)
+	assert.Equal(t, "value:4242", string(val))
+
+	kvl.Set(10_000, "key:00001", "updated")
+	kvl.Set(10_001, "key:10000", "value:10000")
+	kvl.DeletePrefix(10_002, "key:0000")
+	assert.Equal(t, uint64(9_991), kvl.Length())
+
+	var scanned []string
+	require.NoError(t, kvl.ScanRange("key:00008", "key:00012", 0, func(key string, value []byte) error {
+		scanned = append(scanned, key+"="+string(value))
+		return nil
+	}))
+	assert.Equal(t, []string{"key:00010=value:10", "key:00011=value:11"}, scanned)
+
+	kvl.ApplyDeltasReverse(kvl.GetDeltas())
+	kvl.Reset()
+	val, found = kvl.GetLast("key:00001")
+	require.True(t, found)
+	assert.Equal(t, "value:1", string(val))
+	assert.Equal(t, uint64(10_000), kvl.Length())
+
+	kvl.Set(0, "key:00001", "updated")
+	kvl.Set(1, "key:10000", "value:10000")
+	kvl.DeletePrefix(2, "key:0000")
+
+	file, writer, err = kvl.Save(456)
+	require.NoError(t, err)
+	require.NoError(t, writer.Write(context.Background()))
+
+	kvr := newFullKV()
+	require.NoError(t, kvr.Load(context.Background(), file))
+	assert.Equal(t, uint64(9_991), kvr.Length())
+	assert.Equal(t, kvl.SizeBytes(), kvr.SizeBytes())
+
+	_, found = kvr.GetLast("key:00001")
+	assert.False(t, found)
+	val, found = kvr.GetLast("key:10000")
+	require.True(t, found)
+	assert.Equal(t, "value:10000", string(val))
+}
+
+type failingReaderAt struct {
+	io.ReaderAt
+	failing bool
+}
+
+func (r *failingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if r.failing {
+		return 0, fmt.Errorf("connection reset")
+	}
+	return r.ReaderAt.ReadAt(p, off)
+}
+
+func TestFullKV_Lazy_ReadError(t *testing.T) {
+	content, err := (&marshaller.Sorted{}).Marshal(&marshaller.StoreData{Kv: map[string][]byte{
+		"a": []byte("1"),
+		"b": []byte("2"),
+	}})
+	require.NoError(t, err)
+	r := &failingReaderAt{ReaderAt: bytes.NewReader(content)}
+	lazy, err := marshaller.NewSortedReader(r, int64(len(content)))
+	require.NoError(t, err)
+	r.failing = true
+
+	kvs := &FullKV{
+		baseStore: &baseStore{
+			kv:         map[string][]byte{},
+			lazy:       lazy,
+			tombstones: map[string]bool{},
+			logger:     zap.NewNop(),
+			marshaller: marshaller.Default(),
+			Config: &Config{
+				name:          "test",
+				itemSizeLimit: 1_000,
+			},
+		},
+	}
+
+	_, found := kvs.GetLast("a")
+	assert.False(t, found)
+	assert.ErrorContains(t, kvs.Err(), `reading key "a" from store "test" snapshot`)
+
+	assert.ErrorContains(t, kvs.DeleteRange(0, "a", "c"), "connection reset")
+	assert.ErrorContains(t, kvs.DeletePrefix(1, "a"), "connection reset")
+
+	_, _, err = kvs.Save(10)
+	assert.ErrorContains(t, err, "store state is incomplete")
+}
+
+func TestFullKV_Save_Load_Compressed(t *testing.T) {
+	var writtenBytes []byte
+	store := dstore.NewMockStore(func(base string, f io.Reader) (err error) {
+		writtenBytes, err = io.ReadAll(f)
+		return err
+	})
+	store.OpenObjectFunc = func(ctx context.Context, name string) (out io.ReadCloser, err error) {
+		return io.NopCloser(bytes.NewBuffer(writtenBytes)), nil
+	}
+
+	newFullKV := func(codec compression.Codec, sorted bool) *FullKV {
+		return &FullKV{
+			baseStore: &baseStore{
+				kv: map[string][]byte{},
+
+				logger:     zap.NewNop(),
+				marshaller: marshaller.Default(),
+
+				Config: &Config{
+					moduleInitialBlock: 0,
+					objStore:           store,
+					totalSizeLimit:     1_000_000_000,
+					itemSizeLimit:      1_000_000,
+					compression:        codec,
+					sortedSnapshots:    sorted,
+				},
+			},
+		}
+	}
+
+	for _, sorted := range []bool{false, true} {
+		for _, codec := range []compression.Codec{compression.None, compression.Zstd, compression.Snappy} {
+			t.Run(fmt.Sprintf("%s/sorted=%t", codec, sorted), func(t *testing.T) {
+				kvs := newFullKV(codec, sorted)
+				for i := 0; i < 1_000; i++ {
+					kvs.Set(uint64(i), fmt.Sprintf("key:%05d", i), "value")
+				}
+
+				file, writer, err := kvs.Save(123)
+				require.NoError(t, err)
+				require.NoError(t, writer.Write(context.Background()))
+				content, _, err := integrity.Open(writtenBytes)
+				require.NoError(t, err)
+				assert.Equal(t, codec, compression.Detect(content))
+
+				// The codec is detected from the file content, whatever the reader is configured with
+				kvl := newFullKV(compression.None, false)
+				require.NoError(t, kvl.Load(context.Background(), file))
+				assert.Equal(t, sorted, kvl.lazy != nil)
+				assert.Equal(t, uint64(1_000), kvl.Length())
+
+				val, found := kvl.GetLast("key:00042")
+				require.True(t, found)
+				assert.Equal(t, "value", string(val))
+			})
+		}
+	}
+}
+
+func TestFullKV_Load_Corrupted(t *testing.T) {
+	var writtenBytes []byte
+	store := dstore.NewMockStore(func(base string, f io.Reader) (err error) {
+		writtenBytes, err = io.ReadAll(f)
+		return err
+	})
+	store.OpenObjectFunc = func(ctx context.Context, name string) (out io.ReadCloser, err error) {
+		return io.NopCloser(bytes.NewBuffer(writtenBytes)), nil
+	}
+
+	newFullKV := func() *FullKV {
+		return &FullKV{
+			baseStore: &baseStore{
+				kv: map[string][]byte{},
+
+				logger:     zap.NewNop(),
+				marshaller: marshaller.Default(),
+
+				Config: &Config{
+					moduleInitialBlock: 0,
+					objStore:           store,
+					totalSizeLimit:     1_000_000_000,
+					itemSizeLimit:      1_000_000,
+				},
+			},
+		}
+	}
+
+	kvs := newFullKV()
+	kvs.Set(0, "key", "value")
+	file, writer, err := kvs.Save(123)
+	require.NoError(t, err)
+	require.NoError(t, writer.Write(context.Background()))
+
+	writtenBytes[len(writtenBytes)-1] ^= 0xff
+
+	err = newFullKV().Load(context.Background(), file)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, integrity.ErrCorrupted)
+}
+
+func TestAsCorruptedSnapshotError(t *testing.T) {
+	corrupted := &CorruptedSnapshotError{ModuleName: "store_pools", ExclusiveEndBlock: 12000, Err: integrity.ErrCorrupted}
+
+	out, ok := AsCorruptedSnapshotError(fmt.Errorf("load full store: %w", corrupted))
+	require.True(t, ok)
+	assert.Same(t, corrupted, out)
+
+	// as received from a remote worker, through the message only
+	out, ok = AsCorruptedSnapshotError(fmt.Errorf("rpc error: code = DataLoss desc = load full store: %s", corrupted))
+	require.True(t, ok)
+	assert.Equal(t, "store_pools", out.ModuleName)
+	assert.Equal(t, uint64(12000), out.ExclusiveEndBlock)
+
+	_, ok = AsCorruptedSnapshotError(fmt.Errorf("load full store: %w", integrity.ErrCorrupted))
+	assert.False(t, ok)
+
+	_, ok = AsCorruptedSnapshotError(nil)
+	assert.False(t, ok)
+}
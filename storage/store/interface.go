@@ -46,6 +46,18 @@ type Store interface {
 	SumInt64Setter
 	SumFloat64Setter
 	SumBigDecimalSetter
+
+	SetSumBigIntSetter
+	SetSumInt64Setter
+	SetSumFloat64Setter
+	SetSumBigDecimalSetter
+
+	BitwiseOrBigIntSetter
+	BitwiseOrInt64Setter
+	BitwiseAndBigIntSetter
+	BitwiseAndInt64Setter
+
+	CardinalityAdder
 }
 
 type PartialStore interface {
@@ -64,6 +76,18 @@ type Resettable interface {
 	Reset()
 }
 
+// WriteMarker is implemented by stores tracking the block at which their keys
+// were last written, for stores with a ttl.
+type WriteMarker interface {
+	MarkWritten(blockNum uint64)
+}
+
+// Evictable is implemented by stores removing, at store boundaries, the keys
+// that were not written within their ttl.
+type Evictable interface {
+	EvictExpired(boundaryBlock uint64)
+}
+
 type Named interface {
 	Name() string
 }
@@ -92,6 +116,18 @@ type Reader interface {
 	HasFirst(key string) bool
 	HasLast(key string) bool
 	HasAt(ord uint64, key string) bool
+
+	// ScanPrefix calls `f` in lexicographical key order with the last value of each key starting
+	// with `prefix`, stopping after `limit` keys. A `limit` of 0 means no limit.
+	ScanPrefix(prefix string, limit uint64, f func(key string, value []byte) error) error
+	// ScanRange calls `f` in lexicographical key order with the last value of each key between
+	// `lowKey` (inclusive) and `highKey` (exclusive), stopping after `limit` keys. An empty `highKey`
+	// means the range has no upper bound and a `limit` of 0 means no limit.
+	ScanRange(lowKey, highKey string, limit uint64, f func(key string, value []byte) error) error
+
+	// Err returns the error that occurred reading the snapshot of a store loaded lazily, the
+	// methods above then reporting its keys as not found. The store must not be used anymore.
+	Err() error
 }
 
 type Mergeable interface {
@@ -114,11 +150,12 @@ type Appender interface {
 }
 
 type Deleter interface {
-	DeletePrefix(ord uint64, prefix string)
-	//// Deletes a range of keys, lexicographically between `lowKey` and `highKey`
-	//DeleteRange(lowKey, highKey string)
-	//// Deletes a range of keys, first considering the _value_ of such keys as a _pointerSeparator_-separated list of keys to _also_ delete.
-	//DeleteRangePointers(lowKey, highKey, pointerSeparator string)
+	DeletePrefix(ord uint64, prefix string) error
+	// Deletes a range of keys, lexicographically between `lowKey` (inclusive) and `highKey` (exclusive).
+	// An empty `highKey` means the range has no upper bound.
+	DeleteRange(ord uint64, lowKey, highKey string) error
+	// Deletes a range of keys, first considering the _value_ of such keys as a _pointerSeparator_-separated list of keys to _also_ delete.
+	DeleteRangePointers(ord uint64, lowKey, highKey, pointerSeparator string) error
 }
 
 type MaxBigIntSetter interface {
@@ -159,3 +196,35 @@ type SumFloat64Setter interface {
 type SumBigDecimalSetter interface {
 	SumBigDecimal(ord uint64, key string, value decimal.Decimal)
 }
+
+// The set_sum setters take values prefixed with `set:`, which reset the key, or `sum:`, which
+// add to its previous value.
+type SetSumBigIntSetter interface {
+	SetSumBigInt(ord uint64, key string, value string) error
+}
+type SetSumInt64Setter interface {
+	SetSumInt64(ord uint64, key string, value string) error
+}
+type SetSumFloat64Setter interface {
+	SetSumFloat64(ord uint64, key string, value string) error
+}
+type SetSumBigDecimalSetter interface {
+	SetSumBigDecimal(ord uint64, key string, value string) error
+}
+
+type BitwiseOrBigIntSetter interface {
+	BitwiseOrBigInt(ord uint64, key string, value *big.Int) error
+}
+type BitwiseOrInt64Setter interface {
+	BitwiseOrInt64(ord uint64, key string, value int64) error
+}
+type BitwiseAndBigIntSetter interface {
+	BitwiseAndBigInt(ord uint64, key string, value *big.Int) error
+}
+type BitwiseAndInt64Setter interface {
+	BitwiseAndInt64(ord uint64, key string, value int64) error
+}
+
+type CardinalityAdder interface {
+	CardinalityAdd(ord uint64, key string, item []byte)
+}
@@ -0,0 +1,142 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// When a store is loaded from a sorted snapshot, `lazy` holds the loaded keys, which
+// are only decoded when accessed, and `kv` only holds the keys modified since. Keys
+// deleted from `lazy` are tracked in `tombstones`. The accessors below hide that
+// distinction from the rest of the store.
+//
+// Reading `lazy` can fail. As most accessors cannot return an error, the first one
+// is kept in `lazyErr`, the key being then reported as not found, and is returned by
+// Err: a store with an error must not be used anymore.
+
+var errStopIteration = errors.New("stop iteration")
+
+func (b *baseStore) getKV(key string) ([]byte, bool) {
+	if val, found := b.kv[key]; found {
+		return val, true
+	}
+	if b.lazy == nil || b.tombstones[key] {
+		return nil, false
+	}
+
+	val, found, err := b.lazy.Get(key)
+	if err != nil {
+		if b.lazyErr == nil {
+			b.lazyErr = fmt.Errorf("reading key %q from store %q snapshot: %w", key, b.name, err)
+		}
+		return nil, false
+	}
+	return val, found
+}
+
+// Err returns the first error that occurred reading the snapshot the store was
+// lazily loaded from, nil if there was none.
+func (b *baseStore) Err() error {
+	return b.lazyErr
+}
+
+func (b *baseStore) putKV(key string, value []byte) {
+	if b.lazy != nil {
+		if _, found := b.getKV(key); !found {
+			b.lazyKeyCountDelta++
+		}
+		delete(b.tombstones, key)
+	}
+	b.kv[key] = value
+}
+
+func (b *baseStore) deleteKV(key string) {
+	if b.lazy != nil {
+		if _, found := b.getKV(key); found {
+			b.lazyKeyCountDelta--
+		}
+		b.tombstones[key] = true
+	}
+	delete(b.kv, key)
+	delete(b.lastWrittenBlocks, key)
+}
+
+func (b *baseStore) keyCount() int {
+	if b.lazy != nil {
+		return int(int64(b.lazy.Length()) + b.lazyKeyCountDelta)
+	}
+	return len(b.kv)
+}
+
+// iterKV calls `f` for all the keys of the store, in no particular order.
+func (b *baseStore) iterKV(f func(key string, value []byte) error) error {
+	if b.lazy != nil {
+		return b.iterSortedKV("", nil, f)
+	}
+
+	for k, v := range b.kv {
+		if err := f(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// iterSortedKV calls `f`, in lexicographical key order, for the keys of the store greater
+// or equal to `lowKey` while `inRange` holds, or for all of them when `inRange` is nil.
+// The keys in range must be contiguous, like the keys of a prefix or of a key range, so
+// that only them are sorted. Returning `errStopIteration` from `f` stops the iteration
+// without error.
+func (b *baseStore) iterSortedKV(lowKey string, inRange func(key string) bool, f func(key string, value []byte) error) error {
+	var keys []string
+	for key := range b.kv {
+		if key >= lowKey && (inRange == nil || inRange(key)) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	if b.lazy != nil {
+		var fErr error
+		err := b.lazy.IterFrom(lowKey, func(lazyKey string, lazyValue []byte) error {
+			overridden := false
+			for len(keys) > 0 && keys[0] <= lazyKey {
+				overridden = overridden || keys[0] == lazyKey
+				if fErr = f(keys[0], b.kv[keys[0]]); fErr != nil {
+					return fErr
+				}
+				keys = keys[1:]
+			}
+			if inRange != nil && !inRange(lazyKey) {
+				fErr = errStopIteration
+				return fErr
+			}
+			if overridden || b.tombstones[lazyKey] {
+				return nil
+			}
+			fErr = f(lazyKey, lazyValue)
+			return fErr
+		})
+		if err != nil && fErr == nil && b.lazyErr == nil {
+			b.lazyErr = fmt.Errorf("iterating store %q snapshot: %w", b.name, err)
+		}
+		if err != nil {
+			return ignoreStopIteration(err)
+		}
+	}
+
+	for _, key := range keys {
+		if err := f(key, b.kv[key]); err != nil {
+			return ignoreStopIteration(err)
+		}
+	}
+	return nil
+}
+
+func ignoreStopIteration(err error) error {
+	if errors.Is(err, errStopIteration) {
+		return nil
+	}
+	return err
+}
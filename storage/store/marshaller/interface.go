@@ -1,8 +1,35 @@
 package marshaller
 
+import (
+	pbstore "github.com/streamingfast/substreams/storage/store/marshaller/pb"
+)
+
 type StoreData struct {
 	Kv             map[string][]byte
 	DeletePrefixes []string
+	DeleteRanges   []*DeleteRange
+
+	// LastWrittenBlocks is the block at which each key was last written, only
+	// tracked for stores with a ttl.
+	LastWrittenBlocks map[string]uint64
+}
+
+// DeleteRange is a deletion of all keys lexicographically between `LowKey` (inclusive)
+// and `HighKey` (exclusive). When `PointerSeparator` is non-empty, the values of the
+// deleted keys are also considered as `PointerSeparator`-separated lists of keys to delete.
+type DeleteRange struct {
+	LowKey           string
+	HighKey          string
+	PointerSeparator string
+
+	// PrefixCount is the number of delete prefixes recorded before this range, so
+	// that prefix and range deletions are replayed in the order they were made.
+	PrefixCount uint64
+	// WrittenKeys are the keys of the range written in the same segment before the
+	// deletion. Their pointers are the `Pointers`, resolved from the written values,
+	// and not the ones of the values they had before the segment.
+	WrittenKeys []string
+	Pointers    []string
 }
 
 type Marshaller interface {
@@ -13,3 +40,39 @@ type Marshaller interface {
 func Default() Marshaller {
 	return &VTproto{}
 }
+
+func deleteRangesToProto(in []*DeleteRange) (out []*pbstore.DeleteRange) {
+	if len(in) == 0 {
+		return nil
+	}
+	out = make([]*pbstore.DeleteRange, len(in))
+	for i, r := range in {
+		out[i] = &pbstore.DeleteRange{
+			LowKey:           r.LowKey,
+			HighKey:          r.HighKey,
+			PointerSeparator: r.PointerSeparator,
+			PrefixCount:      r.PrefixCount,
+			WrittenKeys:      r.WrittenKeys,
+			Pointers:         r.Pointers,
+		}
+	}
+	return out
+}
+
+func deleteRangesFromProto(in []*pbstore.DeleteRange) (out []*DeleteRange) {
+	if len(in) == 0 {
+		return nil
+	}
+	out = make([]*DeleteRange, len(in))
+	for i, r := range in {
+		out[i] = &DeleteRange{
+			LowKey:           r.LowKey,
+			HighKey:          r.HighKey,
+			PointerSeparator: r.PointerSeparator,
+			PrefixCount:      r.PrefixCount,
+			WrittenKeys:      r.WrittenKeys,
+			Pointers:         r.Pointers,
+		}
+	}
+	return out
+}
@@ -27,6 +27,9 @@ type StoreData struct {
 
 	Kv             map[string][]byte `protobuf:"bytes,1,rep,name=kv,proto3" json:"kv,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 	DeletePrefixes []string          `protobuf:"bytes,2,rep,name=delete_prefixes,json=deletePrefixes,proto3" json:"delete_prefixes,omitempty"`
+	DeleteRanges   []*DeleteRange    `protobuf:"bytes,3,rep,name=delete_ranges,json=deleteRanges,proto3" json:"delete_ranges,omitempty"`
+	// block at which each key was last written, only kept for stores with a ttl
+	LastWrittenBlocks map[string]uint64 `protobuf:"bytes,4,rep,name=last_written_blocks,json=lastWrittenBlocks,proto3" json:"last_written_blocks,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
 }
 
 func (x *StoreData) Reset() {
@@ -75,27 +78,162 @@ func (x *StoreData) GetDeletePrefixes() []string {
 	return nil
 }
 
+func (x *StoreData) GetDeleteRanges() []*DeleteRange {
+	if x != nil {
+		return x.DeleteRanges
+	}
+	return nil
+}
+
+func (x *StoreData) GetLastWrittenBlocks() map[string]uint64 {
+	if x != nil {
+		return x.LastWrittenBlocks
+	}
+	return nil
+}
+
+type DeleteRange struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	LowKey           string `protobuf:"bytes,1,opt,name=low_key,json=lowKey,proto3" json:"low_key,omitempty"`
+	HighKey          string `protobuf:"bytes,2,opt,name=high_key,json=highKey,proto3" json:"high_key,omitempty"`
+	PointerSeparator string `protobuf:"bytes,3,opt,name=pointer_separator,json=pointerSeparator,proto3" json:"pointer_separator,omitempty"`
+	// number of delete_prefixes recorded before this range, so that the deletions
+	// are replayed in the order they were made
+	PrefixCount uint64 `protobuf:"varint,4,opt,name=prefix_count,json=prefixCount,proto3" json:"prefix_count,omitempty"`
+	// keys of the range written in the same segment before the deletion, whose
+	// pointers were resolved from the written value instead of the previous one
+	WrittenKeys []string `protobuf:"bytes,5,rep,name=written_keys,json=writtenKeys,proto3" json:"written_keys,omitempty"`
+	// pointer keys resolved from the values of `written_keys`
+	Pointers []string `protobuf:"bytes,6,rep,name=pointers,proto3" json:"pointers,omitempty"`
+}
+
+func (x *DeleteRange) Reset() {
+	*x = DeleteRange{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_store_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteRange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRange) ProtoMessage() {}
+
+func (x *DeleteRange) ProtoReflect() protoreflect.Message {
+	mi := &file_store_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRange.ProtoReflect.Descriptor instead.
+func (*DeleteRange) Descriptor() ([]byte, []int) {
+	return file_store_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *DeleteRange) GetLowKey() string {
+	if x != nil {
+		return x.LowKey
+	}
+	return ""
+}
+
+func (x *DeleteRange) GetHighKey() string {
+	if x != nil {
+		return x.HighKey
+	}
+	return ""
+}
+
+func (x *DeleteRange) GetPointerSeparator() string {
+	if x != nil {
+		return x.PointerSeparator
+	}
+	return ""
+}
+
+func (x *DeleteRange) GetPrefixCount() uint64 {
+	if x != nil {
+		return x.PrefixCount
+	}
+	return 0
+}
+
+func (x *DeleteRange) GetWrittenKeys() []string {
+	if x != nil {
+		return x.WrittenKeys
+	}
+	return nil
+}
+
+func (x *DeleteRange) GetPointers() []string {
+	if x != nil {
+		return x.Pointers
+	}
+	return nil
+}
+
 var File_store_proto protoreflect.FileDescriptor
 
 var file_store_proto_rawDesc = []byte{
 	0x0a, 0x0b, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x16, 0x73,
 	0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x73, 0x74, 0x6f,
-	0x72, 0x65, 0x2e, 0x76, 0x31, 0x22, 0xa6, 0x01, 0x0a, 0x09, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x44,
+	0x72, 0x65, 0x2e, 0x76, 0x31, 0x22, 0xa0, 0x03, 0x0a, 0x09, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x44,
 	0x61, 0x74, 0x61, 0x12, 0x39, 0x0a, 0x02, 0x6b, 0x76, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
 	0x29, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e,
 	0x73, 0x74, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x44, 0x61,
 	0x74, 0x61, 0x2e, 0x4b, 0x76, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x02, 0x6b, 0x76, 0x12, 0x27,
 	0x0a, 0x0f, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x65,
 	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0e, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x50,
-	0x72, 0x65, 0x66, 0x69, 0x78, 0x65, 0x73, 0x1a, 0x35, 0x0a, 0x07, 0x4b, 0x76, 0x45, 0x6e, 0x74,
-	0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x0c, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x42, 0x41,
-	0x5a, 0x3f, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x74, 0x72,
-	0x65, 0x61, 0x6d, 0x69, 0x6e, 0x67, 0x66, 0x61, 0x73, 0x74, 0x2f, 0x73, 0x75, 0x62, 0x73, 0x74,
-	0x72, 0x65, 0x61, 0x6d, 0x73, 0x2f, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x2f, 0x6d, 0x61, 0x72, 0x73,
-	0x68, 0x61, 0x6c, 0x6c, 0x65, 0x72, 0x2f, 0x70, 0x62, 0x3b, 0x70, 0x62, 0x73, 0x74, 0x6f, 0x72,
-	0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x72, 0x65, 0x66, 0x69, 0x78, 0x65, 0x73, 0x12, 0x48, 0x0a, 0x0d, 0x64, 0x65, 0x6c, 0x65, 0x74,
+	0x65, 0x5f, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x23,
+	0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x73,
+	0x74, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x61,
+	0x6e, 0x67, 0x65, 0x52, 0x0c, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x61, 0x6e, 0x67, 0x65,
+	0x73, 0x12, 0x68, 0x0a, 0x13, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x77, 0x72, 0x69, 0x74, 0x74, 0x65,
+	0x6e, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x38,
+	0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x73,
+	0x74, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x44, 0x61, 0x74,
+	0x61, 0x2e, 0x4c, 0x61, 0x73, 0x74, 0x57, 0x72, 0x69, 0x74, 0x74, 0x65, 0x6e, 0x42, 0x6c, 0x6f,
+	0x63, 0x6b, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x11, 0x6c, 0x61, 0x73, 0x74, 0x57, 0x72,
+	0x69, 0x74, 0x74, 0x65, 0x6e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x1a, 0x35, 0x0a, 0x07, 0x4b,
+	0x76, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02,
+	0x38, 0x01, 0x1a, 0x44, 0x0a, 0x16, 0x4c, 0x61, 0x73, 0x74, 0x57, 0x72, 0x69, 0x74, 0x74, 0x65,
+	0x6e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03,
+	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14,
+	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xd0, 0x01, 0x0a, 0x0b, 0x44, 0x65, 0x6c,
+	0x65, 0x74, 0x65, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x6c, 0x6f, 0x77, 0x5f,
+	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6c, 0x6f, 0x77, 0x4b, 0x65,
+	0x79, 0x12, 0x19, 0x0a, 0x08, 0x68, 0x69, 0x67, 0x68, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x68, 0x69, 0x67, 0x68, 0x4b, 0x65, 0x79, 0x12, 0x2b, 0x0a, 0x11,
+	0x70, 0x6f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x5f, 0x73, 0x65, 0x70, 0x61, 0x72, 0x61, 0x74, 0x6f,
+	0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x65, 0x72,
+	0x53, 0x65, 0x70, 0x61, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x21, 0x0a, 0x0c, 0x70, 0x72, 0x65,
+	0x66, 0x69, 0x78, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x0b, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x21, 0x0a, 0x0c,
+	0x77, 0x72, 0x69, 0x74, 0x74, 0x65, 0x6e, 0x5f, 0x6b, 0x65, 0x79, 0x73, 0x18, 0x05, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x0b, 0x77, 0x72, 0x69, 0x74, 0x74, 0x65, 0x6e, 0x4b, 0x65, 0x79, 0x73, 0x12,
+	0x1a, 0x0a, 0x08, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x08, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x73, 0x42, 0x41, 0x5a, 0x3f, 0x67,
+	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d,
+	0x69, 0x6e, 0x67, 0x66, 0x61, 0x73, 0x74, 0x2f, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x73, 0x2f, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x2f, 0x6d, 0x61, 0x72, 0x73, 0x68, 0x61, 0x6c,
+	0x6c, 0x65, 0x72, 0x2f, 0x70, 0x62, 0x3b, 0x70, 0x62, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -110,18 +248,22 @@ func file_store_proto_rawDescGZIP() []byte {
 	return file_store_proto_rawDescData
 }
 
-var file_store_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_store_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
 var file_store_proto_goTypes = []interface{}{
-	(*StoreData)(nil), // 0: sf.substreams.store.v1.StoreData
-	nil,               // 1: sf.substreams.store.v1.StoreData.KvEntry
+	(*StoreData)(nil),   // 0: sf.substreams.store.v1.StoreData
+	(*DeleteRange)(nil), // 1: sf.substreams.store.v1.DeleteRange
+	nil,                 // 2: sf.substreams.store.v1.StoreData.KvEntry
+	nil,                 // 3: sf.substreams.store.v1.StoreData.LastWrittenBlocksEntry
 }
 var file_store_proto_depIdxs = []int32{
-	1, // 0: sf.substreams.store.v1.StoreData.kv:type_name -> sf.substreams.store.v1.StoreData.KvEntry
-	1, // [1:1] is the sub-list for method output_type
-	1, // [1:1] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	2, // 0: sf.substreams.store.v1.StoreData.kv:type_name -> sf.substreams.store.v1.StoreData.KvEntry
+	1, // 1: sf.substreams.store.v1.StoreData.delete_ranges:type_name -> sf.substreams.store.v1.DeleteRange
+	3, // 2: sf.substreams.store.v1.StoreData.last_written_blocks:type_name -> sf.substreams.store.v1.StoreData.LastWrittenBlocksEntry
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_store_proto_init() }
@@ -142,6 +284,18 @@ func file_store_proto_init() {
 				return nil
 			}
 		}
+		file_store_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeleteRange); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -149,7 +303,7 @@ func file_store_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_store_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   4,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
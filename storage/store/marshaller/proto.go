@@ -15,15 +15,19 @@ func (p *Proto) Unmarshal(in []byte) (*StoreData, uint64, error) {
 		return nil, 0, fmt.Errorf("unmarshal store: %w", err)
 	}
 	return &StoreData{
-		Kv:             stateData.GetKv(),
-		DeletePrefixes: stateData.GetDeletePrefixes(),
+		Kv:                stateData.GetKv(),
+		DeletePrefixes:    stateData.GetDeletePrefixes(),
+		DeleteRanges:      deleteRangesFromProto(stateData.GetDeleteRanges()),
+		LastWrittenBlocks: stateData.GetLastWrittenBlocks(),
 	}, 0, nil
 }
 
 func (p *Proto) Marshal(data *StoreData) ([]byte, error) {
 	stateData := &pbsubstreams.StoreData{
-		Kv:             data.Kv,
-		DeletePrefixes: data.DeletePrefixes,
+		Kv:                data.Kv,
+		DeletePrefixes:    data.DeletePrefixes,
+		DeleteRanges:      deleteRangesToProto(data.DeleteRanges),
+		LastWrittenBlocks: data.LastWrittenBlocks,
 	}
 	return proto.Marshal(stateData)
 }
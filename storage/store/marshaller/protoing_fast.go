@@ -12,6 +12,16 @@ const KVEntryProtoTag = 0x0a
 const KVEntryKeyProtoTag = 0x0a
 const KVEntryValueProtoTag = 0x12
 const DeletePrefixEntryProtoTag = 0x12
+const DeleteRangeEntryProtoTag = 0x1a
+const DeleteRangeLowKeyProtoTag = 0x0a
+const DeleteRangeHighKeyProtoTag = 0x12
+const DeleteRangePointerSeparatorProtoTag = 0x1a
+const DeleteRangePrefixCountProtoTag = 0x20
+const DeleteRangeWrittenKeyProtoTag = 0x2a
+const DeleteRangePointerProtoTag = 0x32
+const LastWrittenBlockEntryProtoTag = 0x22
+const LastWrittenBlockEntryKeyProtoTag = 0x0a
+const LastWrittenBlockEntryValueProtoTag = 0x10
 
 // ProtoingFast is a custom proto marshaller, that will marshal and unmarshall the storeData into a predefined
 // proto struct (see below). The motivation here is that we want to write a proto message, making it readable by
@@ -20,6 +30,17 @@ const DeletePrefixEntryProtoTag = 0x12
 //	message StoreData {
 //		map<string, bytes> kv = 1;
 //		repeated string delete_prefixes = 2;
+//		repeated DeleteRange delete_ranges = 3;
+//		map<string, uint64> last_written_blocks = 4;
+//	}
+//
+//	message DeleteRange {
+//		string low_key = 1;
+//		string high_key = 2;
+//		string pointer_separator = 3;
+//		uint64 prefix_count = 4;
+//		repeated string written_keys = 5;
+//		repeated string pointers = 6;
 //	}
 type ProtoingFast struct{}
 
@@ -29,18 +50,24 @@ func (p *ProtoingFast) Unmarshal(in []byte) (*StoreData, uint64, error) {
 		return nil, 0, fmt.Errorf("unmarshal store: %w", err)
 	}
 	return &StoreData{
-		Kv:             stateData.GetKv(),
-		DeletePrefixes: stateData.GetDeletePrefixes(),
+		Kv:                stateData.GetKv(),
+		DeletePrefixes:    stateData.GetDeletePrefixes(),
+		DeleteRanges:      deleteRangesFromProto(stateData.GetDeleteRanges()),
+		LastWrittenBlocks: stateData.GetLastWrittenBlocks(),
 	}, 0, nil
 }
 
 func (p *ProtoingFast) Marshal(data *StoreData) ([]byte, error) {
 	sizeInBytes := p.kvByteSize(data.Kv)
 	sizeInBytes += p.listByteSize(data.DeletePrefixes)
+	sizeInBytes += p.deleteRangesByteSize(data.DeleteRanges)
+	sizeInBytes += p.lastWrittenBlocksByteSize(data.LastWrittenBlocks)
 	buffer := make([]byte, sizeInBytes)
 	cursor := buffer
 	cursor = p.writeKV(cursor, data.Kv)
-	p.writeDeletePrefix(cursor, data.DeletePrefixes)
+	cursor = p.writeDeletePrefix(cursor, data.DeletePrefixes)
+	cursor = p.writeDeleteRanges(cursor, data.DeleteRanges)
+	p.writeLastWrittenBlocks(cursor, data.LastWrittenBlocks)
 	return buffer, nil
 
 }
@@ -76,6 +103,61 @@ func (p *ProtoingFast) listByteSize(list []string) int {
 	return size
 }
 
+func (p *ProtoingFast) deleteRangesByteSize(ranges []*DeleteRange) int {
+	size := 0
+	for _, r := range ranges {
+		entrySize := deleteRangeEntryByteSize(r)
+		size += 1                                   // List element proto tag 0x1a (field number 3 [the DeleteRanges field], type LEN [message])
+		size += uvarintByteCount(uint64(entrySize)) // Number of bytes in the message
+		size += entrySize
+	}
+	return size
+}
+
+func deleteRangeEntryByteSize(r *DeleteRange) int {
+	size := 0
+	for _, field := range []string{r.LowKey, r.HighKey, r.PointerSeparator} {
+		if len(field) == 0 {
+			continue // proto3 does not write empty strings
+		}
+		size += 1                                    // Field proto tag
+		size += uvarintByteCount(uint64(len(field))) // Number of bytes (characters) in the string
+		size += len(field)                           // string
+	}
+	if r.PrefixCount != 0 {
+		size += 1                               // Field proto tag 0x20 (field number 4 [the PrefixCount field], type VARINT)
+		size += uvarintByteCount(r.PrefixCount) // value
+	}
+	for _, list := range [][]string{r.WrittenKeys, r.Pointers} {
+		for _, l := range list {
+			size += 1                                // List element proto tag
+			size += uvarintByteCount(uint64(len(l))) // Number of bytes (characters) in the string
+			size += len(l)                           // string
+		}
+	}
+	return size
+}
+
+func (p *ProtoingFast) lastWrittenBlocksByteSize(entries map[string]uint64) int {
+	size := 0
+	for k, v := range entries {
+		entrySize := lastWrittenBlockEntryByteSize(k, v)
+		size += 1                                   // Map Key/Value proto tag 0x22 (field number 4 [the LastWrittenBlocks field], type LEN [message])
+		size += uvarintByteCount(uint64(entrySize)) // Number of bytes to represent both key and value
+		size += entrySize
+	}
+	return size
+}
+
+func lastWrittenBlockEntryByteSize(key string, value uint64) int {
+	size := 1                                  // Key proto tag 0x0a (field number 1 [the key], type LEN [string])
+	size += uvarintByteCount(uint64(len(key))) // Number of bytes (characters) in the key
+	size += len(key)                           // key
+	size += 1                                  // Value proto tag 0x10 (field number 2 [the value], type VARINT)
+	size += uvarintByteCount(value)            // value
+	return size
+}
+
 func (p *ProtoingFast) writeKV(cursor []byte, entries map[string][]byte) []byte {
 	for key, value := range entries {
 		copy(cursor, []byte{KVEntryProtoTag})
@@ -118,3 +200,85 @@ func (p *ProtoingFast) writeDeletePrefix(cursor []byte, entries []string) []byte
 	}
 	return cursor
 }
+
+func (p *ProtoingFast) writeDeleteRanges(cursor []byte, ranges []*DeleteRange) []byte {
+	for _, r := range ranges {
+		copy(cursor, []byte{DeleteRangeEntryProtoTag})
+		cursor = cursor[1:]
+
+		written := binary.PutUvarint(cursor, uint64(deleteRangeEntryByteSize(r)))
+		cursor = cursor[written:]
+
+		cursor = writeStringField(cursor, DeleteRangeLowKeyProtoTag, r.LowKey)
+		cursor = writeStringField(cursor, DeleteRangeHighKeyProtoTag, r.HighKey)
+		cursor = writeStringField(cursor, DeleteRangePointerSeparatorProtoTag, r.PointerSeparator)
+		if r.PrefixCount != 0 {
+			copy(cursor, []byte{DeleteRangePrefixCountProtoTag})
+			cursor = cursor[1:]
+
+			written = binary.PutUvarint(cursor, r.PrefixCount)
+			cursor = cursor[written:]
+		}
+		for _, key := range r.WrittenKeys {
+			cursor = writeListElement(cursor, DeleteRangeWrittenKeyProtoTag, key)
+		}
+		for _, pointer := range r.Pointers {
+			cursor = writeListElement(cursor, DeleteRangePointerProtoTag, pointer)
+		}
+	}
+	return cursor
+}
+
+func (p *ProtoingFast) writeLastWrittenBlocks(cursor []byte, entries map[string]uint64) []byte {
+	for key, value := range entries {
+		copy(cursor, []byte{LastWrittenBlockEntryProtoTag})
+		cursor = cursor[1:]
+
+		written := binary.PutUvarint(cursor, uint64(lastWrittenBlockEntryByteSize(key, value)))
+		cursor = cursor[written:]
+
+		copy(cursor, []byte{LastWrittenBlockEntryKeyProtoTag})
+		cursor = cursor[1:]
+
+		written = binary.PutUvarint(cursor, uint64(len(key)))
+		cursor = cursor[written:]
+
+		copy(cursor, unsafeGetBytes(key))
+		cursor = cursor[len(key):]
+
+		copy(cursor, []byte{LastWrittenBlockEntryValueProtoTag})
+		cursor = cursor[1:]
+
+		written = binary.PutUvarint(cursor, value)
+		cursor = cursor[written:]
+	}
+	return cursor
+}
+
+func writeStringField(cursor []byte, tag byte, value string) []byte {
+	if len(value) == 0 {
+		return cursor
+	}
+
+	copy(cursor, []byte{tag})
+	cursor = cursor[1:]
+
+	written := binary.PutUvarint(cursor, uint64(len(value)))
+	cursor = cursor[written:]
+
+	copy(cursor, unsafeGetBytes(value))
+	return cursor[len(value):]
+}
+
+// writeListElement writes an element of a repeated string field, empty strings
+// included unlike writeStringField.
+func writeListElement(cursor []byte, tag byte, value string) []byte {
+	copy(cursor, []byte{tag})
+	cursor = cursor[1:]
+
+	written := binary.PutUvarint(cursor, uint64(len(value)))
+	cursor = cursor[written:]
+
+	copy(cursor, unsafeGetBytes(value))
+	return cursor[len(value):]
+}
@@ -53,6 +53,44 @@ func TestProtoFast_CompareMarshal(t *testing.T) {
 				DeletePrefixes: []string{"22"},
 			},
 		},
+		{
+			name: "only delete ranges",
+			data: &StoreData{
+				DeleteRanges: []*DeleteRange{
+					{LowKey: "a", HighKey: "b"},
+					{LowKey: "idx:", HighKey: "idx;", PointerSeparator: ","},
+				},
+			},
+		},
+		{
+			name: "only last written blocks",
+			data: &StoreData{
+				LastWrittenBlocks: map[string]uint64{
+					"a": 12,
+				},
+			},
+		},
+		{
+			name: "kv and last written blocks",
+			data: &StoreData{
+				Kv: map[string][]byte{
+					"a": {0xaa},
+				},
+				LastWrittenBlocks: map[string]uint64{
+					"a": 0,
+				},
+			},
+		},
+		{
+			name: "delete prefix and delete ranges",
+			data: &StoreData{
+				DeletePrefixes: []string{"22"},
+				DeleteRanges: []*DeleteRange{
+					{LowKey: "", HighKey: "b", PointerSeparator: ":"},
+					{LowKey: "idx:", HighKey: "idx;", PointerSeparator: ",", PrefixCount: 1, WrittenKeys: []string{"idx:a", ""}, Pointers: []string{"p:2"}},
+				},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -77,6 +115,11 @@ func TestProtoFast_CompareMarshal(t *testing.T) {
 
 			assert.Equal(t, test.data, v)
 
+			v, _, err = vp.Unmarshal(vtProtoData)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.data, v)
+
 		})
 	}
 }
@@ -0,0 +1,418 @@
+package marshaller
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// The sorted format is a block-indexed layout, similar to an SSTable, where keys are
+// written in lexicographical order. It is only used for full stores snapshots, so that
+// they can be read lazily through a `SortedReader` instead of being fully loaded in memory.
+//
+//	header:  magic (4 bytes) | version (1 byte)
+//	blocks:  repeated [uvarint key length | key | uvarint value length | value]
+//	index:   uvarint block count | repeated [uvarint first key length | first key | uvarint offset | uvarint length]
+//	footer:  index offset (uint64 LE) | key count (uint64 LE) | data size (uint64 LE) | magic (4 bytes)
+var sortedMagic = []byte("sfkv")
+
+const sortedVersion = 1
+const sortedHeaderSize = 5
+const sortedFooterSize = 28
+
+// SortedBlockSize is the size over which a block is flushed and a new one is started.
+const SortedBlockSize = 64 * 1024
+
+// sortedCachedBlocks is the number of decoded blocks kept in memory by a `SortedReader`.
+const sortedCachedBlocks = 64
+
+// IsSorted returns true if `in` looks like data written in the sorted format.
+func IsSorted(in []byte) bool {
+	if len(in) < sortedHeaderSize+sortedFooterSize {
+		return false
+	}
+	return bytes.Equal(in[:len(sortedMagic)], sortedMagic) && bytes.Equal(in[len(in)-len(sortedMagic):], sortedMagic)
+}
+
+// HasSortedHeader returns true if `prefix`, the first bytes of some data, starts like
+// data written in the sorted format. NewSortedReader validates the rest of it.
+func HasSortedHeader(prefix []byte) bool {
+	return len(prefix) >= sortedHeaderSize && bytes.Equal(prefix[:len(sortedMagic)], sortedMagic)
+}
+
+// Sorted is a Marshaller for the sorted format. It fully decodes the data on Unmarshal,
+// use a `SortedReader` to access it lazily.
+type Sorted struct{}
+
+func (s *Sorted) Marshal(data *StoreData) ([]byte, error) {
+	if len(data.DeletePrefixes) != 0 || len(data.DeleteRanges) != 0 {
+		return nil, fmt.Errorf("sorted format does not support delete prefixes or delete ranges")
+	}
+
+	keys := make([]string, 0, len(data.Kv))
+	for k := range data.Kv {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	buf := bytes.NewBuffer(nil)
+	w := NewSortedWriter(buf)
+	for _, k := range keys {
+		if err := w.Add(k, data.Kv[k]); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *Sorted) Unmarshal(in []byte) (*StoreData, uint64, error) {
+	r, err := NewSortedReader(bytes.NewReader(in), int64(len(in)))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	kv := make(map[string][]byte, r.Length())
+	if err := r.Iter(func(key string, value []byte) error {
+		kv[key] = value
+		return nil
+	}); err != nil {
+		return nil, 0, err
+	}
+	return &StoreData{Kv: kv}, r.DataSize(), nil
+}
+
+type sortedBlockHandle struct {
+	firstKey string
+	offset   uint64
+	length   uint64
+}
+
+// SortedWriter streams entries, which must be added in strictly increasing key order,
+// to `w` in the sorted format.
+type SortedWriter struct {
+	w io.Writer
+
+	offset   uint64
+	block    []byte
+	index    []sortedBlockHandle
+	lastKey  string
+	keyCount uint64
+	dataSize uint64
+	err      error
+}
+
+func NewSortedWriter(w io.Writer) *SortedWriter {
+	sw := &SortedWriter{w: w}
+	sw.write(append(append([]byte{}, sortedMagic...), sortedVersion))
+	return sw
+}
+
+func (w *SortedWriter) Add(key string, value []byte) error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.keyCount != 0 && key <= w.lastKey {
+		return fmt.Errorf("key %q added after %q, keys must be strictly increasing", key, w.lastKey)
+	}
+
+	if len(w.block) == 0 {
+		w.index = append(w.index, sortedBlockHandle{firstKey: key, offset: w.offset})
+	}
+	w.block = binary.AppendUvarint(w.block, uint64(len(key)))
+	w.block = append(w.block, key...)
+	w.block = binary.AppendUvarint(w.block, uint64(len(value)))
+	w.block = append(w.block, value...)
+
+	w.lastKey = key
+	w.keyCount++
+	w.dataSize += uint64(len(key) + len(value))
+
+	if len(w.block) >= SortedBlockSize {
+		w.flushBlock()
+	}
+	return w.err
+}
+
+// Close flushes the last block and writes the index and footer. It does not close the
+// underlying writer.
+func (w *SortedWriter) Close() error {
+	w.flushBlock()
+
+	indexOffset := w.offset
+	var index []byte
+	index = binary.AppendUvarint(index, uint64(len(w.index)))
+	for _, handle := range w.index {
+		index = binary.AppendUvarint(index, uint64(len(handle.firstKey)))
+		index = append(index, handle.firstKey...)
+		index = binary.AppendUvarint(index, handle.offset)
+		index = binary.AppendUvarint(index, handle.length)
+	}
+	w.write(index)
+
+	footer := make([]byte, sortedFooterSize)
+	binary.LittleEndian.PutUint64(footer[0:], indexOffset)
+	binary.LittleEndian.PutUint64(footer[8:], w.keyCount)
+	binary.LittleEndian.PutUint64(footer[16:], w.dataSize)
+	copy(footer[24:], sortedMagic)
+	w.write(footer)
+
+	return w.err
+}
+
+func (w *SortedWriter) flushBlock() {
+	if len(w.block) == 0 {
+		return
+	}
+	w.index[len(w.index)-1].length = uint64(len(w.block))
+	w.write(w.block)
+	w.block = w.block[:0]
+}
+
+func (w *SortedWriter) write(data []byte) {
+	if w.err != nil {
+		return
+	}
+	n, err := w.w.Write(data)
+	w.offset += uint64(n)
+	if err != nil {
+		w.err = fmt.Errorf("writing sorted data: %w", err)
+	}
+}
+
+type sortedEntry struct {
+	key   string
+	value []byte
+}
+
+// SortedReader gives access to data in the sorted format, decoding the blocks on demand.
+// Only the index, and a bounded number of decoded blocks, are kept in memory.
+type SortedReader struct {
+	r        io.ReaderAt
+	index    []sortedBlockHandle
+	keyCount uint64
+	dataSize uint64
+
+	cacheLock  sync.Mutex
+	cache      map[int][]sortedEntry
+	cacheOrder []int
+}
+
+func NewSortedReader(r io.ReaderAt, size int64) (*SortedReader, error) {
+	if size < sortedHeaderSize+sortedFooterSize {
+		return nil, fmt.Errorf("sorted data too small: %d bytes", size)
+	}
+
+	header := make([]byte, sortedHeaderSize)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	if !bytes.Equal(header[:len(sortedMagic)], sortedMagic) {
+		return nil, fmt.Errorf("invalid sorted data header")
+	}
+	if header[len(sortedMagic)] != sortedVersion {
+		return nil, fmt.Errorf("unsupported sorted data version %d", header[len(sortedMagic)])
+	}
+
+	footer := make([]byte, sortedFooterSize)
+	if _, err := r.ReadAt(footer, size-sortedFooterSize); err != nil {
+		return nil, fmt.Errorf("reading footer: %w", err)
+	}
+	if !bytes.Equal(footer[24:], sortedMagic) {
+		return nil, fmt.Errorf("invalid sorted data footer")
+	}
+
+	indexOffset := binary.LittleEndian.Uint64(footer[0:])
+	indexEnd := uint64(size - sortedFooterSize)
+	if indexOffset < sortedHeaderSize || indexOffset > indexEnd {
+		return nil, fmt.Errorf("invalid index offset %d", indexOffset)
+	}
+	rawIndex := make([]byte, indexEnd-indexOffset)
+	if _, err := r.ReadAt(rawIndex, int64(indexOffset)); err != nil {
+		return nil, fmt.Errorf("reading index: %w", err)
+	}
+	index, err := readSortedIndex(rawIndex, indexOffset)
+	if err != nil {
+		return nil, fmt.Errorf("reading index: %w", err)
+	}
+
+	return &SortedReader{
+		r:        r,
+		index:    index,
+		keyCount: binary.LittleEndian.Uint64(footer[8:]),
+		dataSize: binary.LittleEndian.Uint64(footer[16:]),
+		cache:    make(map[int][]sortedEntry),
+	}, nil
+}
+
+func readSortedIndex(in []byte, indexOffset uint64) ([]sortedBlockHandle, error) {
+	cursor := in
+	readUvarint := func() (uint64, error) {
+		v, n := binary.Uvarint(cursor)
+		if n <= 0 {
+			return 0, fmt.Errorf("invalid uvarint")
+		}
+		cursor = cursor[n:]
+		return v, nil
+	}
+
+	count, err := readUvarint()
+	if err != nil {
+		return nil, err
+	}
+	if count > uint64(len(cursor)) {
+		return nil, fmt.Errorf("invalid block count %d", count)
+	}
+
+	index := make([]sortedBlockHandle, count)
+	for i := range index {
+		keyLen, err := readUvarint()
+		if err != nil {
+			return nil, err
+		}
+		if keyLen > uint64(len(cursor)) {
+			return nil, fmt.Errorf("accessing key out of bytes slice")
+		}
+		index[i].firstKey = string(cursor[:keyLen])
+		cursor = cursor[keyLen:]
+
+		if index[i].offset, err = readUvarint(); err != nil {
+			return nil, err
+		}
+		if index[i].length, err = readUvarint(); err != nil {
+			return nil, err
+		}
+		if index[i].offset+index[i].length > indexOffset {
+			return nil, fmt.Errorf("block %d out of data bounds", i)
+		}
+	}
+	return index, nil
+}
+
+// Close closes the underlying reader when it is an io.Closer.
+func (r *SortedReader) Close() error {
+	if closer, ok := r.r.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Length is the number of keys in the data.
+func (r *SortedReader) Length() uint64 { return r.keyCount }
+
+// DataSize is the total size of keys and values in the data.
+func (r *SortedReader) DataSize() uint64 { return r.dataSize }
+
+func (r *SortedReader) Get(key string) ([]byte, bool, error) {
+	blockIdx := r.blockFor(key)
+	if blockIdx < 0 {
+		return nil, false, nil
+	}
+
+	entries, err := r.block(blockIdx)
+	if err != nil {
+		return nil, false, err
+	}
+	i := sort.Search(len(entries), func(i int) bool { return entries[i].key >= key })
+	if i < len(entries) && entries[i].key == key {
+		return entries[i].value, true, nil
+	}
+	return nil, false, nil
+}
+
+// Iter calls `f` for all entries, in lexicographical key order.
+func (r *SortedReader) Iter(f func(key string, value []byte) error) error {
+	return r.IterFrom("", f)
+}
+
+// IterFrom calls `f`, in lexicographical key order, for all entries with a key greater
+// or equal to `lowKey`. An error returned by `f` stops the iteration and is returned as is.
+func (r *SortedReader) IterFrom(lowKey string, f func(key string, value []byte) error) error {
+	start := r.blockFor(lowKey)
+	if start < 0 {
+		start = 0
+	}
+
+	for blockIdx := start; blockIdx < len(r.index); blockIdx++ {
+		entries, err := r.block(blockIdx)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if entry.key < lowKey {
+				continue
+			}
+			if err := f(entry.key, entry.value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// blockFor returns the index of the only block that can contain `key`, or -1
+// if `key` is lower than all the keys of the data.
+func (r *SortedReader) blockFor(key string) int {
+	return sort.Search(len(r.index), func(i int) bool { return r.index[i].firstKey > key }) - 1
+}
+
+func (r *SortedReader) block(blockIdx int) ([]sortedEntry, error) {
+	r.cacheLock.Lock()
+	defer r.cacheLock.Unlock()
+
+	if entries, found := r.cache[blockIdx]; found {
+		return entries, nil
+	}
+
+	handle := r.index[blockIdx]
+	raw := make([]byte, handle.length)
+	if _, err := r.r.ReadAt(raw, int64(handle.offset)); err != nil {
+		return nil, fmt.Errorf("reading block %d: %w", blockIdx, err)
+	}
+	entries, err := readSortedBlock(raw)
+	if err != nil {
+		return nil, fmt.Errorf("decoding block %d: %w", blockIdx, err)
+	}
+
+	if len(r.cacheOrder) >= sortedCachedBlocks {
+		delete(r.cache, r.cacheOrder[0])
+		r.cacheOrder = r.cacheOrder[1:]
+	}
+	r.cache[blockIdx] = entries
+	r.cacheOrder = append(r.cacheOrder, blockIdx)
+
+	return entries, nil
+}
+
+func readSortedBlock(in []byte) (out []sortedEntry, err error) {
+	cursor := in
+	for len(cursor) > 0 {
+		keyLen, n := binary.Uvarint(cursor)
+		if n <= 0 {
+			return nil, fmt.Errorf("no bytes to read from cursor for key")
+		}
+		cursor = cursor[n:]
+		if uint64(len(cursor)) < keyLen {
+			return nil, fmt.Errorf("accessing key out of bytes slice")
+		}
+		key := unsafeGetString(cursor[:keyLen])
+		cursor = cursor[keyLen:]
+
+		valueLen, n := binary.Uvarint(cursor)
+		if n <= 0 {
+			return nil, fmt.Errorf("no bytes to read from cursor for value")
+		}
+		cursor = cursor[n:]
+		if uint64(len(cursor)) < valueLen {
+			return nil, fmt.Errorf("accessing value out of bytes slice")
+		}
+		out = append(out, sortedEntry{key: key, value: cursor[:valueLen:valueLen]})
+		cursor = cursor[valueLen:]
+	}
+	return out, nil
+}
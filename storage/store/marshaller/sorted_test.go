@@ -0,0 +1,96 @@
+package marshaller
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSorted_MarshalUnmarshal(t *testing.T) {
+	data := &StoreData{Kv: map[string][]byte{}}
+	for i := 0; i < 20_000; i++ {
+		data.Kv[fmt.Sprintf("key:%05d", i)] = []byte(fmt.Sprintf("value:%d", i))
+	}
+
+	s := &Sorted{}
+	content, err := s.Marshal(data)
+	require.NoError(t, err)
+	require.True(t, IsSorted(content))
+
+	out, size, err := s.Unmarshal(content)
+	require.NoError(t, err)
+	assert.Equal(t, data, out)
+
+	var expectedSize uint64
+	for k, v := range data.Kv {
+		expectedSize += uint64(len(k) + len(v))
+	}
+	assert.Equal(t, expectedSize, size)
+
+	_, err = s.Marshal(&StoreData{DeletePrefixes: []string{"a"}})
+	require.Error(t, err)
+}
+
+func TestSortedReader(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	w := NewSortedWriter(buf)
+	for i := 0; i < 20_000; i += 2 {
+		require.NoError(t, w.Add(fmt.Sprintf("key:%05d", i), []byte(fmt.Sprintf("value:%d", i))))
+	}
+	require.Error(t, w.Add("key:00000", nil))
+	require.NoError(t, w.Close())
+
+	content := buf.Bytes()
+	r, err := NewSortedReader(bytes.NewReader(content), int64(len(content)))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10_000), r.Length())
+	assert.Greater(t, len(r.index), 1)
+
+	tests := []struct {
+		key           string
+		expectFound   bool
+		expectedValue string
+	}{
+		{"key:00000", true, "value:0"},
+		{"key:00001", false, ""},
+		{"key:12346", true, "value:12346"},
+		{"key:19998", true, "value:19998"},
+		{"key:19999", false, ""},
+		{"a", false, ""},
+		{"z", false, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.key, func(t *testing.T) {
+			val, found, err := r.Get(test.key)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectFound, found)
+			assert.Equal(t, test.expectedValue, string(val))
+		})
+	}
+
+	var keys []string
+	errDone := fmt.Errorf("done")
+	err = r.IterFrom("key:15001", func(key string, value []byte) error {
+		if len(keys) == 3 {
+			return errDone
+		}
+		keys = append(keys, key)
+		return nil
+	})
+	require.Equal(t, errDone, err)
+	assert.Equal(t, []string{"key:15002", "key:15004", "key:15006"}, keys)
+}
+
+func TestSortedReader_Invalid(t *testing.T) {
+	assert.False(t, IsSorted([]byte("sfkv")))
+
+	content, err := (&VTproto{}).Marshal(&StoreData{Kv: map[string][]byte{"a": {0xaa}}})
+	require.NoError(t, err)
+	assert.False(t, IsSorted(content))
+
+	_, err = NewSortedReader(bytes.NewReader(content), int64(len(content)))
+	require.Error(t, err)
+}
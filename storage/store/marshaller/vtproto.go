@@ -16,15 +16,19 @@ func (p *VTproto) Unmarshal(in []byte) (*StoreData, uint64, error) {
 		return nil, 0, fmt.Errorf("unmarshal store: %w", err)
 	}
 	return &StoreData{
-		Kv:             stateData.GetKv(),
-		DeletePrefixes: stateData.GetDeletePrefixes(),
+		Kv:                stateData.GetKv(),
+		DeletePrefixes:    stateData.GetDeletePrefixes(),
+		DeleteRanges:      deleteRangesFromProto(stateData.GetDeleteRanges()),
+		LastWrittenBlocks: stateData.GetLastWrittenBlocks(),
 	}, dataSize, nil
 }
 
 func (p *VTproto) Marshal(data *StoreData) ([]byte, error) {
 	stateData := &pbstore.StoreData{
-		Kv:             data.Kv,
-		DeletePrefixes: data.DeletePrefixes,
+		Kv:                data.Kv,
+		DeletePrefixes:    data.DeletePrefixes,
+		DeleteRanges:      deleteRangesToProto(data.DeleteRanges),
+		LastWrittenBlocks: data.LastWrittenBlocks,
 	}
 
 	return stateData.MarshalVT()
@@ -236,6 +240,154 @@ func unmarshalVT(m *pbstore.StoreData, dAtA []byte) (dataSize uint64, err error)
 			//m.DeletePrefixes = append(m.DeletePrefixes, string(dAtA[iNdEx:postIndex]))
 			m.DeletePrefixes = append(m.DeletePrefixes, unsafeGetString(dAtA[iNdEx:postIndex]))
 			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return 0, fmt.Errorf("proto: wrong wireType = %d for field DeleteRanges", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, pbstore.ErrIntOverflow
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return 0, pbstore.ErrInvalidLength
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return 0, pbstore.ErrInvalidLength
+			}
+			if postIndex > l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			deleteRange := &pbstore.DeleteRange{}
+			if err := deleteRange.UnmarshalVT(dAtA[iNdEx:postIndex]); err != nil {
+				return 0, err
+			}
+			m.DeleteRanges = append(m.DeleteRanges, deleteRange)
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return 0, fmt.Errorf("proto: wrong wireType = %d for field LastWrittenBlocks", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, pbstore.ErrIntOverflow
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return 0, pbstore.ErrInvalidLength
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return 0, pbstore.ErrInvalidLength
+			}
+			if postIndex > l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			if m.LastWrittenBlocks == nil {
+				m.LastWrittenBlocks = make(map[string]uint64)
+			}
+			var mapkey string
+			var mapvalue uint64
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return 0, pbstore.ErrIntOverflow
+					}
+					if iNdEx >= l {
+						return 0, io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return 0, pbstore.ErrIntOverflow
+						}
+						if iNdEx >= l {
+							return 0, io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return 0, pbstore.ErrInvalidLength
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey < 0 {
+						return 0, pbstore.ErrInvalidLength
+					}
+					if postStringIndexmapkey > l {
+						return 0, io.ErrUnexpectedEOF
+					}
+					mapkey = unsafeGetString(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return 0, pbstore.ErrIntOverflow
+						}
+						if iNdEx >= l {
+							return 0, io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						mapvalue |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skip(dAtA[iNdEx:])
+					if err != nil {
+						return 0, err
+					}
+					if (skippy < 0) || (iNdEx+skippy) < 0 {
+						return 0, pbstore.ErrInvalidLength
+					}
+					if (iNdEx + skippy) > postIndex {
+						return 0, io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.LastWrittenBlocks[mapkey] = mapvalue
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skip(dAtA[iNdEx:])
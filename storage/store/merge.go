@@ -3,6 +3,7 @@ package store
 import (
 	"fmt"
 	"math/big"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -15,23 +16,23 @@ import (
 )
 
 func (b *baseStore) setKV(k string, v []byte) {
-	if prev, ok := b.kv[k]; ok {
+	if prev, ok := b.getKV(k); ok {
 		b.totalSizeBytes -= uint64(len(prev))
 	} else {
 		b.totalSizeBytes += uint64(len(k))
 	}
 	b.totalSizeBytes += uint64(len(v))
-	b.kv[k] = v
+	b.putKV(k, v)
 }
 
 func (b *baseStore) setNewKV(k string, v []byte) {
 	b.totalSizeBytes += uint64(len(k) + len(v))
-	b.kv[k] = v
+	b.putKV(k, v)
 }
 
 // Merge nextStore _into_ `s`, where nextStore is for the next contiguous segment's store output.
 func (b *baseStore) Merge(kvPartialStore *PartialKV) error {
-	b.logger.Debug("merging store", zap.Int("current_key_count", len(b.kv)), zap.Uint64("mod_init_block", b.moduleInitialBlock), zap.Int("partial_key_count", len(kvPartialStore.kv)), zap.Uint64("partial_start_block", kvPartialStore.initialBlock))
+	b.logger.Debug("merging store", zap.Int("current_key_count", b.keyCount()), zap.Uint64("mod_init_block", b.moduleInitialBlock), zap.Int("partial_key_count", len(kvPartialStore.kv)), zap.Uint64("partial_start_block", kvPartialStore.initialBlock))
 
 	if kvPartialStore.updatePolicy != b.updatePolicy {
 		return fmt.Errorf("incompatible update policies: policy %q cannot merge policy %q", b.updatePolicy, kvPartialStore.updatePolicy)
@@ -42,29 +43,33 @@ func (b *baseStore) Merge(kvPartialStore *PartialKV) error {
 	}
 
 	partialKvTime := time.Now()
-	for _, prefix := range kvPartialStore.DeletedPrefixes {
-		b.DeletePrefix(kvPartialStore.lastOrdinal, prefix)
+	if err := b.replayDeletions(kvPartialStore); err != nil {
+		return fmt.Errorf("replaying deletions: %w", err)
 	}
-	if len(kvPartialStore.DeletedPrefixes) > 0 {
-		b.logger.Debug("merging: applied delete prefixes", zap.Duration("duration", time.Since(partialKvTime)))
+	if len(kvPartialStore.DeletedPrefixes) > 0 || len(kvPartialStore.DeletedRanges) > 0 {
+		b.logger.Debug("merging: applied delete prefixes and ranges", zap.Duration("duration", time.Since(partialKvTime)))
 	}
 
+	keys := b.mergeOrder(kvPartialStore)
 	intoValueTypeLower := strings.ToLower(b.valueType)
 
 	switch b.updatePolicy {
 	case pbsubstreams.Module_KindStore_UPDATE_POLICY_SET:
-		for k, v := range kvPartialStore.kv {
+		for _, k := range keys {
+			v := kvPartialStore.kv[k]
 			b.setKV(k, v)
 		}
 	case pbsubstreams.Module_KindStore_UPDATE_POLICY_SET_IF_NOT_EXISTS:
-		for k, v := range kvPartialStore.kv {
-			if _, found := b.kv[k]; !found {
+		for _, k := range keys {
+			v := kvPartialStore.kv[k]
+			if _, found := b.getKV(k); !found {
 				b.setNewKV(k, v)
 			}
 		}
 	case pbsubstreams.Module_KindStore_UPDATE_POLICY_APPEND:
-		for k, v := range kvPartialStore.kv {
-			if prevVal, found := b.kv[k]; found {
+		for _, k := range keys {
+			v := kvPartialStore.kv[k]
+			if prevVal, found := b.getKV(k); found {
 				newLen := len(prevVal) + len(v)
 				if b.appendLimit > 0 && uint64(newLen) >= b.appendLimit {
 					return fmt.Errorf("append would exceed limit of %d bytes", b.appendLimit)
@@ -85,8 +90,9 @@ func (b *baseStore) Merge(kvPartialStore *PartialKV) error {
 			sum := func(a, b int64) int64 {
 				return a + b
 			}
-			for k, v := range kvPartialStore.kv {
-				v0b, fv0 := b.kv[k]
+			for _, k := range keys {
+				v := kvPartialStore.kv[k]
+				v0b, fv0 := b.getKV(k)
 				v0 := foundOrZeroInt64(v0b, fv0)
 				v1 := foundOrZeroInt64(v, true)
 				b.setKV(k, []byte(fmt.Sprintf("%d", sum(v0, v1))))
@@ -95,8 +101,9 @@ func (b *baseStore) Merge(kvPartialStore *PartialKV) error {
 			sum := func(a, b float64) float64 {
 				return a + b
 			}
-			for k, v := range kvPartialStore.kv {
-				v0b, fv0 := b.kv[k]
+			for _, k := range keys {
+				v := kvPartialStore.kv[k]
+				v0b, fv0 := b.getKV(k)
 				v0 := foundOrZeroFloat(v0b, fv0)
 				v1 := foundOrZeroFloat(v, true)
 				b.setKV(k, floatToBytes(sum(v0, v1)))
@@ -105,8 +112,9 @@ func (b *baseStore) Merge(kvPartialStore *PartialKV) error {
 			sum := func(a, b *big.Int) *big.Int {
 				return new(big.Int).Add(a, b)
 			}
-			for k, v := range kvPartialStore.kv {
-				v0b, fv0 := b.kv[k]
+			for _, k := range keys {
+				v := kvPartialStore.kv[k]
+				v0b, fv0 := b.getKV(k)
 				v0 := foundOrZeroBigInt(v0b, fv0)
 				v1 := foundOrZeroBigInt(v, true)
 				b.setKV(k, []byte(fmt.Sprintf("%d", sum(v0, v1))))
@@ -114,8 +122,9 @@ func (b *baseStore) Merge(kvPartialStore *PartialKV) error {
 		case manifest.OutputValueTypeBigFloat:
 			fallthrough
 		case manifest.OutputValueTypeBigDecimal:
-			for k, v := range kvPartialStore.kv {
-				v0b, fv0 := b.kv[k]
+			for _, k := range keys {
+				v := kvPartialStore.kv[k]
+				v0b, fv0 := b.getKV(k)
 				v0 := foundOrZeroBigDecimal(v0b, fv0)
 				v1 := foundOrZeroBigDecimal(v, true)
 				b.setKV(k, []byte(v0.Add(v1).String()))
@@ -132,9 +141,10 @@ func (b *baseStore) Merge(kvPartialStore *PartialKV) error {
 				}
 				return b
 			}
-			for k, v := range kvPartialStore.kv {
+			for _, k := range keys {
+				v := kvPartialStore.kv[k]
 				v1 := foundOrZeroInt64(v, true)
-				v, found := b.kv[k]
+				v, found := b.getKV(k)
 				if !found {
 					b.setNewKV(k, []byte(fmt.Sprintf("%d", v1)))
 					continue
@@ -150,9 +160,10 @@ func (b *baseStore) Merge(kvPartialStore *PartialKV) error {
 				}
 				return a
 			}
-			for k, v := range kvPartialStore.kv {
+			for _, k := range keys {
+				v := kvPartialStore.kv[k]
 				v1 := foundOrZeroFloat(v, true)
-				v, found := b.kv[k]
+				v, found := b.getKV(k)
 				if !found {
 					b.setNewKV(k, floatToBytes(v1))
 					continue
@@ -168,9 +179,10 @@ func (b *baseStore) Merge(kvPartialStore *PartialKV) error {
 				}
 				return a
 			}
-			for k, v := range kvPartialStore.kv {
+			for _, k := range keys {
+				v := kvPartialStore.kv[k]
 				v1 := foundOrZeroBigInt(v, true)
-				v, found := b.kv[k]
+				v, found := b.getKV(k)
 				if !found {
 					b.setNewKV(k, []byte(v1.String()))
 					continue
@@ -188,9 +200,10 @@ func (b *baseStore) Merge(kvPartialStore *PartialKV) error {
 				}
 				return a
 			}
-			for k, v := range kvPartialStore.kv {
+			for _, k := range keys {
+				v := kvPartialStore.kv[k]
 				v1 := foundOrZeroBigDecimal(v, true)
-				v, found := b.kv[k]
+				v, found := b.getKV(k)
 				if !found {
 					b.setNewKV(k, []byte(v1.String()))
 					continue
@@ -211,9 +224,10 @@ func (b *baseStore) Merge(kvPartialStore *PartialKV) error {
 				}
 				return b
 			}
-			for k, v := range kvPartialStore.kv {
+			for _, k := range keys {
+				v := kvPartialStore.kv[k]
 				v1 := foundOrZeroInt64(v, true)
-				v, found := b.kv[k]
+				v, found := b.getKV(k)
 				if !found {
 					b.setNewKV(k, []byte(fmt.Sprintf("%d", v1)))
 					continue
@@ -229,9 +243,10 @@ func (b *baseStore) Merge(kvPartialStore *PartialKV) error {
 				}
 				return b
 			}
-			for k, v := range kvPartialStore.kv {
+			for _, k := range keys {
+				v := kvPartialStore.kv[k]
 				v1 := foundOrZeroFloat(v, true)
-				v, found := b.kv[k]
+				v, found := b.getKV(k)
 				if !found {
 					b.setNewKV(k, floatToBytes(v1))
 					continue
@@ -247,9 +262,10 @@ func (b *baseStore) Merge(kvPartialStore *PartialKV) error {
 				}
 				return b
 			}
-			for k, v := range kvPartialStore.kv {
+			for _, k := range keys {
+				v := kvPartialStore.kv[k]
 				v1 := foundOrZeroBigInt(v, true)
-				v, found := b.kv[k]
+				v, found := b.getKV(k)
 				if !found {
 					b.setNewKV(k, []byte(v1.String()))
 					continue
@@ -267,9 +283,10 @@ func (b *baseStore) Merge(kvPartialStore *PartialKV) error {
 				}
 				return b
 			}
-			for k, v := range kvPartialStore.kv {
+			for _, k := range keys {
+				v := kvPartialStore.kv[k]
 				v1 := foundOrZeroBigDecimal(v, true)
-				v, found := b.kv[k]
+				v, found := b.getKV(k)
 				if !found {
 					b.setNewKV(k, []byte(v1.String()))
 					continue
@@ -280,14 +297,120 @@ func (b *baseStore) Merge(kvPartialStore *PartialKV) error {
 		default:
 			return fmt.Errorf("update policy %q not supported for value type %q", b.updatePolicy, b.valueType)
 		}
+	case pbsubstreams.Module_KindStore_UPDATE_POLICY_SET_SUM:
+		add, err := setSumAdderForValueType(intoValueTypeLower)
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			v := kvPartialStore.kv[k]
+			prevVal, found := b.getKV(k)
+			if !found {
+				b.setNewKV(k, v)
+				continue
+			}
+			nextVal, err := mergeSetSum(prevVal, v, add)
+			if err != nil {
+				return fmt.Errorf("merging key %q: %w", k, err)
+			}
+			b.setKV(k, nextVal)
+		}
+	case pbsubstreams.Module_KindStore_UPDATE_POLICY_BITWISE_OR, pbsubstreams.Module_KindStore_UPDATE_POLICY_BITWISE_AND:
+		isOr := b.updatePolicy == pbsubstreams.Module_KindStore_UPDATE_POLICY_BITWISE_OR
+		var merge func(prev, next []byte) ([]byte, error)
+		switch intoValueTypeLower {
+		case manifest.OutputValueTypeInt64:
+			op := func(a, b int64) int64 { return a & b }
+			if isOr {
+				op = func(a, b int64) int64 { return a | b }
+			}
+			merge = func(prev, next []byte) ([]byte, error) { return mergeBitwiseInt64(prev, next, op) }
+		case manifest.OutputValueTypeBigInt:
+			op := func(a, b *big.Int) *big.Int { return new(big.Int).And(a, b) }
+			if isOr {
+				op = func(a, b *big.Int) *big.Int { return new(big.Int).Or(a, b) }
+			}
+			merge = func(prev, next []byte) ([]byte, error) { return mergeBitwiseBigInt(prev, next, op) }
+		default:
+			return fmt.Errorf("update policy %q not supported for value type %q", b.updatePolicy, b.valueType)
+		}
+		for _, k := range keys {
+			v := kvPartialStore.kv[k]
+			prevVal, found := b.getKV(k)
+			if !found {
+				b.setNewKV(k, v)
+				continue
+			}
+			nextVal, err := merge(prevVal, v)
+			if err != nil {
+				return fmt.Errorf("merging key %q: %w", k, err)
+			}
+			b.setKV(k, nextVal)
+		}
+	case pbsubstreams.Module_KindStore_UPDATE_POLICY_CARDINALITY:
+		for _, k := range keys {
+			v := kvPartialStore.kv[k]
+			prevVal, found := b.getKV(k)
+			if !found {
+				b.setNewKV(k, v)
+				continue
+			}
+			nextVal, err := mergeCardinalitySketches(prevVal, v)
+			if err != nil {
+				return fmt.Errorf("merging key %q: %w", k, err)
+			}
+			b.setKV(k, nextVal)
+		}
 	default:
 		return fmt.Errorf("update policy %q not supported", b.updatePolicy) // should have been validated already
 	}
 
+	if err := b.Err(); err != nil {
+		return err
+	}
+	b.mergeLastWrittenBlocks(kvPartialStore)
+
 	b.Reset() // Merge should never keep deltas or ordinals
 	return nil
 }
 
+// mergeOrder returns the keys of the partial in the order to merge them. With a store
+// loaded from a sorted snapshot, they are sorted so that merging reads the snapshot
+// blocks sequentially, each block being decoded once.
+func (b *baseStore) mergeOrder(kvPartialStore *PartialKV) []string {
+	keys := make([]string, 0, len(kvPartialStore.kv))
+	for k := range kvPartialStore.kv {
+		keys = append(keys, k)
+	}
+	if b.lazy != nil {
+		sort.Strings(keys)
+	}
+	return keys
+}
+
+// replayDeletions applies the prefix and range deletions of the partial in the
+// order they were made, against the values the keys had before its segment.
+func (b *baseStore) replayDeletions(kvPartialStore *PartialKV) error {
+	ranges := kvPartialStore.DeletedRanges
+	for i, prefix := range kvPartialStore.DeletedPrefixes {
+		for len(ranges) > 0 && ranges[0].PrefixCount <= uint64(i) {
+			if err := b.replayDeletedRange(kvPartialStore.lastOrdinal, ranges[0]); err != nil {
+				return err
+			}
+			ranges = ranges[1:]
+		}
+		if err := b.DeletePrefix(kvPartialStore.lastOrdinal, prefix); err != nil {
+			return err
+		}
+	}
+	for _, deletedRange := range ranges {
+		if err := b.replayDeletedRange(kvPartialStore.lastOrdinal, deletedRange); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func foundOrZeroInt64(in []byte, found bool) int64 {
 	if !found {
 		return 0
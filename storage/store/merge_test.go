@@ -1,6 +1,7 @@
 package store
 
 import (
+	"bytes"
 	"testing"
 
 	"go.uber.org/zap"
@@ -8,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/streamingfast/substreams/manifest"
+	"github.com/streamingfast/substreams/storage/store/marshaller"
 
 	"github.com/stretchr/testify/assert"
 
@@ -319,6 +321,92 @@ func TestStore_Merge(t *testing.T) {
 				"three": []byte("30.1"),
 			},
 		},
+		{
+			name: "set_sum_int",
+			latest: newPartialStore(map[string][]byte{
+				"one":   []byte("sum:1"),
+				"two":   []byte("set:2"),
+				"three": []byte("sum:3"),
+			}, pbsubstreams.Module_KindStore_UPDATE_POLICY_SET_SUM, manifest.OutputValueTypeInt64, nil),
+			prev: newStore(map[string][]byte{
+				"one":  []byte("set:10"),
+				"two":  []byte("sum:20"),
+				"four": []byte("sum:40"),
+			}, pbsubstreams.Module_KindStore_UPDATE_POLICY_SET_SUM, manifest.OutputValueTypeInt64),
+			expectedError: false,
+			expectedKV: map[string][]byte{
+				"one":   []byte("set:11"),
+				"two":   []byte("set:2"),
+				"three": []byte("sum:3"),
+				"four":  []byte("sum:40"),
+			},
+		},
+		{
+			name: "set_sum_float",
+			latest: newPartialStore(map[string][]byte{
+				"one": []byte("sum:1.5"),
+			}, pbsubstreams.Module_KindStore_UPDATE_POLICY_SET_SUM, manifest.OutputValueTypeFloat64, nil),
+			prev: newStore(map[string][]byte{
+				"one": []byte("set:10.25"),
+			}, pbsubstreams.Module_KindStore_UPDATE_POLICY_SET_SUM, manifest.OutputValueTypeFloat64),
+			expectedError: false,
+			expectedKV: map[string][]byte{
+				"one": []byte("set:11.75"),
+			},
+		},
+		{
+			name: "set_sum invalid value",
+			latest: newPartialStore(map[string][]byte{
+				"one": []byte("1"),
+			}, pbsubstreams.Module_KindStore_UPDATE_POLICY_SET_SUM, manifest.OutputValueTypeInt64, nil),
+			prev: newStore(map[string][]byte{
+				"one": []byte("set:10"),
+			}, pbsubstreams.Module_KindStore_UPDATE_POLICY_SET_SUM, manifest.OutputValueTypeInt64),
+			expectedError: true,
+		},
+		{
+			name: "bitwise_or_int",
+			latest: newPartialStore(map[string][]byte{
+				"one": []byte("5"),
+				"two": []byte("2"),
+			}, pbsubstreams.Module_KindStore_UPDATE_POLICY_BITWISE_OR, manifest.OutputValueTypeInt64, nil),
+			prev: newStore(map[string][]byte{
+				"one":   []byte("10"),
+				"three": []byte("4"),
+			}, pbsubstreams.Module_KindStore_UPDATE_POLICY_BITWISE_OR, manifest.OutputValueTypeInt64),
+			expectedError: false,
+			expectedKV: map[string][]byte{
+				"one":   []byte("15"),
+				"two":   []byte("2"),
+				"three": []byte("4"),
+			},
+		},
+		{
+			name: "bitwise_and_big_int",
+			latest: newPartialStore(map[string][]byte{
+				"one": []byte("340282366920938463463374607431768211455"),
+				"two": []byte("6"),
+			}, pbsubstreams.Module_KindStore_UPDATE_POLICY_BITWISE_AND, manifest.OutputValueTypeBigInt, nil),
+			prev: newStore(map[string][]byte{
+				"one": []byte("18446744073709551616"),
+				"two": []byte("12"),
+			}, pbsubstreams.Module_KindStore_UPDATE_POLICY_BITWISE_AND, manifest.OutputValueTypeBigInt),
+			expectedError: false,
+			expectedKV: map[string][]byte{
+				"one": []byte("18446744073709551616"),
+				"two": []byte("4"),
+			},
+		},
+		{
+			name: "cardinality invalid sketch",
+			latest: newPartialStore(map[string][]byte{
+				"one": []byte("not a sketch"),
+			}, pbsubstreams.Module_KindStore_UPDATE_POLICY_CARDINALITY, "bytes", nil),
+			prev: newStore(map[string][]byte{
+				"one": newCardinalitySketch(),
+			}, pbsubstreams.Module_KindStore_UPDATE_POLICY_CARDINALITY, "bytes"),
+			expectedError: true,
+		},
 		{
 			name: "delete key prefixes",
 			latest: newPartialStore(
@@ -403,3 +491,146 @@ func newStore(kv map[string][]byte, updatePolicy pbsubstreams.Module_KindStore_U
 	}
 	return &FullKV{baseStore: b}
 }
+
+func TestStore_Merge_DeleteRanges(t *testing.T) {
+	latest := newPartialStore(map[string][]byte{
+		"idx:b": []byte("new"),
+	}, pbsubstreams.Module_KindStore_UPDATE_POLICY_SET, manifest.OutputValueTypeString, nil)
+	latest.DeletedRanges = []*marshaller.DeleteRange{
+		{LowKey: "idx:", HighKey: "idx;", PointerSeparator: ","},
+		{LowKey: "t:2", HighKey: "t:4"},
+	}
+
+	prev := newStore(map[string][]byte{
+		"idx:a": []byte("p:1,p:2"),
+		"idx:c": []byte("p:3"),
+		"p:1":   []byte("one"),
+		"p:2":   []byte("two"),
+		"p:3":   []byte("three"),
+		"p:4":   []byte("four"),
+		"t:1":   []byte("a"),
+		"t:2":   []byte("b"),
+		"t:3":   []byte("c"),
+		"t:4":   []byte("d"),
+	}, pbsubstreams.Module_KindStore_UPDATE_POLICY_SET, manifest.OutputValueTypeString)
+
+	require.NoError(t, prev.Merge(latest))
+	assert.Equal(t, map[string][]byte{
+		"idx:b": []byte("new"),
+		"p:4":   []byte("four"),
+		"t:1":   []byte("a"),
+		"t:4":   []byte("d"),
+	}, prev.kv)
+	assert.Nil(t, prev.deltas, "merge should not keep leftover deltas")
+}
+
+func TestStore_Merge_DeletionsReplayedInOrder(t *testing.T) {
+	type segmentStore interface {
+		UpdateKeySetter
+		Deleter
+	}
+	initialKV := map[string]string{
+		"idx:a": "p:1",
+		"idx:b": "p:3",
+		"p:1":   "one",
+		"p:2":   "two",
+		"p:3":   "three",
+	}
+
+	tests := []struct {
+		name       string
+		segment    func(s segmentStore)
+		expectedKV map[string][]byte
+	}{
+		{
+			name: "pointers of a key written before the deletion",
+			segment: func(s segmentStore) {
+				s.Set(0, "idx:a", "p:2")
+				s.DeleteRangePointers(1, "idx:", "idx;", ",")
+			},
+			expectedKV: map[string][]byte{
+				"p:1": []byte("one"),
+			},
+		},
+		{
+			name: "prefix deleted before the range",
+			segment: func(s segmentStore) {
+				s.DeletePrefix(0, "idx:")
+				s.Set(1, "idx:a", "p:2")
+				s.DeleteRangePointers(2, "idx:", "idx;", ",")
+			},
+			expectedKV: map[string][]byte{
+				"p:1": []byte("one"),
+				"p:3": []byte("three"),
+			},
+		},
+		{
+			name: "range deleted before the prefix",
+			segment: func(s segmentStore) {
+				s.DeleteRangePointers(0, "idx:", "idx;", ",")
+				s.Set(1, "p:1", "new one")
+				s.DeletePrefix(2, "p:")
+				s.Set(3, "p:4", "four")
+			},
+			expectedKV: map[string][]byte{
+				"p:4": []byte("four"),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			newFullKV := func() *FullKV {
+				s := &FullKV{baseStore: newTestBaseStore(t, pbsubstreams.Module_KindStore_UPDATE_POLICY_SET, manifest.OutputValueTypeString, nil)}
+				for k, v := range initialKV {
+					s.Set(0, k, v)
+				}
+				s.Reset()
+				return s
+			}
+
+			linear := newFullKV()
+			test.segment(linear)
+			linear.Reset()
+
+			parallel := newFullKV()
+			partial := parallel.DerivePartialStore(0)
+			test.segment(partial)
+			require.NoError(t, parallel.Merge(partial))
+
+			assert.Equal(t, test.expectedKV, linear.kv)
+			assert.Equal(t, test.expectedKV, parallel.kv)
+		})
+	}
+}
+
+func TestStore_Merge_Lazy(t *testing.T) {
+	content, err := (&marshaller.Sorted{}).Marshal(&marshaller.StoreData{Kv: map[string][]byte{
+		"a": []byte("1"),
+		"b": []byte("2"),
+		"d": []byte("4"),
+	}})
+	require.NoError(t, err)
+	lazy, err := marshaller.NewSortedReader(bytes.NewReader(content), int64(len(content)))
+	require.NoError(t, err)
+
+	prev := newStore(map[string][]byte{}, pbsubstreams.Module_KindStore_UPDATE_POLICY_ADD, manifest.OutputValueTypeInt64)
+	prev.lazy = lazy
+	prev.tombstones = map[string]bool{}
+
+	latest := newPartialStore(map[string][]byte{
+		"d": []byte("10"),
+		"a": []byte("10"),
+		"c": []byte("10"),
+	}, pbsubstreams.Module_KindStore_UPDATE_POLICY_ADD, manifest.OutputValueTypeInt64, []string{"b"})
+
+	require.NoError(t, prev.Merge(latest))
+
+	var merged []string
+	require.NoError(t, prev.iterSortedKV("", nil, func(key string, value []byte) error {
+		merged = append(merged, key+"="+string(value))
+		return nil
+	}))
+	assert.Equal(t, []string{"a=11", "c=10", "d=14"}, merged)
+	assert.Equal(t, 3, prev.keyCount())
+}
@@ -15,14 +15,17 @@ type PartialKV struct {
 
 	initialBlock    uint64 // block at which we initialized this store
 	DeletedPrefixes []string
+	DeletedRanges   []*marshaller.DeleteRange
 
 	loadedFrom string
 	seen       map[string]bool
+	seenRanges map[deletedRangeKey]bool
 }
 
 func (p *PartialKV) Roll(lastBlock uint64) {
 	p.initialBlock = lastBlock
 	p.baseStore.kv = map[string][]byte{}
+	p.baseStore.lastWrittenBlocks = nil
 }
 
 func (p *PartialKV) InitialBlock() uint64 { return p.initialBlock }
@@ -47,6 +50,8 @@ func (p *PartialKV) Load(ctx context.Context, file *FileInfo) error {
 	}
 	p.totalSizeBytes = size
 	p.DeletedPrefixes = storeData.DeletePrefixes
+	p.DeletedRanges = storeData.DeleteRanges
+	p.lastWrittenBlocks = storeData.LastWrittenBlocks
 
 	p.logger.Debug("partial store loaded", zap.String("filename", file.Filename), zap.Int("key_count", len(p.kv)), zap.Uint64("data_size", size))
 	return nil
@@ -56,8 +61,10 @@ func (p *PartialKV) Save(endBoundaryBlock uint64) (*FileInfo, *fileWriter, error
 	p.logger.Debug("writing partial store state", zap.Object("store", p))
 
 	stateData := &marshaller.StoreData{
-		Kv:             p.kv,
-		DeletePrefixes: p.DeletedPrefixes,
+		Kv:                p.kv,
+		DeletePrefixes:    p.DeletedPrefixes,
+		DeleteRanges:      p.DeletedRanges,
+		LastWrittenBlocks: p.lastWrittenBlocks,
 	}
 
 	content, err := p.marshaller.Marshal(stateData)
@@ -69,21 +76,76 @@ func (p *PartialKV) Save(endBoundaryBlock uint64) (*FileInfo, *fileWriter, error
 	p.logger.Debug("partial store save written", zap.String("file_name", file.Filename), zap.Stringer("block_range", file.Range))
 
 	fw := &fileWriter{
-		store:    p.objStore,
-		filename: file.Filename,
-		content:  content,
+		store:       p.objStore,
+		filename:    file.Filename,
+		content:     content,
+		compression: p.compression,
 	}
 
 	return file, fw, nil
 }
 
-func (p *PartialKV) DeletePrefix(ord uint64, prefix string) {
-	p.baseStore.DeletePrefix(ord, prefix)
+func (p *PartialKV) DeletePrefix(ord uint64, prefix string) error {
+	if err := p.baseStore.DeletePrefix(ord, prefix); err != nil {
+		return err
+	}
 
 	if !p.seen[prefix] {
 		p.DeletedPrefixes = append(p.DeletedPrefixes, prefix)
 		p.seen[prefix] = true
 	}
+	return nil
+}
+
+func (p *PartialKV) DeleteRange(ord uint64, lowKey, highKey string) error {
+	if _, _, err := p.baseStore.deleteRange(ord, lowKey, highKey, ""); err != nil {
+		return err
+	}
+	p.recordDeletedRange(lowKey, highKey, "", nil, nil)
+	return nil
+}
+
+// DeleteRangePointers records the keys of the range this partial had written,
+// and the pointers resolved from their values: when merging, the previous store
+// only has the values these keys had before the segment.
+func (p *PartialKV) DeleteRangePointers(ord uint64, lowKey, highKey, pointerSeparator string) error {
+	writtenKeys, pointers, err := p.baseStore.deleteRange(ord, lowKey, highKey, pointerSeparator)
+	if err != nil {
+		return err
+	}
+	p.recordDeletedRange(lowKey, highKey, pointerSeparator, writtenKeys, pointers)
+	return nil
+}
+
+type deletedRangeKey struct {
+	lowKey           string
+	highKey          string
+	pointerSeparator string
+	prefixCount      int
+}
+
+func (p *PartialKV) recordDeletedRange(lowKey, highKey, pointerSeparator string, writtenKeys, pointers []string) {
+	deletedRange := &marshaller.DeleteRange{
+		LowKey:           lowKey,
+		HighKey:          highKey,
+		PointerSeparator: pointerSeparator,
+		PrefixCount:      uint64(len(p.DeletedPrefixes)),
+		WrittenKeys:      writtenKeys,
+		Pointers:         pointers,
+	}
+	if len(writtenKeys) != 0 {
+		p.DeletedRanges = append(p.DeletedRanges, deletedRange)
+		return
+	}
+
+	key := deletedRangeKey{lowKey, highKey, pointerSeparator, len(p.DeletedPrefixes)}
+	if p.seenRanges == nil {
+		p.seenRanges = make(map[deletedRangeKey]bool)
+	}
+	if !p.seenRanges[key] {
+		p.DeletedRanges = append(p.DeletedRanges, deletedRange)
+		p.seenRanges[key] = true
+	}
 }
 
 func (p *PartialKV) DeleteStore(ctx context.Context, file *FileInfo) (err error) {
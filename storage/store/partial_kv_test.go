@@ -60,3 +60,16 @@ func TestPartialKV_Save_Load_Empty_MapNotNil(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNilf(t, kvl.kv, "kvl.kv is nil")
 }
+
+func TestPartialKV_DeleteRange_Recorded(t *testing.T) {
+	kvs := (&Config{totalSizeLimit: 9999, itemSizeLimit: 9999}).NewPartialKV(0, zap.NewNop())
+
+	kvs.DeleteRange(0, "a", "b")
+	kvs.DeleteRangePointers(1, "c", "d", ",")
+	kvs.DeleteRange(2, "a", "b")
+
+	require.Equal(t, []*marshaller.DeleteRange{
+		{LowKey: "a", HighKey: "b"},
+		{LowKey: "c", HighKey: "d", PointerSeparator: ","},
+	}, kvs.DeletedRanges)
+}
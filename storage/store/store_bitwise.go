@@ -0,0 +1,93 @@
+package store
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+func (b *baseStore) BitwiseOrInt64(ord uint64, key string, value int64) error {
+	return b.bitwiseInt64(ord, key, value, func(a, b int64) int64 { return a | b })
+}
+
+func (b *baseStore) BitwiseAndInt64(ord uint64, key string, value int64) error {
+	return b.bitwiseInt64(ord, key, value, func(a, b int64) int64 { return a & b })
+}
+
+func (b *baseStore) BitwiseOrBigInt(ord uint64, key string, value *big.Int) error {
+	return b.bitwiseBigInt(ord, key, value, func(a, b *big.Int) *big.Int { return new(big.Int).Or(a, b) })
+}
+
+func (b *baseStore) BitwiseAndBigInt(ord uint64, key string, value *big.Int) error {
+	return b.bitwiseBigInt(ord, key, value, func(a, b *big.Int) *big.Int { return new(big.Int).And(a, b) })
+}
+
+// bitwiseInt64 combines `value` with the previous value of `key` using `op`. A missing key
+// takes `value` as is, so that an AND starts from all the bits set.
+func (b *baseStore) bitwiseInt64(ord uint64, key string, value int64, op func(a, b int64) int64) error {
+	out := value
+	if val, found := b.GetAt(ord, key); found {
+		prev, err := parseBitwiseInt64(val)
+		if err != nil {
+			return fmt.Errorf("key %q: %w", key, err)
+		}
+		out = op(prev, value)
+	}
+	b.set(ord, key, []byte(strconv.FormatInt(out, 10)))
+	return nil
+}
+
+func (b *baseStore) bitwiseBigInt(ord uint64, key string, value *big.Int, op func(a, b *big.Int) *big.Int) error {
+	out := value
+	if val, found := b.GetAt(ord, key); found {
+		prev, err := parseBitwiseBigInt(val)
+		if err != nil {
+			return fmt.Errorf("key %q: %w", key, err)
+		}
+		out = op(prev, value)
+	}
+	b.set(ord, key, []byte(out.String()))
+	return nil
+}
+
+// mergeBitwiseInt64 is bitwiseInt64 for merging a partial store value `next` on top
+// of `prev`.
+func mergeBitwiseInt64(prev, next []byte, op func(a, b int64) int64) ([]byte, error) {
+	v0, err := parseBitwiseInt64(prev)
+	if err != nil {
+		return nil, err
+	}
+	v1, err := parseBitwiseInt64(next)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strconv.FormatInt(op(v0, v1), 10)), nil
+}
+
+func mergeBitwiseBigInt(prev, next []byte, op func(a, b *big.Int) *big.Int) ([]byte, error) {
+	v0, err := parseBitwiseBigInt(prev)
+	if err != nil {
+		return nil, err
+	}
+	v1, err := parseBitwiseBigInt(next)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(op(v0, v1).String()), nil
+}
+
+func parseBitwiseInt64(val []byte) (int64, error) {
+	out, err := strconv.ParseInt(string(val), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid int64 value %q: %w", val, err)
+	}
+	return out, nil
+}
+
+func parseBitwiseBigInt(val []byte) (*big.Int, error) {
+	out, ok := new(big.Int).SetString(string(val), 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid bigint value %q", val)
+	}
+	return out, nil
+}
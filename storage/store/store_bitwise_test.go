@@ -0,0 +1,55 @@
+package store
+
+import (
+	"math/big"
+	"testing"
+
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreBitwiseInt64(t *testing.T) {
+	b := newTestBaseStore(t, pbsubstreams.Module_KindStore_UPDATE_POLICY_BITWISE_OR, "int64", nil)
+	require.NoError(t, b.BitwiseOrInt64(0, "or", 0b0101))
+	require.NoError(t, b.BitwiseOrInt64(1, "or", 0b1000))
+	require.NoError(t, b.BitwiseAndInt64(2, "and", 0b0111))
+	require.NoError(t, b.BitwiseAndInt64(3, "and", 0b1110))
+
+	actual, found := b.GetLast("or")
+	require.True(t, found)
+	assert.Equal(t, "13", string(actual))
+
+	actual, found = b.GetLast("and")
+	require.True(t, found)
+	assert.Equal(t, "6", string(actual))
+}
+
+func TestStoreBitwiseBigInt(t *testing.T) {
+	b := newTestBaseStore(t, pbsubstreams.Module_KindStore_UPDATE_POLICY_BITWISE_OR, "bigint", nil)
+	high := new(big.Int).Lsh(big.NewInt(1), 100)
+	require.NoError(t, b.BitwiseOrBigInt(0, "or", high))
+	require.NoError(t, b.BitwiseOrBigInt(1, "or", big.NewInt(1)))
+	require.NoError(t, b.BitwiseAndBigInt(2, "and", new(big.Int).Add(high, big.NewInt(3))))
+	require.NoError(t, b.BitwiseAndBigInt(3, "and", new(big.Int).Add(high, big.NewInt(6))))
+
+	actual, found := b.GetLast("or")
+	require.True(t, found)
+	assert.Equal(t, new(big.Int).Add(high, big.NewInt(1)).String(), string(actual))
+
+	actual, found = b.GetLast("and")
+	require.True(t, found)
+	assert.Equal(t, new(big.Int).Add(high, big.NewInt(2)).String(), string(actual))
+}
+
+func TestStoreBitwise_InvalidPreviousValue(t *testing.T) {
+	b := newTestBaseStore(t, pbsubstreams.Module_KindStore_UPDATE_POLICY_BITWISE_OR, "int64", nil)
+	b.set(0, "key", []byte("not a number"))
+
+	require.Error(t, b.BitwiseOrInt64(1, "key", 1))
+	require.Error(t, b.BitwiseAndBigInt(1, "key", big.NewInt(1)))
+
+	prev := newStore(map[string][]byte{"key": []byte("not a number")}, pbsubstreams.Module_KindStore_UPDATE_POLICY_BITWISE_OR, "int64")
+	latest := newPartialStore(map[string][]byte{"key": []byte("1")}, pbsubstreams.Module_KindStore_UPDATE_POLICY_BITWISE_OR, "int64", nil)
+	require.Error(t, prev.Merge(latest))
+}
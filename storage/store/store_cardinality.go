@@ -0,0 +1,118 @@
+package store
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/bits"
+)
+
+// Values of `cardinality` stores are HyperLogLog sketches: one byte holding the precision
+// `p`, followed by 2^p one-byte registers. Each register holds the maximum rank seen for the
+// items hashed to it, so two sketches merge by keeping the maximum of each register.
+const cardinalityPrecision = 12
+
+func (b *baseStore) CardinalityAdd(ord uint64, key string, item []byte) {
+	sketch, found := b.GetAt(ord, key)
+	if !found {
+		sketch = newCardinalitySketch()
+	} else if err := validateCardinalitySketch(sketch); err != nil {
+		panic(fmt.Errorf("key %q: %w", key, err))
+	}
+
+	p := uint(sketch[0])
+	hash := hashCardinalityItem(item)
+	index := hash >> (64 - p)
+	rank := uint8(bits.LeadingZeros64(hash<<p|1<<(p-1))) + 1
+
+	if sketch[1+index] >= rank {
+		return
+	}
+
+	next := make([]byte, len(sketch))
+	copy(next, sketch)
+	next[1+index] = rank
+	b.set(ord, key, next)
+}
+
+// EstimateCardinality returns the estimated number of distinct items added to the
+// `cardinality` store value `sketch`.
+func EstimateCardinality(sketch []byte) (uint64, error) {
+	if err := validateCardinalitySketch(sketch); err != nil {
+		return 0, err
+	}
+
+	registers := sketch[1:]
+	m := float64(len(registers))
+	sum := 0.0
+	zeros := 0
+	for _, rank := range registers {
+		sum += math.Ldexp(1, -int(rank))
+		if rank == 0 {
+			zeros++
+		}
+	}
+
+	estimate := 0.7213 / (1 + 1.079/m) * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		// Linear counting is more accurate for small cardinalities
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(math.Round(estimate)), nil
+}
+
+func mergeCardinalitySketches(prev, next []byte) ([]byte, error) {
+	if err := validateCardinalitySketch(prev); err != nil {
+		return nil, err
+	}
+	if err := validateCardinalitySketch(next); err != nil {
+		return nil, err
+	}
+	if prev[0] != next[0] {
+		return nil, fmt.Errorf("cannot merge sketches of precision %d and %d", prev[0], next[0])
+	}
+
+	out := make([]byte, len(prev))
+	copy(out, prev)
+	for i := 1; i < len(out); i++ {
+		if next[i] > out[i] {
+			out[i] = next[i]
+		}
+	}
+	return out, nil
+}
+
+func newCardinalitySketch() []byte {
+	sketch := make([]byte, 1+1<<cardinalityPrecision)
+	sketch[0] = cardinalityPrecision
+	return sketch
+}
+
+func validateCardinalitySketch(sketch []byte) error {
+	if len(sketch) == 0 {
+		return fmt.Errorf("invalid cardinality sketch: empty")
+	}
+	p := int(sketch[0])
+	if p < 4 || p > 18 {
+		return fmt.Errorf("invalid cardinality sketch: unsupported precision %d", p)
+	}
+	if len(sketch) != 1+1<<p {
+		return fmt.Errorf("invalid cardinality sketch: expected %d bytes for precision %d, got %d", 1+1<<p, p, len(sketch))
+	}
+	return nil
+}
+
+// hashCardinalityItem must never change, as sketches written to stores depend on it.
+func hashCardinalityItem(item []byte) uint64 {
+	h := fnv.New64a()
+	h.Write(item)
+	x := h.Sum64()
+
+	// murmur3 finalizer, spreading the FNV output over all the bits
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
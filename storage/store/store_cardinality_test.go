@@ -0,0 +1,59 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreCardinality(t *testing.T) {
+	newCardinalityStore := func() *baseStore {
+		b := newTestBaseStore(t, pbsubstreams.Module_KindStore_UPDATE_POLICY_CARDINALITY, "bytes", nil)
+		b.totalSizeLimit = 1_000_000
+		return b
+	}
+
+	linear := newCardinalityStore()
+	first := newCardinalityStore()
+	second := newCardinalityStore()
+	for i := 0; i < 20_000; i++ {
+		item := []byte(fmt.Sprintf("item:%d", i%10_000))
+		linear.CardinalityAdd(uint64(i), "key", item)
+		if i < 12_000 {
+			first.CardinalityAdd(uint64(i), "key", item)
+		} else {
+			second.CardinalityAdd(uint64(i), "key", item)
+		}
+	}
+
+	linearSketch, found := linear.GetLast("key")
+	require.True(t, found)
+	estimate, err := EstimateCardinality(linearSketch)
+	require.NoError(t, err)
+	assert.InDelta(t, 10_000, estimate, 10_000*0.03)
+
+	firstSketch, _ := first.GetLast("key")
+	secondSketch, _ := second.GetLast("key")
+	merged, err := mergeCardinalitySketches(firstSketch, secondSketch)
+	require.NoError(t, err)
+	assert.Equal(t, linearSketch, merged)
+}
+
+func TestEstimateCardinality_Small(t *testing.T) {
+	b := newTestBaseStore(t, pbsubstreams.Module_KindStore_UPDATE_POLICY_CARDINALITY, "bytes", nil)
+	for i := 0; i < 10; i++ {
+		b.CardinalityAdd(uint64(i), "key", []byte(fmt.Sprintf("item:%d", i%5)))
+	}
+
+	sketch, found := b.GetLast("key")
+	require.True(t, found)
+	estimate, err := EstimateCardinality(sketch)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), estimate)
+
+	_, err = EstimateCardinality([]byte{12, 0})
+	require.Error(t, err)
+}
@@ -0,0 +1,164 @@
+package store
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"github.com/streamingfast/substreams/manifest"
+)
+
+// Values of `set_sum` stores are prefixed with the operation that produced them: `set:`
+// values reset the key, while `sum:` values are added to the previous value of the key.
+// The prefix is kept in the store so that a partial store only holding increments can
+// still be merged on top of the previous segments.
+const (
+	SetSumSetPrefix = "set:"
+	SetSumSumPrefix = "sum:"
+)
+
+// setSumAdder adds two numbers formatted as strings and returns the formatted sum.
+type setSumAdder func(a, b string) (string, error)
+
+func (b *baseStore) SetSumInt64(ord uint64, key string, value string) error {
+	return b.setSum(ord, key, value, addInt64Strings)
+}
+
+func (b *baseStore) SetSumFloat64(ord uint64, key string, value string) error {
+	return b.setSum(ord, key, value, addFloat64Strings)
+}
+
+func (b *baseStore) SetSumBigInt(ord uint64, key string, value string) error {
+	return b.setSum(ord, key, value, addBigIntStrings)
+}
+
+func (b *baseStore) SetSumBigDecimal(ord uint64, key string, value string) error {
+	return b.setSum(ord, key, value, addBigDecimalStrings)
+}
+
+func (b *baseStore) setSum(ord uint64, key string, value string, add setSumAdder) error {
+	prefix, number, err := splitSetSum([]byte(value))
+	if err != nil {
+		return err
+	}
+	normalized, err := add(number, "0")
+	if err != nil {
+		return fmt.Errorf("invalid set_sum value %q: %w", value, err)
+	}
+	next := []byte(prefix + normalized)
+
+	if prefix == SetSumSumPrefix {
+		if prev, found := b.GetAt(ord, key); found {
+			next, err = mergeSetSum(prev, next, add)
+			if err != nil {
+				return fmt.Errorf("key %q: %w", key, err)
+			}
+		}
+	}
+
+	b.set(ord, key, next)
+	return nil
+}
+
+// mergeSetSum applies the `next` set_sum value on top of `prev`: a `set:` value replaces
+// `prev`, while a `sum:` value is added to it, keeping the prefix of `prev`.
+func mergeSetSum(prev, next []byte, add setSumAdder) ([]byte, error) {
+	nextPrefix, nextNumber, err := splitSetSum(next)
+	if err != nil {
+		return nil, err
+	}
+	if nextPrefix == SetSumSetPrefix {
+		return next, nil
+	}
+
+	prevPrefix, prevNumber, err := splitSetSum(prev)
+	if err != nil {
+		return nil, err
+	}
+	sum, err := add(prevNumber, nextNumber)
+	if err != nil {
+		return nil, fmt.Errorf("adding %q to %q: %w", next, prev, err)
+	}
+	return []byte(prevPrefix + sum), nil
+}
+
+func splitSetSum(value []byte) (prefix string, number string, err error) {
+	str := string(value)
+	switch {
+	case strings.HasPrefix(str, SetSumSetPrefix):
+		return SetSumSetPrefix, str[len(SetSumSetPrefix):], nil
+	case strings.HasPrefix(str, SetSumSumPrefix):
+		return SetSumSumPrefix, str[len(SetSumSumPrefix):], nil
+	}
+	return "", "", fmt.Errorf("invalid set_sum value %q: expected %q or %q prefix", str, SetSumSetPrefix, SetSumSumPrefix)
+}
+
+// TrimSetSumPrefix returns the number held by a set_sum store value, without its operation prefix.
+func TrimSetSumPrefix(value []byte) (string, error) {
+	_, number, err := splitSetSum(value)
+	return number, err
+}
+
+func setSumAdderForValueType(valueType string) (setSumAdder, error) {
+	switch valueType {
+	case manifest.OutputValueTypeInt64:
+		return addInt64Strings, nil
+	case manifest.OutputValueTypeFloat64:
+		return addFloat64Strings, nil
+	case manifest.OutputValueTypeBigInt:
+		return addBigIntStrings, nil
+	case manifest.OutputValueTypeBigFloat, manifest.OutputValueTypeBigDecimal:
+		return addBigDecimalStrings, nil
+	}
+	return nil, fmt.Errorf("value type %q not supported by set_sum", valueType)
+}
+
+func addInt64Strings(a, b string) (string, error) {
+	v0, err := strconv.ParseInt(a, 10, 64)
+	if err != nil {
+		return "", err
+	}
+	v1, err := strconv.ParseInt(b, 10, 64)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(v0+v1, 10), nil
+}
+
+func addFloat64Strings(a, b string) (string, error) {
+	v0, err := strconv.ParseFloat(a, 64)
+	if err != nil {
+		return "", err
+	}
+	v1, err := strconv.ParseFloat(b, 64)
+	if err != nil {
+		return "", err
+	}
+	return floatToStr(v0 + v1), nil
+}
+
+func addBigIntStrings(a, b string) (string, error) {
+	v0, ok := new(big.Int).SetString(a, 10)
+	if !ok {
+		return "", fmt.Errorf("invalid bigint %q", a)
+	}
+	v1, ok := new(big.Int).SetString(b, 10)
+	if !ok {
+		return "", fmt.Errorf("invalid bigint %q", b)
+	}
+	return v0.Add(v0, v1).String(), nil
+}
+
+func addBigDecimalStrings(a, b string) (string, error) {
+	v0, err := decimal.NewFromString(a)
+	if err != nil {
+		return "", err
+	}
+	v1, err := decimal.NewFromString(b)
+	if err != nil {
+		return "", err
+	}
+	return v0.Add(v1).Truncate(34).String(), nil
+}
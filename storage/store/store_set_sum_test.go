@@ -0,0 +1,93 @@
+package store
+
+import (
+	"testing"
+
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreSetSumInt64(t *testing.T) {
+	tests := []struct {
+		name          string
+		existingValue []byte
+		values        []string
+		expectedValue string
+		expectedError bool
+	}{
+		{
+			name:          "sum not found",
+			values:        []string{"sum:4"},
+			expectedValue: "sum:4",
+		},
+		{
+			name:          "sum keeps set prefix",
+			existingValue: []byte("set:3"),
+			values:        []string{"sum:4", "sum:-2"},
+			expectedValue: "set:5",
+		},
+		{
+			name:          "set resets",
+			existingValue: []byte("sum:3"),
+			values:        []string{"sum:4", "set:1", "sum:1"},
+			expectedValue: "set:2",
+		},
+		{
+			name:          "missing prefix",
+			values:        []string{"4"},
+			expectedError: true,
+		},
+		{
+			name:          "invalid number",
+			values:        []string{"sum:4.5"},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b := newTestBaseStore(t, pbsubstreams.Module_KindStore_UPDATE_POLICY_SET_SUM, "int64", nil)
+			if test.existingValue != nil {
+				b.kv["key"] = test.existingValue
+				b.totalSizeBytes += uint64(len("key") + len(test.existingValue))
+			}
+
+			var err error
+			for i, value := range test.values {
+				if err = b.SetSumInt64(uint64(i), "key", value); err != nil {
+					break
+				}
+			}
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			actual, found := b.GetLast("key")
+			require.True(t, found)
+			assert.Equal(t, test.expectedValue, string(actual))
+		})
+	}
+}
+
+func TestStoreSetSumFloat64(t *testing.T) {
+	b := newTestBaseStore(t, pbsubstreams.Module_KindStore_UPDATE_POLICY_SET_SUM, "float64", nil)
+	require.NoError(t, b.SetSumFloat64(0, "key", "set:1.5"))
+	require.NoError(t, b.SetSumFloat64(1, "key", "sum:2.25"))
+
+	actual, found := b.GetLast("key")
+	require.True(t, found)
+	assert.Equal(t, "set:3.75", string(actual))
+}
+
+func TestStoreSetSumFloat64_ShortestForm(t *testing.T) {
+	b := newTestBaseStore(t, pbsubstreams.Module_KindStore_UPDATE_POLICY_SET_SUM, "float64", nil)
+	require.NoError(t, b.SetSumFloat64(0, "key", "set:0.1"))
+	require.NoError(t, b.SetSumFloat64(1, "key", "sum:0.2"))
+
+	actual, found := b.GetLast("key")
+	require.True(t, found)
+	assert.Equal(t, "set:0.30000000000000004", string(actual), "sums are formatted like the add policy")
+}
@@ -0,0 +1,75 @@
+package store
+
+import (
+	"sort"
+
+	"go.uber.org/zap"
+
+	pbssinternal "github.com/streamingfast/substreams/pb/sf/substreams/intern/v2"
+)
+
+// MarkWritten records `blockNum` as the block at which the keys of the current
+// deltas were last written. It is to be called before the store is reset at the
+// end of each block, and is a no-op for stores without a ttl.
+func (b *baseStore) MarkWritten(blockNum uint64) {
+	if b.ttlBlocks == 0 {
+		return
+	}
+	if b.lastWrittenBlocks == nil {
+		b.lastWrittenBlocks = make(map[string]uint64)
+	}
+
+	for _, delta := range b.deltas {
+		if delta.Operation == pbssinternal.StoreDelta_DELETE {
+			// deleted keys are removed from lastWrittenBlocks along with their value
+			continue
+		}
+		b.lastWrittenBlocks[delta.Key] = blockNum
+	}
+}
+
+// EvictExpired removes the keys that were not written in the `ttlBlocks` blocks
+// before `boundaryBlock`. Evictions happen at store boundaries, in the same way
+// for linear and parallel processing, so that snapshots are the same whichever
+// way they were produced. They are recorded as DELETE deltas, in key order, so
+// that they are sent along the other deltas of the store and can be undone.
+func (s *FullKV) EvictExpired(boundaryBlock uint64) {
+	if s.ttlBlocks == 0 || boundaryBlock <= s.ttlBlocks {
+		return
+	}
+	expiredBelow := boundaryBlock - s.ttlBlocks
+
+	var expired []string
+	for key, blockNum := range s.lastWrittenBlocks {
+		if blockNum < expiredBelow {
+			expired = append(expired, key)
+		}
+	}
+	sort.Strings(expired)
+
+	evicted := 0
+	for _, key := range expired {
+		if val, found := s.getKV(key); found {
+			s.deleteKey(s.lastOrdinal, key, val)
+			evicted++
+		}
+		delete(s.lastWrittenBlocks, key)
+	}
+
+	s.logger.Debug("evicted expired keys", zap.Uint64("boundary_block", boundaryBlock), zap.Uint64("ttl_blocks", s.ttlBlocks), zap.Int("evicted_count", evicted))
+}
+
+// mergeLastWrittenBlocks takes the last written blocks of the keys of `kvPartialStore`,
+// which were all written after the ones of the store it is merged into.
+func (b *baseStore) mergeLastWrittenBlocks(kvPartialStore *PartialKV) {
+	if b.ttlBlocks == 0 || len(kvPartialStore.lastWrittenBlocks) == 0 {
+		return
+	}
+	if b.lastWrittenBlocks == nil {
+		b.lastWrittenBlocks = make(map[string]uint64, len(kvPartialStore.lastWrittenBlocks))
+	}
+
+	for key, blockNum := range kvPartialStore.lastWrittenBlocks {
+		b.lastWrittenBlocks[key] = blockNum
+	}
+}
@@ -0,0 +1,107 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/streamingfast/substreams/manifest"
+	pbssinternal "github.com/streamingfast/substreams/pb/sf/substreams/intern/v2"
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+)
+
+func TestFullKV_EvictExpired_LinearAndParallel(t *testing.T) {
+	// keys written at each block, for blocks 0 to 299
+	writes := map[uint64][]string{
+		10:  {"a", "b"},
+		60:  {"c"},
+		120: {"a"},
+		180: {"d"},
+		199: {"e"},
+		250: {"b"},
+	}
+	const interval = 100
+
+	newFullKV := func() *FullKV {
+		b := newTestBaseStore(t, pbsubstreams.Module_KindStore_UPDATE_POLICY_SET, manifest.OutputValueTypeString, nil)
+		b.ttlBlocks = 150
+		return &FullKV{baseStore: b}
+	}
+	processBlocks := func(s WriteMarker, setter UpdateKeySetter, resetter Resettable, startBlock, endBlock uint64) {
+		for blockNum := startBlock; blockNum < endBlock; blockNum++ {
+			for _, key := range writes[blockNum] {
+				setter.Set(0, key, fmt.Sprintf("%s@%d", key, blockNum))
+			}
+			s.MarkWritten(blockNum)
+			resetter.Reset()
+		}
+	}
+
+	linear := newFullKV()
+	for start := uint64(0); start < 300; start += interval {
+		processBlocks(linear, linear, linear, start, start+interval)
+		linear.EvictExpired(start + interval)
+	}
+
+	parallel := newFullKV()
+	processBlocks(parallel, parallel, parallel, 0, interval)
+	parallel.EvictExpired(interval)
+	for start := uint64(interval); start < 300; start += interval {
+		partial := parallel.DerivePartialStore(start)
+		processBlocks(partial, partial, partial, start, start+interval)
+		require.NoError(t, parallel.Merge(partial))
+		parallel.EvictExpired(start + interval)
+	}
+
+	// at 300, keys last written before 150 are evicted: "a" and "c", while "b"
+	// was evicted at 200 and written again at 250
+	expectedKV := map[string][]byte{
+		"b": []byte("b@250"),
+		"d": []byte("d@180"),
+		"e": []byte("e@199"),
+	}
+	expectedLastWrittenBlocks := map[string]uint64{"b": 250, "d": 180, "e": 199}
+
+	assert.Equal(t, expectedKV, linear.kv)
+	assert.Equal(t, expectedLastWrittenBlocks, linear.lastWrittenBlocks)
+	assert.Equal(t, expectedKV, parallel.kv)
+	assert.Equal(t, expectedLastWrittenBlocks, parallel.lastWrittenBlocks)
+	assert.Equal(t, linear.SizeBytes(), parallel.SizeBytes())
+}
+
+func TestFullKV_EvictExpired_NoTTL(t *testing.T) {
+	s := &FullKV{baseStore: newTestBaseStore(t, pbsubstreams.Module_KindStore_UPDATE_POLICY_SET, manifest.OutputValueTypeString, nil)}
+	s.Set(0, "a", "1")
+	s.MarkWritten(1)
+	s.Reset()
+
+	s.EvictExpired(1_000_000)
+	assert.Nil(t, s.lastWrittenBlocks)
+	assert.Equal(t, uint64(1), s.Length())
+}
+
+func TestFullKV_EvictExpired_Deltas(t *testing.T) {
+	b := newTestBaseStore(t, pbsubstreams.Module_KindStore_UPDATE_POLICY_SET, manifest.OutputValueTypeString, nil)
+	b.ttlBlocks = 150
+	s := &FullKV{baseStore: b}
+
+	for blockNum, key := range map[uint64]string{10: "b", 20: "a", 100: "c"} {
+		s.Set(0, key, key+"@"+fmt.Sprint(blockNum))
+		s.MarkWritten(blockNum)
+		s.Reset()
+	}
+	sizeBefore := s.SizeBytes()
+
+	s.EvictExpired(200)
+	assert.Equal(t, []*pbssinternal.StoreDelta{
+		{Operation: pbssinternal.StoreDelta_DELETE, Key: "a", OldValue: []byte("a@20")},
+		{Operation: pbssinternal.StoreDelta_DELETE, Key: "b", OldValue: []byte("b@10")},
+	}, s.GetDeltas())
+	assert.Equal(t, uint64(1), s.Length())
+
+	s.ApplyDeltasReverse(s.GetDeltas())
+	assert.Equal(t, uint64(3), s.Length())
+	assert.Equal(t, sizeBefore, s.SizeBytes())
+}
@@ -1,10 +1,12 @@
 package store
 
 import (
+	"fmt"
 	"sort"
 	"strings"
 
 	pbssinternal "github.com/streamingfast/substreams/pb/sf/substreams/intern/v2"
+	"github.com/streamingfast/substreams/storage/store/marshaller"
 )
 
 //func (s *baseStore) Del(ord uint64, key string) {
@@ -24,9 +26,13 @@ import (
 //	}
 //}
 
-func (b *baseStore) DeletePrefix(ord uint64, prefix string) {
+func (b *baseStore) DeletePrefix(ord uint64, prefix string) error {
 	b.bumpOrdinal(ord)
 
+	if b.lazy != nil {
+		return b.deleteLazyPrefix(ord, prefix)
+	}
+
 	var deltas []*pbssinternal.StoreDelta
 	for key, val := range b.kv {
 		if !strings.HasPrefix(key, prefix) {
@@ -46,4 +52,124 @@ func (b *baseStore) DeletePrefix(ord uint64, prefix string) {
 		return deltas[i].Key < deltas[j].Key
 	})
 	b.deltas = append(b.deltas, deltas...)
+	return nil
+}
+
+// deleteLazyPrefix deletes the keys with `prefix` of a store loaded from a sorted
+// snapshot, seeking to the prefix instead of scanning all the keys.
+func (b *baseStore) deleteLazyPrefix(ord uint64, prefix string) error {
+	var deltas []*pbssinternal.StoreDelta
+	err := b.iterSortedKV(prefix, hasPrefix(prefix), func(key string, val []byte) error {
+		deltas = append(deltas, &pbssinternal.StoreDelta{
+			Operation: pbssinternal.StoreDelta_DELETE,
+			Ordinal:   ord,
+			Key:       key,
+			OldValue:  val,
+			NewValue:  nil,
+		})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("deleting prefix %q from store %q: %w", prefix, b.name, err)
+	}
+	for _, delta := range deltas {
+		b.ApplyDelta(delta)
+	}
+	b.deltas = append(b.deltas, deltas...)
+	return b.Err()
+}
+
+func (b *baseStore) DeleteRange(ord uint64, lowKey, highKey string) error {
+	_, _, err := b.deleteRange(ord, lowKey, highKey, "")
+	return err
+}
+
+func (b *baseStore) DeleteRangePointers(ord uint64, lowKey, highKey, pointerSeparator string) error {
+	_, _, err := b.deleteRange(ord, lowKey, highKey, pointerSeparator)
+	return err
+}
+
+// deleteRange deletes the keys in the range and, when `pointerSeparator` is
+// non-empty, the keys their values point to. It returns the keys deleted in the
+// range and the pointers resolved from their values.
+func (b *baseStore) deleteRange(ord uint64, lowKey, highKey, pointerSeparator string) (deletedKeys, pointers []string, err error) {
+	return b.deleteRangeSkipping(ord, lowKey, highKey, pointerSeparator, nil)
+}
+
+// deleteRangeSkipping is deleteRange not resolving the pointers of the
+// `stalePointers` keys.
+func (b *baseStore) deleteRangeSkipping(ord uint64, lowKey, highKey, pointerSeparator string, stalePointers map[string]bool) (deletedKeys, pointers []string, err error) {
+	b.bumpOrdinal(ord)
+
+	var keys []string
+	err = b.iterSortedKV(lowKey, inKeyRange(lowKey, highKey), func(key string, _ []byte) error {
+		keys = append(keys, key)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("deleting range [%q, %q) from store %q: %w", lowKey, highKey, b.name, err)
+	}
+
+	for _, key := range keys {
+		val, found := b.getKV(key)
+		if !found {
+			// already deleted as the pointer of a previous key in the range
+			continue
+		}
+		b.deleteKey(ord, key, val)
+		deletedKeys = append(deletedKeys, key)
+
+		if pointerSeparator == "" || stalePointers[key] {
+			continue
+		}
+		for _, pointer := range strings.Split(string(val), pointerSeparator) {
+			pointers = append(pointers, pointer)
+			if pointerVal, found := b.getKV(pointer); found {
+				b.deleteKey(ord, pointer, pointerVal)
+			}
+		}
+	}
+	return deletedKeys, pointers, b.Err()
+}
+
+// replayDeletedRange applies a range deletion recorded by a PartialKV. The
+// pointers of the keys the partial had written before deleting them were
+// resolved by the partial, the ones of the other keys are resolved here.
+func (b *baseStore) replayDeletedRange(ord uint64, deletedRange *marshaller.DeleteRange) error {
+	var writtenKeys map[string]bool
+	if len(deletedRange.WrittenKeys) > 0 {
+		writtenKeys = make(map[string]bool, len(deletedRange.WrittenKeys))
+		for _, key := range deletedRange.WrittenKeys {
+			writtenKeys[key] = true
+		}
+	}
+
+	if _, _, err := b.deleteRangeSkipping(ord, deletedRange.LowKey, deletedRange.HighKey, deletedRange.PointerSeparator, writtenKeys); err != nil {
+		return err
+	}
+	for _, pointer := range deletedRange.Pointers {
+		if pointerVal, found := b.getKV(pointer); found {
+			b.deleteKey(ord, pointer, pointerVal)
+		}
+	}
+	return b.Err()
+}
+
+func (b *baseStore) deleteKey(ord uint64, key string, val []byte) {
+	delta := &pbssinternal.StoreDelta{
+		Operation: pbssinternal.StoreDelta_DELETE,
+		Ordinal:   ord,
+		Key:       key,
+		OldValue:  val,
+		NewValue:  nil,
+	}
+	b.ApplyDelta(delta)
+	b.deltas = append(b.deltas, delta)
+}
+
+func keyInRange(key, lowKey, highKey string) bool {
+	if key < lowKey {
+		return false
+	}
+	return highKey == "" || key < highKey
 }
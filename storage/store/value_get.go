@@ -24,7 +24,7 @@ func (b *baseStore) GetFirst(key string) ([]byte, bool) {
 
 	}
 
-	val, found := b.kv[key]
+	val, found := b.getKV(key)
 	return val, found
 }
 
@@ -46,7 +46,7 @@ func (b *baseStore) HasFirst(key string) bool {
 
 	}
 
-	_, found := b.kv[key]
+	_, found := b.getKV(key)
 	return found
 }
 
@@ -67,7 +67,7 @@ func (b *baseStore) GetLast(key string) ([]byte, bool) {
 		}
 	}
 
-	val, found := b.kv[key]
+	val, found := b.getKV(key)
 	return val, found
 }
 
@@ -88,7 +88,7 @@ func (b *baseStore) HasLast(key string) bool {
 		}
 	}
 
-	_, found := b.kv[key]
+	_, found := b.getKV(key)
 	return found
 }
 
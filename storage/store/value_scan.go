@@ -0,0 +1,35 @@
+package store
+
+import (
+	"strings"
+)
+
+func (b *baseStore) ScanPrefix(prefix string, limit uint64, f func(key string, value []byte) error) error {
+	return b.scan(prefix, hasPrefix(prefix), limit, f)
+}
+
+func (b *baseStore) ScanRange(lowKey, highKey string, limit uint64, f func(key string, value []byte) error) error {
+	return b.scan(lowKey, inKeyRange(lowKey, highKey), limit, f)
+}
+
+// scan calls `f` in lexicographical key order for each key starting at `lowKey` while
+// `inRange` holds, stopping after `limit` keys when `limit` is non-zero. Values are the
+// last value of the keys.
+func (b *baseStore) scan(lowKey string, inRange func(key string) bool, limit uint64, f func(key string, value []byte) error) error {
+	var count uint64
+	return b.iterSortedKV(lowKey, inRange, func(key string, value []byte) error {
+		if limit != 0 && count >= limit {
+			return errStopIteration
+		}
+		count++
+		return f(key, value)
+	})
+}
+
+func hasPrefix(prefix string) func(key string) bool {
+	return func(key string) bool { return strings.HasPrefix(key, prefix) }
+}
+
+func inKeyRange(lowKey, highKey string) func(key string) bool {
+	return func(key string) bool { return keyInRange(key, lowKey, highKey) }
+}
@@ -1,16 +1,82 @@
 package store
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"fmt"
+	"io"
+
 	"github.com/streamingfast/dstore"
+
+	"github.com/streamingfast/substreams/storage/compression"
+	"github.com/streamingfast/substreams/storage/integrity"
 )
 
 type fileWriter struct {
-	store    dstore.Store
-	filename string
-	content  []byte
+	store       dstore.Store
+	filename    string
+	content     []byte
+	compression compression.Codec
+
+	// spilled, set instead of content, is the content already compressed to a
+	// temporary file, and spilledHeader its integrity header.
+	spilled       *spilledContent
+	spilledHeader []byte
+}
+
+// newSpilledFileWriter returns a fileWriter for the content written by `write`, which
+// is compressed and sealed to a temporary file as it is written instead of being
+// buffered in memory.
+func newSpilledFileWriter(store dstore.Store, filename string, codec compression.Codec, write func(w io.Writer) error) (*fileWriter, error) {
+	file, err := newTempFile()
+	if err != nil {
+		return nil, err
+	}
+
+	sealer := &integrity.Sealer{}
+	buffered := bufio.NewWriter(file)
+	compressed, err := compression.NewWriter(codec, io.MultiWriter(buffered, sealer))
+	if err == nil {
+		err = write(compressed)
+	}
+	if err == nil {
+		err = compressed.Close()
+	}
+	if err == nil {
+		err = buffered.Flush()
+	}
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("spilling %s: %w", filename, err)
+	}
+
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("spilling %s: %w", filename, err)
+	}
+
+	return &fileWriter{
+		store:         store,
+		filename:      filename,
+		compression:   codec,
+		spilled:       &spilledContent{SectionReader: io.NewSectionReader(file, 0, size), file: file},
+		spilledHeader: sealer.Header(),
+	}, nil
 }
 
 func (f *fileWriter) Write(ctx context.Context) error {
-	return saveStore(ctx, f.store, f.filename, f.content)
+	if f.spilled != nil {
+		defer f.spilled.Close()
+		return saveStoreFrom(ctx, f.store, f.filename, func() (io.Reader, error) {
+			return io.MultiReader(bytes.NewReader(f.spilledHeader), io.NewSectionReader(f.spilled, 0, f.spilled.Size())), nil
+		})
+	}
+
+	content, err := compression.Compress(f.compression, f.content)
+	if err != nil {
+		return fmt.Errorf("compressing %s: %w", f.filename, err)
+	}
+	return saveStore(ctx, f.store, f.filename, integrity.Seal(content))
 }
@@ -390,6 +390,25 @@ func TestOneStoreOneMap(t *testing.T) {
 	}
 }
 
+func TestOneStoreOneMap_LocalWorkers(t *testing.T) {
+	run := newTestRun(t, 25, 38, 38, "assert_test_store_add_i64")
+	run.ProductionMode = true
+	run.ParallelSubrequests = 5
+	run.LocalWorkers = true
+	require.NoError(t, run.Run(t, "local_workers"))
+
+	mapOutput := run.MapOutput("assert_test_store_add_i64")
+	assert.Contains(t, mapOutput, `assert_test_store_add_i64: 0801`)
+	assert.Equal(t, 13, strings.Count(mapOutput, "\n"))
+	assertFiles(t, run.TempDir,
+		"states/0000000010-0000000001.kv",
+		"states/0000000020-0000000001.kv",
+		"states/0000000030-0000000001.kv",
+		"outputs/0000000020-0000000030.output",
+		"outputs/0000000030-0000000038.output",
+	)
+}
+
 func TestStoreDeletePrefix(t *testing.T) {
 	run := newTestRun(t, 30, 41, 41, "assert_test_store_delete_prefix")
 	run.BlockProcessedCallback = func(ctx *execContext) {
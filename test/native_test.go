@@ -0,0 +1,56 @@
+package integration
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/streamingfast/substreams/manifest"
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/streamingfast/substreams/wasm"
+	"github.com/streamingfast/substreams/wasm/native"
+)
+
+func TestNativeModules(t *testing.T) {
+	native.Register("native_test", "native_store_count", func(ctx context.Context, call *wasm.Call, arguments []wasm.Argument) error {
+		call.DoAddInt64(0, "blocks", 1)
+		return nil
+	})
+	native.Register("native_test", "native_map_count", func(ctx context.Context, call *wasm.Call, arguments []wasm.Argument) error {
+		clock := &pbsubstreams.Clock{}
+		if err := proto.Unmarshal(arguments[0].(wasm.ValueArgument).Value(), clock); err != nil {
+			return fmt.Errorf("unmarshalling clock: %w", err)
+		}
+		count, _ := call.DoGetLast(0, "blocks")
+		call.AppendLog(fmt.Sprintf("block %d", clock.Number))
+
+		out, err := proto.Marshal(&pbsubstreams.StoreEntry{Key: "blocks", Value: count})
+		if err != nil {
+			return err
+		}
+		call.SetReturnValue(out)
+		return nil
+	})
+	defer native.Unregister("native_test")
+
+	run := newTestRun(t, 20, 20, 23, "native_map_count")
+	run.Package = manifest.TestReadManifest(t, "./testdata/native/substreams.yaml")
+	run.ParallelSubrequests = 2
+
+	require.NoError(t, run.Run(t, "native_map_count"))
+
+	var expected []string
+	for blockNum := uint64(20); blockNum < 23; blockNum++ {
+		out, err := proto.Marshal(&pbsubstreams.StoreEntry{Key: "blocks", Value: []byte(fmt.Sprint(blockNum + 1))})
+		require.NoError(t, err)
+		expected = append(expected, fmt.Sprintf("%d: native_map_count: %s", blockNum, hex.EncodeToString(out)))
+	}
+	assert.Equal(t, "\n"+strings.Join(expected, "\n"), run.MapOutput("native_map_count"))
+	assert.Equal(t, []string{"block 20", "block 21", "block 22"}, run.Logs())
+}
@@ -0,0 +1,80 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	pbsubstreamsrpc "github.com/streamingfast/substreams/pb/sf/substreams/rpc/v2"
+	"github.com/streamingfast/substreams/wasm"
+	_ "github.com/streamingfast/substreams/wasm/wasmtime"
+)
+
+// TestRuntimeParity runs the modules of the test package with all the wasm runtimes,
+// selected through the SUBSTREAMS_WASM_RUNTIME env var, and expects byte for byte
+// identical outputs, store deltas and logs. Only the modules no other module depends
+// on are requested, the outputs of all their dependencies are returned along with theirs.
+func TestRuntimeParity(t *testing.T) {
+	require.Len(t, wasm.RuntimeNames(), 2, "all runtimes should be registered")
+
+	pkg := newTestRun(t, 0, 0, 0, "").Package
+	dependedOn := map[string]bool{}
+	for _, module := range pkg.Modules.Modules {
+		for _, input := range module.Inputs {
+			if store := input.GetStore(); store != nil {
+				dependedOn[store.ModuleName] = true
+			}
+			if mapInput := input.GetMap(); mapInput != nil {
+				dependedOn[mapInput.ModuleName] = true
+			}
+		}
+	}
+
+	for _, module := range pkg.Modules.Modules {
+		if dependedOn[module.Name] {
+			continue
+		}
+		moduleName := module.Name
+		startBlock := int64(module.InitialBlock)
+
+		t.Run(moduleName, func(t *testing.T) {
+			var reference [][]byte
+			for _, runtimeName := range wasm.RuntimeNames() {
+				t.Setenv("SUBSTREAMS_WASM_RUNTIME", runtimeName)
+
+				run := newTestRun(t, startBlock, uint64(startBlock), uint64(startBlock)+20, moduleName)
+				run.Params = map[string]string{"test_map": "my test params"}
+				require.NoError(t, run.Run(t, moduleName), "runtime %q", runtimeName)
+
+				outputs := blockScopedDataBytes(t, run.Responses)
+				require.NotEmpty(t, outputs, "runtime %q", runtimeName)
+				if reference == nil {
+					reference = outputs
+					continue
+				}
+				assert.Equal(t, reference, outputs, "outputs of runtime %q differ from %q", runtimeName, wasm.RuntimeNames()[0])
+			}
+		})
+	}
+}
+
+func blockScopedDataBytes(t *testing.T, responses []*pbsubstreamsrpc.Response) (out [][]byte) {
+	t.Helper()
+	for _, response := range responses {
+		data := response.GetBlockScopedData()
+		if data == nil {
+			continue
+		}
+		// The cursor and block time differ from one run to another
+		data = proto.Clone(data).(*pbsubstreamsrpc.BlockScopedData)
+		data.Cursor = ""
+		data.Clock.Timestamp = nil
+
+		cnt, err := proto.MarshalOptions{Deterministic: true}.Marshal(data)
+		require.NoError(t, err)
+		out = append(out, cnt)
+	}
+	return
+}
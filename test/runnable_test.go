@@ -23,6 +23,7 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.uber.org/zap"
 
+	"github.com/streamingfast/substreams"
 	"github.com/streamingfast/substreams/manifest"
 	"github.com/streamingfast/substreams/orchestrator/work"
 	pbssinternal "github.com/streamingfast/substreams/pb/sf/substreams/intern/v2"
@@ -47,6 +48,7 @@ type testRun struct {
 	BlockProcessedCallback blockProcessedCallBack
 	LinearHandoffBlockNum  uint64 // defaults to the request's StopBlock, so no linear handoff, only backprocessing
 	ProductionMode         bool
+	LocalWorkers           bool // run the jobs with work.LocalWorker instead of TestWorker
 	// PreWork can be done to perform tier2 work in advance, to simulate when
 	// pre-existing data is available in different conditions
 	PreWork testPreWork
@@ -138,6 +140,13 @@ func (f *testRun) Run(t *testing.T, testName string) error {
 		}
 	}
 
+	if f.LocalWorkers {
+		workerFactory = work.NewLocalWorkerFactory(func(ctx context.Context, request *pbssinternal.ProcessRangeRequest, respFunc substreams.ResponseFunc) error {
+			svc := newTestServiceTier2(t, newBlockGenerator, f.BlockProcessedCallback, testTempDir)
+			return svc.TestProcessRange(ctx, request, respFunc, nil)
+		}, 2)
+	}
+
 	if f.PreWork != nil {
 		f.PreWork(t, f, workerFactory)
 	}
@@ -232,7 +241,6 @@ func processInternalRequest(
 	t *testing.T,
 	ctx context.Context,
 	request *pbssinternal.ProcessRangeRequest,
-	workerFactory work.WorkerFactory,
 	newGenerator BlockGeneratorFactory,
 	responseCollector *responseCollector,
 	blockProcessedCallBack blockProcessedCallBack,
@@ -241,6 +249,18 @@ func processInternalRequest(
 ) error {
 	t.Helper()
 
+	svc := newTestServiceTier2(t, newGenerator, blockProcessedCallBack, testTempDir)
+	return svc.TestProcessRange(ctx, request, responseCollector.Collect, traceID)
+}
+
+func newTestServiceTier2(
+	t *testing.T,
+	newGenerator BlockGeneratorFactory,
+	blockProcessedCallBack blockProcessedCallBack,
+	testTempDir string,
+) *service.Tier2Service {
+	t.Helper()
+
 	baseStoreStore, err := dstore.NewStore(filepath.Join(testTempDir, "test.store"), "", "none", true)
 	require.NoError(t, err)
 
@@ -259,11 +279,9 @@ func processInternalRequest(
 		0,
 		baseStoreStore,
 		"tag",
-		workerFactory,
+		nil,
 	)
-	svc := service.TestNewServiceTier2(runtimeConfig, tr.StreamFactory)
-
-	return svc.TestProcessRange(ctx, request, responseCollector.Collect, traceID)
+	return service.TestNewServiceTier2(runtimeConfig, tr.StreamFactory)
 }
 
 func processRequest(
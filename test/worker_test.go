@@ -49,8 +49,8 @@ func (w *TestWorker) Work(ctx context.Context, unit stage.Unit, workRange *block
 	)
 
 	return func() loop.Msg {
-		if err := processInternalRequest(w.t, ctx, request, nil, w.newBlockGenerator, w.responseCollector, w.blockProcessedCallBack, w.testTempDir, w.traceID); err != nil {
-			return work.MsgJobFailed{Unit: unit, Error: fmt.Errorf("processing test tier2 request: %w", err)}
+		if err := processInternalRequest(w.t, ctx, request, w.newBlockGenerator, w.responseCollector, w.blockProcessedCallBack, w.testTempDir, w.traceID); err != nil {
+			return work.MsgJobFailed{Unit: unit, Worker: w, Error: fmt.Errorf("processing test tier2 request: %w", err)}
 		}
 		logger.Info("worker done running job",
 			zap.String("output_module", request.OutputModule),
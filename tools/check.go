@@ -8,6 +8,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/spf13/cobra"
+	"github.com/streamingfast/cli"
 	"github.com/streamingfast/dstore"
 	"github.com/streamingfast/substreams/block"
 	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
@@ -16,17 +17,31 @@ import (
 var checkCmd = &cobra.Command{
 	Use:   "check <store_url>",
 	Short: "checks the integrity of the kv files in a given store",
-	Args:  cobra.ExactArgs(1),
-	RunE:  checkE,
+	Long: cli.Dedent(`
+		Checks that the partial files of the store at <store_url> cover contiguous ranges.
+
+		With '--verify', walks the whole bucket at <store_url> instead and verifies the checksum of every
+		store snapshot, partial store and execution output file, reporting the corrupted ones.
+	`),
+	Args: cobra.ExactArgs(1),
+	RunE: checkE,
 }
 
 func init() {
+	checkCmd.Flags().Bool("verify", false, "Verify the checksum of all the store and output files found under <store_url>")
+	checkCmd.Flags().Bool("delete-corrupted", false, "With --verify, delete the corrupted files so that they are recomputed")
+	checkCmd.Flags().Uint64("parallelism", 10, "With --verify, number of files verified concurrently")
+
 	Cmd.AddCommand(checkCmd)
 }
 
 func checkE(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 
+	if mustGetBool(cmd, "verify") {
+		return verifyE(cmd, args)
+	}
+
 	stateStore, _, err := newStore(args[0])
 	if err != nil {
 		return fmt.Errorf("failed to create store: %w", err)
@@ -0,0 +1,101 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+
+	"github.com/abourget/llerrgroup"
+	"github.com/spf13/cobra"
+	"github.com/streamingfast/dstore"
+	"go.uber.org/zap"
+
+	"github.com/streamingfast/substreams/storage/compression"
+	"github.com/streamingfast/substreams/storage/integrity"
+)
+
+// verifyE verifies the checksum of all the store snapshots, partial stores and
+// execution outputs found in the bucket.
+func verifyE(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	remoteStore, err := dstore.NewStore(args[0], "zst", "zstd", false)
+	if err != nil {
+		return fmt.Errorf("could not create store from %s: %w", args[0], err)
+	}
+	deleteCorrupted := mustGetBool(cmd, "delete-corrupted")
+
+	var verified, unsealed, corrupted atomic.Uint64
+	eg := llerrgroup.New(int(mustGetUint64(cmd, "parallelism")))
+	err = remoteStore.Walk(ctx, "", func(filename string) error {
+		if !isCacheFile(filename) {
+			return nil
+		}
+		if eg.Stop() {
+			return dstore.StopIteration
+		}
+
+		eg.Go(func() error {
+			header, err := verifyCacheFile(ctx, remoteStore, filename)
+			if err == nil {
+				verified.Add(1)
+				if header == nil {
+					unsealed.Add(1)
+				}
+				return nil
+			}
+
+			corrupted.Add(1)
+			fmt.Printf("corrupted: %s: %s\n", filename, err)
+			if deleteCorrupted {
+				if err := remoteStore.DeleteObject(ctx, filename); err != nil {
+					zlog.Warn("error deleting file", zap.String("filename", filename), zap.Error(err))
+				}
+			}
+			return nil
+		})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("walking store: %w", err)
+	}
+	if err := eg.Wait(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nVerified %d files (%d written without checksum), %d corrupted\n", verified.Load(), unsealed.Load(), corrupted.Load())
+	if corrupted.Load() != 0 && !deleteCorrupted {
+		return fmt.Errorf("found %d corrupted files", corrupted.Load())
+	}
+	return nil
+}
+
+func isCacheFile(filename string) bool {
+	return strings.HasSuffix(filename, ".kv") || strings.HasSuffix(filename, ".partial") || strings.HasSuffix(filename, ".output")
+}
+
+// verifyCacheFile returns the integrity header of `filename`, nil for files written
+// before checksums were introduced, or an error if the file is corrupted.
+func verifyCacheFile(ctx context.Context, remoteStore dstore.Store, filename string) (*integrity.Header, error) {
+	r, err := remoteStore.OpenObject(ctx, filename)
+	if err != nil {
+		return nil, fmt.Errorf("opening file: %w", err)
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+
+	content, header, err := integrity.Open(data)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := compression.Decompress(content); err != nil {
+		return nil, fmt.Errorf("decompressing: %w", err)
+	}
+	return header, nil
+}
@@ -145,6 +145,8 @@ func runDecodeStatesModuleRunE(cmd *cobra.Command, args []string) error {
 	switch matchingModule.Kind.(type) {
 	case *pbsubstreams.Module_KindMap_:
 		return fmt.Errorf("no states are available for a mapper")
+	case *pbsubstreams.Module_KindBlockIndex_:
+		return fmt.Errorf("no states are available for a block index")
 	case *pbsubstreams.Module_KindStore_:
 		return searchStateModule(ctx, startBlock, moduleHash, key, matchingModule, objStore, protoFiles)
 	}
@@ -220,7 +222,7 @@ func runDecodeOutputsModuleRunE(cmd *cobra.Command, args []string) error {
 	startBlock := execout.ComputeStartBlock(blockNumber, saveInterval)
 
 	switch matchingModule.Kind.(type) {
-	case *pbsubstreams.Module_KindMap_:
+	case *pbsubstreams.Module_KindMap_, *pbsubstreams.Module_KindBlockIndex_:
 		return searchOutputsModule(ctx, blockNumber, startBlock, saveInterval, moduleHash, matchingModule, s, protoFiles)
 	case *pbsubstreams.Module_KindStore_:
 		return searchOutputsModule(ctx, blockNumber, startBlock, saveInterval, moduleHash, matchingModule, s, protoFiles)
@@ -307,7 +309,7 @@ func printObject(module *pbsubstreams.Module, protoFiles []*descriptorpb.FileDes
 	valuePrinted := false
 
 	switch module.Kind.(type) {
-	case *pbsubstreams.Module_KindMap_:
+	case *pbsubstreams.Module_KindMap_, *pbsubstreams.Module_KindBlockIndex_:
 		protoDefinition = module.Output.GetType()
 	case *pbsubstreams.Module_KindStore_:
 		protoDefinition = module.Kind.(*pbsubstreams.Module_KindStore_).KindStore.ValueType
@@ -324,7 +326,7 @@ func printObject(module *pbsubstreams.Module, protoFiles []*descriptorpb.FileDes
 		msgDesc = file.FindMessage(strings.TrimPrefix(protoDefinition, "proto:"))
 		if msgDesc != nil {
 			switch module.Kind.(type) {
-			case *pbsubstreams.Module_KindMap_, *pbsubstreams.Module_KindStore_:
+			case *pbsubstreams.Module_KindMap_, *pbsubstreams.Module_KindStore_, *pbsubstreams.Module_KindBlockIndex_:
 				dynMsg := dynamic.NewMessageFactoryWithDefaults().NewDynamicMessage(msgDesc)
 				val, err := unmarshalData(data, dynMsg)
 				if err != nil {
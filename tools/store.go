@@ -0,0 +1,149 @@
+package tools
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/streamingfast/dstore"
+
+	"github.com/streamingfast/substreams/manifest"
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/streamingfast/substreams/storage/store"
+)
+
+var storeCmd = &cobra.Command{
+	Use:          "store",
+	Short:        "Inspect and manage store snapshots on the state store",
+	SilenceUsage: true,
+}
+
+func init() {
+	Cmd.AddCommand(storeCmd)
+}
+
+// storeModule is a store module of a manifest, along with its config on the state store.
+type storeModule struct {
+	module     *pbsubstreams.Module
+	descriptor *manifest.ModuleDescriptor
+	config     *store.Config
+	moduleHash string
+	stateStore dstore.Store
+}
+
+func loadStoreModule(manifestPath, moduleName, stateStoreURL string) (*storeModule, error) {
+	stateStore, err := dstore.NewStore(stateStoreURL, "zst", "zstd", false)
+	if err != nil {
+		return nil, fmt.Errorf("initializing dstore for %q: %w", stateStoreURL, err)
+	}
+
+	manifestReader, err := manifest.NewReader(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("manifest reader: %w", err)
+	}
+
+	pkg, graph, err := manifestReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read manifest %q: %w", manifestPath, err)
+	}
+
+	module, err := graph.Module(moduleName)
+	if err != nil {
+		return nil, fmt.Errorf("module %q not found: %w", moduleName, err)
+	}
+	kindStore := module.GetKindStore()
+	if kindStore == nil {
+		return nil, fmt.Errorf("module %q is not a store", moduleName)
+	}
+
+	hash, err := manifest.NewModuleHashes().HashModule(pkg.Modules, module, graph)
+	if err != nil {
+		return nil, fmt.Errorf("hashing module %q: %w", moduleName, err)
+	}
+	moduleHash := hex.EncodeToString(hash)
+
+	descriptors, err := manifest.BuildMessageDescriptors(pkg)
+	if err != nil {
+		return nil, fmt.Errorf("building message descriptors: %w", err)
+	}
+
+	config, err := store.NewConfig(module.Name, module.InitialBlock, moduleHash, kindStore.UpdatePolicy, kindStore.ValueType, stateStore, "")
+	if err != nil {
+		return nil, fmt.Errorf("initializing store config module %q: %w", module.Name, err)
+	}
+
+	return &storeModule{
+		module:     module,
+		descriptor: descriptors[module.Name],
+		config:     config,
+		moduleHash: moduleHash,
+		stateStore: stateStore,
+	}, nil
+}
+
+// withModuleHash returns the same store module, reading the snapshots of the module hash `moduleHash`.
+func (s *storeModule) withModuleHash(moduleHash string) (*storeModule, error) {
+	kindStore := s.module.GetKindStore()
+	config, err := store.NewConfig(s.module.Name, s.module.InitialBlock, moduleHash, kindStore.UpdatePolicy, kindStore.ValueType, s.stateStore, "")
+	if err != nil {
+		return nil, fmt.Errorf("initializing store config module %q: %w", s.module.Name, err)
+	}
+
+	out := *s
+	out.config = config
+	out.moduleHash = moduleHash
+	return &out, nil
+}
+
+// fullSnapshots returns the complete snapshots of the store, sorted by end block.
+func (s *storeModule) fullSnapshots(ctx context.Context) ([]*store.FileInfo, error) {
+	files, err := s.config.ListSnapshotFiles(ctx, math.MaxUint64)
+	if err != nil {
+		return nil, fmt.Errorf("listing snapshot files: %w", err)
+	}
+
+	var out []*store.FileInfo
+	for _, file := range files {
+		if !file.Partial {
+			out = append(out, file)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Range.ExclusiveEndBlock < out[j].Range.ExclusiveEndBlock
+	})
+	return out, nil
+}
+
+// loadSnapshot loads the complete snapshot ending at `block`, or the latest one when `block` is 0.
+func (s *storeModule) loadSnapshot(ctx context.Context, block uint64) (*store.FullKV, *store.FileInfo, error) {
+	files, err := s.fullSnapshots(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(files) == 0 {
+		return nil, nil, fmt.Errorf("no complete snapshot found for module %q (hash %s)", s.module.Name, s.moduleHash)
+	}
+
+	file := files[len(files)-1]
+	if block != 0 {
+		file = nil
+		for _, candidate := range files {
+			if candidate.Range.ExclusiveEndBlock == block {
+				file = candidate
+				break
+			}
+		}
+		if file == nil {
+			return nil, nil, fmt.Errorf("no complete snapshot ending at block %d for module %q, available: %s", block, s.module.Name, store.FileInfos(files))
+		}
+	}
+
+	kv := s.config.NewFullKV(zlog)
+	if err := kv.Load(ctx, file); err != nil {
+		return nil, nil, fmt.Errorf("loading snapshot %q: %w", file.Filename, err)
+	}
+	return kv, file, nil
+}
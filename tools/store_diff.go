@@ -0,0 +1,213 @@
+package tools
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/streamingfast/cli"
+	"go.uber.org/zap"
+
+	"github.com/streamingfast/substreams/storage/store"
+)
+
+var storeDiffCmd = &cobra.Command{
+	Use:   "diff [<manifest_file>] <module_name> <state_store_url>",
+	Short: "Compare two snapshots of a store and report the added, removed and changed keys",
+	Long: cli.Dedent(`
+		Compares two complete snapshots of a store: the 'base' snapshot, read from <state_store_url> for the module
+		hash of <module_name> in the manifest, and the 'against' snapshot. By default both are the latest snapshot;
+		use '--block' and '--against-block' to select the snapshots ending at other block boundaries.
+
+		To compare two versions of a module, point the 'against' side to the other version with '--against-manifest',
+		or directly to its module hash with '--against-module-hash'. '--against-state-store-url' reads the 'against'
+		snapshot from another state store, for example one with a different cache tag.
+
+		Keys are reported with their values decoded using the module's value type, followed by summary counts.
+	`),
+	Example: string(cli.ExamplePrefixed("substreams tools store diff", `
+		store_pools gs://[bucket-url-path] --block 12480000 --against-block 12490000
+		substreams.yaml store_pools gs://[bucket-url-path] --against-manifest ./v2/substreams.yaml
+		store_pools gs://[bucket-url-path]/v1 --against-state-store-url gs://[bucket-url-path]/v2 --summary-only
+	`)),
+	RunE:         storeDiffE,
+	Args:         cobra.RangeArgs(2, 3),
+	SilenceUsage: true,
+}
+
+func init() {
+	storeDiffCmd.Flags().Uint64("block", 0, "Exclusive end block of the base snapshot, defaults to the latest snapshot")
+	storeDiffCmd.Flags().Uint64("against-block", 0, "Exclusive end block of the snapshot to compare against, defaults to the latest snapshot")
+	storeDiffCmd.Flags().String("against-manifest", "", "Manifest holding the version of the module to compare against, defaults to the base manifest")
+	storeDiffCmd.Flags().String("against-module-hash", "", "Module hash to compare against, overriding the one computed from the manifest")
+	storeDiffCmd.Flags().String("against-state-store-url", "", "State store to read the snapshot to compare against from, defaults to <state_store_url>")
+	storeDiffCmd.Flags().Bool("summary-only", false, "Only print the summary counts")
+
+	storeCmd.AddCommand(storeDiffCmd)
+}
+
+func storeDiffE(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	manifestPath := ""
+	if len(args) == 3 {
+		manifestPath = args[0]
+		args = args[1:]
+	}
+	moduleName := args[0]
+	stateStoreURL := args[1]
+
+	againstManifestPath := mustGetString(cmd, "against-manifest")
+	if againstManifestPath == "" {
+		againstManifestPath = manifestPath
+	}
+	againstStateStoreURL := mustGetString(cmd, "against-state-store-url")
+	if againstStateStoreURL == "" {
+		againstStateStoreURL = stateStoreURL
+	}
+
+	base, err := loadStoreModule(manifestPath, moduleName, stateStoreURL)
+	if err != nil {
+		return fmt.Errorf("base: %w", err)
+	}
+	against, err := loadStoreModule(againstManifestPath, moduleName, againstStateStoreURL)
+	if err != nil {
+		return fmt.Errorf("against: %w", err)
+	}
+	if againstModuleHash := mustGetString(cmd, "against-module-hash"); againstModuleHash != "" {
+		if against, err = against.withModuleHash(againstModuleHash); err != nil {
+			return fmt.Errorf("against: %w", err)
+		}
+	}
+
+	baseKV, baseFile, err := base.loadSnapshot(ctx, mustGetUint64(cmd, "block"))
+	if err != nil {
+		return fmt.Errorf("base: %w", err)
+	}
+	againstKV, againstFile, err := against.loadSnapshot(ctx, mustGetUint64(cmd, "against-block"))
+	if err != nil {
+		return fmt.Errorf("against: %w", err)
+	}
+
+	zlog.Info("comparing store snapshots",
+		zap.String("module", moduleName),
+		zap.String("base_hash", base.moduleHash),
+		zap.String("base_file", baseFile.Filename),
+		zap.String("against_hash", against.moduleHash),
+		zap.String("against_file", againstFile.Filename),
+	)
+
+	summaryOnly := mustGetBool(cmd, "summary-only")
+	baseDecoder, err := newStoreValueDecoder(base.descriptor)
+	if err != nil {
+		return fmt.Errorf("base store: %w", err)
+	}
+	againstDecoder, err := newStoreValueDecoder(against.descriptor)
+	if err != nil {
+		return fmt.Errorf("against store: %w", err)
+	}
+	decode := func(decoder *storeValueDecoder, value []byte) string {
+		text, _, err := decoder.decode(value)
+		if err != nil {
+			return fmt.Sprintf("<undecodable: %s>", err)
+		}
+		return text
+	}
+
+	summary, err := diffStores(baseKV, againstKV, func(entry *storeDiffEntry) error {
+		if summaryOnly {
+			return nil
+		}
+		switch entry.kind {
+		case storeDiffAdded:
+			fmt.Printf("+ %s: %s\n", entry.key, decode(againstDecoder, entry.againstValue))
+		case storeDiffRemoved:
+			fmt.Printf("- %s: %s\n", entry.key, decode(baseDecoder, entry.baseValue))
+		case storeDiffChanged:
+			fmt.Printf("~ %s: %s -> %s\n", entry.key, decode(baseDecoder, entry.baseValue), decode(againstDecoder, entry.againstValue))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\nBase: %s (%s), against: %s (%s)\n", baseFile.Filename, base.moduleHash, againstFile.Filename, against.moduleHash)
+	fmt.Printf("Added: %d, removed: %d, changed: %d, unchanged: %d\n", summary.added, summary.removed, summary.changed, summary.unchanged)
+	return nil
+}
+
+type storeDiffKind int
+
+const (
+	storeDiffAdded storeDiffKind = iota
+	storeDiffRemoved
+	storeDiffChanged
+)
+
+type storeDiffEntry struct {
+	kind         storeDiffKind
+	key          string
+	baseValue    []byte
+	againstValue []byte
+}
+
+type storeDiffSummary struct {
+	added     uint64
+	removed   uint64
+	changed   uint64
+	unchanged uint64
+}
+
+// diffStores calls `f` for each key removed or changed from `base` to `against`, in key
+// order, then for each key added in `against`, in key order. It fails if a snapshot
+// could not be read completely, as its missing keys would be reported as differences.
+func diffStores(base, against store.Reader, f func(entry *storeDiffEntry) error) (*storeDiffSummary, error) {
+	summary := &storeDiffSummary{}
+
+	err := base.ScanPrefix("", 0, func(key string, baseValue []byte) error {
+		againstValue, found := against.GetLast(key)
+		switch {
+		case !found:
+			summary.removed++
+			return f(&storeDiffEntry{kind: storeDiffRemoved, key: key, baseValue: baseValue})
+		case !bytes.Equal(baseValue, againstValue):
+			summary.changed++
+			return f(&storeDiffEntry{kind: storeDiffChanged, key: key, baseValue: baseValue, againstValue: againstValue})
+		}
+		summary.unchanged++
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scanning base store: %w", err)
+	}
+	if err := storesErr(base, against); err != nil {
+		return nil, err
+	}
+
+	err = against.ScanPrefix("", 0, func(key string, againstValue []byte) error {
+		if base.HasLast(key) {
+			return nil
+		}
+		summary.added++
+		return f(&storeDiffEntry{kind: storeDiffAdded, key: key, againstValue: againstValue})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scanning against store: %w", err)
+	}
+	if err := storesErr(base, against); err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+func storesErr(base, against store.Reader) error {
+	if err := base.Err(); err != nil {
+		return fmt.Errorf("reading base store: %w", err)
+	}
+	if err := against.Err(); err != nil {
+		return fmt.Errorf("reading against store: %w", err)
+	}
+	return nil
+}
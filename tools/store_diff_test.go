@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/streamingfast/dstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/streamingfast/substreams/storage/store"
+)
+
+// failingReader is a store whose snapshot could not be read completely.
+type failingReader struct {
+	store.Reader
+	err error
+}
+
+func (r *failingReader) Err() error { return r.err }
+
+func Test_diffStores(t *testing.T) {
+	newStore := func(kv map[string]string) *store.FullKV {
+		conf, err := store.NewConfig("test", 0, "abc", pbsubstreams.Module_KindStore_UPDATE_POLICY_SET, "string", dstore.NewMockStore(nil), "")
+		require.NoError(t, err)
+		s := conf.NewFullKV(zap.NewNop())
+		for k, v := range kv {
+			s.Set(0, k, v)
+		}
+		return s
+	}
+	readErr := errors.New("unexpected EOF")
+
+	tests := []struct {
+		name        string
+		base        store.Reader
+		against     store.Reader
+		wantEntries []storeDiffEntry
+		wantSummary *storeDiffSummary
+		wantErr     string
+	}{
+		{
+			name:        "unchanged",
+			base:        newStore(map[string]string{"a": "1", "b": "2"}),
+			against:     newStore(map[string]string{"a": "1", "b": "2"}),
+			wantSummary: &storeDiffSummary{unchanged: 2},
+		},
+		{
+			name:    "added, removed and changed",
+			base:    newStore(map[string]string{"a": "1", "b": "2", "c": "3"}),
+			against: newStore(map[string]string{"b": "2", "c": "4", "d": "5"}),
+			wantEntries: []storeDiffEntry{
+				{kind: storeDiffRemoved, key: "a", baseValue: []byte("1")},
+				{kind: storeDiffChanged, key: "c", baseValue: []byte("3"), againstValue: []byte("4")},
+				{kind: storeDiffAdded, key: "d", againstValue: []byte("5")},
+			},
+			wantSummary: &storeDiffSummary{added: 1, removed: 1, changed: 1, unchanged: 1},
+		},
+		{
+			name:    "base read error",
+			base:    &failingReader{Reader: newStore(map[string]string{"a": "1"}), err: readErr},
+			against: newStore(map[string]string{"a": "1", "b": "2"}),
+			wantErr: "reading base store: unexpected EOF",
+		},
+		{
+			name:    "against read error",
+			base:    newStore(map[string]string{"a": "1", "b": "2"}),
+			against: &failingReader{Reader: newStore(map[string]string{"a": "1"}), err: readErr},
+			wantErr: "reading against store: unexpected EOF",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var entries []storeDiffEntry
+			summary, err := diffStores(tt.base, tt.against, func(entry *storeDiffEntry) error {
+				entries = append(entries, *entry)
+				return nil
+			})
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantErr, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantEntries, entries)
+			assert.Equal(t, tt.wantSummary, summary)
+		})
+	}
+}
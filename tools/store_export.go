@@ -0,0 +1,213 @@
+package tools
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/spf13/cobra"
+	"github.com/streamingfast/cli"
+	"go.uber.org/zap"
+
+	"github.com/streamingfast/substreams/manifest"
+)
+
+var storeExportCmd = &cobra.Command{
+	Use:   "export [<manifest_file>] <module_name> <state_store_url>",
+	Short: "Export the content of a store snapshot as JSONL, CSV or Parquet",
+	Long: cli.Dedent(`
+		Exports all the keys of a complete store snapshot, in key order, along with their values decoded using
+		the module's value type: protobuf values are rendered as JSON, bytes values as base64 and other values as is.
+		The latest snapshot is exported unless '--block' is set to the end block of another one.
+
+		The Parquet format is only available in binaries built with the 'parquet' build tag
+		('go install -tags parquet ./cmd/substreams'), keeping its dependencies out of the default builds.
+
+		The manifest is optional as it will try to find a file named 'substreams.yaml' in current working directory
+		if nothing entered. You may enter a directory that contains a 'substreams.yaml' file in place of '<manifest_file>',
+		or a link to a remote .spkg file, using urls gs://, http(s)://, ipfs://, etc.
+	`),
+	Example: string(cli.ExamplePrefixed("substreams tools store export", `
+		store_pools gs://[bucket-url-path] --format parquet -o pools.parquet
+		uniswap-v3.spkg store_pools gs://[bucket-url-path] --block 12490000 --format csv
+	`)),
+	RunE:         storeExportE,
+	Args:         cobra.RangeArgs(2, 3),
+	SilenceUsage: true,
+}
+
+func init() {
+	storeExportCmd.Flags().Uint64("block", 0, "Exclusive end block of the snapshot to export, defaults to the latest snapshot")
+	storeExportCmd.Flags().String("format", "jsonl", "Export format, one of 'jsonl', 'csv' or 'parquet' (only in binaries built with the 'parquet' build tag)")
+	storeExportCmd.Flags().StringP("output", "o", "", "Output file, defaults to stdout")
+
+	storeCmd.AddCommand(storeExportCmd)
+}
+
+func storeExportE(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	manifestPath := ""
+	if len(args) == 3 {
+		manifestPath = args[0]
+		args = args[1:]
+	}
+	moduleName := args[0]
+	stateStoreURL := args[1]
+	blockNum := mustGetUint64(cmd, "block")
+	format := mustGetString(cmd, "format")
+	outputPath := mustGetString(cmd, "output")
+
+	s, err := loadStoreModule(manifestPath, moduleName, stateStoreURL)
+	if err != nil {
+		return err
+	}
+
+	kv, file, err := s.loadSnapshot(ctx, blockNum)
+	if err != nil {
+		return err
+	}
+	zlog.Info("exporting store snapshot",
+		zap.String("module", moduleName),
+		zap.String("hash", s.moduleHash),
+		zap.String("file", file.Filename),
+		zap.Uint64("key_count", kv.Length()),
+		zap.String("format", format),
+	)
+
+	var output io.Writer = os.Stdout
+	if outputPath != "" {
+		f, err := os.Create(outputPath)
+		if err != nil {
+			return fmt.Errorf("creating output file: %w", err)
+		}
+		defer f.Close()
+		output = f
+	}
+
+	exporter, err := newStoreExporter(format, output)
+	if err != nil {
+		return err
+	}
+
+	decoder, err := newStoreValueDecoder(s.descriptor)
+	if err != nil {
+		return err
+	}
+	if err := kv.ScanPrefix("", 0, func(key string, value []byte) error {
+		text, isJSON, err := decoder.decode(value)
+		if err != nil {
+			return fmt.Errorf("decoding value of key %q: %w", key, err)
+		}
+		return exporter.Export(key, text, isJSON)
+	}); err != nil {
+		return err
+	}
+
+	return exporter.Close()
+}
+
+// storeValueDecoder renders store values as text: JSON for protobuf values, base64 for
+// bytes values and the value itself for all the other value types.
+type storeValueDecoder struct {
+	valueType string
+	msgDesc   *desc.MessageDescriptor
+}
+
+func newStoreValueDecoder(descriptor *manifest.ModuleDescriptor) (*storeValueDecoder, error) {
+	if descriptor == nil {
+		return nil, fmt.Errorf("no descriptor for the store value type")
+	}
+	if descriptor.ProtoMessageType != "" && descriptor.MessageDescriptor == nil {
+		return nil, fmt.Errorf("protobuf message %q of the store values not found in the package", descriptor.ProtoMessageType)
+	}
+	return &storeValueDecoder{
+		valueType: descriptor.StoreValueType,
+		msgDesc:   descriptor.MessageDescriptor,
+	}, nil
+}
+
+func (d *storeValueDecoder) decode(value []byte) (text string, isJSON bool, err error) {
+	if d.msgDesc != nil {
+		msg := dynamic.NewMessageFactoryWithDefaults().NewDynamicMessage(d.msgDesc)
+		if err := msg.Unmarshal(value); err != nil {
+			return "", false, fmt.Errorf("unmarshalling %s: %w", d.msgDesc.GetFullyQualifiedName(), err)
+		}
+		cnt, err := msg.MarshalJSON()
+		if err != nil {
+			return "", false, fmt.Errorf("marshalling json: %w", err)
+		}
+		return string(cnt), true, nil
+	}
+
+	if d.valueType == "bytes" {
+		return base64.StdEncoding.EncodeToString(value), false, nil
+	}
+	return string(value), false, nil
+}
+
+// newParquetStoreExporter is only set in binaries built with the `parquet` build tag,
+// keeping the parquet library and its dependencies out of the default builds.
+var newParquetStoreExporter func(w io.Writer) (storeExporter, error)
+
+type storeExporter interface {
+	// Export writes one key of the store, `isJSON` telling if `value` is a JSON document.
+	Export(key, value string, isJSON bool) error
+	Close() error
+}
+
+func newStoreExporter(format string, w io.Writer) (storeExporter, error) {
+	switch format {
+	case "jsonl":
+		return &jsonlStoreExporter{encoder: json.NewEncoder(w)}, nil
+	case "csv":
+		writer := csv.NewWriter(w)
+		if err := writer.Write([]string{"key", "value"}); err != nil {
+			return nil, fmt.Errorf("writing csv header: %w", err)
+		}
+		return &csvStoreExporter{writer: writer}, nil
+	case "parquet":
+		if newParquetStoreExporter == nil {
+			return nil, fmt.Errorf("parquet format not available, the binary must be built with the 'parquet' build tag (go install -tags parquet ./cmd/substreams)")
+		}
+		return newParquetStoreExporter(w)
+	}
+	return nil, fmt.Errorf("invalid format %q, expected one of 'jsonl', 'csv' or 'parquet'", format)
+}
+
+type jsonlStoreExporter struct {
+	encoder *json.Encoder
+}
+
+func (e *jsonlStoreExporter) Export(key, value string, isJSON bool) error {
+	row := struct {
+		Key   string `json:"key"`
+		Value any    `json:"value"`
+	}{Key: key, Value: value}
+	if isJSON {
+		row.Value = json.RawMessage(value)
+	}
+	return e.encoder.Encode(row)
+}
+
+func (e *jsonlStoreExporter) Close() error {
+	return nil
+}
+
+type csvStoreExporter struct {
+	writer *csv.Writer
+}
+
+func (e *csvStoreExporter) Export(key, value string, _ bool) error {
+	return e.writer.Write([]string{key, value})
+}
+
+func (e *csvStoreExporter) Close() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
@@ -0,0 +1,46 @@
+//go:build parquet
+
+package tools
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// Parquet is the format notebooks load most efficiently, but the only pure Go writer
+// brings a large dependency tree (thrift, arrow and compression codecs), so it is only
+// built in with the `parquet` build tag.
+func init() {
+	newParquetStoreExporter = func(w io.Writer) (storeExporter, error) {
+		pw, err := writer.NewParquetWriterFromWriter(w, new(storeExportRow), 1)
+		if err != nil {
+			return nil, fmt.Errorf("creating parquet writer: %w", err)
+		}
+		return &parquetStoreExporter{writer: pw}, nil
+	}
+}
+
+type storeExportRow struct {
+	Key   string `parquet:"name=key, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Value string `parquet:"name=value, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+type parquetStoreExporter struct {
+	writer *writer.ParquetWriter
+}
+
+func (e *parquetStoreExporter) Export(key, value string, _ bool) error {
+	if err := e.writer.Write(storeExportRow{Key: key, Value: value}); err != nil {
+		return fmt.Errorf("writing parquet row: %w", err)
+	}
+	return nil
+}
+
+func (e *parquetStoreExporter) Close() error {
+	if err := e.writer.WriteStop(); err != nil {
+		return fmt.Errorf("writing parquet footer: %w", err)
+	}
+	return nil
+}
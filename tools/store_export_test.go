@@ -0,0 +1,115 @@
+package tools
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/streamingfast/substreams/manifest"
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+)
+
+func Test_storeValueDecoder(t *testing.T) {
+	clockDesc, err := desc.LoadMessageDescriptorForMessage(&pbsubstreams.Clock{})
+	require.NoError(t, err)
+	clock, err := proto.Marshal(&pbsubstreams.Clock{Id: "abc", Number: 12})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		descriptor *manifest.ModuleDescriptor
+		value      []byte
+		wantText   string
+		wantJSON   bool
+		wantErr    bool
+	}{
+		{
+			name:       "proto",
+			descriptor: &manifest.ModuleDescriptor{StoreValueType: "proto", ProtoMessageType: "sf.substreams.v1.Clock", MessageDescriptor: clockDesc},
+			value:      clock,
+			wantText:   `{"id":"abc","number":"12"}`,
+			wantJSON:   true,
+		},
+		{
+			name:       "invalid proto",
+			descriptor: &manifest.ModuleDescriptor{StoreValueType: "proto", ProtoMessageType: "sf.substreams.v1.Clock", MessageDescriptor: clockDesc},
+			value:      []byte{0xff},
+			wantErr:    true,
+		},
+		{name: "bigint", descriptor: &manifest.ModuleDescriptor{StoreValueType: "bigint"}, value: []byte("-12345678901234567890"), wantText: "-12345678901234567890"},
+		{name: "bigdecimal", descriptor: &manifest.ModuleDescriptor{StoreValueType: "bigdecimal"}, value: []byte("1.5"), wantText: "1.5"},
+		{name: "int64", descriptor: &manifest.ModuleDescriptor{StoreValueType: "int64"}, value: []byte("42"), wantText: "42"},
+		{name: "float64", descriptor: &manifest.ModuleDescriptor{StoreValueType: "float64"}, value: []byte("0.3"), wantText: "0.3"},
+		{name: "string", descriptor: &manifest.ModuleDescriptor{StoreValueType: "string"}, value: []byte("hello"), wantText: "hello"},
+		{name: "bytes", descriptor: &manifest.ModuleDescriptor{StoreValueType: "bytes"}, value: []byte{0x00, 0xff}, wantText: "AP8="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder, err := newStoreValueDecoder(tt.descriptor)
+			require.NoError(t, err)
+
+			text, isJSON, err := decoder.decode(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantText, text)
+			assert.Equal(t, tt.wantJSON, isJSON)
+		})
+	}
+
+	_, err = newStoreValueDecoder(&manifest.ModuleDescriptor{StoreValueType: "proto", ProtoMessageType: "unknown.Message"})
+	assert.Error(t, err, "message descriptor missing from the package")
+}
+
+func Test_storeExporter(t *testing.T) {
+	type entry struct {
+		key    string
+		value  string
+		isJSON bool
+	}
+	entries := []entry{
+		{key: "pool:a", value: `{"id":"a"}`, isJSON: true},
+		{key: "pool:b", value: "with, comma"},
+		{key: "pool:c", value: `say "hi"`},
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: "jsonl",
+			want: `{"key":"pool:a","value":{"id":"a"}}` + "\n" +
+				`{"key":"pool:b","value":"with, comma"}` + "\n" +
+				`{"key":"pool:c","value":"say \"hi\""}` + "\n",
+		},
+		{
+			format: "csv",
+			want: "key,value\n" +
+				`pool:a,"{""id"":""a""}"` + "\n" +
+				`pool:b,"with, comma"` + "\n" +
+				`pool:c,"say ""hi"""` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			exporter, err := newStoreExporter(tt.format, out)
+			require.NoError(t, err)
+			for _, e := range entries {
+				require.NoError(t, exporter.Export(e.key, e.value, e.isJSON))
+			}
+			require.NoError(t, exporter.Close())
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+
+	_, err := newStoreExporter("xml", &bytes.Buffer{})
+	assert.Error(t, err)
+}
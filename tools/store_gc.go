@@ -0,0 +1,311 @@
+package tools
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/abourget/llerrgroup"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	"github.com/streamingfast/cli"
+	"github.com/streamingfast/dstore"
+	"go.uber.org/zap"
+
+	"github.com/streamingfast/substreams/manifest"
+	"github.com/streamingfast/substreams/storage/store"
+)
+
+var storeGCCmd = &cobra.Command{
+	Use:   "gc <state_store_url> <manifest_file> [<manifest_file>...]",
+	Short: "Delete the store snapshots, partial stores and module caches that are no longer needed",
+	Long: cli.Dedent(`
+		Garbage-collects the state store at <state_store_url> (including the cache tag, if any) for the modules
+		of the given manifests:
+
+		- for each store module, only the full snapshots ending on a multiple of '--keep-every' bundles of
+		  '--state-bundle-size' blocks are kept, along with the latest one;
+		- partial stores ending before the latest full snapshot, already squashed into it, are deleted;
+		- module hash directories, with their snapshots and execution outputs, not referenced by any module of
+		  the given manifests are deleted.
+
+		Use '--dry-run' to only report what would be deleted and the bytes it would reclaim.
+	`),
+	Example: string(cli.ExamplePrefixed("substreams tools store gc", `
+		gs://[bucket-url-path]/[cache-tag] substreams.yaml --dry-run
+		gs://[bucket-url-path]/[cache-tag] ./v1/substreams.yaml ./v2/substreams.yaml --keep-every 100
+	`)),
+	RunE:         storeGCE,
+	Args:         cobra.MinimumNArgs(2),
+	SilenceUsage: true,
+}
+
+func init() {
+	storeGCCmd.Flags().Uint64("keep-every", 10, "Keep the full snapshots ending on a multiple of this number of bundles, the latest snapshot is always kept")
+	storeGCCmd.Flags().Uint64("state-bundle-size", 1000, "Number of blocks between two full snapshots, as configured on the tier2 servers writing them")
+	storeGCCmd.Flags().Bool("dry-run", false, "Only report the files that would be deleted and the bytes they hold")
+	storeGCCmd.Flags().Uint64("parallelism", 10, "Number of files to inspect or delete concurrently")
+
+	storeCmd.AddCommand(storeGCCmd)
+}
+
+// storeGCFile is a file of the state store that is no longer needed.
+type storeGCFile struct {
+	filename string
+	size     uint64
+}
+
+// storeGCGroup is a set of files deleted for the same reason, reported together.
+type storeGCGroup struct {
+	description string
+	files       []*storeGCFile
+}
+
+func (g *storeGCGroup) add(filename string) {
+	g.files = append(g.files, &storeGCFile{filename: filename})
+}
+
+func (g *storeGCGroup) size() (out uint64) {
+	for _, file := range g.files {
+		out += file.size
+	}
+	return out
+}
+
+func storeGCE(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	stateStoreURL := args[0]
+	manifestPaths := args[1:]
+
+	keepEvery := mustGetUint64(cmd, "keep-every")
+	if keepEvery == 0 {
+		return fmt.Errorf("--keep-every must be greater than 0")
+	}
+	bundleSize := mustGetUint64(cmd, "state-bundle-size")
+	if bundleSize == 0 {
+		return fmt.Errorf("--state-bundle-size must be greater than 0")
+	}
+	dryRun := mustGetBool(cmd, "dry-run")
+	parallelism := int(mustGetUint64(cmd, "parallelism"))
+
+	stateStore, err := dstore.NewStore(stateStoreURL, "zst", "zstd", false)
+	if err != nil {
+		return fmt.Errorf("initializing dstore for %q: %w", stateStoreURL, err)
+	}
+
+	referencedHashes := map[string]bool{}
+	var groups []*storeGCGroup
+	for _, manifestPath := range manifestPaths {
+		manifestGroups, err := storeGCManifest(ctx, stateStore, manifestPath, keepEvery*bundleSize, referencedHashes)
+		if err != nil {
+			return fmt.Errorf("manifest %q: %w", manifestPath, err)
+		}
+		groups = append(groups, manifestGroups...)
+	}
+
+	orphanGroups, err := storeGCOrphanHashes(ctx, stateStore, referencedHashes)
+	if err != nil {
+		return err
+	}
+	groups = append(groups, orphanGroups...)
+
+	var files []*storeGCFile
+	for _, group := range groups {
+		files = append(files, group.files...)
+	}
+	if err := storeGCFetchSizes(ctx, stateStore, files, parallelism); err != nil {
+		return err
+	}
+
+	var totalSize uint64
+	for _, group := range groups {
+		if len(group.files) == 0 {
+			continue
+		}
+		groupSize := group.size()
+		totalSize += groupSize
+		fmt.Printf("%s: %d files (%s)\n", group.description, len(group.files), humanize.Bytes(groupSize))
+	}
+
+	if dryRun {
+		fmt.Printf("\nDry run, would reclaim %s in %d files\n", humanize.Bytes(totalSize), len(files))
+		return nil
+	}
+
+	deleted, err := storeGCDelete(ctx, stateStore, files, parallelism)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\nDeleted %d of %d files, reclaimed up to %s\n", deleted, len(files), humanize.Bytes(totalSize))
+	return nil
+}
+
+// storeGCManifest lists the snapshots and partial stores of the store modules of
+// `manifestPath` that can be deleted, and marks the hashes of all its modules as referenced.
+func storeGCManifest(ctx context.Context, stateStore dstore.Store, manifestPath string, keepInterval uint64, referencedHashes map[string]bool) ([]*storeGCGroup, error) {
+	manifestReader, err := manifest.NewReader(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("manifest reader: %w", err)
+	}
+
+	pkg, graph, err := manifestReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+
+	hashes := manifest.NewModuleHashes()
+	var groups []*storeGCGroup
+	for _, module := range pkg.Modules.Modules {
+		hash, err := hashes.HashModule(pkg.Modules, module, graph)
+		if err != nil {
+			return nil, fmt.Errorf("hashing module %q: %w", module.Name, err)
+		}
+		moduleHash := hex.EncodeToString(hash)
+		if referencedHashes[moduleHash] {
+			// Already collected for a previous manifest
+			continue
+		}
+		referencedHashes[moduleHash] = true
+
+		kindStore := module.GetKindStore()
+		if kindStore == nil {
+			continue
+		}
+
+		config, err := store.NewConfig(module.Name, module.InitialBlock, moduleHash, kindStore.UpdatePolicy, kindStore.ValueType, stateStore, "")
+		if err != nil {
+			return nil, fmt.Errorf("initializing store config module %q: %w", module.Name, err)
+		}
+
+		files, err := config.ListSnapshotFiles(ctx, math.MaxUint64)
+		if err != nil {
+			return nil, fmt.Errorf("listing snapshot files of module %q: %w", module.Name, err)
+		}
+
+		snapshots, partials := storeGCSnapshots(files, keepInterval)
+		snapshotsGroup := &storeGCGroup{description: fmt.Sprintf("module %q (%s), full snapshots", module.Name, moduleHash)}
+		for _, file := range snapshots {
+			snapshotsGroup.add(fmt.Sprintf("%s/states/%s", moduleHash, file.Filename))
+		}
+		partialsGroup := &storeGCGroup{description: fmt.Sprintf("module %q (%s), squashed partial stores", module.Name, moduleHash)}
+		for _, file := range partials {
+			partialsGroup.add(fmt.Sprintf("%s/states/%s", moduleHash, file.Filename))
+		}
+		groups = append(groups, snapshotsGroup, partialsGroup)
+	}
+
+	return groups, nil
+}
+
+// storeGCSnapshots returns the full snapshots of `files` not ending on a multiple of
+// `keepInterval`, except the latest one, and the partial stores ending before it.
+func storeGCSnapshots(files []*store.FileInfo, keepInterval uint64) (snapshots, partials []*store.FileInfo) {
+	var latest uint64
+	for _, file := range files {
+		if !file.Partial && file.Range.ExclusiveEndBlock > latest {
+			latest = file.Range.ExclusiveEndBlock
+		}
+	}
+
+	for _, file := range files {
+		end := file.Range.ExclusiveEndBlock
+		if file.Partial {
+			if end <= latest {
+				partials = append(partials, file)
+			}
+			continue
+		}
+		if end != latest && end%keepInterval != 0 {
+			snapshots = append(snapshots, file)
+		}
+	}
+	return
+}
+
+// storeGCOrphanHashes lists the files of the module hash directories of `stateStore` that are not in `referencedHashes`.
+func storeGCOrphanHashes(ctx context.Context, stateStore dstore.Store, referencedHashes map[string]bool) ([]*storeGCGroup, error) {
+	groupsByHash := map[string]*storeGCGroup{}
+	err := stateStore.Walk(ctx, "", func(filename string) error {
+		moduleHash, _, found := strings.Cut(filename, "/")
+		if !found || !isModuleHash(moduleHash) || referencedHashes[moduleHash] {
+			return nil
+		}
+
+		group, found := groupsByHash[moduleHash]
+		if !found {
+			group = &storeGCGroup{description: fmt.Sprintf("unreferenced module hash %s", moduleHash)}
+			groupsByHash[moduleHash] = group
+		}
+		group.add(filename)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walking state store: %w", err)
+	}
+
+	out := make([]*storeGCGroup, 0, len(groupsByHash))
+	for _, group := range groupsByHash {
+		out = append(out, group)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].description < out[j].description
+	})
+	return out, nil
+}
+
+func isModuleHash(in string) bool {
+	if len(in) != 40 {
+		return false
+	}
+	_, err := hex.DecodeString(in)
+	return err == nil
+}
+
+func storeGCFetchSizes(ctx context.Context, stateStore dstore.Store, files []*storeGCFile, parallelism int) error {
+	eg := llerrgroup.New(parallelism)
+	for _, file := range files {
+		if eg.Stop() {
+			break
+		}
+
+		file := file
+		eg.Go(func() error {
+			attrs, err := stateStore.ObjectAttributes(ctx, file.filename)
+			if err != nil {
+				return fmt.Errorf("reading attributes of %q: %w", file.filename, err)
+			}
+			file.size = uint64(attrs.Size)
+			return nil
+		})
+	}
+	return eg.Wait()
+}
+
+func storeGCDelete(ctx context.Context, stateStore dstore.Store, files []*storeGCFile, parallelism int) (uint64, error) {
+	var deleted atomic.Uint64
+	eg := llerrgroup.New(parallelism)
+	for _, file := range files {
+		if eg.Stop() {
+			break
+		}
+
+		file := file
+		eg.Go(func() error {
+			if err := stateStore.DeleteObject(ctx, file.filename); err != nil {
+				zlog.Warn("error deleting file", zap.String("filename", file.filename), zap.Error(err))
+				return nil
+			}
+			deleted.Add(1)
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return 0, fmt.Errorf("running deletes: %w", err)
+	}
+	return deleted.Load(), nil
+}
@@ -0,0 +1,151 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/streamingfast/dstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/streamingfast/substreams/storage/store"
+)
+
+func Test_storeGCSnapshots(t *testing.T) {
+	full := func(end uint64) *store.FileInfo {
+		return store.NewCompleteFileInfo("test", 0, end)
+	}
+	partial := func(start, end uint64) *store.FileInfo {
+		return store.NewPartialFileInfo("test", start, end, "abc")
+	}
+
+	tests := []struct {
+		name          string
+		files         []*store.FileInfo
+		keepInterval  uint64
+		wantSnapshots []*store.FileInfo
+		wantPartials  []*store.FileInfo
+	}{
+		{
+			name:         "no files",
+			keepInterval: 10000,
+		},
+		{
+			name:         "latest snapshot always kept",
+			files:        []*store.FileInfo{full(1000), full(2000), full(3000)},
+			keepInterval: 10000,
+			wantSnapshots: []*store.FileInfo{
+				full(1000), full(2000),
+			},
+		},
+		{
+			name:         "snapshots on keep interval kept",
+			files:        []*store.FileInfo{full(1000), full(2000), full(3000), full(4000), full(5000)},
+			keepInterval: 2000,
+			wantSnapshots: []*store.FileInfo{
+				full(1000), full(3000),
+			},
+		},
+		{
+			name:         "partials squashed into latest snapshot deleted",
+			files:        []*store.FileInfo{full(2000), partial(1000, 2000), partial(2000, 3000), partial(3000, 4000)},
+			keepInterval: 10000,
+			wantPartials: []*store.FileInfo{
+				partial(1000, 2000),
+			},
+		},
+		{
+			name:         "partials kept without snapshot",
+			files:        []*store.FileInfo{partial(0, 1000), partial(1000, 2000)},
+			keepInterval: 10000,
+		},
+		{
+			name:         "module with initial block",
+			files:        []*store.FileInfo{store.NewCompleteFileInfo("test", 1500, 2000), store.NewCompleteFileInfo("test", 1500, 3000), partial(2000, 3000)},
+			keepInterval: 10000,
+			wantSnapshots: []*store.FileInfo{
+				store.NewCompleteFileInfo("test", 1500, 2000),
+			},
+			wantPartials: []*store.FileInfo{
+				partial(2000, 3000),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshots, partials := storeGCSnapshots(tt.files, tt.keepInterval)
+			assert.Equal(t, tt.wantSnapshots, snapshots)
+			assert.Equal(t, tt.wantPartials, partials)
+		})
+	}
+}
+
+func Test_storeGCOrphanHashes(t *testing.T) {
+	referenced := strings.Repeat("a", 40)
+	orphan1 := strings.Repeat("b", 40)
+	orphan2 := strings.Repeat("c", 40)
+
+	tests := []struct {
+		name       string
+		files      []string
+		referenced map[string]bool
+		want       map[string][]string
+	}{
+		{
+			name:       "referenced hash kept",
+			files:      []string{referenced + "/states/0000001000-0000000000.kv", referenced + "/outputs/0000000000-0000001000.output"},
+			referenced: map[string]bool{referenced: true},
+			want:       map[string][]string{},
+		},
+		{
+			name: "orphan hashes grouped",
+			files: []string{
+				referenced + "/states/0000001000-0000000000.kv",
+				orphan1 + "/states/0000001000-0000000000.kv",
+				orphan1 + "/outputs/0000000000-0000001000.output",
+				orphan2 + "/states/0000002000-0000001000.abc.partial",
+			},
+			referenced: map[string]bool{referenced: true},
+			want: map[string][]string{
+				"unreferenced module hash " + orphan1: {
+					orphan1 + "/outputs/0000000000-0000001000.output",
+					orphan1 + "/states/0000001000-0000000000.kv",
+				},
+				"unreferenced module hash " + orphan2: {
+					orphan2 + "/states/0000002000-0000001000.abc.partial",
+				},
+			},
+		},
+		{
+			name: "non module hash directories ignored",
+			files: []string{
+				"substreams.partial.spkg",
+				"not-a-hash/states/0000001000-0000000000.kv",
+				strings.Repeat("z", 40) + "/states/0000001000-0000000000.kv",
+				strings.Repeat("b", 39) + "/states/0000001000-0000000000.kv",
+			},
+			referenced: map[string]bool{},
+			want:       map[string][]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stateStore := dstore.NewMockStore(nil)
+			for _, file := range tt.files {
+				stateStore.SetFile(file, nil)
+			}
+
+			groups, err := storeGCOrphanHashes(context.Background(), stateStore, tt.referenced)
+			require.NoError(t, err)
+
+			got := map[string][]string{}
+			for _, group := range groups {
+				for _, file := range group.files {
+					got[group.description] = append(got[group.description], file.filename)
+				}
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
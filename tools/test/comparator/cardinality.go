@@ -0,0 +1,54 @@
+package comparator
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/streamingfast/substreams/storage/store"
+)
+
+var _ Comparable = (*Cardinality)(nil)
+
+// Cardinality compares the estimate of a base64 encoded `cardinality` store value. The
+// `error` argument sets the accepted relative error, 2% by default.
+type Cardinality struct {
+	expect uint64
+	error  float64
+}
+
+func newCardinality(expect string, args url.Values) (*Cardinality, error) {
+	expected, err := strconv.ParseUint(expect, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse expected cardinality: %w", err)
+	}
+	c := &Cardinality{expect: expected, error: 0.02}
+	if error := args.Get("error"); error != "" {
+		c.error, err = strconv.ParseFloat(error, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse cardinality error: %w", err)
+		}
+	}
+	return c, nil
+}
+
+func (c *Cardinality) Cmp(actual string) (bool, string, error) {
+	sketch, err := base64.StdEncoding.DecodeString(actual)
+	if err != nil {
+		return false, "", fmt.Errorf("[cardinality] failed to decode %q as base64: %w", actual, err)
+	}
+	estimate, err := store.EstimateCardinality(sketch)
+	if err != nil {
+		return false, "", fmt.Errorf("[cardinality] %w", err)
+	}
+
+	dt := float64(estimate) - float64(c.expect)
+	if dt < 0 {
+		dt = -dt
+	}
+	if dt > c.error*float64(c.expect) {
+		return false, fmt.Sprintf("[cardinality] expected estimate %d to equal %d within relative error: %g", estimate, c.expect, c.error), nil
+	}
+	return true, "", nil
+}
@@ -0,0 +1,32 @@
+package comparator
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/streamingfast/substreams/storage/store"
+)
+
+var _ Comparable = (*SetSum)(nil)
+
+// SetSum compares the number held by a `set_sum` store value, ignoring its
+// `set:` or `sum:` prefix.
+type SetSum struct {
+	number *Float
+}
+
+func newSetSum(expect string, args url.Values) (*SetSum, error) {
+	number, err := newFloat(expect, args)
+	if err != nil {
+		return nil, err
+	}
+	return &SetSum{number: number}, nil
+}
+
+func (s *SetSum) Cmp(actual string) (bool, string, error) {
+	number, err := store.TrimSetSumPrefix([]byte(actual))
+	if err != nil {
+		return false, "", fmt.Errorf("[set_sum] %w", err)
+	}
+	return s.number.Cmp(number)
+}
@@ -27,6 +27,10 @@ func NewComparable(expect string, op string, args string) (cmp Comparable, err e
 		cmp, err = newInt(expect, params)
 	case "float":
 		cmp, err = newFloat(expect, params)
+	case "set_sum":
+		cmp, err = newSetSum(expect, params)
+	case "cardinality":
+		cmp, err = newCardinality(expect, params)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("unknown op %q", op)
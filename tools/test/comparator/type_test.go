@@ -20,6 +20,9 @@ func TestNewComparable(t *testing.T) {
 		{"use the op", "helloworld", "string", "", reflect.TypeOf(&String{}), false},
 		{"fails if expect not float", "adsa", "float", "", nil, true},
 		{"fails if expect not int", "adsa", "int", "", nil, true},
+		{"use the set_sum op", "42", "set_sum", "", reflect.TypeOf(&SetSum{}), false},
+		{"use the cardinality op", "1000", "cardinality", "error=0.05", reflect.TypeOf(&Cardinality{}), false},
+		{"fails if expect not cardinality", "-3", "cardinality", "", nil, true},
 	}
 
 	for _, test := range tests {
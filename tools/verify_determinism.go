@@ -0,0 +1,149 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/streamingfast/cli"
+	"github.com/streamingfast/dstore"
+
+	"github.com/streamingfast/substreams"
+	"github.com/streamingfast/substreams/manifest"
+	pbssinternal "github.com/streamingfast/substreams/pb/sf/substreams/intern/v2"
+	"github.com/streamingfast/substreams/pipeline/exec"
+	"github.com/streamingfast/substreams/pipeline/outputmodules"
+	"github.com/streamingfast/substreams/service"
+	"github.com/streamingfast/substreams/wasm"
+)
+
+var verifyDeterminismCmd = &cobra.Command{
+	Use:   "verify-determinism <manifest_url> <module_name> <start_block> <stop_block>",
+	Short: "Runs a module and its dependencies locally, replaying blocks to detect non-deterministic executions",
+	Long: cli.Dedent(`
+		Runs the module and its dependencies from the merged blocks in the given range,
+		executing them a second time in fresh instances on the sampled blocks and comparing
+		the outputs and store deltas of both executions byte for byte. The first divergence
+		is reported with its block number and module name.
+
+		The stores the module depends on are loaded from the state store at the start block,
+		which must be the initial block of the modules unless the state store holds their
+		snapshots at that block.
+	`),
+	Example: Example(`
+		substreams tools verify-determinism ./substreams.yaml map_events 12000000 12001000 --merged-blocks-store ./merged-blocks
+	`),
+	Args: cobra.ExactArgs(4),
+	RunE: verifyDeterminismE,
+}
+
+func init() {
+	verifyDeterminismCmd.Flags().String("merged-blocks-store", "", "URL of the merged blocks store to read the blocks from (required)")
+	verifyDeterminismCmd.Flags().String("state-store", "", "URL of the store holding the stores snapshots and execution outputs, a temporary directory if empty")
+	verifyDeterminismCmd.Flags().Uint64("state-bundle-size", 1000, "Interval in blocks at which the stores are snapshotted")
+	verifyDeterminismCmd.Flags().Uint64("interval", 1, "Replay the modules on the blocks multiple of this interval")
+	verifyDeterminismCmd.Flags().String("wasm-runtime", "", "WASM runtime executing the modules, the default one if empty")
+	verifyDeterminismCmd.Flags().StringArrayP("params", "p", nil, "Set a params for parameterizable modules. Can be specified multiple times. Ex: -p module1=valA -p module2=valX&valY")
+
+	Cmd.AddCommand(verifyDeterminismCmd)
+}
+
+func verifyDeterminismE(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	manifestPath := args[0]
+	moduleName := args[1]
+	startBlock, err := strconv.ParseUint(args[2], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid start block %q: %w", args[2], err)
+	}
+	stopBlock, err := strconv.ParseUint(args[3], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid stop block %q: %w", args[3], err)
+	}
+
+	interval := mustGetUint64(cmd, "interval")
+	if interval == 0 {
+		return fmt.Errorf("interval must be at least 1")
+	}
+
+	manifestReader, err := manifest.NewReader(manifestPath)
+	if err != nil {
+		return fmt.Errorf("manifest reader: %w", err)
+	}
+	pkg, _, err := manifestReader.Read()
+	if err != nil {
+		return fmt.Errorf("read manifest %q: %w", manifestPath, err)
+	}
+
+	params, err := manifest.ParseParams(mustGetStringArray(cmd, "params"))
+	if err != nil {
+		return fmt.Errorf("parsing params: %w", err)
+	}
+	if err := manifest.ApplyParams(params, pkg); err != nil {
+		return fmt.Errorf("apply params: %w", err)
+	}
+
+	outputGraph, err := outputmodules.NewOutputModuleGraph(moduleName, true, pkg.Modules)
+	if err != nil {
+		return fmt.Errorf("module graph: %w", err)
+	}
+
+	mergedBlocksStoreURL := mustGetString(cmd, "merged-blocks-store")
+	if mergedBlocksStoreURL == "" {
+		return fmt.Errorf("the --merged-blocks-store flag is required")
+	}
+	mergedBlocksStore, err := dstore.NewDBinStore(mergedBlocksStoreURL)
+	if err != nil {
+		return fmt.Errorf("setting up merged blocks store from url %q: %w", mergedBlocksStoreURL, err)
+	}
+
+	stateStoreURL := mustGetString(cmd, "state-store")
+	if stateStoreURL == "" {
+		dir, err := os.MkdirTemp("", "substreams-verify-determinism-")
+		if err != nil {
+			return fmt.Errorf("creating temporary state store: %w", err)
+		}
+		defer os.RemoveAll(dir)
+		stateStoreURL = dir
+	}
+	stateStore, err := dstore.NewStore(stateStoreURL, "zst", "zstd", false)
+	if err != nil {
+		return fmt.Errorf("could not create store from %s: %w", stateStoreURL, err)
+	}
+
+	opts := []service.Option{service.WithDeterminismCheck(interval)}
+	if runtime := mustGetString(cmd, "wasm-runtime"); runtime != "" {
+		if err := wasm.ValidateRuntime(runtime); err != nil {
+			return fmt.Errorf("invalid wasm runtime: %w", err)
+		}
+		opts = append(opts, service.WithWasmRuntime(runtime))
+	}
+
+	svc, err := service.NewTier2(zlog, mergedBlocksStore, stateStore, "", mustGetUint64(cmd, "state-bundle-size"), opts...)
+	if err != nil {
+		return fmt.Errorf("setting up local tier2: %w", err)
+	}
+
+	// The last stage executes the modules of the previous stages too
+	err = svc.ProcessLocalRange(ctx, &pbssinternal.ProcessRangeRequest{
+		StartBlockNum: startBlock,
+		StopBlockNum:  stopBlock,
+		OutputModule:  moduleName,
+		Modules:       pkg.Modules,
+		Stage:         uint32(len(outputGraph.StagedUsedModules()) - 1),
+	}, func(substreams.ResponseFromAnyTier) error { return nil })
+
+	var divergence *exec.NonDeterministicError
+	if errors.As(err, &divergence) {
+		fmt.Printf("Divergence at block %d in module %q: %s\n", divergence.BlockNum, divergence.Module, divergence.Reason)
+		return fmt.Errorf("module %q is not deterministic", divergence.Module)
+	}
+	if err != nil {
+		return fmt.Errorf("processing range: %w", err)
+	}
+
+	fmt.Printf("No divergence found replaying the blocks multiple of %d between %d and %d\n", interval, startBlock, stopBlock)
+	return nil
+}
@@ -28,11 +28,7 @@ func (ui *TUI) decoratedBlockScopedData(
 		if _, ok := ui.msgTypes[out.Name]; !ok {
 			continue
 		}
-		if out.DebugInfo != nil {
-			for _, log := range out.DebugInfo.Logs {
-				s = append(s, fmt.Sprintf("%s: log: %s\n", out.Name, log))
-			}
-		}
+		s = append(s, ui.decoratedLogs(out.Name, out.DebugInfo)...)
 
 		if len(out.MapOutput.Value) != 0 {
 			msgDesc := ui.msgDescs[out.Name]
@@ -50,9 +46,7 @@ func (ui *TUI) decoratedBlockScopedData(
 		if _, ok := ui.msgTypes[out.Name]; !ok {
 			continue
 		}
-		for _, log := range out.DebugInfo.Logs {
-			s = append(s, fmt.Sprintf("%s: log: %s\n", out.Name, log))
-		}
+		s = append(s, ui.decoratedLogs(out.Name, out.DebugInfo)...)
 
 		if len(out.DebugStoreDeltas) != 0 {
 			if out.DebugInfo != nil && out.DebugInfo.Cached {
@@ -68,6 +62,16 @@ func (ui *TUI) decoratedBlockScopedData(
 	return nil
 }
 
+func (ui *TUI) decoratedLogs(modName string, debugInfo *pbsubstreamsrpc.OutputDebugInfo) (s []string) {
+	if debugInfo == nil {
+		return nil
+	}
+	for _, i := range debugInfo.FilterLogs(ui.minLogLevel) {
+		s = append(s, fmt.Sprintf("%s: log: %s\n", modName, debugInfo.Logs[i]))
+	}
+	return
+}
+
 func cachedValues(name string) string {
 	return fmt.Sprintf("Cached value(s) for %s\n", name)
 }
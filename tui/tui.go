@@ -33,6 +33,7 @@ type TUI struct {
 	isTerminal        bool
 	outputMode        OutputMode
 	prettyPrintOutput bool
+	minLogLevel       pbsubstreamsrpc.ModuleLog_Level
 
 	prog          *tea.Program
 	seenFirstData bool
@@ -56,6 +57,12 @@ func New(req *pbsubstreamsrpc.Request, pkg *pbsubstreams.Package, outputStreamNa
 	return ui
 }
 
+// SetMinLogLevel only prints the module logs at `level` or above, all of them if it is
+// UNSET.
+func (ui *TUI) SetMinLogLevel(level pbsubstreamsrpc.ModuleLog_Level) {
+	ui.minLogLevel = level
+}
+
 func (ui *TUI) Init(outputMode string) error {
 	if err := ui.configureOutputMode(outputMode); err != nil {
 		return err
@@ -79,6 +86,8 @@ func (ui *TUI) Init(outputMode string) error {
 					msgType = modKind.KindStore.ValueType
 				case *pbsubstreams.Module_KindMap_:
 					msgType = modKind.KindMap.OutputType
+				case *pbsubstreams.Module_KindBlockIndex_:
+					msgType = modKind.KindBlockIndex.OutputType
 				}
 				msgType = strings.TrimPrefix(msgType, "proto:")
 
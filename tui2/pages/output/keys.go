@@ -24,6 +24,7 @@ func (o *Output) FullHelp() [][]key.Binding {
 		{
 			keymap.PrevNextModule,
 			keymap.ToggleLogs,
+			keymap.CycleLogLevel,
 			keymap.ToggleBytesFormat,
 		},
 		{
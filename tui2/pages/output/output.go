@@ -51,6 +51,7 @@ type Output struct {
 	//moduleSearchView
 	outputModule string
 	logsEnabled  bool
+	minLogLevel  pbsubstreamsrpc.ModuleLog_Level
 
 	searchEnabled                   bool
 	searchCtx                       *search.Search
@@ -225,6 +226,9 @@ func (o *Output) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "L":
 			o.logsEnabled = !o.logsEnabled
 			o.setOutputViewContent(true)
+		case "V":
+			o.minLogLevel = (o.minLogLevel + 1) % pbsubstreamsrpc.ModuleLog_Level(len(pbsubstreamsrpc.ModuleLog_Level_name))
+			o.setOutputViewContent(true)
 		case "m":
 			o.moduleSearchEnabled = true
 			o.setOutputViewContent(true)
@@ -285,6 +289,7 @@ func (o *Output) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 type displayContext struct {
 	blockCtx          request.BlockContext
 	logsEnabled       bool
+	minLogLevel       pbsubstreamsrpc.ModuleLog_Level
 	searchViewEnabled bool
 	searchQuery       string
 	payload           *pbsubstreamsrpc.AnyModuleOutput
@@ -295,6 +300,7 @@ type displayContext struct {
 func (o *Output) setOutputViewContent(forcedRender bool) {
 	displayCtx := &displayContext{
 		logsEnabled:       o.logsEnabled,
+		minLogLevel:       o.minLogLevel,
 		blockCtx:          o.active,
 		searchViewEnabled: o.searchEnabled,
 		searchQuery:       o.searchCtx.Current.Query,
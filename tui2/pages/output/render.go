@@ -48,6 +48,16 @@ func (o *Output) wrapLogs(log string) string {
 	return result
 }
 
+func (o *Output) logLabelStyle(level pbsubstreamsrpc.ModuleLog_Level) lipgloss.Style {
+	switch level {
+	case pbsubstreamsrpc.ModuleLog_WARN:
+		return o.Styles.Output.LogWarnLabel
+	case pbsubstreamsrpc.ModuleLog_ERROR:
+		return o.Styles.Output.LogErrorLabel
+	}
+	return o.Styles.Output.LogLabel
+}
+
 type renderedOutput struct {
 	plainErrorReceived string
 	plainLogs          string
@@ -78,15 +88,20 @@ func (o *Output) renderedOutput(in *pbsubstreamsrpc.AnyModuleOutput, withStyle b
 	if o.logsEnabled {
 		if debugInfo := in.DebugInfo(); debugInfo != nil {
 			var plainLogs []string
-			for _, log := range debugInfo.Logs {
+			indexes := debugInfo.FilterLogs(o.minLogLevel)
+			if withStyle && o.minLogLevel != pbsubstreamsrpc.ModuleLog_UNSET {
+				out.styledLogs.WriteString(o.Styles.Output.LogLabel.Render(fmt.Sprintf("logs at %s level and above (%d of %d)\n", o.minLogLevel, len(indexes), len(debugInfo.Logs))))
+			}
+			for _, i := range indexes {
+				log := debugInfo.Logs[i]
 				plainLogs = append(plainLogs, fmt.Sprintf("log: %s", log))
 				if withStyle {
-					out.styledLogs.WriteString(o.Styles.Output.LogLabel.Render("log: "))
+					out.styledLogs.WriteString(o.logLabelStyle(debugInfo.LogLevel(i)).Render("log: "))
 					out.styledLogs.WriteString(o.Styles.Output.LogLine.Render(o.wrapLogs(log)))
 					out.styledLogs.WriteString("\n")
 				}
 			}
-			if withStyle && len(debugInfo.Logs) != 0 {
+			if withStyle && (len(indexes) != 0 || o.minLogLevel != pbsubstreamsrpc.ModuleLog_UNSET) {
 				out.styledLogs.WriteString("\n")
 			}
 			out.plainLogs = strings.Join(plainLogs, "\n")
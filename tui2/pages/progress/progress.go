@@ -162,7 +162,11 @@ func (p *Progress) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					mod.TotalStoreDeleteprefixCount/totalBlocks,
 					mod.TotalStoreOperationTimeMs/mod.TotalProcessingTimeMs)
 			}
-			newSlowestModules = append(newSlowestModules, fmt.Sprintf("%*s %8sms per block%s%s", moduleNameLen, mod.Name, humanize.Comma(int64(ratio)), storeMetrics, externalMetrics))
+			var fuelMetrics string
+			if mod.TotalFuelConsumed != 0 {
+				fuelMetrics = fmt.Sprintf(" [fuel: %s/blk]", humanize.Comma(int64(mod.TotalFuelConsumed/totalBlocks)))
+			}
+			newSlowestModules = append(newSlowestModules, fmt.Sprintf("%*s %8sms per block%s%s%s", moduleNameLen, mod.Name, humanize.Comma(int64(ratio)), fuelMetrics, storeMetrics, externalMetrics))
 		}
 
 		for i, stage := range msg.Stages {
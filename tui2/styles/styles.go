@@ -72,9 +72,11 @@ type NavigatorStyle struct {
 }
 
 type OutputStyle struct {
-	LogLabel  lipgloss.Style
-	LogLine   lipgloss.Style
-	ErrorLine lipgloss.Style
+	LogLabel      lipgloss.Style
+	LogWarnLabel  lipgloss.Style
+	LogErrorLabel lipgloss.Style
+	LogLine       lipgloss.Style
+	ErrorLine     lipgloss.Style
 }
 
 type ModSelectStyle struct {
@@ -246,9 +248,11 @@ func DefaultStyles() *Styles {
 	}
 
 	s.Output = OutputStyle{
-		LogLabel:  lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Dark: "243", Light: "248"}),
-		LogLine:   lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Dark: "252", Light: "242"}),
-		ErrorLine: lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Dark: "1", Light: "9"}),
+		LogLabel:      lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Dark: "243", Light: "248"}),
+		LogWarnLabel:  lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Dark: "3", Light: "11"}),
+		LogErrorLabel: lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Dark: "1", Light: "9"}),
+		LogLine:       lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Dark: "252", Light: "242"}),
+		ErrorLine:     lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Dark: "1", Light: "9"}),
 	}
 
 	s.ModSelect = ModSelectStyle{
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/streamingfast/substreams/wasm"
+)
+
+// TestCompilationCache expects all runtimes to produce the same outputs when loading the
+// benchmark module from the compilation cache as when compiling it.
+func TestCompilationCache(t *testing.T) {
+	wasmCode := readCode(t, "substreams_wasm/substreams.wasm")
+	arguments := args(blockInputFile(t, "testdata/ethereum_mainnet_block_16021772.binpb"))
+
+	cache, err := wasm.OpenCompilationCache(t.TempDir(), wasm.DefaultCompilationCacheMaxBytes)
+	require.NoError(t, err)
+
+	for _, runtimeName := range wasm.RuntimeNames() {
+		t.Run(runtimeName, func(t *testing.T) {
+			reference := executeCall(t, runtimeName, wasmCode, "map_block", arguments)
+
+			compiled := executeCall(t, runtimeName, wasmCode, "map_block", arguments, wasm.WithCompilationCache(cache))
+			cached := executeCall(t, runtimeName, wasmCode, "map_block", arguments, wasm.WithCompilationCache(cache))
+
+			assert.Equal(t, reference.Output(), compiled.Output())
+			assert.Equal(t, reference.Output(), cached.Output())
+		})
+	}
+}
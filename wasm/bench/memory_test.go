@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/streamingfast/substreams/wasm"
+)
+
+// TestMemoryLimit expects all runtimes to report the memory used by a call, and to fail
+// the modules using more than the configured limit. The benchmark module starts with 17
+// pages of memory, and grows to around 50 pages to decode a block.
+func TestMemoryLimit(t *testing.T) {
+	wasmCode := readCode(t, "substreams_wasm/substreams.wasm")
+	arguments := args(blockInputFile(t, "testdata/ethereum_mainnet_block_16021772.binpb"))
+
+	for _, runtimeName := range wasm.RuntimeNames() {
+		t.Run(runtimeName, func(t *testing.T) {
+			call := executeCall(t, runtimeName, wasmCode, "map_block", arguments)
+			pages := call.MemorySize / wasm.PageSize
+			require.Greater(t, pages, uint64(20))
+
+			call = executeCall(t, runtimeName, wasmCode, "map_block", arguments, wasm.WithMaxMemoryPages(uint32(pages)))
+			assert.Equal(t, pages*wasm.PageSize, call.MemorySize)
+
+			_, err := tryExecuteCall(runtimeName, wasmCode, "map_block", arguments, wasm.WithMaxMemoryPages(uint32(pages)-4))
+			assert.ErrorIs(t, err, wasm.ErrMemoryLimitExceeded)
+
+			// smaller than the initial memory of the module
+			_, err = tryExecuteCall(runtimeName, wasmCode, "map_block", arguments, wasm.WithMaxMemoryPages(10))
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/streamingfast/substreams/metrics"
+	"github.com/streamingfast/substreams/wasm"
+)
+
+// TestRuntimeParity runs the entrypoints of the benchmark module on every block of
+// `testdata` with all runtimes, and expects byte for byte identical outputs and logs.
+func TestRuntimeParity(t *testing.T) {
+	require.Len(t, wasm.RuntimeNames(), 2, "all runtimes should be registered")
+	wasmCode := readCode(t, "substreams_wasm/substreams.wasm")
+
+	blockFiles, err := filepath.Glob("testdata/*.binpb")
+	require.NoError(t, err)
+	require.NotEmpty(t, blockFiles)
+
+	for _, blockFile := range blockFiles {
+		for _, entrypoint := range []string{"map_noop", "map_decode_proto_only", "map_block"} {
+			t.Run(fmt.Sprintf("%s/%s", filepath.Base(blockFile), entrypoint), func(t *testing.T) {
+				arguments := args(blockInputFile(t, blockFile))
+				if entrypoint == "map_noop" {
+					arguments = args(wasm.NewParamsInput(""))
+				}
+
+				var reference *wasm.Call
+				for _, runtimeName := range wasm.RuntimeNames() {
+					call := executeCall(t, runtimeName, wasmCode, entrypoint, arguments)
+					if reference == nil {
+						reference = call
+						continue
+					}
+
+					assert.Equal(t, reference.Output(), call.Output(), "output of runtime %q differs from %q", runtimeName, wasm.RuntimeNames()[0])
+					assert.Equal(t, reference.Logs, call.Logs, "logs of runtime %q differ from %q", runtimeName, wasm.RuntimeNames()[0])
+				}
+			})
+		}
+	}
+}
+
+// TestFuelMetering expects all runtimes to report the same fuel consumed by a call when
+// fuel metering is enabled, and none otherwise.
+func TestFuelMetering(t *testing.T) {
+	wasmCode := readCode(t, "substreams_wasm/substreams.wasm")
+	arguments := args(blockInputFile(t, "testdata/ethereum_mainnet_block_16021772.binpb"))
+
+	fuelConsumed := map[string]uint64{}
+	for _, runtimeName := range wasm.RuntimeNames() {
+		t.Run(runtimeName, func(t *testing.T) {
+			call := executeCall(t, runtimeName, wasmCode, "map_block", arguments)
+			assert.Zero(t, call.FuelConsumed)
+
+			call = executeCall(t, runtimeName, wasmCode, "map_block", arguments, wasm.WithFuelMetering())
+			assert.NotZero(t, call.FuelConsumed)
+			fuelConsumed[runtimeName] = call.FuelConsumed
+
+			again := executeCall(t, runtimeName, wasmCode, "map_block", arguments, wasm.WithFuelMetering())
+			assert.Equal(t, call.FuelConsumed, again.FuelConsumed, "fuel consumption should be deterministic")
+		})
+	}
+	assert.Equal(t, fuelConsumed["wasmtime"], fuelConsumed["wazero"], "all runtimes should consume the same fuel")
+}
+
+func executeCall(t *testing.T, runtimeName string, code []byte, entrypoint string, arguments []wasm.Argument, opts ...wasm.RegistryOption) *wasm.Call {
+	t.Helper()
+	call, err := tryExecuteCall(runtimeName, code, entrypoint, arguments, opts...)
+	require.NoError(t, err, "runtime %q", runtimeName)
+	require.NoError(t, call.Err(), "runtime %q", runtimeName)
+	return call
+}
+
+func tryExecuteCall(runtimeName string, code []byte, entrypoint string, arguments []wasm.Argument, opts ...wasm.RegistryOption) (*wasm.Call, error) {
+	ctx := context.Background()
+
+	module, err := wasm.NewRegistryWithRuntime(runtimeName, nil, 0, opts...).NewModule(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	defer module.Close(ctx)
+
+	stats := metrics.NewReqStats(&metrics.Config{}, zap.NewNop())
+	call := wasm.NewCall(nil, entrypoint, entrypoint, stats, arguments)
+	instance, err := module.ExecuteNewCall(ctx, call, nil, arguments)
+	if err != nil {
+		return call, err
+	}
+	return call, instance.Close(ctx)
+}
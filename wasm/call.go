@@ -7,6 +7,7 @@ import (
 
 	"github.com/dustin/go-humanize"
 	"github.com/shopspring/decimal"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/streamingfast/substreams/metrics"
 	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
@@ -28,8 +29,11 @@ type Call struct {
 	panicError  *PanicError
 
 	Logs           []string
+	StructuredLogs []LogEntry // nil unless a structured log was appended, then one entry per log of `Logs`
 	LogsByteCount  uint64
 	ExecutionStack []string
+	FuelConsumed   uint64 // 0 unless fuel metering is enabled on the registry
+	MemorySize     uint64 // size in bytes of the module's linear memory after the call
 	stats          *metrics.Stats
 }
 
@@ -82,6 +86,23 @@ func (c *Call) Err() error {
 	return nil
 }
 
+// StoreErr returns the error that occurred reading the snapshot of the output store or
+// of one of the input stores during the call. Unlike the errors of Err, it does not
+// depend on the module and its inputs.
+func (c *Call) StoreErr() error {
+	if c.outputStore != nil {
+		if err := c.outputStore.Err(); err != nil {
+			return err
+		}
+	}
+	for _, inputStore := range c.inputStores {
+		if err := inputStore.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (c *Call) Output() []byte {
 	return c.returnValue
 }
@@ -95,6 +116,32 @@ func (c *Call) SetPanicError(message string, filename string, lineNo int, colNo
 }
 
 func (c *Call) AppendLog(message string) {
+	c.appendLog(LogEntry{Message: message}, message)
+}
+
+// AppendStructuredLog appends a log with a level and the fields encoded in `fields` as
+// a JSON object, see ParseLogFields. It is kept rendered in `Logs`, and as is in
+// `StructuredLogs`.
+func (c *Call) AppendStructuredLog(level LogLevel, message string, fields []byte) {
+	if level <= LogLevelUnset || level > LogLevelError {
+		panic(fmt.Errorf("invalid log level %d, must be between %d (trace) and %d (error)", level, LogLevelTrace, LogLevelError))
+	}
+	parsedFields, err := ParseLogFields(fields)
+	if err != nil {
+		panic(err)
+	}
+
+	if c.StructuredLogs == nil {
+		c.StructuredLogs = make([]LogEntry, 0, len(c.Logs)+1)
+		for _, log := range c.Logs {
+			c.StructuredLogs = append(c.StructuredLogs, LogEntry{Message: log})
+		}
+	}
+	entry := LogEntry{Level: level, Message: message, Fields: parsedFields}
+	c.appendLog(entry, entry.String())
+}
+
+func (c *Call) appendLog(entry LogEntry, message string) {
 	// len(<string>) in Go count number of bytes and not characters, so we are good here
 	if len(message) > MaxLogByteCount {
 		panic(fmt.Errorf("message to log is too big, size is %s, max is %s", humanize.IBytes(uint64(len(message))), humanize.IBytes(uint64(MaxLogByteCount))))
@@ -102,6 +149,9 @@ func (c *Call) AppendLog(message string) {
 	c.LogsByteCount += uint64(len(message))
 	if !c.ReachedLogsMaxByteCount() {
 		c.Logs = append(c.Logs, message)
+		if c.StructuredLogs != nil {
+			c.StructuredLogs = append(c.StructuredLogs, entry)
+		}
 		c.ExecutionStack = append(c.ExecutionStack, fmt.Sprintf("log: %s", message))
 	}
 }
@@ -136,7 +186,25 @@ func (c *Call) DoAppend(ord uint64, key string, value []byte) {
 func (c *Call) DoDeletePrefix(ord uint64, prefix string) {
 	defer c.stats.RecordModuleWasmStoreDeletePrefix(c.ModuleName, c.outputStore.SizeBytes(), time.Since(time.Now()))
 	c.traceStateWrites("delete_prefix", prefix)
-	c.outputStore.DeletePrefix(ord, prefix)
+	if err := c.outputStore.DeletePrefix(ord, prefix); err != nil {
+		c.ReturnError(err)
+	}
+}
+func (c *Call) DoDeleteRange(ord uint64, lowKey, highKey string) {
+	start := time.Now()
+	defer func() { c.stats.RecordModuleWasmStoreWrite(c.ModuleName, c.outputStore.SizeBytes(), time.Since(start)) }()
+	c.traceStateWrites("delete_range", fmt.Sprintf("%s..%s", lowKey, highKey))
+	if err := c.outputStore.DeleteRange(ord, lowKey, highKey); err != nil {
+		c.ReturnError(err)
+	}
+}
+func (c *Call) DoDeleteRangePointers(ord uint64, lowKey, highKey, pointerSeparator string) {
+	start := time.Now()
+	defer func() { c.stats.RecordModuleWasmStoreWrite(c.ModuleName, c.outputStore.SizeBytes(), time.Since(start)) }()
+	c.traceStateWrites("delete_range_pointers", fmt.Sprintf("%s..%s", lowKey, highKey))
+	if err := c.outputStore.DeleteRangePointers(ord, lowKey, highKey, pointerSeparator); err != nil {
+		c.ReturnError(err)
+	}
 }
 func (c *Call) DoAddBigInt(ord uint64, key string, value string) {
 	defer c.stats.RecordModuleWasmStoreWrite(c.ModuleName, c.outputStore.SizeBytes(), time.Since(time.Now()))
@@ -216,13 +284,93 @@ func (c *Call) DoSetMaxBigDecimal(ord uint64, key string, value string) {
 	}
 	c.outputStore.SetMaxBigDecimal(ord, key, toAdd.Truncate(34))
 }
+func (c *Call) DoSetSumInt64(ord uint64, key string, value string) {
+	start := time.Now()
+	defer func() { c.stats.RecordModuleWasmStoreWrite(c.ModuleName, c.outputStore.SizeBytes(), time.Since(start)) }()
+	c.validateWithValueType("set_sum_int64", pbsubstreams.Module_KindStore_UPDATE_POLICY_SET_SUM, "int64", key)
+	if err := c.outputStore.SetSumInt64(ord, key, value); err != nil {
+		c.ReturnError(err)
+	}
+}
+func (c *Call) DoSetSumBigInt(ord uint64, key string, value string) {
+	start := time.Now()
+	defer func() { c.stats.RecordModuleWasmStoreWrite(c.ModuleName, c.outputStore.SizeBytes(), time.Since(start)) }()
+	c.validateWithValueType("set_sum_bigint", pbsubstreams.Module_KindStore_UPDATE_POLICY_SET_SUM, "bigint", key)
+	if err := c.outputStore.SetSumBigInt(ord, key, value); err != nil {
+		c.ReturnError(err)
+	}
+}
+func (c *Call) DoSetSumFloat64(ord uint64, key string, value string) {
+	start := time.Now()
+	defer func() { c.stats.RecordModuleWasmStoreWrite(c.ModuleName, c.outputStore.SizeBytes(), time.Since(start)) }()
+	c.validateWithValueType("set_sum_float64", pbsubstreams.Module_KindStore_UPDATE_POLICY_SET_SUM, "float64", key)
+	if err := c.outputStore.SetSumFloat64(ord, key, value); err != nil {
+		c.ReturnError(err)
+	}
+}
+func (c *Call) DoSetSumBigDecimal(ord uint64, key string, value string) {
+	start := time.Now()
+	defer func() { c.stats.RecordModuleWasmStoreWrite(c.ModuleName, c.outputStore.SizeBytes(), time.Since(start)) }()
+	c.validateWithTwoValueTypes("set_sum_bigdecimal", pbsubstreams.Module_KindStore_UPDATE_POLICY_SET_SUM, "bigdecimal", "bigfloat", key)
+	if err := c.outputStore.SetSumBigDecimal(ord, key, value); err != nil {
+		c.ReturnError(err)
+	}
+}
+func (c *Call) DoBitwiseOrInt64(ord uint64, key string, value int64) {
+	start := time.Now()
+	defer func() { c.stats.RecordModuleWasmStoreWrite(c.ModuleName, c.outputStore.SizeBytes(), time.Since(start)) }()
+	c.validateWithValueType("bitwise_or_int64", pbsubstreams.Module_KindStore_UPDATE_POLICY_BITWISE_OR, "int64", key)
+	if err := c.outputStore.BitwiseOrInt64(ord, key, value); err != nil {
+		c.ReturnError(err)
+	}
+}
+func (c *Call) DoBitwiseOrBigInt(ord uint64, key string, value string) {
+	start := time.Now()
+	defer func() { c.stats.RecordModuleWasmStoreWrite(c.ModuleName, c.outputStore.SizeBytes(), time.Since(start)) }()
+	c.validateWithValueType("bitwise_or_bigint", pbsubstreams.Module_KindStore_UPDATE_POLICY_BITWISE_OR, "bigint", key)
+	toOr, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		c.ReturnError(fmt.Errorf("parsing bigint: invalid value %q", value))
+	}
+	if err := c.outputStore.BitwiseOrBigInt(ord, key, toOr); err != nil {
+		c.ReturnError(err)
+	}
+}
+func (c *Call) DoBitwiseAndInt64(ord uint64, key string, value int64) {
+	start := time.Now()
+	defer func() { c.stats.RecordModuleWasmStoreWrite(c.ModuleName, c.outputStore.SizeBytes(), time.Since(start)) }()
+	c.validateWithValueType("bitwise_and_int64", pbsubstreams.Module_KindStore_UPDATE_POLICY_BITWISE_AND, "int64", key)
+	if err := c.outputStore.BitwiseAndInt64(ord, key, value); err != nil {
+		c.ReturnError(err)
+	}
+}
+func (c *Call) DoBitwiseAndBigInt(ord uint64, key string, value string) {
+	start := time.Now()
+	defer func() { c.stats.RecordModuleWasmStoreWrite(c.ModuleName, c.outputStore.SizeBytes(), time.Since(start)) }()
+	c.validateWithValueType("bitwise_and_bigint", pbsubstreams.Module_KindStore_UPDATE_POLICY_BITWISE_AND, "bigint", key)
+	toAnd, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		c.ReturnError(fmt.Errorf("parsing bigint: invalid value %q", value))
+	}
+	if err := c.outputStore.BitwiseAndBigInt(ord, key, toAnd); err != nil {
+		c.ReturnError(err)
+	}
+}
+func (c *Call) DoCardinalityAdd(ord uint64, key string, item []byte) {
+	start := time.Now()
+	defer func() { c.stats.RecordModuleWasmStoreWrite(c.ModuleName, c.outputStore.SizeBytes(), time.Since(start)) }()
+	c.validateSimple("cardinality_add", pbsubstreams.Module_KindStore_UPDATE_POLICY_CARDINALITY, key)
+	c.outputStore.CardinalityAdd(ord, key, item)
+}
 
 func (c *Call) DoGetAt(storeIndex int, ord uint64, key string) (value []byte, found bool) {
 	defer c.stats.RecordModuleWasmStoreRead(c.ModuleName, time.Since(time.Now()))
 	c.validateStoreIndex(storeIndex, "get_at")
 	readStore := c.inputStores[storeIndex]
 	c.traceStateReads("get_at", storeIndex, found, key)
-	return readStore.GetAt(ord, key)
+	value, found = readStore.GetAt(ord, key)
+	c.validateStoreErr(readStore)
+	return value, found
 }
 
 func (c *Call) DoHasAt(storeIndex int, ord uint64, key string) (found bool) {
@@ -230,7 +378,9 @@ func (c *Call) DoHasAt(storeIndex int, ord uint64, key string) (found bool) {
 	c.validateStoreIndex(storeIndex, "has_at")
 	readStore := c.inputStores[storeIndex]
 	c.traceStateReads("has_at", storeIndex, found, key)
-	return readStore.HasAt(ord, key)
+	found = readStore.HasAt(ord, key)
+	c.validateStoreErr(readStore)
+	return found
 }
 
 func (c *Call) DoGetFirst(storeIndex int, key string) (value []byte, found bool) {
@@ -238,7 +388,9 @@ func (c *Call) DoGetFirst(storeIndex int, key string) (value []byte, found bool)
 	c.validateStoreIndex(storeIndex, "get_first")
 	readStore := c.inputStores[storeIndex]
 	c.traceStateReads("get_first", storeIndex, found, key)
-	return readStore.GetFirst(key)
+	value, found = readStore.GetFirst(key)
+	c.validateStoreErr(readStore)
+	return value, found
 }
 
 func (c *Call) DoHasFirst(storeIndex int, key string) (found bool) {
@@ -246,7 +398,9 @@ func (c *Call) DoHasFirst(storeIndex int, key string) (found bool) {
 	c.validateStoreIndex(storeIndex, "has_first")
 	readStore := c.inputStores[storeIndex]
 	c.traceStateReads("has_first", storeIndex, found, key)
-	return readStore.HasFirst(key)
+	found = readStore.HasFirst(key)
+	c.validateStoreErr(readStore)
+	return found
 }
 
 func (c *Call) DoGetLast(storeIndex int, key string) (value []byte, found bool) {
@@ -254,7 +408,9 @@ func (c *Call) DoGetLast(storeIndex int, key string) (value []byte, found bool)
 	c.validateStoreIndex(storeIndex, "get_last")
 	readStore := c.inputStores[storeIndex]
 	c.traceStateReads("get_last", storeIndex, found, key)
-	return readStore.GetLast(key)
+	value, found = readStore.GetLast(key)
+	c.validateStoreErr(readStore)
+	return value, found
 }
 
 func (c *Call) DoHasLast(storeIndex int, key string) (found bool) {
@@ -262,7 +418,64 @@ func (c *Call) DoHasLast(storeIndex int, key string) (found bool) {
 	c.validateStoreIndex(storeIndex, "has_last")
 	readStore := c.inputStores[storeIndex]
 	c.traceStateReads("has_last", storeIndex, found, key)
-	return readStore.HasLast(key)
+	found = readStore.HasLast(key)
+	c.validateStoreErr(readStore)
+	return found
+}
+
+func (c *Call) DoScanPrefix(storeIndex int, prefix string, limit uint64) (entries []byte, count int) {
+	start := time.Now()
+	defer func() { c.stats.RecordModuleWasmStoreRead(c.ModuleName, time.Since(start)) }()
+	c.validateStoreIndex(storeIndex, "scan_prefix")
+	readStore := c.inputStores[storeIndex]
+	entries, count = c.scanEntries("scan_prefix", func(f func(key string, value []byte) error) error {
+		return readStore.ScanPrefix(prefix, scanLimit(limit), f)
+	})
+	c.traceStateReads("scan_prefix", storeIndex, count != 0, prefix)
+	return entries, count
+}
+
+func (c *Call) DoScanRange(storeIndex int, lowKey, highKey string, limit uint64) (entries []byte, count int) {
+	start := time.Now()
+	defer func() { c.stats.RecordModuleWasmStoreRead(c.ModuleName, time.Since(start)) }()
+	c.validateStoreIndex(storeIndex, "scan_range")
+	readStore := c.inputStores[storeIndex]
+	entries, count = c.scanEntries("scan_range", func(f func(key string, value []byte) error) error {
+		return readStore.ScanRange(lowKey, highKey, scanLimit(limit), f)
+	})
+	c.traceStateReads("scan_range", storeIndex, count != 0, fmt.Sprintf("%s..%s", lowKey, highKey))
+	return entries, count
+}
+
+// MaxScanLimit is the maximum number of entries returned by a single `scan_prefix` or
+// `scan_range` call, also applied when no limit is given. Modules read more entries by
+// scanning again from the key following the last one returned.
+const MaxScanLimit = 10_000
+
+func scanLimit(limit uint64) uint64 {
+	if limit == 0 || limit > MaxScanLimit {
+		return MaxScanLimit
+	}
+	return limit
+}
+
+func (c *Call) scanEntries(stateFunc string, scan func(f func(key string, value []byte) error) error) ([]byte, int) {
+	out := &pbsubstreams.StoreEntries{}
+	if err := scan(func(key string, value []byte) error {
+		out.Entries = append(out.Entries, &pbsubstreams.StoreEntry{Key: key, Value: value})
+		return nil
+	}); err != nil {
+		c.ReturnError(fmt.Errorf("%q failed: %w", stateFunc, err))
+	}
+	if len(out.Entries) == 0 {
+		return nil, 0
+	}
+
+	data, err := proto.Marshal(out)
+	if err != nil {
+		c.ReturnError(fmt.Errorf("%q failed: marshalling entries: %w", stateFunc, err))
+	}
+	return data, len(out.Entries)
 }
 
 func (c *Call) validateStoreIndex(storeIndex int, stateFunc string) {
@@ -309,6 +522,13 @@ func (c *Call) traceStateReads(stateFunc string, storeIndex int, found bool, key
 	c.ExecutionStack = append(c.ExecutionStack, line)
 }
 
+// validateStoreErr aborts the call when reading `store` failed, see StoreErr.
+func (c *Call) validateStoreErr(store store.Reader) {
+	if err := store.Err(); err != nil {
+		c.ReturnError(err)
+	}
+}
+
 func (c *Call) returnInvalidPolicy(stateFunc, policy string) {
 	panic(fmt.Errorf("module %q: invalid store operation %q, only valid for stores with %s", c.ModuleName, stateFunc, policy))
 }
@@ -325,4 +545,8 @@ var policyMap = map[pbsubstreams.Module_KindStore_UpdatePolicy]string{
 	pbsubstreams.Module_KindStore_UPDATE_POLICY_MIN:               "min",
 	pbsubstreams.Module_KindStore_UPDATE_POLICY_MAX:               "max",
 	pbsubstreams.Module_KindStore_UPDATE_POLICY_APPEND:            "append",
+	pbsubstreams.Module_KindStore_UPDATE_POLICY_SET_SUM:           "set_sum",
+	pbsubstreams.Module_KindStore_UPDATE_POLICY_BITWISE_OR:        "bitwise_or",
+	pbsubstreams.Module_KindStore_UPDATE_POLICY_BITWISE_AND:       "bitwise_and",
+	pbsubstreams.Module_KindStore_UPDATE_POLICY_CARDINALITY:       "cardinality",
 }
@@ -1,11 +1,14 @@
 package wasm
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/streamingfast/dstore"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/streamingfast/substreams/metrics"
 	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
@@ -480,6 +483,40 @@ func Test_CallStoreOps(t *testing.T) {
 	}
 }
 
+func Test_CallScan(t *testing.T) {
+	inputCall := newTestCall(pbsubstreams.Module_KindStore_UPDATE_POLICY_SET, "string")
+	inputCall.DoSet(0, "token:b", []byte("2"))
+	inputCall.DoSet(1, "token:a", []byte("1"))
+	inputCall.DoSet(2, "pool:a", []byte("3"))
+
+	c := &Call{inputStores: []store.Reader{inputCall.outputStore}, stats: inputCall.stats}
+
+	data, count := c.DoScanPrefix(0, "token:", 0)
+	require.Equal(t, 2, count)
+	entries := &pbsubstreams.StoreEntries{}
+	require.NoError(t, proto.Unmarshal(data, entries))
+	assert.Equal(t, "token:a", entries.Entries[0].Key)
+	assert.Equal(t, "1", string(entries.Entries[0].Value))
+	assert.Equal(t, "token:b", entries.Entries[1].Key)
+
+	_, count = c.DoScanRange(0, "a", "token:b", 1)
+	assert.Equal(t, 1, count)
+
+	data, count = c.DoScanRange(0, "x", "", 0)
+	assert.Equal(t, 0, count)
+	assert.Nil(t, data)
+
+	assert.Panics(t, func() { c.DoScanPrefix(1, "token:", 0) })
+
+	for i := 0; i <= MaxScanLimit; i++ {
+		inputCall.DoSet(3, fmt.Sprintf("many:%06d", i), []byte("v"))
+	}
+	_, count = c.DoScanPrefix(0, "many:", 0)
+	assert.Equal(t, MaxScanLimit, count, "scans without limit are capped")
+	_, count = c.DoScanPrefix(0, "many:", MaxScanLimit+1)
+	assert.Equal(t, MaxScanLimit, count, "larger limits are capped")
+}
+
 func newTestCall(updatePolicy pbsubstreams.Module_KindStore_UpdatePolicy, valueType string) *Call {
 	myStore := dstore.NewMockStore(nil)
 	storeConf, err := store.NewConfig("test", 0, "", updatePolicy, valueType, myStore, "test")
@@ -0,0 +1,241 @@
+package wasm
+
+import (
+	"container/list"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/streamingfast/substreams/metrics"
+)
+
+// CompilationCache keeps the code compiled by the runtimes on disk, so that a module is
+// only compiled once across requests and process restarts. Each runtime stores its
+// entries in its own directory, named after a hash of the WASM code and of the settings
+// it was compiled with: either one file per module, or a directory per module holding the
+// files the runtime names itself. The total size of the entries is bounded: the least recently used ones are
+// evicted first.
+type CompilationCache struct {
+	dir      string
+	maxBytes uint64
+
+	lock    sync.Mutex
+	lru     *list.List               // of *cacheEntry, the most recently used first
+	entries map[string]*list.Element // path -> element of lru
+	size    uint64
+}
+
+type cacheEntry struct {
+	path string
+	size uint64
+}
+
+// DefaultCompilationCacheMaxBytes is the default maximum size of a compilation cache.
+const DefaultCompilationCacheMaxBytes = 4 * 1024 * 1024 * 1024
+
+const tempFileSuffix = ".tmp"
+
+var (
+	compilationCachesLock sync.Mutex
+	compilationCaches     = map[string]*CompilationCache{} // absolute dir -> cache
+)
+
+// OpenCompilationCache returns the compilation cache stored in `dir`, holding at most
+// `maxBytes` of compiled code. The entries left in `dir` by previous processes are
+// indexed, the least recently modified being the first evicted. The cache is shared by
+// the whole process: opening the same directory again returns the same cache.
+func OpenCompilationCache(dir string, maxBytes uint64) (*CompilationCache, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("resolving compilation cache directory: %w", err)
+	}
+
+	compilationCachesLock.Lock()
+	defer compilationCachesLock.Unlock()
+
+	if c := compilationCaches[dir]; c != nil {
+		if c.maxBytes != maxBytes {
+			return nil, fmt.Errorf("compilation cache %q already opened with a maximum size of %d bytes", dir, c.maxBytes)
+		}
+		return c, nil
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating compilation cache directory: %w", err)
+	}
+
+	c := &CompilationCache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		lru:      list.New(),
+		entries:  map[string]*list.Element{},
+	}
+
+	type existingEntry struct {
+		cacheEntry
+		modTime time.Time
+	}
+	var existing []existingEntry
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if strings.HasSuffix(path, tempFileSuffix) {
+			// left by an interrupted write
+			return os.Remove(path)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		existing = append(existing, existingEntry{cacheEntry{path, uint64(info.Size())}, info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("indexing compilation cache: %w", err)
+	}
+
+	sort.Slice(existing, func(i, j int) bool { return existing[i].modTime.Before(existing[j].modTime) })
+	for _, entry := range existing {
+		c.add(entry.path, entry.size)
+	}
+	c.evict()
+
+	compilationCaches[dir] = c
+	return c, nil
+}
+
+// Dir returns the directory where `runtime` stores its entries.
+func (c *CompilationCache) Dir(runtime string) string {
+	return filepath.Join(c.dir, runtime)
+}
+
+// Get returns whether the entry at `path` is cached, marking it as the most recently
+// used, and counts a hit or a miss of `runtime`.
+func (c *CompilationCache) Get(runtime string, path string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if elem := c.entries[path]; elem != nil {
+		if _, err := os.Stat(path); err == nil {
+			c.lru.MoveToFront(elem)
+			metrics.WasmCompilationCacheHits.Inc(runtime)
+			return true
+		}
+		c.remove(elem) // deleted by the runtime
+	}
+
+	metrics.WasmCompilationCacheMisses.Inc(runtime)
+	return false
+}
+
+// Put indexes the entry written by a runtime at `path` as the most recently used, then
+// evicts the least recently used entries while the cache is over its maximum size.
+func (c *CompilationCache) Put(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("indexing compilation cache entry: %w", err)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if elem := c.entries[path]; elem != nil {
+		c.remove(elem)
+	}
+	c.add(path, uint64(info.Size()))
+	c.evict()
+	return nil
+}
+
+// Touch marks the entries at `paths` as the most recently used, and counts a hit of
+// `runtime`, for the runtimes writing their entries themselves and indexing them with Put.
+func (c *CompilationCache) Touch(runtime string, paths []string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, path := range paths {
+		if elem := c.entries[path]; elem != nil {
+			c.lru.MoveToFront(elem)
+		}
+	}
+	metrics.WasmCompilationCacheHits.Inc(runtime)
+}
+
+// CountMiss counts a miss of `runtime`, for the runtimes writing their entries themselves
+// and indexing them with Put.
+func (c *CompilationCache) CountMiss(runtime string) {
+	metrics.WasmCompilationCacheMisses.Inc(runtime)
+}
+
+// Write writes `content` at `path` atomically and indexes it, see Put.
+func (c *CompilationCache) Write(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating compilation cache directory: %w", err)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), "*"+tempFileSuffix)
+	if err != nil {
+		return fmt.Errorf("writing compilation cache entry: %w", err)
+	}
+	_, err = f.Write(content)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("writing compilation cache entry: %w", err)
+	}
+
+	return c.Put(path)
+}
+
+// Remove deletes the entry at `path`, when the runtime cannot load it.
+func (c *CompilationCache) Remove(path string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if elem := c.entries[path]; elem != nil {
+		c.remove(elem)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		zlog.Warn("removing compilation cache entry", zap.String("path", path), zap.Error(err))
+	}
+}
+
+func (c *CompilationCache) add(path string, size uint64) {
+	c.entries[path] = c.lru.PushFront(&cacheEntry{path: path, size: size})
+	c.size += size
+	metrics.WasmCompilationCacheSize.SetUint64(c.size)
+}
+
+func (c *CompilationCache) remove(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*cacheEntry)
+	delete(c.entries, entry.path)
+	c.size -= entry.size
+	metrics.WasmCompilationCacheSize.SetUint64(c.size)
+}
+
+// evict removes the least recently used entries while the cache is over its maximum size,
+// always keeping the most recently used one.
+func (c *CompilationCache) evict() {
+	for c.size > c.maxBytes && c.lru.Len() > 1 {
+		elem := c.lru.Back()
+		path := elem.Value.(*cacheEntry).path
+		c.remove(elem)
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			zlog.Warn("evicting compilation cache entry", zap.String("path", path), zap.Error(err))
+		}
+		metrics.WasmCompilationCacheEvictions.Inc()
+	}
+}
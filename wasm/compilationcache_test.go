@@ -0,0 +1,71 @@
+package wasm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompilationCache(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := OpenCompilationCache(dir, 25)
+	require.NoError(t, err)
+
+	same, err := OpenCompilationCache(dir, 25)
+	require.NoError(t, err)
+	assert.Same(t, cache, same)
+	_, err = OpenCompilationCache(dir, 30)
+	assert.Error(t, err)
+
+	entry := func(name string) string { return filepath.Join(cache.Dir("test"), name) }
+
+	assert.False(t, cache.Get("test", entry("a")))
+	require.NoError(t, cache.Write(entry("a"), make([]byte, 10)))
+	require.NoError(t, cache.Write(entry("b"), make([]byte, 10)))
+	assert.True(t, cache.Get("test", entry("a")))
+
+	// over the maximum size, the least recently used entry is evicted
+	require.NoError(t, cache.Write(entry("c"), make([]byte, 10)))
+	assert.True(t, cache.Get("test", entry("a")))
+	assert.False(t, cache.Get("test", entry("b")))
+	assert.True(t, cache.Get("test", entry("c")))
+	assert.NoFileExists(t, entry("b"))
+	assert.Equal(t, uint64(20), cache.size)
+
+	// entries deleted behind its back are forgotten
+	require.NoError(t, os.Remove(entry("c")))
+	assert.False(t, cache.Get("test", entry("c")))
+	assert.Equal(t, uint64(10), cache.size)
+
+	cache.Remove(entry("a"))
+	assert.NoFileExists(t, entry("a"))
+	assert.Equal(t, uint64(0), cache.size)
+}
+
+func TestCompilationCache_ReindexesExistingEntries(t *testing.T) {
+	dir := t.TempDir()
+	runtimeDir := filepath.Join(dir, "test")
+	require.NoError(t, os.MkdirAll(runtimeDir, 0o755))
+
+	now := time.Now()
+	for i, name := range []string{"old", "recent", "newest"} {
+		path := filepath.Join(runtimeDir, name)
+		require.NoError(t, os.WriteFile(path, make([]byte, 10), 0o644))
+		modTime := now.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(runtimeDir, "interrupted"+tempFileSuffix), []byte{1}, 0o644))
+
+	cache, err := OpenCompilationCache(dir, 20)
+	require.NoError(t, err)
+
+	assert.NoFileExists(t, filepath.Join(runtimeDir, "old"))
+	assert.NoFileExists(t, filepath.Join(runtimeDir, "interrupted"+tempFileSuffix))
+	assert.True(t, cache.Get("test", filepath.Join(runtimeDir, "recent")))
+	assert.True(t, cache.Get("test", filepath.Join(runtimeDir, "newest")))
+	assert.Equal(t, uint64(20), cache.size)
+}
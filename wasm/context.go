@@ -11,3 +11,9 @@ func WithContext(ctx context.Context, call *Call) context.Context {
 func FromContext(ctx context.Context) *Call {
 	return ctx.Value("call").(*Call)
 }
+
+// CallFromContext returns the Call of `ctx`, false if there is none.
+func CallFromContext(ctx context.Context) (*Call, bool) {
+	call, ok := ctx.Value("call").(*Call)
+	return call, ok
+}
@@ -0,0 +1,111 @@
+package wasm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LogLevel is the level of a structured log, with the values of the `level` argument
+// of the `logger.log` import. Logs emitted through `logger.println` have no level.
+type LogLevel int32
+
+const (
+	LogLevelUnset LogLevel = iota
+	LogLevelTrace
+	LogLevelDebug
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+)
+
+var logLevelNames = []string{"", "TRACE", "DEBUG", "INFO", "WARN", "ERROR"}
+
+func (l LogLevel) String() string {
+	if l < 0 || int(l) >= len(logLevelNames) {
+		return fmt.Sprintf("LogLevel(%d)", int32(l))
+	}
+	return logLevelNames[l]
+}
+
+// LogField is a key/value pair attached to a structured log.
+type LogField struct {
+	Key   string
+	Value string
+}
+
+// LogEntry is a log emitted by a module, with the level and fields of the structured
+// logs.
+type LogEntry struct {
+	Level   LogLevel
+	Message string
+	Fields  []LogField
+}
+
+// String renders the entry as a single line, as kept in `Call.Logs`: the level between
+// brackets, the message, then the fields as `key=value`, with the values quoted when they
+// hold spaces or quotes.
+func (e LogEntry) String() string {
+	var out strings.Builder
+	if e.Level != LogLevelUnset {
+		out.WriteString("[" + e.Level.String() + "] ")
+	}
+	out.WriteString(e.Message)
+	for _, field := range e.Fields {
+		value := field.Value
+		if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+			value = strconv.Quote(value)
+		}
+		out.WriteString(" " + field.Key + "=" + value)
+	}
+	return out.String()
+}
+
+// ParseLogFields decodes the fields of a structured log, passed to `logger.log` as a JSON
+// object, keeping the order of its keys. String values are kept as is, the other values as
+// their JSON representation. Empty input means no fields.
+func ParseLogFields(in []byte) ([]LogField, error) {
+	if len(in) == 0 {
+		return nil, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(in))
+	decoder.UseNumber()
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, fmt.Errorf("log fields must be a JSON object")
+	}
+
+	var fields []LogField
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("decoding log fields: %w", err)
+		}
+		key := token.(string)
+
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("decoding log field %q: %w", key, err)
+		}
+		value := string(raw)
+		var str string
+		if json.Unmarshal(raw, &str) == nil {
+			value = str
+		} else {
+			var compact bytes.Buffer
+			if err := json.Compact(&compact, raw); err == nil {
+				value = compact.String()
+			}
+		}
+		fields = append(fields, LogField{Key: key, Value: value})
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, fmt.Errorf("decoding log fields: %w", err)
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("log fields must be a single JSON object")
+	}
+	return fields, nil
+}
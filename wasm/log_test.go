@@ -0,0 +1,56 @@
+package wasm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLogFields(t *testing.T) {
+	tests := []struct {
+		name        string
+		in          string
+		expect      []LogField
+		expectedErr bool
+	}{
+		{"empty", "", nil, false},
+		{"empty object", "{}", nil, false},
+		{"keeps order", `{"b":"2","a":"1"}`, []LogField{{"b", "2"}, {"a", "1"}}, false},
+		{"non-string values", `{"n": 12.50, "ok": true, "list": [1, 2]}`, []LogField{{"n", "12.50"}, {"ok", "true"}, {"list", "[1,2]"}}, false},
+		{"not an object", `["a"]`, nil, true},
+		{"trailing data", `{"a":"1"}{}`, nil, true},
+		{"invalid", `{"a":`, nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fields, err := ParseLogFields([]byte(test.in))
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expect, fields)
+		})
+	}
+}
+
+func TestCall_AppendStructuredLog(t *testing.T) {
+	call := &Call{}
+	call.AppendLog("starting")
+	assert.Nil(t, call.StructuredLogs)
+
+	call.AppendStructuredLog(LogLevelWarn, "slow block", []byte(`{"block":"12","reason":"too many calls"}`))
+	call.AppendLog("done")
+
+	assert.Equal(t, []string{"starting", `[WARN] slow block block=12 reason="too many calls"`, "done"}, call.Logs)
+	assert.Equal(t, []LogEntry{
+		{Message: "starting"},
+		{Level: LogLevelWarn, Message: "slow block", Fields: []LogField{{"block", "12"}, {"reason", "too many calls"}}},
+		{Message: "done"},
+	}, call.StructuredLogs)
+
+	assert.Panics(t, func() { call.AppendStructuredLog(LogLevel(6), "invalid", nil) })
+	assert.Panics(t, func() { call.AppendStructuredLog(LogLevelInfo, "invalid", []byte("not json")) })
+}
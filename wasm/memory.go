@@ -0,0 +1,50 @@
+package wasm
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dustin/go-humanize"
+)
+
+// PageSize is the size of a page of WASM linear memory
+const PageSize = 65536
+
+// MaxMemoryPages is the largest number of pages a 32 bits WASM memory can hold (4GiB)
+const MaxMemoryPages = 65536
+
+var ErrMemoryLimitExceeded = errors.New("wasm memory limit exceeded")
+
+// NewMemoryLimitError reports that a module needs `pages` pages of memory, more than
+// the `limit` configured on the registry.
+func NewMemoryLimitError(pages uint64, limit uint32) error {
+	return fmt.Errorf("%w: module uses %d pages (%s), limit is %d pages (%s)", ErrMemoryLimitExceeded,
+		pages, humanize.IBytes(pages*PageSize),
+		limit, humanize.IBytes(uint64(limit)*PageSize),
+	)
+}
+
+// RecordMemory records the size of the module's memory after the call, `pages` pages,
+// and checks it against the `limit` configured on the registry (0 when unlimited).
+//
+// Runtimes enforcing the limit when the memory grows make the module's allocations fail
+// instead, and report it with `growFailed`: Rust modules then abort with an `unreachable`
+// trap, without panicking, so a failed call that could not grow the memory is reported
+// as exceeding the limit. Other failed calls only get the memory size as context.
+func (c *Call) RecordMemory(pages uint64, limit uint32, growFailed bool, callErr error) error {
+	c.MemorySize = pages * PageSize
+	if limit == 0 {
+		return callErr
+	}
+
+	if pages > uint64(limit) {
+		return NewMemoryLimitError(pages, limit)
+	}
+	if callErr == nil {
+		return nil
+	}
+	if growFailed {
+		return fmt.Errorf("%w, failed to grow the memory: %s", NewMemoryLimitError(pages, limit), callErr)
+	}
+	return fmt.Errorf("%w (module uses %d pages of memory, limit is %d pages)", callErr, pages, limit)
+}
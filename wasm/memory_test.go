@@ -0,0 +1,42 @@
+package wasm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCall_RecordMemory(t *testing.T) {
+	trap := errors.New("wasm error: unreachable")
+
+	tests := []struct {
+		name          string
+		pages         uint64
+		limit         uint32
+		growFailed    bool
+		callErr       error
+		expectLimit   bool
+		expectCallErr bool
+	}{
+		{name: "unlimited", pages: 100, callErr: trap, expectCallErr: true},
+		{name: "within limit", pages: 10, limit: 20},
+		{name: "over limit", pages: 30, limit: 20, expectLimit: true},
+		{name: "failed grow", pages: 19, limit: 20, growFailed: true, callErr: trap, expectLimit: true, expectCallErr: true},
+		{name: "trap close to the limit", pages: 19, limit: 20, callErr: trap, expectCallErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &Call{}
+			err := c.RecordMemory(test.pages, test.limit, test.growFailed, test.callErr)
+			assert.Equal(t, test.pages*PageSize, c.MemorySize)
+			assert.Equal(t, test.expectLimit, errors.Is(err, ErrMemoryLimitExceeded))
+			if test.expectCallErr {
+				assert.ErrorContains(t, err, trap.Error())
+			}
+			if !test.expectLimit && !test.expectCallErr {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
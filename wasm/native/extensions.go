@@ -0,0 +1,36 @@
+package native
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/streamingfast/substreams/reqctx"
+	"github.com/streamingfast/substreams/wasm"
+)
+
+type extensionsKey struct{}
+
+func withExtensions(ctx context.Context, extensions map[string]map[string]wasm.WASMExtension) context.Context {
+	return context.WithValue(ctx, extensionsKey{}, extensions)
+}
+
+// CallExtension calls the wasm extension `function` of `namespace` from a handler, the
+// way a WASM module calls its imports, with the `ctx` passed to the handler.
+func CallExtension(ctx context.Context, namespace, function string, in []byte) ([]byte, error) {
+	extensions, _ := ctx.Value(extensionsKey{}).(map[string]map[string]wasm.WASMExtension)
+	ext := extensions[namespace][function]
+	if ext == nil {
+		return nil, fmt.Errorf("wasm extension %q::%q is not defined", namespace, function)
+	}
+
+	var requestID string
+	if details := reqctx.Details(ctx); details != nil {
+		requestID = details.UniqueIDString()
+	}
+
+	out, err := ext(ctx, requestID, wasm.FromContext(ctx).Clock, in)
+	if err != nil {
+		return nil, fmt.Errorf(`running wasm extension "%s::%s": %w`, namespace, function, err)
+	}
+	return out, nil
+}
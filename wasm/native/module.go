@@ -0,0 +1,125 @@
+// Package native implements a runtime executing module handlers written in Go in place
+// of WASM code, to unit-test and debug Substreams with regular Go tooling before porting
+// their logic to Rust. Handlers are registered in-process under the name of a binary of
+// type `native/go-v1`, one per module entrypoint:
+//
+//	binaries:
+//	  default:
+//	    type: native/go-v1
+//	    native: my_substreams
+//
+// Importing this package registers the runtime, the registry then executes binaries of
+// that type with it, alongside WASM binaries. Never use it in production: the module
+// hashes only cover the binary name, not the Go code of its handlers.
+package native
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/streamingfast/substreams/wasm"
+)
+
+// Handler executes a module for a block. It receives the module's arguments in the
+// same order as the WASM entrypoints: `wasm.ValueArgument`s hold the serialized inputs
+// and params, `*wasm.StoreReaderInput`s the input stores, read through the
+// `call.DoGet...` functions with their index among the store inputs, and a
+// `*wasm.StoreWriterOutput` the output store of store modules, written through the
+// `call.Do...` functions.
+//
+// Map modules set their output with `call.SetReturnValue`, and log with `call.AppendLog`.
+// Returning an error fails the execution like a panic of a WASM module.
+type Handler func(ctx context.Context, call *wasm.Call, arguments []wasm.Argument) error
+
+var (
+	handlersLock sync.RWMutex
+	handlers     = map[string]map[string]Handler{} // binary name -> entrypoint -> handler
+)
+
+func init() {
+	wasm.RegisterModuleFactory(wasm.NativeRuntime, wasm.ModuleFactoryFunc(newModule))
+}
+
+// Register sets the handler executing the `entrypoint` of the native binary named
+// `binary`, the entrypoint of a module being its name.
+func Register(binary, entrypoint string, handler Handler) {
+	handlersLock.Lock()
+	defer handlersLock.Unlock()
+
+	if handlers[binary] == nil {
+		handlers[binary] = map[string]Handler{}
+	}
+	handlers[binary][entrypoint] = handler
+}
+
+// Unregister removes the handlers of the native binary named `binary`.
+func Unregister(binary string) {
+	handlersLock.Lock()
+	defer handlersLock.Unlock()
+
+	delete(handlers, binary)
+}
+
+// A Module holds the handlers of a native binary, as registered when the module was
+// loaded.
+type Module struct {
+	binary     string
+	handlers   map[string]Handler
+	extensions map[string]map[string]wasm.WASMExtension
+}
+
+func newModule(_ context.Context, code []byte, registry *wasm.Registry) (wasm.Module, error) {
+	binary := string(code)
+
+	handlersLock.RLock()
+	defer handlersLock.RUnlock()
+
+	registered := handlers[binary]
+	if len(registered) == 0 {
+		return nil, fmt.Errorf("no native handlers registered for binary %q", binary)
+	}
+
+	m := &Module{
+		binary:     binary,
+		handlers:   make(map[string]Handler, len(registered)),
+		extensions: registry.Extensions,
+	}
+	for entrypoint, handler := range registered {
+		m.handlers[entrypoint] = handler
+	}
+	return m, nil
+}
+
+func (m *Module) NewInstance(ctx context.Context) (wasm.Instance, error) {
+	return instance{}, nil
+}
+
+func (m *Module) ExecuteNewCall(ctx context.Context, call *wasm.Call, _ wasm.Instance, arguments []wasm.Argument) (out wasm.Instance, err error) {
+	handler := m.handlers[call.Entrypoint]
+	if handler == nil {
+		return instance{}, fmt.Errorf("could not find entrypoint %q in native binary %q", call.Entrypoint, m.binary)
+	}
+
+	// the `call.Do...` functions panic on invalid operations, like the host functions
+	// of the WASM runtimes
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("call: %v", r)
+		}
+	}()
+
+	ctx = wasm.WithContext(withExtensions(ctx, m.extensions), call)
+	if err := handler(ctx, call, arguments); err != nil {
+		call.SetPanicError(err.Error(), "", 0, 0)
+	}
+	return instance{}, nil
+}
+
+func (m *Module) Close(ctx context.Context) error { return nil }
+
+// instance is stateless, handlers keep no state between calls.
+type instance struct{}
+
+func (instance) Cleanup(ctx context.Context) error { return nil }
+func (instance) Close(ctx context.Context) error   { return nil }
@@ -4,10 +4,23 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 
 	"go.uber.org/zap"
 	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+)
+
+const (
+	// NativeBinaryType is the type of binaries naming a set of module handlers written in
+	// Go, registered in-process, instead of holding WASM code.
+	NativeBinaryType = "native/go-v1"
+
+	// NativeRuntime is the name of the runtime executing binaries of type NativeBinaryType.
+	NativeRuntime = "native"
 )
 
 // Registry from Substreams's perspective is a singleton that is
@@ -18,6 +31,38 @@ type Registry struct {
 	maxFuel              uint64
 	runtimeStack         ModuleFactory
 	instanceCacheEnabled bool
+	profilingDir         string
+	fuelMetering         bool
+	maxMemoryPages       uint32
+	compilationCache     *CompilationCache
+}
+
+type RegistryOption func(*Registry)
+
+// WithFuelMetering makes the runtimes measure the fuel consumed by each call, reported
+// in `Call.FuelConsumed`, as the number of instructions executed, counted the same way by
+// all runtimes. Measuring it slows down the execution.
+func WithFuelMetering() RegistryOption {
+	return func(r *Registry) {
+		r.fuelMetering = true
+	}
+}
+
+// WithMaxMemoryPages limits the linear memory of each module instance to `pages` pages
+// of 64KiB, calls going over it fail with ErrMemoryLimitExceeded. Modules declaring a
+// larger initial memory are rejected when instantiated.
+func WithMaxMemoryPages(pages uint32) RegistryOption {
+	return func(r *Registry) {
+		r.maxMemoryPages = pages
+	}
+}
+
+// WithCompilationCache makes the runtimes keep the code they compile in `cache`, and
+// load it from there instead of compiling it again.
+func WithCompilationCache(cache *CompilationCache) RegistryOption {
+	return func(r *Registry) {
+		r.compilationCache = cache
+	}
 }
 
 func (r *Registry) registerWASMExtension(namespace string, importName string, ext WASMExtension) {
@@ -44,30 +89,83 @@ func (r *Registry) registerWASMExtension(namespace string, importName string, ex
 }
 func (r *Registry) MaxFuel() uint64            { return r.maxFuel }
 func (r *Registry) InstanceCacheEnabled() bool { return r.instanceCacheEnabled }
+func (r *Registry) FuelMetering() bool         { return r.fuelMetering }
+func (r *Registry) MaxMemoryPages() uint32     { return r.maxMemoryPages } // 0 when unlimited
+
+// CompilationCache is the cache of compiled code of the runtimes supporting it, nil when
+// disabled.
+func (r *Registry) CompilationCache() *CompilationCache { return r.compilationCache }
+
+// ProfilingDir is the directory where runtimes supporting it write the execution profile
+// of each module, profiling is disabled when empty.
+func (r *Registry) ProfilingDir() string { return r.profilingDir }
 
 func (r *Registry) NewModule(ctx context.Context, wasmCode []byte) (Module, error) {
 	return r.runtimeStack.NewModule(ctx, wasmCode, r)
 }
 
-func NewRegistry(extensions []WASMExtensioner, maxFuel uint64) *Registry {
-	runtimeName := "wazero" // default
+// NewModuleForBinary instantiates `binary` with the runtime able to execute its type:
+// native binaries run on the native runtime, WASM code on the registry's runtime.
+func (r *Registry) NewModuleForBinary(ctx context.Context, binary *pbsubstreams.Binary) (Module, error) {
+	if binary.Type == NativeBinaryType {
+		factory := runtimes[NativeRuntime]
+		if factory == nil {
+			return nil, fmt.Errorf("binary type %q requires the native runtime, registered by importing the wasm/native package", NativeBinaryType)
+		}
+		return factory.NewModule(ctx, binary.Content, r)
+	}
+	return r.NewModule(ctx, binary.Content)
+}
 
+// DefaultRuntime is the name of the runtime used when none is configured, `wazero` unless
+// overridden by the `SUBSTREAMS_WASM_RUNTIME` env var.
+func DefaultRuntime() string {
 	if selectRuntime := os.Getenv("SUBSTREAMS_WASM_RUNTIME"); selectRuntime != "" {
-		selectedRuntime := runtimes[selectRuntime]
-		if selectedRuntime == nil {
-			panic(fmt.Errorf("could not find wasm runtime specified by `SUBSTREAMS_WASM_RUNTIME` env var: %q", selectRuntime))
-		}
-	} else {
-		zlog.Info("using default wasm runtime", zap.String("runtime", runtimeName))
+		return selectRuntime
+	}
+	return "wazero"
+}
+
+// RuntimeNames returns the names of the registered WASM runtimes, sorted. The native
+// runtime is not one of them, it only executes native binaries.
+func RuntimeNames() []string {
+	names := maps.Keys(runtimes)
+	names = slices.DeleteFunc(names, func(name string) bool { return name == NativeRuntime })
+	sort.Strings(names)
+	return names
+}
+
+// NativeRuntimeRegistered returns whether the native runtime is available to execute
+// binaries of type NativeBinaryType.
+func NativeRuntimeRegistered() bool {
+	return runtimes[NativeRuntime] != nil
+}
+
+// ValidateRuntime returns an error if no WASM runtime is registered under `runtimeName`.
+func ValidateRuntime(runtimeName string) error {
+	if runtimeName == NativeRuntime || runtimes[runtimeName] == nil {
+		return fmt.Errorf("unknown wasm runtime %q (valid values are %q)", runtimeName, strings.Join(RuntimeNames(), ", "))
+	}
+	return nil
+}
+
+func NewRegistry(extensions []WASMExtensioner, maxFuel uint64, opts ...RegistryOption) *Registry {
+	runtimeName := DefaultRuntime()
+	if err := ValidateRuntime(runtimeName); err != nil {
+		panic(fmt.Errorf("invalid runtime specified by `SUBSTREAMS_WASM_RUNTIME` env var: %w", err))
 	}
+	zlog.Info("using wasm runtime", zap.String("runtime", runtimeName))
 
-	return NewRegistryWithRuntime(runtimeName, extensions, maxFuel)
+	return NewRegistryWithRuntime(runtimeName, extensions, maxFuel, opts...)
 }
 
-func NewRegistryWithRuntime(runtimeName string, extensions []WASMExtensioner, maxFuel uint64) *Registry {
+func NewRegistryWithRuntime(runtimeName string, extensions []WASMExtensioner, maxFuel uint64, opts ...RegistryOption) *Registry {
 	r := &Registry{
 		maxFuel: maxFuel,
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
 
 	for _, ext := range extensions {
 		for ns, exts := range ext.WASMExtensions() {
@@ -82,11 +180,15 @@ func NewRegistryWithRuntime(runtimeName string, extensions []WASMExtensioner, ma
 		r.instanceCacheEnabled = true
 	}
 
-	var found bool
-	r.runtimeStack, found = runtimes[runtimeName]
-	if !found {
-		panic(fmt.Errorf("could not find wasm runtime %q (valid values are %q)", runtimeName, strings.Join(maps.Keys(runtimes), ", ")))
+	if dir := os.Getenv("SUBSTREAMS_WASM_PROFILE_DIR"); dir != "" {
+		zlog.Warn("profiling WASM execution because SUBSTREAMS_WASM_PROFILE_DIR variable was set -- this slows down execution significantly, never use it in production.", zap.String("dir", dir))
+		r.profilingDir = dir
+	}
+
+	if err := ValidateRuntime(runtimeName); err != nil {
+		panic(err)
 	}
+	r.runtimeStack = runtimes[runtimeName]
 
 	return r
 }
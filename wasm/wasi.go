@@ -0,0 +1,159 @@
+package wasm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// WASIModuleName is the import module of the WASI preview1 functions, provided to the
+// modules importing it by the runtimes supporting WASI.
+//
+// The WASI layer is deterministic: modules only see a fake clock advancing by a fixed
+// step on each reading, random bytes that are all zeros, no filesystem, no environment
+// variables and no arguments. Their standard output and error are discarded. The same
+// applies to the WASI preview2 interfaces, see WASIPreview2Interface.
+const WASIModuleName = "wasi_snapshot_preview1"
+
+// WASIPreview2Version is the version of the WASI preview2 interfaces provided to the
+// modules importing them, any of its patch versions being accepted.
+const WASIPreview2Version = "0.2"
+
+var wasmMagic = []byte("\x00asm")
+
+// CoreModule returns the core module to instantiate for `code`: `code` itself when it is
+// a core module, or the main core module of a WASM component, which WASI preview2 builds
+// on. The main module is the one defining and exporting its memory; it is instantiated
+// on its own, its WASI preview2 imports being provided by the runtime directly instead
+// of through the component's adapters, so components must not rely on other core
+// modules than the preview1 adapter and the shims generated along with it.
+func CoreModule(code []byte) ([]byte, error) {
+	// core modules have layer 0 in bytes 6-7 of their preamble, components layer 1
+	if len(code) < 8 || !bytes.Equal(code[:4], wasmMagic) || code[6] != 0x01 || code[7] != 0x00 {
+		return code, nil
+	}
+
+	var main []byte
+	for pos := 8; pos < len(code); {
+		id := code[pos]
+		size, n := binary.Uvarint(code[pos+1:])
+		if n <= 0 {
+			return nil, fmt.Errorf("invalid wasm component: reading section size")
+		}
+		start := pos + 1 + n
+		end := start + int(size)
+		if size > uint64(len(code)) || end > len(code) {
+			return nil, fmt.Errorf("invalid wasm component: section %d overflows the component", id)
+		}
+		pos = end
+
+		const sectionCoreModule = 1
+		if id != sectionCoreModule {
+			continue
+		}
+		module := code[start:end]
+		isMain, err := exportsOwnMemory(module)
+		if err != nil {
+			return nil, fmt.Errorf("invalid wasm component: %w", err)
+		}
+		if !isMain {
+			continue
+		}
+		if main != nil {
+			return nil, fmt.Errorf("wasm component has several core modules defining their memory, expected a single main module")
+		}
+		main = module
+	}
+	if main == nil {
+		return nil, fmt.Errorf("wasm component has no core module defining its memory")
+	}
+	return main, nil
+}
+
+var errInvalidExportSection = fmt.Errorf("core module export section is invalid")
+
+// exportsOwnMemory returns whether the core module `code` defines a memory and exports it.
+func exportsOwnMemory(code []byte) (bool, error) {
+	const (
+		sectionMemory    = 5
+		sectionExport    = 7
+		exportKindMemory = 2
+	)
+	if len(code) < 8 || !bytes.Equal(code[:4], wasmMagic) {
+		return false, fmt.Errorf("core module is not a wasm module")
+	}
+
+	var definesMemory, exportsMemory bool
+	for pos := 8; pos < len(code); {
+		id := code[pos]
+		size, n := binary.Uvarint(code[pos+1:])
+		if n <= 0 || size > uint64(len(code)) || pos+1+n+int(size) > len(code) {
+			return false, fmt.Errorf("core module section %d is invalid", id)
+		}
+		content := code[pos+1+n : pos+1+n+int(size)]
+		pos += 1 + n + int(size)
+
+		switch id {
+		case sectionMemory:
+			count, _ := binary.Uvarint(content)
+			definesMemory = count != 0
+		case sectionExport:
+			count, n := binary.Uvarint(content)
+			if n <= 0 {
+				return false, errInvalidExportSection
+			}
+			content = content[n:]
+			for i := uint64(0); i < count; i++ {
+				nameLen, n := binary.Uvarint(content)
+				if n <= 0 || uint64(len(content)-n) < nameLen+1 {
+					return false, errInvalidExportSection
+				}
+				kindPos := n + int(nameLen)
+				_, indexLen := binary.Uvarint(content[kindPos+1:])
+				if indexLen <= 0 {
+					return false, errInvalidExportSection
+				}
+				exportsMemory = exportsMemory || content[kindPos] == exportKindMemory
+				content = content[kindPos+1+indexLen:]
+			}
+		}
+	}
+	return definesMemory && exportsMemory, nil
+}
+
+// UsesWASI returns whether a module importing functions from `importModules` uses WASI
+// preview1.
+func UsesWASI(importModules []string) bool {
+	for _, name := range importModules {
+		if name == WASIModuleName {
+			return true
+		}
+	}
+	return false
+}
+
+// UsesWASIPreview2 returns whether a module importing functions from `importModules`
+// uses WASI preview2 interfaces.
+func UsesWASIPreview2(importModules []string) bool {
+	for _, name := range importModules {
+		if strings.HasPrefix(name, "wasi:") {
+			return true
+		}
+	}
+	return false
+}
+
+// WASIPreview2Interface returns the name of the WASI preview2 interface imported as
+// `importModule`, without its version, for example `wasi:clocks/wall-clock` for
+// `wasi:clocks/wall-clock@0.2.0`, or an error if its version is not WASIPreview2Version.
+func WASIPreview2Interface(importModule string) (string, error) {
+	name, version, found := strings.Cut(importModule, "@")
+	if !found {
+		return "", fmt.Errorf("WASI interface %q has no version, expected version %s", importModule, WASIPreview2Version)
+	}
+	if version != WASIPreview2Version && !strings.HasPrefix(version, WASIPreview2Version+".") {
+		return "", fmt.Errorf("WASI interface %q is not supported, expected version %s", importModule, WASIPreview2Version)
+	}
+	return name, nil
+}
@@ -0,0 +1,49 @@
+package wasmtime
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+
+	wasmtime "github.com/bytecodealliance/wasmtime-go/v4"
+	"go.uber.org/zap"
+
+	"github.com/streamingfast/substreams/wasm"
+)
+
+// newCachedModule deserializes the module compiled from `wasmCode` from `cache`, or
+// compiles it and serializes it there. Entries are named after the hash of the code and
+// of whether it consumes fuel, wasmtime rejecting the ones compiled by another version.
+func newCachedModule(engine *wasmtime.Engine, cache *wasm.CompilationCache, wasmCode []byte, consumeFuel bool) (*wasmtime.Module, error) {
+	h := sha256.New()
+	h.Write(wasmCode)
+	if consumeFuel {
+		h.Write([]byte{1})
+	} else {
+		h.Write([]byte{0})
+	}
+	path := filepath.Join(cache.Dir("wasmtime"), hex.EncodeToString(h.Sum(nil)))
+
+	if cache.Get("wasmtime", path) {
+		module, err := wasmtime.NewModuleDeserializeFile(engine, path)
+		if err == nil {
+			return module, nil
+		}
+		zlog.Info("discarding compilation cache entry", zap.String("path", path), zap.Error(err))
+		cache.Remove(path)
+	}
+
+	module, err := wasmtime.NewModule(engine, wasmCode)
+	if err != nil {
+		return nil, err
+	}
+
+	serialized, err := module.Serialize()
+	if err == nil {
+		err = cache.Write(path, serialized)
+	}
+	if err != nil {
+		zlog.Warn("could not add compiled module to the compilation cache", zap.Error(err))
+	}
+	return module, nil
+}
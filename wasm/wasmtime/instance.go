@@ -39,6 +39,24 @@ func (i *instance) Cleanup(ctx context.Context) error {
 	return nil
 }
 
+// memoryPages returns the current size of the instance memory, in pages.
+func (i *instance) memoryPages() uint64 {
+	return i.Heap.memory.Size(i.wasmStore)
+}
+
+// checkMemoryLimit rejects instances whose memory is larger than `limit` pages, 0 being
+// unlimited. The wasmtime runtime cannot limit the memory growth during a call, so the
+// limit is checked at instantiation and after each call.
+func (i *instance) checkMemoryLimit(limit uint32) error {
+	if limit == 0 {
+		return nil
+	}
+	if pages := i.memoryPages(); pages > uint64(limit) {
+		return wasm.NewMemoryLimitError(pages, limit)
+	}
+	return nil
+}
+
 func (i *instance) newExtensionFunction(ctx context.Context, namespace, name string, f wasm.WASMExtension) interface{} {
 	return func(ptr, length, outputPtr int32) {
 		data := i.Heap.ReadBytes(ptr, length)
@@ -108,6 +126,18 @@ func (i *instance) registerLoggerImports(linker *wasmtime.Linker) error {
 	); err != nil {
 		return fmt.Errorf("registering println import: %w", err)
 	}
+	if err := linker.FuncWrap("logger", "log",
+		func(level int32, ptr int32, length int32, fieldsPtr int32, fieldsLength int32) {
+			message := i.Heap.ReadString(ptr, length)
+			var fields []byte
+			if fieldsLength != 0 {
+				fields = i.Heap.ReadBytes(fieldsPtr, fieldsLength)
+			}
+			i.CurrentCall.AppendStructuredLog(wasm.LogLevel(level), message, fields)
+		},
+	); err != nil {
+		return fmt.Errorf("registering log import: %w", err)
+	}
 	return nil
 }
 
@@ -117,6 +147,8 @@ func (i *instance) registerStateImports(linker *wasmtime.Linker) error {
 	functions["set_if_not_exists"] = i.setIfNotExists
 	functions["append"] = i.append
 	functions["delete_prefix"] = i.deletePrefix
+	functions["delete_range"] = i.deleteRange
+	functions["delete_range_pointers"] = i.deleteRangePointers
 	functions["add_bigint"] = i.addBigInt
 	functions["add_bigdecimal"] = i.addBigDecimal
 	functions["add_bigfloat"] = i.addBigDecimal
@@ -132,12 +164,23 @@ func (i *instance) registerStateImports(linker *wasmtime.Linker) error {
 	functions["set_max_float64"] = i.setMaxFloat64
 	functions["set_max_bigdecimal"] = i.setMaxBigDecimal
 	functions["set_max_bigfloat"] = i.setMaxBigDecimal
+	functions["set_sum_int64"] = i.setSumInt64
+	functions["set_sum_bigint"] = i.setSumBigInt
+	functions["set_sum_float64"] = i.setSumFloat64
+	functions["set_sum_bigdecimal"] = i.setSumBigDecimal
+	functions["bitwise_or_int64"] = i.bitwiseOrInt64
+	functions["bitwise_or_bigint"] = i.bitwiseOrBigInt
+	functions["bitwise_and_int64"] = i.bitwiseAndInt64
+	functions["bitwise_and_bigint"] = i.bitwiseAndBigInt
+	functions["cardinality_add"] = i.cardinalityAdd
 	functions["get_at"] = i.getAt
 	functions["get_first"] = i.getFirst
 	functions["get_last"] = i.getLast
 	functions["has_at"] = i.hasAt
 	functions["has_first"] = i.hasFirst
 	functions["has_last"] = i.hasLast
+	functions["scan_prefix"] = i.scanPrefix
+	functions["scan_range"] = i.scanRange
 
 	for n, f := range functions {
 		if err := linker.FuncWrap("state", n, f); err != nil {
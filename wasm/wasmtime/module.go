@@ -3,12 +3,16 @@ package wasmtime
 import (
 	"context"
 	"fmt"
+	"math"
 
 	wasmtime "github.com/bytecodealliance/wasmtime-go/v4"
 
 	"github.com/streamingfast/substreams/wasm"
 )
 
+// unlimitedFuel is the fuel given to each call when it is only metered, not limited
+const unlimitedFuel = math.MaxInt64 / 2
+
 type Module struct {
 	module   *wasmtime.Module
 	engine   *wasmtime.Engine
@@ -21,16 +25,35 @@ func init() {
 
 func newModule(ctx context.Context, wasmCode []byte, registry *wasm.Registry) (wasm.Module, error) {
 	cfg := wasmtime.NewConfig()
-	if registry.MaxFuel() != 0 {
+	consumeFuel := registry.MaxFuel() != 0 || registry.FuelMetering()
+	if consumeFuel {
 		cfg.SetConsumeFuel(true)
 	}
 	engine := wasmtime.NewEngineWithConfig(cfg)
 
-	module, err := wasmtime.NewModule(engine, wasmCode)
+	wasmCode, err := wasm.CoreModule(wasmCode)
+	if err != nil {
+		return nil, err
+	}
+	var module *wasmtime.Module
+	if cache := registry.CompilationCache(); cache != nil {
+		module, err = newCachedModule(engine, cache, wasmCode, consumeFuel)
+	} else {
+		module, err = wasmtime.NewModule(engine, wasmCode)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("creating new module: %w", err)
 	}
 
+	// the WASI implementation of wasmtime exposes the host clock and randomness
+	var importModules []string
+	for _, imp := range module.Imports() {
+		importModules = append(importModules, imp.Module())
+	}
+	if wasm.UsesWASI(importModules) || wasm.UsesWASIPreview2(importModules) {
+		return nil, fmt.Errorf("WASI modules are only supported on the wazero runtime")
+	}
+
 	// TODO: IF POSSIBLE, hook up all the wasm imports at this point, not at
 	// instantiation time.
 
@@ -79,6 +102,9 @@ func (m *Module) ExecuteNewCall(ctx context.Context, call *wasm.Call, cachedInst
 	}
 
 	maxFuel := m.registry.MaxFuel()
+	if maxFuel == 0 && m.registry.FuelMetering() {
+		maxFuel = unlimitedFuel
+	}
 	if maxFuel != 0 {
 		if remaining, _ := inst.wasmStore.ConsumeFuel(maxFuel); remaining != 0 {
 			inst.wasmStore.ConsumeFuel(remaining) // don't accumulate fuel from previous executions
@@ -109,7 +135,13 @@ func (m *Module) ExecuteNewCall(ctx context.Context, call *wasm.Call, cachedInst
 	}
 
 	inst.CurrentCall = call
+	fuelBefore, _ := inst.wasmStore.FuelConsumed()
 	_, err = entrypoint.Call(inst.wasmStore, args...)
+	if m.registry.FuelMetering() {
+		fuelAfter, _ := inst.wasmStore.FuelConsumed()
+		call.FuelConsumed = fuelAfter - fuelBefore
+	}
+	err = call.RecordMemory(inst.memoryPages(), m.registry.MaxMemoryPages(), false, err)
 	if err != nil {
 		return inst, fmt.Errorf("call: %w", err)
 	}
@@ -152,5 +184,9 @@ func (m *Module) newInstance(ctx context.Context) (*instance, error) {
 	heap := NewHeap(memory, alloc, dealloc, i.wasmStore)
 	i.Heap = heap
 	i.wasmInstance = instance
+	if err := i.checkMemoryLimit(m.registry.MaxMemoryPages()); err != nil {
+		i.Close(ctx)
+		return nil, err
+	}
 	return i, nil
 }
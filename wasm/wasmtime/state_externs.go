@@ -27,6 +27,19 @@ func (i *instance) deletePrefix(ord int64, keyPtr, keyLength int32) {
 	i.CurrentCall.DoDeletePrefix(uint64(ord), prefix)
 }
 
+func (i *instance) deleteRange(ord int64, lowKeyPtr, lowKeyLength, highKeyPtr, highKeyLength int32) {
+	lowKey := i.Heap.ReadString(lowKeyPtr, lowKeyLength)
+	highKey := i.Heap.ReadString(highKeyPtr, highKeyLength)
+	i.CurrentCall.DoDeleteRange(uint64(ord), lowKey, highKey)
+}
+
+func (i *instance) deleteRangePointers(ord int64, lowKeyPtr, lowKeyLength, highKeyPtr, highKeyLength, separatorPtr, separatorLength int32) {
+	lowKey := i.Heap.ReadString(lowKeyPtr, lowKeyLength)
+	highKey := i.Heap.ReadString(highKeyPtr, highKeyLength)
+	pointerSeparator := i.Heap.ReadString(separatorPtr, separatorLength)
+	i.CurrentCall.DoDeleteRangePointers(uint64(ord), lowKey, highKey, pointerSeparator)
+}
+
 func (i *instance) addBigInt(ord int64, keyPtr, keyLength, valPtr, valLength int32) {
 	key := i.Heap.ReadString(keyPtr, keyLength)
 	value := i.Heap.ReadString(valPtr, valLength)
@@ -93,6 +106,58 @@ func (i *instance) setMaxBigDecimal(ord int64, keyPtr, keyLength, valPtr, valLen
 	i.CurrentCall.DoSetMaxBigDecimal(uint64(ord), key, value)
 }
 
+func (i *instance) setSumInt64(ord int64, keyPtr, keyLength, valPtr, valLength int32) {
+	key := i.Heap.ReadString(keyPtr, keyLength)
+	value := i.Heap.ReadString(valPtr, valLength)
+	i.CurrentCall.DoSetSumInt64(uint64(ord), key, value)
+}
+
+func (i *instance) setSumBigInt(ord int64, keyPtr, keyLength, valPtr, valLength int32) {
+	key := i.Heap.ReadString(keyPtr, keyLength)
+	value := i.Heap.ReadString(valPtr, valLength)
+	i.CurrentCall.DoSetSumBigInt(uint64(ord), key, value)
+}
+
+func (i *instance) setSumFloat64(ord int64, keyPtr, keyLength, valPtr, valLength int32) {
+	key := i.Heap.ReadString(keyPtr, keyLength)
+	value := i.Heap.ReadString(valPtr, valLength)
+	i.CurrentCall.DoSetSumFloat64(uint64(ord), key, value)
+}
+
+func (i *instance) setSumBigDecimal(ord int64, keyPtr, keyLength, valPtr, valLength int32) {
+	key := i.Heap.ReadString(keyPtr, keyLength)
+	value := i.Heap.ReadString(valPtr, valLength)
+	i.CurrentCall.DoSetSumBigDecimal(uint64(ord), key, value)
+}
+
+func (i *instance) bitwiseOrInt64(ord int64, keyPtr, keyLength int32, value int64) {
+	key := i.Heap.ReadString(keyPtr, keyLength)
+	i.CurrentCall.DoBitwiseOrInt64(uint64(ord), key, value)
+}
+
+func (i *instance) bitwiseOrBigInt(ord int64, keyPtr, keyLength, valPtr, valLength int32) {
+	key := i.Heap.ReadString(keyPtr, keyLength)
+	value := i.Heap.ReadString(valPtr, valLength)
+	i.CurrentCall.DoBitwiseOrBigInt(uint64(ord), key, value)
+}
+
+func (i *instance) bitwiseAndInt64(ord int64, keyPtr, keyLength int32, value int64) {
+	key := i.Heap.ReadString(keyPtr, keyLength)
+	i.CurrentCall.DoBitwiseAndInt64(uint64(ord), key, value)
+}
+
+func (i *instance) bitwiseAndBigInt(ord int64, keyPtr, keyLength, valPtr, valLength int32) {
+	key := i.Heap.ReadString(keyPtr, keyLength)
+	value := i.Heap.ReadString(valPtr, valLength)
+	i.CurrentCall.DoBitwiseAndBigInt(uint64(ord), key, value)
+}
+
+func (i *instance) cardinalityAdd(ord int64, keyPtr, keyLength, valPtr, valLength int32) {
+	key := i.Heap.ReadString(keyPtr, keyLength)
+	item := i.Heap.ReadBytes(valPtr, valLength)
+	i.CurrentCall.DoCardinalityAdd(uint64(ord), key, item)
+}
+
 func (i *instance) getAt(storeIndex int32, ord int64, keyPtr, keyLength, outputPtr int32) int32 {
 	key := i.Heap.ReadString(keyPtr, keyLength)
 	value, found := i.CurrentCall.DoGetAt(int(storeIndex), uint64(ord), key)
@@ -129,6 +194,29 @@ func (i *instance) hasLast(storeIndex int32, keyPtr, keyLength int32) int32 {
 	return returnIfFound(found)
 }
 
+func (i *instance) scanPrefix(storeIndex int32, prefixPtr, prefixLength, limit, outputPtr int32) int32 {
+	prefix := i.Heap.ReadString(prefixPtr, prefixLength)
+	entries, count := i.CurrentCall.DoScanPrefix(int(storeIndex), prefix, uint64(uint32(limit)))
+	return writeToHeapIfAny(i, outputPtr, entries, count)
+}
+
+func (i *instance) scanRange(storeIndex int32, lowKeyPtr, lowKeyLength, highKeyPtr, highKeyLength, limit, outputPtr int32) int32 {
+	lowKey := i.Heap.ReadString(lowKeyPtr, lowKeyLength)
+	highKey := i.Heap.ReadString(highKeyPtr, highKeyLength)
+	entries, count := i.CurrentCall.DoScanRange(int(storeIndex), lowKey, highKey, uint64(uint32(limit)))
+	return writeToHeapIfAny(i, outputPtr, entries, count)
+}
+
+func writeToHeapIfAny(i *instance, outputPtr int32, value []byte, count int) int32 {
+	if count == 0 {
+		return 0
+	}
+	if err := writeOutputToHeap(i, outputPtr, value); err != nil {
+		i.CurrentCall.ReturnError(fmt.Errorf("writing output to heap: %w", err))
+	}
+	return int32(count)
+}
+
 func writeToHeapIfFound(i *instance, outputPtr int32, value []byte, found bool) int32 {
 	if !found {
 		return 0
@@ -0,0 +1,136 @@
+package wazero
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/tetratelabs/wazero"
+	"go.uber.org/zap"
+
+	"github.com/streamingfast/substreams/wasm"
+)
+
+var (
+	moduleCachesLock sync.Mutex
+	moduleCaches     = map[moduleCacheKey]*moduleCache{}
+)
+
+type moduleCacheKey struct {
+	cache *wasm.CompilationCache
+	hash  string
+}
+
+// moduleCache is the wazero compilation cache of a single module, storing its files in
+// their own directory of the compilation cache: wazero names its files privately, the
+// directory attributes them to the module. It is shared by all the runtimes of the
+// process, so that they also share the module compiled in memory.
+type moduleCache struct {
+	lock     sync.Mutex // held while compiling the module
+	cache    *wasm.CompilationCache
+	dir      string
+	wazCache wazero.CompilationCache
+}
+
+// moduleCompilationCache returns the wazero compilation cache of the module compiled from
+// `wasmCode`, with or without function listeners, in `cache`.
+func moduleCompilationCache(cache *wasm.CompilationCache, wasmCode []byte, withListeners bool) (*moduleCache, error) {
+	h := sha256.New()
+	h.Write(wasmCode)
+	h.Write([]byte{boolToByte(withListeners)})
+	key := moduleCacheKey{cache: cache, hash: hex.EncodeToString(h.Sum(nil))}
+
+	moduleCachesLock.Lock()
+	defer moduleCachesLock.Unlock()
+
+	if c := moduleCaches[key]; c != nil {
+		return c, nil
+	}
+	dir := filepath.Join(cache.Dir("wazero"), key.hash)
+	wazCache, err := wazero.NewCompilationCacheWithDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("creating wazero compilation cache: %w", err)
+	}
+	c := &moduleCache{cache: cache, dir: dir, wazCache: wazCache}
+	moduleCaches[key] = c
+	return c, nil
+}
+
+// compile compiles the module with `runtime`, configured with the wazero compilation cache
+// of the module. Compiling it without writing files in its directory is a hit, marking
+// its files as the most recently used, otherwise the written files are indexed in the
+// compilation cache and count a miss.
+func (c *moduleCache) compile(ctx context.Context, runtime wazero.Runtime, wasmCode []byte) (wazero.CompiledModule, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	before, err := dirFiles(c.dir)
+	if err != nil {
+		zlog.Debug("could not list compiled module files", zap.String("dir", c.dir), zap.Error(err))
+	}
+
+	mod, err := runtime.CompileModule(ctx, wasmCode)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := dirFiles(c.dir)
+	if err != nil {
+		zlog.Debug("could not index compiled module in the compilation cache", zap.String("dir", c.dir), zap.Error(err))
+		return mod, nil
+	}
+
+	var written, files []string
+	for path, size := range after {
+		files = append(files, path)
+		if beforeSize, found := before[path]; !found || beforeSize != size {
+			written = append(written, path)
+		}
+	}
+	if len(written) == 0 {
+		c.cache.Touch("wazero", files)
+		return mod, nil
+	}
+	c.cache.CountMiss("wazero")
+	for _, path := range written {
+		if err := c.cache.Put(path); err != nil {
+			zlog.Debug("could not index compiled module in the compilation cache", zap.String("path", path), zap.Error(err))
+		}
+	}
+	return mod, nil
+}
+
+// dirFiles returns the size of the files in `dir` and its subdirectories, by path.
+func dirFiles(dir string) (map[string]int64, error) {
+	out := map[string]int64{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		out[path] = info.Size()
+		return nil
+	})
+	return out, err
+}
+
+func boolToByte(b bool) byte {
+	if b {
+		return 1
+	}
+	return 0
+}
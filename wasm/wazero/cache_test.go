@@ -0,0 +1,81 @@
+package wazero
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/streamingfast/substreams/wasm"
+)
+
+func TestModule_CompilationCache(t *testing.T) {
+	ctx := context.Background()
+	code, err := os.ReadFile("../bench/substreams_wasm/substreams.wasm")
+	require.NoError(t, err)
+
+	cache, err := wasm.OpenCompilationCache(t.TempDir(), wasm.DefaultCompilationCacheMaxBytes)
+	require.NoError(t, err)
+
+	registry := wasm.NewRegistryWithRuntime("wazero", nil, 0, wasm.WithCompilationCache(cache))
+	module, err := registry.NewModule(ctx, code)
+	require.NoError(t, err)
+	require.NoError(t, module.Close(ctx))
+
+	files := cacheFiles(t, cache)
+	require.NotEmpty(t, files, "wazero must write the compiled module in the cache directory")
+	for _, file := range files {
+		assert.True(t, cache.Get("wazero", file), "the files written by wazero must be indexed")
+		assert.Equal(t, moduleDir(t, cache, files[0]), moduleDir(t, cache, file), "the files of a module must be in its own directory")
+	}
+
+	module, err = registry.NewModule(ctx, code)
+	require.NoError(t, err)
+	require.NoError(t, module.Close(ctx))
+	assert.Equal(t, files, cacheFiles(t, cache), "the module must be loaded from the cache")
+
+	// metered modules are compiled from the instrumented code, cached separately, each
+	// concurrent compile waiting for the first one to write the module
+	meteredRegistry := wasm.NewRegistryWithRuntime("wazero", nil, 0, wasm.WithCompilationCache(cache), wasm.WithFuelMetering())
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			module, err := meteredRegistry.NewModule(ctx, code)
+			if assert.NoError(t, err) {
+				assert.NoError(t, module.Close(ctx))
+			}
+		}()
+	}
+	wg.Wait()
+	allFiles := cacheFiles(t, cache)
+	assert.Equal(t, 2*len(files), len(allFiles))
+	for _, file := range allFiles {
+		assert.True(t, cache.Get("wazero", file), "the files written by wazero must be indexed")
+	}
+}
+
+func moduleDir(t *testing.T, cache *wasm.CompilationCache, file string) string {
+	t.Helper()
+	rel, err := filepath.Rel(cache.Dir("wazero"), file)
+	require.NoError(t, err)
+	return strings.Split(rel, string(filepath.Separator))[0]
+}
+
+func cacheFiles(t *testing.T, cache *wasm.CompilationCache) (out []string) {
+	t.Helper()
+	require.NoError(t, filepath.WalkDir(cache.Dir("wazero"), func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			out = append(out, path)
+		}
+		return err
+	}))
+	return out
+}
@@ -9,6 +9,7 @@ import (
 type instance struct {
 	api.Module
 	allocations []allocation
+	wasiClocks  *wasip2Clocks
 }
 
 type allocation struct {
@@ -22,6 +23,7 @@ func (i *instance) Cleanup(ctx context.Context) error {
 }
 
 func (i *instance) Close(ctx context.Context) error {
+	i.wasiClocks.release(i.Module)
 	return i.Module.Close(ctx)
 }
 
@@ -0,0 +1,539 @@
+package wazero
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// instructionsGlobalName is the name of the global exported by the modules returned by
+// instrumentInstructions, holding the number of instructions they executed.
+//
+// Instructions are counted the way wasmtime consumes fuel, so that both runtimes meter
+// the same amount of fuel: `unreachable`, `nop`, `block`, `loop`, `else`, `end`, `return`
+// and `drop` are free, all the other instructions cost one, and so does entering a function.
+const instructionsGlobalName = "__substreams_instructions"
+
+// growFailedGlobalName is the name of the global exported by the modules returned by
+// instrumentMemoryGrow, set to 1 when growing their memory failed.
+const growFailedGlobalName = "__substreams_grow_failed"
+
+const (
+	sectionImport = 2
+	sectionGlobal = 6
+	sectionExport = 7
+	sectionCode   = 10
+
+	importKindGlobal = 3
+	exportKindGlobal = 3
+)
+
+// sectionRanks orders the known sections the way the binary format requires them.
+var sectionRanks = map[byte]int{1: 1, 2: 2, 3: 3, 4: 4, 5: 5, 13: 6, 6: 7, 7: 8, 8: 9, 9: 10, 12: 11, 10: 12, 11: 13}
+
+type wasmSection struct {
+	id      byte
+	content []byte
+}
+
+// instrumentInstructions returns `code` with a mutable i64 global, exported as
+// instructionsGlobalName, to which each straight-line segment of the function bodies
+// adds its number of instructions when it is entered. Segments end on the control
+// instructions and on the branches out of them, so that the count is exact.
+func instrumentInstructions(code []byte) ([]byte, error) {
+	return instrumentModule(code, []addedGlobal{{name: instructionsGlobalName, valueType: valueTypeI64}}, instrumentBody)
+}
+
+// instrumentMemoryGrow returns `code` with a mutable i32 global, exported as
+// growFailedGlobalName, set to 1 when a `memory.grow` instruction fails, which happens
+// when the memory would go over the limit configured on the runtime.
+func instrumentMemoryGrow(code []byte) ([]byte, error) {
+	globals := []addedGlobal{
+		{valueType: valueTypeI32}, // result of the last memory.grow
+		{name: growFailedGlobalName, valueType: valueTypeI32},
+	}
+	return instrumentModule(code, globals, instrumentGrowBody)
+}
+
+// addedGlobal is a mutable global, initialized to 0, added to a module by an instrumentation.
+type addedGlobal struct {
+	name      string // name it is exported as, not exported when empty
+	valueType byte
+}
+
+const (
+	valueTypeI32 = 0x7f
+	valueTypeI64 = 0x7e
+)
+
+// instrumentModule returns `code` with `globals` added after its own globals, and each of
+// its function bodies rewritten by `rewriteBody`, which is given the index of the first
+// added global.
+func instrumentModule(code []byte, globals []addedGlobal, rewriteBody func(body []byte, globalIdx uint64) ([]byte, error)) ([]byte, error) {
+	if len(code) < 8 || !bytes.Equal(code[:4], []byte("\x00asm")) {
+		return nil, fmt.Errorf("not a wasm module")
+	}
+
+	var sections []*wasmSection
+	for pos := 8; pos < len(code); {
+		id := code[pos]
+		size, next, err := readULEB(code, pos+1)
+		if err != nil {
+			return nil, fmt.Errorf("reading section size: %w", err)
+		}
+		end := next + int(size)
+		if end > len(code) {
+			return nil, fmt.Errorf("section %d overflows the module", id)
+		}
+		sections = append(sections, &wasmSection{id: id, content: code[next:end]})
+		pos = end
+	}
+
+	var globalIdx uint64
+	for _, section := range sections {
+		switch section.id {
+		case sectionImport:
+			count, err := countImportedGlobals(section.content)
+			if err != nil {
+				return nil, fmt.Errorf("reading import section: %w", err)
+			}
+			globalIdx += count
+		case sectionGlobal:
+			count, _, err := readULEB(section.content, 0)
+			if err != nil {
+				return nil, fmt.Errorf("reading global section: %w", err)
+			}
+			globalIdx += count
+		}
+	}
+
+	var err error
+	for i, added := range globals {
+		global := []byte{added.valueType, 0x01} // mutable
+		if added.valueType == valueTypeI64 {
+			global = append(global, 0x42, 0x00, 0x0b) // i64.const 0
+		} else {
+			global = append(global, 0x41, 0x00, 0x0b) // i32.const 0
+		}
+		if sections, err = appendVectorEntry(sections, sectionGlobal, global); err != nil {
+			return nil, fmt.Errorf("adding global: %w", err)
+		}
+		if added.name == "" {
+			continue
+		}
+
+		export := appendULEB(nil, uint64(len(added.name)))
+		export = append(export, added.name...)
+		export = append(export, exportKindGlobal)
+		export = appendULEB(export, globalIdx+uint64(i))
+		if sections, err = appendVectorEntry(sections, sectionExport, export); err != nil {
+			return nil, fmt.Errorf("adding export: %w", err)
+		}
+	}
+
+	for _, section := range sections {
+		if section.id != sectionCode {
+			continue
+		}
+		if section.content, err = instrumentCodeSection(section.content, globalIdx, rewriteBody); err != nil {
+			return nil, fmt.Errorf("instrumenting code section: %w", err)
+		}
+	}
+
+	out := append([]byte(nil), code[:8]...)
+	for _, section := range sections {
+		out = append(out, section.id)
+		out = appendULEB(out, uint64(len(section.content)))
+		out = append(out, section.content...)
+	}
+	return out, nil
+}
+
+func countImportedGlobals(content []byte) (out uint64, err error) {
+	count, pos, err := readULEB(content, 0)
+	if err != nil {
+		return 0, err
+	}
+	for i := uint64(0); i < count; i++ {
+		for name := 0; name < 2; name++ {
+			var length uint64
+			if length, pos, err = readULEB(content, pos); err != nil {
+				return 0, err
+			}
+			pos += int(length)
+		}
+		if pos >= len(content) {
+			return 0, errUnexpectedEnd
+		}
+		kind := content[pos]
+		pos++
+		switch kind {
+		case 0x00: // function
+			pos, err = skipLEB(content, pos)
+		case 0x01: // table
+			pos, err = skipLimits(content, pos+1)
+		case 0x02: // memory
+			pos, err = skipLimits(content, pos)
+		case importKindGlobal:
+			out++
+			pos += 2
+		case 0x04: // tag
+			pos, err = skipLEB(content, pos+1)
+		default:
+			return 0, fmt.Errorf("unknown import kind %d", kind)
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	return out, nil
+}
+
+func skipLimits(content []byte, pos int) (int, error) {
+	if pos >= len(content) {
+		return 0, errUnexpectedEnd
+	}
+	flags := content[pos]
+	pos, err := skipLEB(content, pos+1)
+	if err != nil || flags&0x01 == 0 {
+		return pos, err
+	}
+	return skipLEB(content, pos)
+}
+
+// appendVectorEntry appends `entry` to the vector of the section `id`, creating the
+// section at its place when the module does not have it.
+func appendVectorEntry(sections []*wasmSection, id byte, entry []byte) ([]*wasmSection, error) {
+	for _, section := range sections {
+		if section.id != id {
+			continue
+		}
+		count, pos, err := readULEB(section.content, 0)
+		if err != nil {
+			return nil, err
+		}
+		content := appendULEB(nil, count+1)
+		content = append(content, section.content[pos:]...)
+		section.content = append(content, entry...)
+		return sections, nil
+	}
+
+	created := &wasmSection{id: id, content: append(appendULEB(nil, 1), entry...)}
+	for i, section := range sections {
+		if rank, known := sectionRanks[section.id]; known && rank > sectionRanks[id] {
+			return append(sections[:i], append([]*wasmSection{created}, sections[i:]...)...), nil
+		}
+	}
+	return append(sections, created), nil
+}
+
+func instrumentCodeSection(content []byte, globalIdx uint64, rewriteBody func(body []byte, globalIdx uint64) ([]byte, error)) ([]byte, error) {
+	count, pos, err := readULEB(content, 0)
+	if err != nil {
+		return nil, err
+	}
+	out := appendULEB(nil, count)
+	for i := uint64(0); i < count; i++ {
+		var size uint64
+		if size, pos, err = readULEB(content, pos); err != nil {
+			return nil, err
+		}
+		end := pos + int(size)
+		if end > len(content) {
+			return nil, errUnexpectedEnd
+		}
+		body, err := rewriteBody(content[pos:end], globalIdx)
+		if err != nil {
+			return nil, fmt.Errorf("function body %d: %w", i, err)
+		}
+		out = appendULEB(out, uint64(len(body)))
+		out = append(out, body...)
+		pos = end
+	}
+	return out, nil
+}
+
+// skipLocals returns the offset of the first instruction of a function body.
+func skipLocals(body []byte) (int, error) {
+	localGroups, pos, err := readULEB(body, 0)
+	if err != nil {
+		return 0, err
+	}
+	for i := uint64(0); i < localGroups; i++ {
+		if pos, err = skipLEB(body, pos); err != nil {
+			return 0, err
+		}
+		pos++ // value type
+	}
+	return checkEnd(body, pos)
+}
+
+// instrumentBody inserts, at the start of each segment of the body, the instructions
+// adding its number of instructions to the global `globalIdx`.
+func instrumentBody(body []byte, globalIdx uint64) ([]byte, error) {
+	pos, err := skipLocals(body)
+	if err != nil {
+		return nil, err
+	}
+
+	out := append([]byte(nil), body[:pos]...)
+	segmentStart := pos
+	instructions := int64(functionEntryCost)
+	flush := func(end int) {
+		if instructions != 0 {
+			out = append(out, 0x23) // global.get
+			out = appendULEB(out, globalIdx)
+			out = append(out, 0x42) // i64.const
+			out = appendSLEB(out, instructions)
+			out = append(out, 0x7c, 0x24) // i64.add, global.set
+			out = appendULEB(out, globalIdx)
+		}
+		out = append(out, body[segmentStart:end]...)
+		segmentStart = end
+		instructions = 0
+	}
+
+	depth := 0
+	for pos < len(body) {
+		op := body[pos]
+		next, err := skipInstruction(body, pos)
+		if err != nil {
+			return nil, fmt.Errorf("instruction 0x%02x at offset %d: %w", op, pos, err)
+		}
+		if !freeInstructions[op] {
+			instructions++
+		}
+		pos = next
+
+		switch op {
+		case 0x02, 0x03, 0x04: // block, loop, if
+			depth++
+		case 0x0b: // end
+			if depth == 0 {
+				flush(pos)
+				if pos != len(body) {
+					return nil, fmt.Errorf("trailing bytes after the function end")
+				}
+				return out, nil
+			}
+			depth--
+		case 0x00, 0x05, 0x0c, 0x0d, 0x0e, 0x0f, 0x12, 0x13: // unreachable, else, br, br_if, br_table, return, return_call, return_call_indirect
+		default:
+			continue
+		}
+		flush(pos)
+	}
+	return nil, errUnexpectedEnd
+}
+
+// instrumentGrowBody inserts, after each `memory.grow` of the body, the instructions
+// setting the global `globalIdx+1` to 1 when it returned -1, using the global `globalIdx`
+// to hold its result. It is applied after instrumentBody, so that the instructions it
+// adds are not counted.
+func instrumentGrowBody(body []byte, globalIdx uint64) ([]byte, error) {
+	pos, err := skipLocals(body)
+	if err != nil {
+		return nil, err
+	}
+
+	resultIdx, failedIdx := globalIdx, globalIdx+1
+	out := append([]byte(nil), body[:pos]...)
+	for pos < len(body) {
+		op := body[pos]
+		next, err := skipInstruction(body, pos)
+		if err != nil {
+			return nil, fmt.Errorf("instruction 0x%02x at offset %d: %w", op, pos, err)
+		}
+		out = append(out, body[pos:next]...)
+		pos = next
+		if op != 0x40 { // memory.grow
+			continue
+		}
+
+		out = append(out, 0x24) // global.set
+		out = appendULEB(out, resultIdx)
+		out = append(out, 0x23) // global.get
+		out = appendULEB(out, failedIdx)
+		out = append(out, 0x23) // global.get
+		out = appendULEB(out, resultIdx)
+		out = append(out, 0x41, 0x7f, 0x46, 0x72, 0x24) // i32.const -1, i32.eq, i32.or, global.set
+		out = appendULEB(out, failedIdx)
+		out = append(out, 0x23) // global.get
+		out = appendULEB(out, resultIdx)
+	}
+	return out, nil
+}
+
+const functionEntryCost = 1
+
+var freeInstructions = map[byte]bool{0x00: true, 0x01: true, 0x02: true, 0x03: true, 0x05: true, 0x0b: true, 0x0f: true, 0x1a: true}
+
+var errUnexpectedEnd = errors.New("unexpected end of content")
+
+// skipInstruction returns the offset of the instruction following the one at `pos`.
+func skipInstruction(code []byte, pos int) (int, error) {
+	op := code[pos]
+	pos++
+	var err error
+	switch {
+	case op == 0x02 || op == 0x03 || op == 0x04: // block type
+		if pos >= len(code) {
+			return 0, errUnexpectedEnd
+		}
+		switch code[pos] {
+		case 0x40, 0x7f, 0x7e, 0x7d, 0x7c, 0x7b, 0x70, 0x6f:
+			return pos + 1, nil
+		}
+		return skipLEB(code, pos)
+	case op == 0x0c || op == 0x0d || op == 0x10 || op == 0x12 || (op >= 0x20 && op <= 0x26) || op == 0xd2:
+		return skipLEB(code, pos)
+	case op == 0x11 || op == 0x13: // call_indirect, return_call_indirect
+		if pos, err = skipLEB(code, pos); err != nil {
+			return 0, err
+		}
+		return skipLEB(code, pos)
+	case op == 0x0e: // br_table
+		var count uint64
+		if count, pos, err = readULEB(code, pos); err != nil {
+			return 0, err
+		}
+		for i := uint64(0); i <= count; i++ {
+			if pos, err = skipLEB(code, pos); err != nil {
+				return 0, err
+			}
+		}
+		return pos, nil
+	case op == 0x1c: // select t*
+		var count uint64
+		if count, pos, err = readULEB(code, pos); err != nil {
+			return 0, err
+		}
+		return checkEnd(code, pos+int(count))
+	case op >= 0x28 && op <= 0x3e:
+		return skipMemArg(code, pos)
+	case op == 0x3f || op == 0x40 || op == 0x41 || op == 0x42: // memory.size, memory.grow, i32.const, i64.const
+		return skipLEB(code, pos)
+	case op == 0x43:
+		return checkEnd(code, pos+4)
+	case op == 0x44:
+		return checkEnd(code, pos+8)
+	case op == 0xd0: // ref.null
+		return checkEnd(code, pos+1)
+	case op <= 0x01 || op == 0x05 || op == 0x0b || op == 0x0f || op == 0x1a || op == 0x1b || (op >= 0x45 && op <= 0xc4) || op == 0xd1:
+		return pos, nil
+	case op == 0xfc:
+		return skipPrefixedFC(code, pos)
+	case op == 0xfd:
+		return skipPrefixedFD(code, pos)
+	case op == 0xfe:
+		var sub uint64
+		if sub, pos, err = readULEB(code, pos); err != nil {
+			return 0, err
+		}
+		if sub == 0x03 { // atomic.fence
+			return checkEnd(code, pos+1)
+		}
+		return skipMemArg(code, pos)
+	}
+	return 0, fmt.Errorf("unsupported opcode")
+}
+
+func skipPrefixedFC(code []byte, pos int) (int, error) {
+	sub, pos, err := readULEB(code, pos)
+	if err != nil {
+		return 0, err
+	}
+	var immediates int
+	switch {
+	case sub <= 7:
+	case sub == 9 || sub == 11 || sub == 13 || sub == 15 || sub == 16 || sub == 17:
+		immediates = 1
+	case sub == 8 || sub == 10 || sub == 12 || sub == 14:
+		immediates = 2
+	default:
+		return 0, fmt.Errorf("unsupported 0xfc opcode %d", sub)
+	}
+	for i := 0; i < immediates; i++ {
+		if pos, err = skipLEB(code, pos); err != nil {
+			return 0, err
+		}
+	}
+	return pos, nil
+}
+
+func skipPrefixedFD(code []byte, pos int) (int, error) {
+	sub, pos, err := readULEB(code, pos)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case sub <= 0x0b || sub == 0x5c || sub == 0x5d: // loads and stores
+		return skipMemArg(code, pos)
+	case sub == 0x0c || sub == 0x0d: // v128.const, i8x16.shuffle
+		return checkEnd(code, pos+16)
+	case sub >= 0x15 && sub <= 0x22: // extract and replace lane
+		return checkEnd(code, pos+1)
+	case sub >= 0x54 && sub <= 0x5b: // load and store lane
+		if pos, err = skipMemArg(code, pos); err != nil {
+			return 0, err
+		}
+		return checkEnd(code, pos+1)
+	}
+	return pos, nil
+}
+
+func skipMemArg(code []byte, pos int) (int, error) {
+	align, pos, err := readULEB(code, pos)
+	if err != nil {
+		return 0, err
+	}
+	if align&0x40 != 0 { // multi-memory index
+		if pos, err = skipLEB(code, pos); err != nil {
+			return 0, err
+		}
+	}
+	return skipLEB(code, pos)
+}
+
+func checkEnd(code []byte, pos int) (int, error) {
+	if pos > len(code) {
+		return 0, errUnexpectedEnd
+	}
+	return pos, nil
+}
+
+func readULEB(code []byte, pos int) (uint64, int, error) {
+	if pos > len(code) {
+		return 0, 0, errUnexpectedEnd
+	}
+	value, n := binary.Uvarint(code[pos:])
+	if n <= 0 {
+		return 0, 0, fmt.Errorf("invalid leb128 value at offset %d", pos)
+	}
+	return value, pos + n, nil
+}
+
+func skipLEB(code []byte, pos int) (int, error) {
+	for ; pos < len(code); pos++ {
+		if code[pos]&0x80 == 0 {
+			return pos + 1, nil
+		}
+	}
+	return 0, errUnexpectedEnd
+}
+
+func appendULEB(b []byte, v uint64) []byte {
+	return binary.AppendUvarint(b, v)
+}
+
+func appendSLEB(b []byte, v int64) []byte {
+	for {
+		c := byte(v & 0x7f)
+		v >>= 7
+		if (v == 0 && c&0x40 == 0) || (v == -1 && c&0x40 != 0) {
+			return append(b, c)
+		}
+		b = append(b, c|0x80)
+	}
+}
@@ -0,0 +1,73 @@
+package wazero
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+func TestInstrumentInstructions(t *testing.T) {
+	ctx := context.Background()
+	code := []byte{
+		0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00, // header
+		0x01, 0x05, 0x01, 0x60, 0x00, 0x01, 0x7f, // type: () -> i32
+		0x03, 0x02, 0x01, 0x00, // function
+		0x07, 0x07, 0x01, 0x03, 'r', 'u', 'n', 0x00, 0x00, // export "run"
+		0x0a, 0x09, 0x01, 0x07, 0x00, 0x41, 0x02, 0x41, 0x03, 0x6a, 0x0b, // i32.const 2, i32.const 3, i32.add, end
+	}
+
+	instrumented, err := instrumentInstructions(code)
+	require.NoError(t, err)
+
+	runtime := wazero.NewRuntime(ctx)
+	defer runtime.Close(ctx)
+	mod, err := runtime.Instantiate(ctx, instrumented)
+	require.NoError(t, err)
+
+	for i := uint64(1); i <= 2; i++ {
+		results, err := mod.ExportedFunction("run").Call(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{5}, results)
+		assert.Equal(t, 4*i, executedInstructions(mod))
+	}
+}
+
+func TestInstrumentMemoryGrow(t *testing.T) {
+	ctx := context.Background()
+	code := []byte{
+		0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00, // header
+		0x01, 0x06, 0x01, 0x60, 0x01, 0x7f, 0x01, 0x7f, // type: (i32) -> i32
+		0x03, 0x02, 0x01, 0x00, // function
+		0x05, 0x03, 0x01, 0x00, 0x01, // memory: 1 page
+		0x07, 0x08, 0x01, 0x04, 'g', 'r', 'o', 'w', 0x00, 0x00, // export "grow"
+		0x0a, 0x08, 0x01, 0x06, 0x00, 0x20, 0x00, 0x40, 0x00, 0x0b, // local.get 0, memory.grow, end
+	}
+
+	instrumented, err := instrumentInstructions(code)
+	require.NoError(t, err)
+	instrumented, err = instrumentMemoryGrow(instrumented)
+	require.NoError(t, err)
+
+	runtime := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().WithMemoryLimitPages(4))
+	defer runtime.Close(ctx)
+	mod, err := runtime.Instantiate(ctx, instrumented)
+	require.NoError(t, err)
+	growFailed := mod.ExportedGlobal(growFailedGlobalName).(api.MutableGlobal)
+
+	results, err := mod.ExportedFunction("grow").Call(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{1}, results)
+	assert.Equal(t, uint64(0), growFailed.Get())
+
+	results, err = mod.ExportedFunction("grow").Call(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0xffffffff), uint32(results[0]))
+	assert.Equal(t, uint64(1), growFailed.Get())
+
+	// the instructions added around memory.grow are not counted
+	assert.Equal(t, uint64(2*3), executedInstructions(mod))
+}
@@ -0,0 +1,46 @@
+package wazero
+
+import (
+	"context"
+
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental"
+)
+
+// listenerFactories notifies all its listeners, in order before the calls and in reverse
+// order after them, wazero only accepts a single function listener factory.
+type listenerFactories []experimental.FunctionListenerFactory
+
+// NewListener implements experimental.FunctionListenerFactory
+func (f listenerFactories) NewListener(def api.FunctionDefinition) experimental.FunctionListener {
+	var out listeners
+	for _, factory := range f {
+		if listener := factory.NewListener(def); listener != nil {
+			out = append(out, listener)
+		}
+	}
+	switch len(out) {
+	case 0:
+		return nil
+	case 1:
+		return out[0]
+	}
+	return out
+}
+
+type listeners []experimental.FunctionListener
+
+// Before implements experimental.FunctionListener
+func (l listeners) Before(ctx context.Context, mod api.Module, def api.FunctionDefinition, paramValues []uint64, stackIterator experimental.StackIterator) context.Context {
+	for _, listener := range l {
+		ctx = listener.Before(ctx, mod, def, paramValues, stackIterator)
+	}
+	return ctx
+}
+
+// After implements experimental.FunctionListener
+func (l listeners) After(ctx context.Context, mod api.Module, def api.FunctionDefinition, err error, resultValues []uint64) {
+	for i := len(l) - 1; i >= 0; i-- {
+		l[i].After(ctx, mod, def, err, resultValues)
+	}
+}
@@ -38,4 +38,34 @@ var loggerFuncs = []funcs{
 			return
 		}),
 	},
+	{
+		"log",
+		[]parm{i32, i32, i32, i32, i32}, // level, ptr, len, fields ptr, fields len
+		[]parm{},
+		api.GoModuleFunc(func(ctx context.Context, mod api.Module, stack []uint64) {
+			level := wasm.LogLevel(int32(stack[0]))
+			length := uint32(stack[2])
+			call := wasm.FromContext(ctx)
+
+			if call.ReachedLogsMaxByteCount() {
+				return
+			}
+
+			if length > wasm.MaxLogByteCount {
+				panic(fmt.Errorf("message to log is too big, max size is %s", humanize.IBytes(uint64(length))))
+			}
+
+			message := readString(mod, uint32(stack[1]), length)
+			var fields []byte
+			if fieldsLength := uint32(stack[4]); fieldsLength != 0 {
+				fields = readBytes(mod, uint32(stack[3]), fieldsLength)
+			}
+
+			if tracer.Enabled() {
+				zlog.Debug(message, zap.String("module_name", call.ModuleName), zap.Stringer("level", level), zap.ByteString("fields", fields))
+			}
+
+			call.AppendStructuredLog(level, message, fields)
+		}),
+	},
 }
@@ -7,6 +7,8 @@ import (
 
 	"github.com/tetratelabs/wazero"
 	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental"
+	"go.uber.org/zap"
 
 	"github.com/streamingfast/substreams/reqctx"
 	"github.com/streamingfast/substreams/wasm"
@@ -20,6 +22,12 @@ type Module struct {
 	wazModuleConfig wazero.ModuleConfig
 	hostModules     []wazero.CompiledModule
 	userModule      wazero.CompiledModule
+
+	wasiClocks     *wasip2Clocks
+	profiler       *profiler // nil unless profiling is enabled on the registry
+	profilesDir    string
+	fuelMetering   bool
+	maxMemoryPages uint32
 }
 
 func init() {
@@ -27,12 +35,49 @@ func init() {
 }
 
 func newModule(ctx context.Context, wasmCode []byte, registry *wasm.Registry) (wasm.Module, error) {
+	var err error
+	if wasmCode, err = wasm.CoreModule(wasmCode); err != nil {
+		return nil, err
+	}
+
+	var prof *profiler
+	var factories listenerFactories
+	if registry.ProfilingDir() != "" {
+		prof = newProfiler()
+		factories = append(factories, prof)
+	}
+	compileCtx := ctx
+	if len(factories) != 0 {
+		compileCtx = context.WithValue(ctx, experimental.FunctionListenerFactoryKey{}, factories)
+	}
+	if prof != nil || registry.FuelMetering() {
+		if wasmCode, err = instrumentInstructions(wasmCode); err != nil {
+			return nil, fmt.Errorf("instrumenting module: %w", err)
+		}
+	}
+	if registry.MaxMemoryPages() != 0 {
+		if wasmCode, err = instrumentMemoryGrow(wasmCode); err != nil {
+			return nil, fmt.Errorf("instrumenting module memory: %w", err)
+		}
+	}
+
 	// What's the effect of `ctx` here? Will it kill all the WASM if it cancels?
 	// TODO: try with: wazero.NewRuntimeConfigCompiler()
 	// TODO: try config := wazero.NewRuntimeConfig().WithCompilationCache(cache)
 	runtimeConfig := wazero.NewRuntimeConfigCompiler()
-	// TODO: can we use some caching in the RuntimeConfig so perhaps we reuse
-	// things across runtimes creations?
+	if maxPages := registry.MaxMemoryPages(); maxPages != 0 {
+		// modules declaring a larger minimum memory fail to compile
+		runtimeConfig = runtimeConfig.WithMemoryLimitPages(maxPages)
+	}
+	var cache *moduleCache
+	if registryCache := registry.CompilationCache(); registryCache != nil {
+		if cache, err = moduleCompilationCache(registryCache, wasmCode, len(factories) != 0); err != nil {
+			return nil, err
+		}
+		runtimeConfig = runtimeConfig.WithCompilationCache(cache.wazCache)
+	}
+	// TODO: where to `Close()` the `runtime` here?
+	// One runtime per request?
 	runtime := wazero.NewRuntimeWithConfig(ctx, runtimeConfig)
 	hostModules, err := addExtensionFunctions(ctx, runtime, registry)
 	if err != nil {
@@ -52,13 +97,23 @@ func newModule(ctx context.Context, wasmCode []byte, registry *wasm.Registry) (w
 	}
 	hostModules = append(hostModules, envModule, stateModule, loggerModule)
 
-	// TODO: where to `Close()` the `runtime` here?
-	// One runtime per request?
-	mod, err := runtime.CompileModule(ctx, wasmCode)
+	var mod wazero.CompiledModule
+	if cache != nil {
+		mod, err = cache.compile(compileCtx, runtime, wasmCode)
+	} else {
+		mod, err = runtime.CompileModule(compileCtx, wasmCode)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("creating new module: %w", err)
 	}
 
+	wasiClocks := &wasip2Clocks{}
+	wasiModules, moduleConfig, err := addWASI(ctx, runtime, mod, wazero.NewModuleConfig(), wasiClocks)
+	if err != nil {
+		return nil, err
+	}
+	hostModules = append(hostModules, wasiModules...)
+
 	funcs := mod.ExportedFunctions()
 	if funcs["alloc"] == nil {
 		return nil, fmt.Errorf("missing required functions: alloc")
@@ -68,14 +123,25 @@ func newModule(ctx context.Context, wasmCode []byte, registry *wasm.Registry) (w
 	}
 
 	return &Module{
-		wazModuleConfig: wazero.NewModuleConfig(),
+		wazModuleConfig: moduleConfig,
 		wazRuntime:      runtime,
 		userModule:      mod,
 		hostModules:     hostModules,
+		wasiClocks:      wasiClocks,
+		profiler:        prof,
+		profilesDir:     registry.ProfilingDir(),
+		fuelMetering:    registry.FuelMetering(),
+		maxMemoryPages:  registry.MaxMemoryPages(),
 	}, nil
 }
 
 func (m *Module) Close(ctx context.Context) error {
+	if m.profiler != nil {
+		if err := m.profiler.writeProfiles(m.profilesDir); err != nil {
+			zlog.Warn("error writing wasm execution profiles", zap.Error(err))
+		}
+	}
+
 	closeFuncs := []func(context.Context) error{
 		m.wazRuntime.Close,
 		m.userModule.Close,
@@ -97,7 +163,7 @@ func (m *Module) NewInstance(ctx context.Context) (out wasm.Instance, err error)
 		return nil, fmt.Errorf("could not instantiate wasm module: %w", err)
 	}
 
-	return &instance{Module: mod}, nil
+	return &instance{Module: mod, wasiClocks: m.wasiClocks}, nil
 }
 
 func (m *Module) ExecuteNewCall(ctx context.Context, call *wasm.Call, cachedInstance wasm.Instance, arguments []wasm.Argument) (out wasm.Instance, err error) {
@@ -110,7 +176,7 @@ func (m *Module) ExecuteNewCall(ctx context.Context, call *wasm.Call, cachedInst
 			return nil, fmt.Errorf("could not instantiate wasm module: %w", err)
 		}
 	}
-	inst := &instance{Module: mod}
+	inst := &instance{Module: mod, wasiClocks: m.wasiClocks}
 
 	f := mod.ExportedFunction(call.Entrypoint)
 	if f == nil {
@@ -138,7 +204,16 @@ func (m *Module) ExecuteNewCall(ctx context.Context, call *wasm.Call, cachedInst
 		}
 	}
 
+	growFailed, _ := mod.ExportedGlobal(growFailedGlobalName).(api.MutableGlobal)
+	if growFailed != nil {
+		growFailed.Set(0)
+	}
+	instructionsBefore := executedInstructions(mod)
 	_, err = f.Call(wasm.WithContext(withInstanceContext(ctx, inst), call), args...)
+	if m.fuelMetering {
+		call.FuelConsumed = executedInstructions(mod) - instructionsBefore
+	}
+	err = call.RecordMemory(uint64(mod.Memory().Size())/wasm.PageSize, m.maxMemoryPages, growFailed != nil && growFailed.Get() != 0, err)
 	if err != nil {
 		return inst, fmt.Errorf("call: %w", err)
 	}
@@ -0,0 +1,243 @@
+package wazero
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/pprof/profile"
+	"github.com/ianlancetaylor/demangle"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental"
+	"go.uber.org/zap"
+
+	"github.com/streamingfast/substreams/wasm"
+)
+
+// profiler is a wazero function listener recording, for each Substreams module, the
+// number of calls, the number of WASM instructions executed and the time spent in each
+// function of the user WASM code, keyed by call stack. Calls made outside of a module
+// execution (module start, allocations made to pass the arguments) are not recorded.
+//
+// The call and instruction counts only depend on the code and its inputs, the timings
+// vary from a run to another. Instructions are counted by the code added by
+// instrumentInstructions, which the profiled modules must be compiled from, the way the
+// fuel is metered (see instructionsGlobalName). Host
+// functions are not instrumented, their time is accounted to their caller.
+type profiler struct {
+	mu       sync.Mutex
+	funcs    []api.FunctionDefinition
+	funcIdx  map[api.FunctionDefinition]uint64
+	profiles map[string]map[string]*profileSample // module name -> stack key -> sample
+}
+
+type profileSample struct {
+	stack        []uint64 // function indices, leaf first
+	calls        int64
+	instructions int64
+	nanos        int64
+}
+
+// profileFrame is the function being called, carried in the context of the call so
+// that nested calls can find their caller.
+type profileFrame struct {
+	parent            *profileFrame
+	funcIdx           uint64
+	start             time.Time
+	startInstructions uint64
+	childNanos        int64
+	childInstructions int64
+}
+
+type profileFrameKey struct{}
+
+func newProfiler() *profiler {
+	return &profiler{
+		funcIdx:  map[api.FunctionDefinition]uint64{},
+		profiles: map[string]map[string]*profileSample{},
+	}
+}
+
+// NewListener implements experimental.FunctionListenerFactory
+func (p *profiler) NewListener(def api.FunctionDefinition) experimental.FunctionListener {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, found := p.funcIdx[def]; !found {
+		p.funcIdx[def] = uint64(len(p.funcs))
+		p.funcs = append(p.funcs, def)
+	}
+	return p
+}
+
+// Before implements experimental.FunctionListener
+func (p *profiler) Before(ctx context.Context, mod api.Module, def api.FunctionDefinition, _ []uint64, _ experimental.StackIterator) context.Context {
+	if _, ok := wasm.CallFromContext(ctx); !ok {
+		return ctx
+	}
+
+	p.mu.Lock()
+	idx := p.funcIdx[def]
+	p.mu.Unlock()
+
+	parent, _ := ctx.Value(profileFrameKey{}).(*profileFrame)
+	return context.WithValue(ctx, profileFrameKey{}, &profileFrame{
+		parent:            parent,
+		funcIdx:           idx,
+		start:             time.Now(),
+		startInstructions: executedInstructions(mod),
+	})
+}
+
+// After implements experimental.FunctionListener
+func (p *profiler) After(ctx context.Context, mod api.Module, _ api.FunctionDefinition, _ error, _ []uint64) {
+	frame, ok := ctx.Value(profileFrameKey{}).(*profileFrame)
+	if !ok {
+		return
+	}
+	call := wasm.FromContext(ctx)
+
+	elapsed := time.Since(frame.start).Nanoseconds()
+	instructions := int64(executedInstructions(mod) - frame.startInstructions)
+	if frame.parent != nil {
+		frame.parent.childNanos += elapsed
+		frame.parent.childInstructions += instructions
+	}
+
+	var stack []uint64
+	var key strings.Builder
+	for f := frame; f != nil; f = f.parent {
+		stack = append(stack, f.funcIdx)
+		fmt.Fprintf(&key, "%d;", f.funcIdx)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	samples := p.profiles[call.ModuleName]
+	if samples == nil {
+		samples = map[string]*profileSample{}
+		p.profiles[call.ModuleName] = samples
+	}
+	sample := samples[key.String()]
+	if sample == nil {
+		sample = &profileSample{stack: stack}
+		samples[key.String()] = sample
+	}
+	sample.calls++
+	sample.instructions += instructions - frame.childInstructions
+	sample.nanos += elapsed - frame.childNanos
+}
+
+// executedInstructions returns the number of instructions executed so far by the
+// instance `mod`, 0 when it was not instrumented.
+func executedInstructions(mod api.Module) uint64 {
+	if mod == nil {
+		return 0
+	}
+	global := mod.ExportedGlobal(instructionsGlobalName)
+	if global == nil {
+		return 0
+	}
+	return global.Get()
+}
+
+// profile returns the pprof profile of `moduleName`, nil if it was never executed.
+func (p *profiler) profile(moduleName string) *profile.Profile {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	samples := p.profiles[moduleName]
+	if len(samples) == 0 {
+		return nil
+	}
+
+	prof := &profile.Profile{
+		SampleType: []*profile.ValueType{
+			{Type: "calls", Unit: "count"},
+			{Type: "instructions", Unit: "count"},
+			{Type: "cpu", Unit: "nanoseconds"},
+		},
+		DefaultSampleType: "cpu",
+	}
+
+	locations := map[uint64]*profile.Location{}
+	location := func(funcIdx uint64) *profile.Location {
+		if loc, found := locations[funcIdx]; found {
+			return loc
+		}
+		fn := &profile.Function{
+			ID:         funcIdx + 1,
+			Name:       functionName(p.funcs[funcIdx]),
+			SystemName: p.funcs[funcIdx].Name(),
+		}
+		loc := &profile.Location{
+			ID:   funcIdx + 1,
+			Line: []profile.Line{{Function: fn}},
+		}
+		prof.Function = append(prof.Function, fn)
+		prof.Location = append(prof.Location, loc)
+		locations[funcIdx] = loc
+		return loc
+	}
+
+	for _, sample := range samples {
+		s := &profile.Sample{Value: []int64{sample.calls, sample.instructions, sample.nanos}}
+		for _, funcIdx := range sample.stack {
+			s.Location = append(s.Location, location(funcIdx))
+		}
+		prof.Sample = append(prof.Sample, s)
+	}
+
+	return prof
+}
+
+// writeProfiles writes the profile of each executed module to `<dir>/<module name>-<random>.pprof`.
+func (p *profiler) writeProfiles(dir string) error {
+	p.mu.Lock()
+	var moduleNames []string
+	for moduleName := range p.profiles {
+		moduleNames = append(moduleNames, moduleName)
+	}
+	p.mu.Unlock()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating profiles directory %q: %w", dir, err)
+	}
+
+	for _, moduleName := range moduleNames {
+		prof := p.profile(moduleName)
+		if prof == nil {
+			continue
+		}
+
+		f, err := os.CreateTemp(dir, moduleName+"-*.pprof")
+		if err != nil {
+			return fmt.Errorf("creating profile of module %q: %w", moduleName, err)
+		}
+		if err := prof.Write(f); err != nil {
+			f.Close()
+			return fmt.Errorf("writing profile of module %q: %w", moduleName, err)
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("closing profile of module %q: %w", moduleName, err)
+		}
+		zlog.Info("wrote wasm execution profile", zap.String("module_name", moduleName), zap.String("path", f.Name()))
+	}
+	return nil
+}
+
+// functionName returns the demangled name of `def`, Rust modules keep their mangled
+// symbol names in the name section.
+func functionName(def api.FunctionDefinition) string {
+	if name := def.Name(); name != "" {
+		return demangle.Filter(name)
+	}
+	if names := def.ExportNames(); len(names) != 0 {
+		return names[0]
+	}
+	return def.DebugName()
+}
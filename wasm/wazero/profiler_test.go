@@ -0,0 +1,65 @@
+package wazero
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/streamingfast/substreams/metrics"
+	"github.com/streamingfast/substreams/wasm"
+)
+
+func TestModule_Profiling(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("SUBSTREAMS_WASM_PROFILE_DIR", dir)
+
+	ctx := context.Background()
+	code, err := os.ReadFile("../bench/substreams_wasm/substreams.wasm")
+	require.NoError(t, err)
+	block, err := os.ReadFile("../bench/testdata/ethereum_mainnet_block_16021772.binpb")
+	require.NoError(t, err)
+
+	registry := wasm.NewRegistryWithRuntime("wazero", nil, 0)
+	module, err := registry.NewModule(ctx, code)
+	require.NoError(t, err)
+
+	input := wasm.NewSourceInput("sf.ethereum.type.v2.Block")
+	input.SetValue(block)
+	arguments := []wasm.Argument{input}
+	stats := metrics.NewReqStats(&metrics.Config{}, zap.NewNop())
+
+	for i := 0; i < 2; i++ {
+		call := wasm.NewCall(nil, "map_block", "map_block", stats, arguments)
+		instance, err := module.ExecuteNewCall(ctx, call, nil, arguments)
+		require.NoError(t, err)
+		require.NoError(t, instance.Close(ctx))
+	}
+	require.NoError(t, module.Close(ctx))
+
+	files, err := filepath.Glob(filepath.Join(dir, "map_block-*.pprof"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	f, err := os.Open(files[0])
+	require.NoError(t, err)
+	defer f.Close()
+	prof, err := profile.Parse(f)
+	require.NoError(t, err)
+
+	var entrypointCalls, instructions int64
+	for _, sample := range prof.Sample {
+		if len(sample.Location) == 1 && sample.Location[0].Line[0].Function.Name == "map_block" {
+			entrypointCalls += sample.Value[0]
+		}
+		instructions += sample.Value[1]
+	}
+	assert.Equal(t, int64(2), entrypointCalls)
+	assert.Greater(t, instructions, int64(0))
+	assert.Greater(t, len(prof.Function), 1)
+}
@@ -75,6 +75,33 @@ var stateFuncs = []funcs{
 			call.DoDeletePrefix(ord, prefix)
 		}),
 	},
+	{
+		"delete_range",
+		[]parm{i64, i32, i32, i32, i32},
+		[]parm{},
+		api.GoModuleFunc(func(ctx context.Context, mod api.Module, stack []uint64) {
+			ord := stack[0]
+			lowKey := readStringFromStack(mod, stack[1:])
+			highKey := readStringFromStack(mod, stack[3:])
+			call := wasm.FromContext(ctx)
+
+			call.DoDeleteRange(ord, lowKey, highKey)
+		}),
+	},
+	{
+		"delete_range_pointers",
+		[]parm{i64, i32, i32, i32, i32, i32, i32},
+		[]parm{},
+		api.GoModuleFunc(func(ctx context.Context, mod api.Module, stack []uint64) {
+			ord := stack[0]
+			lowKey := readStringFromStack(mod, stack[1:])
+			highKey := readStringFromStack(mod, stack[3:])
+			pointerSeparator := readStringFromStack(mod, stack[5:])
+			call := wasm.FromContext(ctx)
+
+			call.DoDeleteRangePointers(ord, lowKey, highKey, pointerSeparator)
+		}),
+	},
 	{
 		"add_bigint",
 		[]parm{i64, i32, i32, i32, i32},
@@ -232,6 +259,123 @@ var stateFuncs = []funcs{
 			call.DoSetMaxBigDecimal(ord, key, value)
 		}),
 	},
+	{
+		"set_sum_int64",
+		[]parm{i64, i32, i32, i32, i32},
+		[]parm{},
+		api.GoModuleFunc(func(ctx context.Context, mod api.Module, stack []uint64) {
+			ord := stack[0]
+			key := readStringFromStack(mod, stack[1:])
+			value := readStringFromStack(mod, stack[3:])
+			call := wasm.FromContext(ctx)
+
+			call.DoSetSumInt64(ord, key, value)
+		}),
+	},
+	{
+		"set_sum_bigint",
+		[]parm{i64, i32, i32, i32, i32},
+		[]parm{},
+		api.GoModuleFunc(func(ctx context.Context, mod api.Module, stack []uint64) {
+			ord := stack[0]
+			key := readStringFromStack(mod, stack[1:])
+			value := readStringFromStack(mod, stack[3:])
+			call := wasm.FromContext(ctx)
+
+			call.DoSetSumBigInt(ord, key, value)
+		}),
+	},
+	{
+		"set_sum_float64",
+		[]parm{i64, i32, i32, i32, i32},
+		[]parm{},
+		api.GoModuleFunc(func(ctx context.Context, mod api.Module, stack []uint64) {
+			ord := stack[0]
+			key := readStringFromStack(mod, stack[1:])
+			value := readStringFromStack(mod, stack[3:])
+			call := wasm.FromContext(ctx)
+
+			call.DoSetSumFloat64(ord, key, value)
+		}),
+	},
+	{
+		"set_sum_bigdecimal",
+		[]parm{i64, i32, i32, i32, i32},
+		[]parm{},
+		api.GoModuleFunc(func(ctx context.Context, mod api.Module, stack []uint64) {
+			ord := stack[0]
+			key := readStringFromStack(mod, stack[1:])
+			value := readStringFromStack(mod, stack[3:])
+			call := wasm.FromContext(ctx)
+
+			call.DoSetSumBigDecimal(ord, key, value)
+		}),
+	},
+	{
+		"bitwise_or_int64",
+		[]parm{i64, i32, i32, i64},
+		[]parm{},
+		api.GoModuleFunc(func(ctx context.Context, mod api.Module, stack []uint64) {
+			ord := stack[0]
+			key := readStringFromStack(mod, stack[1:])
+			value := int64(stack[3])
+			call := wasm.FromContext(ctx)
+
+			call.DoBitwiseOrInt64(ord, key, value)
+		}),
+	},
+	{
+		"bitwise_or_bigint",
+		[]parm{i64, i32, i32, i32, i32},
+		[]parm{},
+		api.GoModuleFunc(func(ctx context.Context, mod api.Module, stack []uint64) {
+			ord := stack[0]
+			key := readStringFromStack(mod, stack[1:])
+			value := readStringFromStack(mod, stack[3:])
+			call := wasm.FromContext(ctx)
+
+			call.DoBitwiseOrBigInt(ord, key, value)
+		}),
+	},
+	{
+		"bitwise_and_int64",
+		[]parm{i64, i32, i32, i64},
+		[]parm{},
+		api.GoModuleFunc(func(ctx context.Context, mod api.Module, stack []uint64) {
+			ord := stack[0]
+			key := readStringFromStack(mod, stack[1:])
+			value := int64(stack[3])
+			call := wasm.FromContext(ctx)
+
+			call.DoBitwiseAndInt64(ord, key, value)
+		}),
+	},
+	{
+		"bitwise_and_bigint",
+		[]parm{i64, i32, i32, i32, i32},
+		[]parm{},
+		api.GoModuleFunc(func(ctx context.Context, mod api.Module, stack []uint64) {
+			ord := stack[0]
+			key := readStringFromStack(mod, stack[1:])
+			value := readStringFromStack(mod, stack[3:])
+			call := wasm.FromContext(ctx)
+
+			call.DoBitwiseAndBigInt(ord, key, value)
+		}),
+	},
+	{
+		"cardinality_add",
+		[]parm{i64, i32, i32, i32, i32},
+		[]parm{},
+		api.GoModuleFunc(func(ctx context.Context, mod api.Module, stack []uint64) {
+			ord := stack[0]
+			key := readStringFromStack(mod, stack[1:])
+			item := readBytesFromStack(mod, stack[3:])
+			call := wasm.FromContext(ctx)
+
+			call.DoCardinalityAdd(ord, key, item)
+		}),
+	},
 
 	// Getter functions
 
@@ -321,6 +465,48 @@ var stateFuncs = []funcs{
 			setStack0Bool(stack, found)
 		}),
 	},
+	{
+		"scan_prefix",
+		[]parm{i32, i32, i32, i32, i32},
+		[]parm{i32},
+		api.GoModuleFunc(func(ctx context.Context, mod api.Module, stack []uint64) {
+			storeIndex := uint32(stack[0])
+			prefix := readStringFromStack(mod, stack[1:])
+			limit := uint32(stack[3])
+			outputPtr := uint32(stack[4])
+			call := wasm.FromContext(ctx)
+			inst := instanceFromContext(ctx)
+
+			entries, count := call.DoScanPrefix(int(storeIndex), prefix, uint64(limit))
+			setStackCountAndOutput(ctx, stack, call, count, inst, outputPtr, entries)
+		}),
+	},
+	{
+		"scan_range",
+		[]parm{i32, i32, i32, i32, i32, i32, i32},
+		[]parm{i32},
+		api.GoModuleFunc(func(ctx context.Context, mod api.Module, stack []uint64) {
+			storeIndex := uint32(stack[0])
+			lowKey := readStringFromStack(mod, stack[1:])
+			highKey := readStringFromStack(mod, stack[3:])
+			limit := uint32(stack[5])
+			outputPtr := uint32(stack[6])
+			call := wasm.FromContext(ctx)
+			inst := instanceFromContext(ctx)
+
+			entries, count := call.DoScanRange(int(storeIndex), lowKey, highKey, uint64(limit))
+			setStackCountAndOutput(ctx, stack, call, count, inst, outputPtr, entries)
+		}),
+	},
+}
+
+func setStackCountAndOutput(ctx context.Context, stack []uint64, call *wasm.Call, count int, inst *instance, outputPtr uint32, value []byte) {
+	if count != 0 {
+		if err := writeOutputToHeap(ctx, inst, outputPtr, value); err != nil {
+			call.ReturnError(fmt.Errorf("writing output to heap: %w", err))
+		}
+	}
+	stack[0] = uint64(count)
 }
 
 func setStackAndOutput(ctx context.Context, stack []uint64, call *wasm.Call, found bool, inst *instance, outputPtr uint32, value []byte) {
@@ -0,0 +1,64 @@
+package wazero
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+
+	"github.com/streamingfast/substreams/wasm"
+)
+
+// addWASI compiles the WASI host modules the user module imports: the WASI preview1 module
+// and the WASI preview2 interfaces, see addWASIPreview2. It returns them along with the
+// configuration the user module is then instantiated with. Its WASI layer is deterministic,
+// see wasm.WASIModuleName: for preview1, wazero defaults to fake clocks, no filesystem, no
+// arguments or environment, and discards the standard output and error, we only replace
+// its pseudo-random source with zeros.
+func addWASI(ctx context.Context, runtime wazero.Runtime, mod wazero.CompiledModule, config wazero.ModuleConfig, clocks *wasip2Clocks) ([]wazero.CompiledModule, wazero.ModuleConfig, error) {
+	var importModules []string
+	for _, f := range mod.ImportedFunctions() {
+		moduleName, _, _ := f.Import()
+		importModules = append(importModules, moduleName)
+	}
+	usesPreview1, usesPreview2 := wasm.UsesWASI(importModules), wasm.UsesWASIPreview2(importModules)
+	if !usesPreview1 && !usesPreview2 {
+		return nil, config, nil
+	}
+
+	funcs := mod.ExportedFunctions()
+	if funcs["_start"] != nil && funcs["_initialize"] == nil {
+		return nil, nil, fmt.Errorf("WASI command modules (exporting `_start`) are not supported, build the module as a reactor (exporting `_initialize`)")
+	}
+
+	var out []wazero.CompiledModule
+	if usesPreview1 {
+		wasiModule, err := wasi_snapshot_preview1.NewBuilder(runtime).Compile(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("compiling WASI host module: %w", err)
+		}
+		out = append(out, wasiModule)
+		config = config.WithRandSource(zeroReader{})
+	}
+	if usesPreview2 {
+		preview2Modules, err := addWASIPreview2(ctx, runtime, mod, clocks)
+		if err != nil {
+			return nil, nil, err
+		}
+		out = append(out, preview2Modules...)
+	}
+
+	// `_initialize` sets up the runtime of the module's language on each instantiation
+	if funcs["_initialize"] != nil {
+		config = config.WithStartFunctions("_initialize")
+	}
+	return out, config, nil
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
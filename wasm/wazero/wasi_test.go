@@ -0,0 +1,270 @@
+package wazero
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/streamingfast/substreams/metrics"
+	"github.com/streamingfast/substreams/wasm"
+)
+
+func TestModule_WASI(t *testing.T) {
+	ctx := context.Background()
+	registry := wasm.NewRegistryWithRuntime("wazero", nil, 0)
+	module, err := registry.NewModule(ctx, wasiTestModule(wasm.WASIModuleName, "_initialize"))
+	require.NoError(t, err)
+	defer module.Close(ctx)
+
+	input := wasm.NewSourceInput("sf.substreams.v1.Clock")
+	input.SetValue([]byte{0x01})
+	arguments := []wasm.Argument{input}
+	stats := metrics.NewReqStats(&metrics.Config{}, zap.NewNop())
+
+	var outputs [][]byte
+	for i := 0; i < 2; i++ {
+		call := wasm.NewCall(nil, "map_wasi", "map_wasi", stats, arguments)
+		instance, err := module.ExecuteNewCall(ctx, call, nil, arguments)
+		require.NoError(t, err)
+		require.NoError(t, instance.Close(ctx))
+		require.NoError(t, call.Err())
+		require.Len(t, call.Output(), 20)
+		outputs = append(outputs, call.Output())
+	}
+
+	out := outputs[0]
+	assert.Equal(t, out, outputs[1], "executions of a WASI module must be deterministic")
+	assert.Less(t, binary.LittleEndian.Uint64(out[0:8]), uint64(time.Now().Add(-24*time.Hour).UnixNano()), "the wall clock must not be the host's")
+	assert.Equal(t, make([]byte, 8), out[8:16], "random bytes must be zeros")
+	assert.Equal(t, uint32(42), binary.LittleEndian.Uint32(out[16:20]), "_initialize must run when instantiating")
+}
+
+func TestModule_WASIPreview2(t *testing.T) {
+	component := append([]byte("\x00asm\x0d\x00\x01\x00"), 0x00, 0x05, 0x04, 't', 'e', 's', 't') // custom section
+	component = append(component, 0x01, 0x08)
+	component = append(component, "\x00asm\x01\x00\x00\x00"...) // empty core module, like the shims
+	component = append(component, 0x01)
+	component = appendULEB(component, uint64(len(wasip2TestModule("0.2.0"))))
+	component = append(component, wasip2TestModule("0.2.0")...)
+
+	tests := []struct {
+		name string
+		code []byte
+	}{
+		{"core module", wasip2TestModule("0.2.0")},
+		{"patch version", wasip2TestModule("0.2.3")},
+		{"component", component},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			registry := wasm.NewRegistryWithRuntime("wazero", nil, 0)
+			module, err := registry.NewModule(ctx, test.code)
+			require.NoError(t, err)
+			defer module.Close(ctx)
+
+			input := wasm.NewSourceInput("sf.substreams.v1.Clock")
+			input.SetValue([]byte{0x01})
+			arguments := []wasm.Argument{input}
+			stats := metrics.NewReqStats(&metrics.Config{}, zap.NewNop())
+
+			var outputs [][]byte
+			for i := 0; i < 2; i++ {
+				call := wasm.NewCall(nil, "map_wasi", "map_wasi", stats, arguments)
+				instance, err := module.ExecuteNewCall(ctx, call, nil, arguments)
+				require.NoError(t, err)
+				require.NoError(t, instance.Close(ctx))
+				require.NoError(t, call.Err())
+				require.Len(t, call.Output(), 44)
+				outputs = append(outputs, call.Output())
+			}
+
+			out := outputs[0]
+			assert.Equal(t, out, outputs[1], "executions of a WASI module must be deterministic")
+			assert.Equal(t, uint64(1640995200), binary.LittleEndian.Uint64(out[0:8]), "the wall clock must be fake")
+			assert.Equal(t, uint32(1_000_000), binary.LittleEndian.Uint32(out[8:12]), "the wall clock must advance on each reading")
+			assert.Equal(t, []byte{32, 0, 0, 0, 8, 0, 0, 0}, out[16:24], "random bytes must be allocated with cabi_realloc")
+			assert.Equal(t, make([]byte, 8), out[24:32], "there must be no arguments")
+			assert.Equal(t, make([]byte, 8), out[32:40], "random bytes must be zeros")
+			assert.Equal(t, uint32(42), binary.LittleEndian.Uint32(out[40:44]), "_initialize must run when instantiating")
+
+			call := wasm.NewCall(nil, "stat_file", "stat_file", stats, arguments)
+			_, err = module.ExecuteNewCall(ctx, call, nil, arguments)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), `WASI function "[method]descriptor.stat" of "wasi:filesystem/types@`)
+		})
+	}
+}
+
+func TestModule_WASIUnsupported(t *testing.T) {
+	tests := []struct {
+		name        string
+		code        []byte
+		expectedErr string
+	}{
+		{"component without main module", []byte("\x00asm\x0d\x00\x01\x00"), "wasm component has no core module defining its memory"},
+		{"preview2 version", wasip2TestModule("0.3.0"), `WASI interface "wasi:clocks/wall-clock@0.3.0" is not supported, expected version 0.2`},
+		{"command", wasiTestModule(wasm.WASIModuleName, "_start"), "WASI command modules (exporting `_start`) are not supported"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			registry := wasm.NewRegistryWithRuntime("wazero", nil, 0)
+			_, err := registry.NewModule(context.Background(), test.code)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.expectedErr)
+		})
+	}
+}
+
+// wasiTestModule encodes a module importing `random_get` and `clock_time_get` from
+// `wasiModule`, whose `map_wasi` entrypoint outputs 20 bytes: the realtime clock, 8
+// random bytes and the value 42 stored by its start function, exported as `start`.
+func wasiTestModule(wasiModule string, start string) []byte {
+	const i32, i64 = 0x7f, 0x7e
+	funcType := func(params, results []byte) []byte {
+		out := append([]byte{0x60, byte(len(params))}, params...)
+		return append(append(out, byte(len(results))), results...)
+	}
+	name := func(s string) []byte { return append([]byte{byte(len(s))}, s...) }
+	vec := func(items ...[]byte) []byte {
+		out := []byte{byte(len(items))}
+		for _, item := range items {
+			out = append(out, item...)
+		}
+		return out
+	}
+	section := func(id byte, content []byte) []byte {
+		return append([]byte{id, byte(len(content))}, content...)
+	}
+	entry := func(parts ...[]byte) (out []byte) {
+		for _, part := range parts {
+			out = append(out, part...)
+		}
+		return out
+	}
+	body := func(code ...byte) []byte {
+		return append([]byte{byte(len(code) + 1), 0x00}, code...) // no locals
+	}
+
+	return entry(
+		[]byte("\x00asm\x01\x00\x00\x00"),
+		section(1, vec(
+			funcType([]byte{i32, i32}, []byte{i32}),      // 0: random_get
+			funcType([]byte{i32, i64, i32}, []byte{i32}), // 1: clock_time_get
+			funcType([]byte{i32, i32}, nil),              // 2: output, dealloc, map_wasi
+			funcType([]byte{i32}, []byte{i32}),           // 3: alloc
+			funcType(nil, nil),                           // 4: start
+		)),
+		section(2, vec(
+			entry(name(wasiModule), name("random_get"), []byte{0x00, 0}),
+			entry(name(wasiModule), name("clock_time_get"), []byte{0x00, 1}),
+			entry(name("env"), name("output"), []byte{0x00, 2}),
+		)),
+		section(3, vec([]byte{3}, []byte{2}, []byte{2}, []byte{4})),
+		section(5, vec([]byte{0x00, 1})),
+		section(7, vec(
+			entry(name("memory"), []byte{0x02, 0}),
+			entry(name("alloc"), []byte{0x00, 3}),
+			entry(name("dealloc"), []byte{0x00, 4}),
+			entry(name("map_wasi"), []byte{0x00, 5}),
+			entry(name(start), []byte{0x00, 6}),
+		)),
+		section(10, vec(
+			body(0x41, 0x80, 0x08, 0x0b), // alloc: 1024
+			body(0x0b),                   // dealloc
+			body( // map_wasi
+				0x41, 0, 0x42, 1, 0x41, 0, 0x10, 1, 0x1a, // clock_time_get(realtime, 1, 0)
+				0x41, 8, 0x41, 8, 0x10, 0, 0x1a, // random_get(8, 8)
+				0x41, 0, 0x41, 20, 0x10, 2, // output(0, 20)
+				0x0b,
+			),
+			body(0x41, 16, 0x41, 42, 0x36, 2, 0, 0x0b), // start: store 42 at 16
+		)),
+	)
+}
+
+// wasip2TestModule encodes a module importing WASI preview2 interfaces at `version`,
+// whose `map_wasi` entrypoint outputs 44 bytes: the second reading of the wall clock
+// (seconds and nanoseconds, then padding), the list of 8 random bytes allocated with
+// `cabi_realloc` at 32, the empty list of arguments, the random bytes and the value 42
+// stored by `_initialize`. Its `stat_file` entrypoint calls a filesystem function.
+func wasip2TestModule(version string) []byte {
+	const i32, i64 = 0x7f, 0x7e
+	funcType := func(params, results []byte) []byte {
+		out := append([]byte{0x60, byte(len(params))}, params...)
+		return append(append(out, byte(len(results))), results...)
+	}
+	name := func(s string) []byte { return append([]byte{byte(len(s))}, s...) }
+	vec := func(items ...[]byte) []byte {
+		out := []byte{byte(len(items))}
+		for _, item := range items {
+			out = append(out, item...)
+		}
+		return out
+	}
+	section := func(id byte, content []byte) []byte {
+		return append(appendULEB([]byte{id}, uint64(len(content))), content...)
+	}
+	entry := func(parts ...[]byte) (out []byte) {
+		for _, part := range parts {
+			out = append(out, part...)
+		}
+		return out
+	}
+	body := func(code ...byte) []byte {
+		return append([]byte{byte(len(code) + 1), 0x00}, code...) // no locals
+	}
+	iface := func(s string) []byte { return name("wasi:" + s + "@" + version) }
+
+	return entry(
+		[]byte("\x00asm\x01\x00\x00\x00"),
+		section(1, vec(
+			funcType([]byte{i32}, nil),                        // 0: now, get-arguments, drop
+			funcType([]byte{i64, i32}, nil),                   // 1: get-random-bytes
+			funcType([]byte{i32, i32}, nil),                   // 2: stat, output, dealloc, map_wasi, stat_file
+			funcType([]byte{i32}, []byte{i32}),                // 3: alloc
+			funcType(nil, nil),                                // 4: _initialize
+			funcType([]byte{i32, i32, i32, i32}, []byte{i32}), // 5: cabi_realloc
+		)),
+		section(2, vec(
+			entry(iface("clocks/wall-clock"), name("now"), []byte{0x00, 0}),
+			entry(iface("random/random"), name("get-random-bytes"), []byte{0x00, 1}),
+			entry(iface("cli/environment"), name("get-arguments"), []byte{0x00, 0}),
+			entry(iface("filesystem/types"), name("[method]descriptor.stat"), []byte{0x00, 2}),
+			entry(iface("filesystem/types"), name("[resource-drop]descriptor"), []byte{0x00, 0}),
+			entry(name("env"), name("output"), []byte{0x00, 2}),
+		)),
+		section(3, vec([]byte{3}, []byte{2}, []byte{2}, []byte{4}, []byte{5}, []byte{2})),
+		section(5, vec([]byte{0x00, 1})),
+		section(7, vec(
+			entry(name("memory"), []byte{0x02, 0}),
+			entry(name("alloc"), []byte{0x00, 6}),
+			entry(name("dealloc"), []byte{0x00, 7}),
+			entry(name("map_wasi"), []byte{0x00, 8}),
+			entry(name("_initialize"), []byte{0x00, 9}),
+			entry(name("cabi_realloc"), []byte{0x00, 10}),
+			entry(name("stat_file"), []byte{0x00, 11}),
+		)),
+		section(10, vec(
+			body(0x41, 0x80, 0x08, 0x0b), // alloc: 1024
+			body(0x0b),                   // dealloc
+			body( // map_wasi
+				0x41, 0, 0x10, 0, // wall-clock now(0)
+				0x41, 0, 0x10, 0, // wall-clock now(0)
+				0x42, 8, 0x41, 16, 0x10, 1, // get-random-bytes(8, 16)
+				0x41, 24, 0x10, 2, // get-arguments(24)
+				0x41, 0, 0x10, 4, // [resource-drop]descriptor(0)
+				0x41, 0, 0x41, 44, 0x10, 5, // output(0, 44)
+				0x0b,
+			),
+			body(0x41, 32, 0x42, 0x7f, 0x37, 3, 0, 0x41, 40, 0x41, 42, 0x36, 2, 0, 0x0b), // _initialize: store -1 at 32, 42 at 40
+			body(0x41, 32, 0x0b),                  // cabi_realloc: 32
+			body(0x41, 0, 0x41, 0, 0x10, 3, 0x0b), // stat_file: stat(0, 0)
+		)),
+	)
+}
@@ -0,0 +1,324 @@
+package wazero
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+
+	"github.com/streamingfast/substreams/wasm"
+)
+
+// The WASI preview2 functions are provided with the flattened signatures of their canonical
+// ABI lowering: results that do not fit a single value are written at a pointer passed as
+// the last parameter, and lists returned to the module are allocated with its exported
+// `cabi_realloc` function.
+//
+// The layer is deterministic, see wasm.WASIModuleName: the clocks are fake, the random
+// bytes are zeros, there are no arguments, environment variables or preopened directories,
+// the standard input is closed and writes to the standard output and error are discarded.
+// The functions that can only be used with a resource the module cannot get, like a file
+// descriptor or a socket, trap when called.
+
+// Handles of the resources given to the modules, which never hold more than one of each.
+const (
+	wasip2Stdin = iota + 1
+	wasip2Stdout
+	wasip2Stderr
+	wasip2Pollable
+)
+
+// wasip2OutputPermit is the number of bytes the output streams accept on each write.
+const wasip2OutputPermit = 4096
+
+type wasip2Func struct {
+	params  []parm
+	results []parm
+	f       func(ctx context.Context, mod api.Module, clocks *wasip2Clocks, stack []uint64)
+}
+
+// wasip2Funcs are the implemented WASI preview2 functions, by interface and function name.
+var wasip2Funcs = map[string]wasip2Func{
+	"wasi:clocks/wall-clock#now": {[]parm{i32}, nil, func(ctx context.Context, mod api.Module, clocks *wasip2Clocks, stack []uint64) {
+		nanos := clocks.walltime(mod)
+		writeDatetime(mod, uint32(stack[0]), uint64(nanos/1e9), uint32(nanos%1e9))
+	}},
+	"wasi:clocks/wall-clock#resolution": {[]parm{i32}, nil, func(ctx context.Context, mod api.Module, clocks *wasip2Clocks, stack []uint64) {
+		writeDatetime(mod, uint32(stack[0]), 0, wasip2ClockStep)
+	}},
+	"wasi:clocks/monotonic-clock#now": {nil, []parm{i64}, func(ctx context.Context, mod api.Module, clocks *wasip2Clocks, stack []uint64) {
+		stack[0] = uint64(clocks.nanotime(mod))
+	}},
+	"wasi:clocks/monotonic-clock#resolution": {nil, []parm{i64}, func(ctx context.Context, mod api.Module, clocks *wasip2Clocks, stack []uint64) {
+		stack[0] = wasip2ClockStep
+	}},
+	"wasi:clocks/monotonic-clock#subscribe-instant":  {[]parm{i64}, []parm{i32}, returnHandle(wasip2Pollable)},
+	"wasi:clocks/monotonic-clock#subscribe-duration": {[]parm{i64}, []parm{i32}, returnHandle(wasip2Pollable)},
+
+	// pollables are always ready, nothing ever blocks
+	"wasi:io/poll#[method]pollable.ready": {[]parm{i32}, []parm{i32}, func(ctx context.Context, mod api.Module, clocks *wasip2Clocks, stack []uint64) {
+		stack[0] = 1
+	}},
+	"wasi:io/poll#[method]pollable.block": {[]parm{i32}, nil, func(ctx context.Context, mod api.Module, clocks *wasip2Clocks, stack []uint64) {}},
+	"wasi:io/poll#poll": {[]parm{i32, i32, i32}, nil, func(ctx context.Context, mod api.Module, clocks *wasip2Clocks, stack []uint64) {
+		count := uint32(stack[1])
+		ready := make([]byte, 4*count)
+		for i := uint32(0); i < count; i++ {
+			binary.LittleEndian.PutUint32(ready[4*i:], i)
+		}
+		writeList(ctx, mod, uint32(stack[2]), ready, count, 4)
+	}},
+
+	// the standard input is closed, writes to the standard output and error are discarded
+	"wasi:io/streams#[method]input-stream.read":          {[]parm{i32, i64, i32}, nil, returnStreamClosed(4)},
+	"wasi:io/streams#[method]input-stream.blocking-read": {[]parm{i32, i64, i32}, nil, returnStreamClosed(4)},
+	"wasi:io/streams#[method]input-stream.skip":          {[]parm{i32, i64, i32}, nil, returnStreamClosed(8)},
+	"wasi:io/streams#[method]input-stream.blocking-skip": {[]parm{i32, i64, i32}, nil, returnStreamClosed(8)},
+	"wasi:io/streams#[method]input-stream.subscribe":     {[]parm{i32}, []parm{i32}, returnHandle(wasip2Pollable)},
+	"wasi:io/streams#[method]output-stream.check-write": {[]parm{i32, i32}, nil, func(ctx context.Context, mod api.Module, clocks *wasip2Clocks, stack []uint64) {
+		retPtr := uint32(stack[1])
+		writeUint8(mod, retPtr, 0)
+		writeUint64(mod, retPtr+8, wasip2OutputPermit)
+	}},
+	"wasi:io/streams#[method]output-stream.write":                           {[]parm{i32, i32, i32, i32}, nil, returnOk(3)},
+	"wasi:io/streams#[method]output-stream.blocking-write-and-flush":        {[]parm{i32, i32, i32, i32}, nil, returnOk(3)},
+	"wasi:io/streams#[method]output-stream.flush":                           {[]parm{i32, i32}, nil, returnOk(1)},
+	"wasi:io/streams#[method]output-stream.blocking-flush":                  {[]parm{i32, i32}, nil, returnOk(1)},
+	"wasi:io/streams#[method]output-stream.write-zeroes":                    {[]parm{i32, i64, i32}, nil, returnOk(2)},
+	"wasi:io/streams#[method]output-stream.blocking-write-zeroes-and-flush": {[]parm{i32, i64, i32}, nil, returnOk(2)},
+	"wasi:io/streams#[method]output-stream.splice":                          {[]parm{i32, i32, i64, i32}, nil, returnStreamClosed(8)},
+	"wasi:io/streams#[method]output-stream.blocking-splice":                 {[]parm{i32, i32, i64, i32}, nil, returnStreamClosed(8)},
+	"wasi:io/streams#[method]output-stream.subscribe":                       {[]parm{i32}, []parm{i32}, returnHandle(wasip2Pollable)},
+
+	"wasi:cli/environment#get-environment": {[]parm{i32}, nil, returnEmptyList},
+	"wasi:cli/environment#get-arguments":   {[]parm{i32}, nil, returnEmptyList},
+	"wasi:cli/environment#initial-cwd":     {[]parm{i32}, nil, returnNone},
+	"wasi:cli/exit#exit": {[]parm{i32}, nil, func(ctx context.Context, mod api.Module, clocks *wasip2Clocks, stack []uint64) {
+		status := "ok"
+		if stack[0] != 0 {
+			status = "error"
+		}
+		panic(fmt.Errorf("module called WASI exit with status %s", status))
+	}},
+	"wasi:cli/stdin#get-stdin":                     {nil, []parm{i32}, returnHandle(wasip2Stdin)},
+	"wasi:cli/stdout#get-stdout":                   {nil, []parm{i32}, returnHandle(wasip2Stdout)},
+	"wasi:cli/stderr#get-stderr":                   {nil, []parm{i32}, returnHandle(wasip2Stderr)},
+	"wasi:cli/terminal-stdin#get-terminal-stdin":   {[]parm{i32}, nil, returnNone},
+	"wasi:cli/terminal-stdout#get-terminal-stdout": {[]parm{i32}, nil, returnNone},
+	"wasi:cli/terminal-stderr#get-terminal-stderr": {[]parm{i32}, nil, returnNone},
+
+	"wasi:random/random#get-random-bytes":            {[]parm{i64, i32}, nil, returnZeroBytes},
+	"wasi:random/random#get-random-u64":              {nil, []parm{i64}, returnZero},
+	"wasi:random/insecure#get-insecure-random-bytes": {[]parm{i64, i32}, nil, returnZeroBytes},
+	"wasi:random/insecure#get-insecure-random-u64":   {nil, []parm{i64}, returnZero},
+	"wasi:random/insecure-seed#insecure-seed": {[]parm{i32}, nil, func(ctx context.Context, mod api.Module, clocks *wasip2Clocks, stack []uint64) {
+		retPtr := uint32(stack[0])
+		writeUint64(mod, retPtr, 0)
+		writeUint64(mod, retPtr+8, 0)
+	}},
+
+	"wasi:filesystem/preopens#get-directories": {[]parm{i32}, nil, returnEmptyList},
+	"wasi:filesystem/types#filesystem-error-code": {[]parm{i32, i32}, nil, func(ctx context.Context, mod api.Module, clocks *wasip2Clocks, stack []uint64) {
+		writeUint8(mod, uint32(stack[1]), 0) // none
+	}},
+}
+
+// addWASIPreview2 compiles a host module for each of the WASI preview2 interfaces
+// imported by `mod`, providing the functions of wasip2Funcs. The other functions it
+// imports from them are provided too, so that it can be instantiated: resources drops
+// do nothing, and the others trap when called.
+func addWASIPreview2(ctx context.Context, runtime wazero.Runtime, mod wazero.CompiledModule, clocks *wasip2Clocks) ([]wazero.CompiledModule, error) {
+	builders := map[string]wazero.HostModuleBuilder{}
+	var moduleNames []string
+	for _, def := range mod.ImportedFunctions() {
+		moduleName, name, _ := def.Import()
+		if !strings.HasPrefix(moduleName, "wasi:") {
+			continue
+		}
+		iface, err := wasm.WASIPreview2Interface(moduleName)
+		if err != nil {
+			return nil, err
+		}
+
+		builder := builders[moduleName]
+		if builder == nil {
+			builder = runtime.NewHostModuleBuilder(moduleName)
+			builders[moduleName] = builder
+			moduleNames = append(moduleNames, moduleName)
+		}
+
+		params, results := def.ParamTypes(), def.ResultTypes()
+		var f api.GoModuleFunc
+		if impl, found := wasip2Funcs[iface+"#"+name]; found {
+			if !slices.Equal(params, impl.params) || !slices.Equal(results, impl.results) {
+				return nil, fmt.Errorf("WASI function %q of %q is imported with an invalid signature", name, moduleName)
+			}
+			f = func(ctx context.Context, mod api.Module, stack []uint64) { impl.f(ctx, mod, clocks, stack) }
+		} else if strings.HasPrefix(name, "[resource-drop]") {
+			f = func(ctx context.Context, mod api.Module, stack []uint64) {}
+		} else {
+			f = func(ctx context.Context, mod api.Module, stack []uint64) {
+				panic(fmt.Errorf("WASI function %q of %q is not available to substreams modules", name, moduleName))
+			}
+		}
+		builder.NewFunctionBuilder().WithGoModuleFunction(f, params, results).Export(name)
+	}
+
+	var out []wazero.CompiledModule
+	for _, moduleName := range moduleNames {
+		hostModule, err := builders[moduleName].Compile(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("compiling WASI host module %q: %w", moduleName, err)
+		}
+		out = append(out, hostModule)
+	}
+	return out, nil
+}
+
+// wasip2ClockStep is the duration, in nanoseconds, by which the fake clocks advance on
+// each reading, as the clocks of wazero's WASI preview1 implementation.
+const wasip2ClockStep = 1_000_000
+
+// wasip2FakeEpochNanos is the first time read on the fake wall clock, midnight UTC
+// 2022-01-01, as the wall clock of wazero's WASI preview1 implementation.
+const wasip2FakeEpochNanos = 1640995200 * 1_000_000_000
+
+// wasip2Clocks holds the fake clocks of the instances of a module, each instance reading
+// the same sequence of times. Instances release their clocks when they are closed.
+type wasip2Clocks struct {
+	lock   sync.Mutex
+	clocks map[api.Module]*wasip2Clock
+}
+
+type wasip2Clock struct {
+	readings int64
+}
+
+func (c *wasip2Clocks) walltime(mod api.Module) int64 {
+	return wasip2FakeEpochNanos + c.read(mod)*wasip2ClockStep
+}
+
+func (c *wasip2Clocks) nanotime(mod api.Module) int64 {
+	return c.read(mod) * wasip2ClockStep
+}
+
+// read returns the number of times the clocks of `mod` were read before.
+func (c *wasip2Clocks) read(mod api.Module) int64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.clocks == nil {
+		c.clocks = map[api.Module]*wasip2Clock{}
+	}
+	clock := c.clocks[mod]
+	if clock == nil {
+		clock = &wasip2Clock{}
+		c.clocks[mod] = clock
+	}
+	clock.readings++
+	return clock.readings - 1
+}
+
+func (c *wasip2Clocks) release(mod api.Module) {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.clocks, mod)
+}
+
+func returnHandle(handle uint64) func(ctx context.Context, mod api.Module, clocks *wasip2Clocks, stack []uint64) {
+	return func(ctx context.Context, mod api.Module, clocks *wasip2Clocks, stack []uint64) {
+		stack[0] = handle
+	}
+}
+
+func returnZero(ctx context.Context, mod api.Module, clocks *wasip2Clocks, stack []uint64) {
+	stack[0] = 0
+}
+
+func returnNone(ctx context.Context, mod api.Module, clocks *wasip2Clocks, stack []uint64) {
+	writeUint8(mod, uint32(stack[0]), 0)
+}
+
+func returnEmptyList(ctx context.Context, mod api.Module, clocks *wasip2Clocks, stack []uint64) {
+	writeList(ctx, mod, uint32(stack[0]), nil, 0, 1)
+}
+
+func returnZeroBytes(ctx context.Context, mod api.Module, clocks *wasip2Clocks, stack []uint64) {
+	length := stack[0]
+	if length > uint64(mod.Memory().Size()) {
+		panic(fmt.Errorf("requested %d random bytes, more than the memory of the module", length))
+	}
+	writeList(ctx, mod, uint32(stack[1]), make([]byte, length), uint32(length), 1)
+}
+
+// returnOk returns a `result<_, stream-error>` holding `ok`, written at the pointer
+// passed as the parameter `retPtrIdx`.
+func returnOk(retPtrIdx int) func(ctx context.Context, mod api.Module, clocks *wasip2Clocks, stack []uint64) {
+	return func(ctx context.Context, mod api.Module, clocks *wasip2Clocks, stack []uint64) {
+		writeUint8(mod, uint32(stack[retPtrIdx]), 0)
+	}
+}
+
+// returnStreamClosed returns a `result<T, stream-error>` holding the `closed` error,
+// written at the pointer passed as the last parameter, the error being at
+// `errOffset` of the result.
+func returnStreamClosed(errOffset uint32) func(ctx context.Context, mod api.Module, clocks *wasip2Clocks, stack []uint64) {
+	return func(ctx context.Context, mod api.Module, clocks *wasip2Clocks, stack []uint64) {
+		retPtr := uint32(stack[len(stack)-1])
+		writeUint8(mod, retPtr, 1)
+		writeUint8(mod, retPtr+errOffset, 1) // closed
+	}
+}
+
+func writeDatetime(mod api.Module, ptr uint32, seconds uint64, nanoseconds uint32) {
+	writeUint64(mod, ptr, seconds)
+	writeUint32(mod, ptr+8, nanoseconds)
+}
+
+// writeList copies `data`, holding `count` elements aligned on `align`, to memory allocated
+// with the `cabi_realloc` function of `mod`, and writes the resulting list at `ptr`.
+func writeList(ctx context.Context, mod api.Module, ptr uint32, data []byte, count uint32, align uint32) {
+	var dataPtr uint32
+	if len(data) != 0 {
+		realloc := mod.ExportedFunction("cabi_realloc")
+		if realloc == nil {
+			panic(fmt.Errorf("module does not export the `cabi_realloc` function required to return lists from WASI functions"))
+		}
+		stack := []uint64{0, 0, uint64(align), uint64(len(data))}
+		if err := realloc.CallWithStack(ctx, stack); err != nil {
+			panic(fmt.Errorf("allocating WASI list: %w", err))
+		}
+		dataPtr = uint32(stack[0])
+		if !mod.Memory().Write(dataPtr, data) {
+			panic(fmt.Errorf("writing WASI list out of memory bounds"))
+		}
+	}
+	writeUint32(mod, ptr, dataPtr)
+	writeUint32(mod, ptr+4, count)
+}
+
+func writeUint8(mod api.Module, ptr uint32, v byte) {
+	if !mod.Memory().WriteByte(ptr, v) {
+		panic(fmt.Errorf("writing WASI result out of memory bounds"))
+	}
+}
+
+func writeUint32(mod api.Module, ptr uint32, v uint32) {
+	if !mod.Memory().WriteUint32Le(ptr, v) {
+		panic(fmt.Errorf("writing WASI result out of memory bounds"))
+	}
+}
+
+func writeUint64(mod api.Module, ptr uint32, v uint64) {
+	if !mod.Memory().WriteUint64Le(ptr, v) {
+		panic(fmt.Errorf("writing WASI result out of memory bounds"))
+	}
+}